@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/mkbrr/torrent"
+)
+
+// benchOptions encapsulates command-line flag values for the bench command
+type benchOptions struct {
+	size         string
+	pieceLength  uint
+	workers      []int
+	comparePaths bool
+	content      bool
+}
+
+var benchOpts benchOptions
+
+var benchCmd = &cobra.Command{
+	Use:   "bench [dir]",
+	Short: "Measure piece hashing throughput of a disk",
+	Long: `Writes a temporary test file and hashes it with 1, 2, 4 and 8 workers and with the
+automatic worker count, printing the throughput of each so you can pick --workers
+for your hardware. The file is written to the given directory, or the system temp
+directory, and removed afterwards.
+
+Use --compare-paths with two or more directories on different storage devices to
+compare them. A test file that fits in memory may be read back from the page cache;
+use a --size larger than the free memory to measure the disk itself.
+
+Use --content to hash existing files or directories in place instead of a test file,
+e.g. a release you are about to create a torrent for. Nothing is written; only the
+first run is likely to read from the disk, later runs from the page cache.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if benchOpts.comparePaths {
+			return cobra.MinimumNArgs(2)(cmd, args)
+		}
+		if benchOpts.content {
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		return cobra.MaximumNArgs(1)(cmd, args)
+	},
+	RunE:                       runBench,
+	DisableFlagsInUseLine:      true,
+	SuggestionsMinimumDistance: 1,
+	SilenceUsage:               true,
+}
+
+func init() {
+	benchCmd.Flags().SortFlags = false
+	benchCmd.Flags().StringVar(&benchOpts.size, "size", "1GiB", "size of the test file, e.g. 500MB or 4GiB")
+	benchCmd.Flags().UintVarP(&benchOpts.pieceLength, "piece-length", "l", 20, "piece length as 2^n bytes (14-27)")
+	benchCmd.Flags().IntSliceVar(&benchOpts.workers, "workers", nil, "worker counts to measure, 0 for automatic (default 1,2,4,8,0)")
+	benchCmd.Flags().BoolVar(&benchOpts.comparePaths, "compare-paths", false, "benchmark each of two or more directories given as arguments")
+	benchCmd.Flags().BoolVar(&benchOpts.content, "content", false, "hash the files at the given paths instead of writing a test file")
+
+	benchCmd.SetUsageTemplate(`Usage:
+  {{.CommandPath}} [dir] [flags]
+  {{.CommandPath}} --compare-paths <dir1> <dir2> [flags]
+  {{.CommandPath}} --content <path> [flags]
+
+Flags:
+{{.LocalFlags.FlagUsages | trimTrailingWhitespaces}}
+`)
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	if benchOpts.content && cmd.Flags().Changed("size") {
+		return fmt.Errorf("--size cannot be used with --content")
+	}
+	size, err := torrent.ParseByteRate(benchOpts.size)
+	if err != nil {
+		return fmt.Errorf("invalid --size: %w", err)
+	}
+	if size <= 0 {
+		return fmt.Errorf("invalid --size %q: must be greater than zero", benchOpts.size)
+	}
+
+	dirs := args
+	if len(dirs) == 0 {
+		dirs = []string{""}
+	}
+
+	display := torrent.NewDisplay(torrent.NewFormatter(false))
+	results := make([]*torrent.BenchResult, 0, len(dirs))
+	for _, dir := range dirs {
+		opts := torrent.BenchOptions{
+			Dir:            dir,
+			Size:           size,
+			PieceLengthExp: benchOpts.pieceLength,
+			Workers:        benchOpts.workers,
+		}
+		if benchOpts.content {
+			opts = torrent.BenchOptions{
+				ContentPath:    dir,
+				PieceLengthExp: benchOpts.pieceLength,
+				Workers:        benchOpts.workers,
+			}
+		}
+		result, err := torrent.Bench(opts)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+	}
+
+	display.ShowBenchResults(results)
+	return nil
+}
@@ -1,21 +1,37 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/autobrr/mkbrr/torrent"
 )
 
 // checkOptions encapsulates all the flags for the check command
 type checkOptions struct {
-	Verbose bool
-	Quiet   bool
-	Workers int
+	Batch        string
+	Pieces       string
+	Throttle     string
+	Report       string
+	Verbose      bool
+	Quiet        bool
+	FailFast     bool
+	AutoDetect   bool
+	Fuzzy        bool
+	IgnoreName   bool
+	RestoreAttrs bool
+	Extraneous   bool
+	DeleteExtra  bool
+	Workers      int
+	Parallel     int
 }
 
 var checkOpts checkOptions
@@ -25,8 +41,47 @@ var checkCmd = &cobra.Command{
 	Short: "Verify the integrity of content against a torrent file",
 	Long: `Checks if the data in the specified content path (file or directory) matches
 the pieces defined in the torrent file. This is useful for verifying downloads
-or checking data integrity after moving files.`,
-	Args:                       cobra.ExactArgs(2),
+or checking data integrity after moving files.
+Use --batch with a YAML config listing torrent_path/content_path pairs to verify many torrents at once.
+Use --pieces with a file from mkbrr inspect --export-pieces to verify without the torrent file.
+Use --report with a .json or .csv file to keep a machine-readable copy of the result.
+Use --fuzzy to verify a folder renamed after download, e.g. with a -FIXED suffix, when content-path does not exist.
+Use --ignore-name to verify a renamed file of a single-file torrent inside the content-path folder.
+Use --extraneous to list files inside the content folder that are not in the torrent, and
+--delete-extraneous to remove them after confirming.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if checkOpts.Batch != "" {
+			if len(args) > 0 {
+				return fmt.Errorf("cannot specify both torrent/content arguments and --batch flag")
+			}
+			if checkOpts.Pieces != "" {
+				return fmt.Errorf("cannot use both --pieces and --batch")
+			}
+			if checkOpts.RestoreAttrs {
+				return fmt.Errorf("cannot use both --restore-attrs and --batch")
+			}
+			if checkOpts.Fuzzy {
+				return fmt.Errorf("cannot use both --fuzzy and --batch")
+			}
+			if checkOpts.IgnoreName {
+				return fmt.Errorf("cannot use both --ignore-name and --batch")
+			}
+			if checkOpts.Report != "" {
+				return fmt.Errorf("cannot use both --report and --batch")
+			}
+			if checkOpts.Extraneous || checkOpts.DeleteExtra {
+				return fmt.Errorf("cannot use --extraneous or --delete-extraneous with --batch")
+			}
+			return nil
+		}
+		if checkOpts.Pieces != "" {
+			if checkOpts.AutoDetect {
+				return fmt.Errorf("cannot use both --pieces and --auto-detect")
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
 	RunE:                       runCheck,
 	DisableFlagsInUseLine:      true,
 	SuggestionsMinimumDistance: 1,
@@ -38,8 +93,29 @@ func init() {
 	checkCmd.Flags().BoolVarP(&checkOpts.Verbose, "verbose", "v", false, "show list of bad piece indices")
 	checkCmd.Flags().BoolVarP(&checkOpts.Quiet, "quiet", "q", false, "reduced output mode (prints only completion percentage)")
 	checkCmd.Flags().IntVar(&checkOpts.Workers, "workers", 0, "number of worker goroutines for verification (0 for automatic)")
+	checkCmd.Flags().StringVar(&checkOpts.Throttle, "throttle", "", "limit disk reads to this rate per second, e.g. 100MB (alias --max-read-rate, default unlimited)")
+	// --max-read-rate is accepted as another name of --throttle
+	checkCmd.Flags().SetNormalizeFunc(func(_ *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "max-read-rate" {
+			name = "throttle"
+		}
+		return pflag.NormalizedName(name)
+	})
+	checkCmd.Flags().BoolVar(&checkOpts.AutoDetect, "auto-detect", false, "find the content inside content-path by matching the torrent name")
+	checkCmd.Flags().BoolVar(&checkOpts.Fuzzy, "fuzzy", false, "if content-path does not exist, verify the entry next to it closest to the torrent name (edit distance up to 2)")
+	checkCmd.Flags().BoolVar(&checkOpts.IgnoreName, "ignore-name", false, "for a single-file torrent, verify the file in content-path with the torrent's size when none has its name")
+	checkCmd.Flags().BoolVar(&checkOpts.RestoreAttrs, "restore-attrs", false, "make verified files executable when the torrent marks them executable")
+	checkCmd.Flags().BoolVar(&checkOpts.Extraneous, "extraneous", false, "list files in the content folder of a multi-file torrent that are not in the torrent")
+	checkCmd.Flags().BoolVar(&checkOpts.DeleteExtra, "delete-extraneous", false, "delete the files found by --extraneous after asking for confirmation (implies --extraneous)")
+	checkCmd.Flags().StringVar(&checkOpts.Report, "report", "", "also write the result with a per-file breakdown to this file, as JSON or CSV by its extension")
+	checkCmd.Flags().StringVar(&checkOpts.Pieces, "pieces", "", "verify against a piece export from inspect --export-pieces instead of a torrent file")
+	checkCmd.Flags().StringVarP(&checkOpts.Batch, "batch", "b", "", "batch verify config file (YAML), \"-\" for stdin or an http(s) URL")
+	checkCmd.Flags().IntVar(&checkOpts.Parallel, "parallel", 1, "number of torrents verified at once in batch mode")
+	checkCmd.Flags().BoolVar(&checkOpts.FailFast, "fail-fast", false, "stop batch verification after the first failed torrent")
 	checkCmd.SetUsageTemplate(`Usage:
   {{.CommandPath}} <torrent-file> <content-path> [flags]
+  {{.CommandPath}} --pieces <export-file> <content-path> [flags]
+  {{.CommandPath}} --batch <config.yaml> [flags]
 
 Arguments:
   torrent-file   Path to the .torrent file
@@ -50,8 +126,11 @@ Flags:
 `)
 }
 
-// validateCheckArgs validates the command arguments and returns the paths
-func validateCheckArgs(args []string) (torrentPath string, contentPath string, err error) {
+// validateCheckArgs validates the command arguments and returns the paths.
+// With autoDetect, a directory content path is resolved to the entry inside it
+// matching the torrent name. With fuzzy, a missing content path is left for
+// VerifyData to match.
+func validateCheckArgs(args []string, autoDetect, fuzzy bool) (torrentPath string, contentPath string, err error) {
 	torrentPath = args[0]
 	contentPath = args[1]
 
@@ -60,23 +139,81 @@ func validateCheckArgs(args []string) (torrentPath string, contentPath string, e
 	}
 
 	if _, err := os.Stat(contentPath); err != nil {
+		if fuzzy && os.IsNotExist(err) {
+			return torrentPath, contentPath, nil
+		}
 		return "", "", fmt.Errorf("invalid content path %q: %w", contentPath, err)
 	}
 
+	if autoDetect {
+		contentPath, err = torrent.ResolveContentPath(torrentPath, contentPath)
+		if err != nil {
+			return "", "", fmt.Errorf("could not detect content path: %w", err)
+		}
+	}
+
 	return torrentPath, contentPath, nil
 }
 
+// validatePiecesArgs checks that the piece export and content path exist and
+// returns the content path.
+func validatePiecesArgs(piecesPath, contentPath string, fuzzy bool) (string, error) {
+	if _, err := os.Stat(piecesPath); err != nil {
+		return "", fmt.Errorf("invalid piece export path %q: %w", piecesPath, err)
+	}
+
+	if _, err := os.Stat(contentPath); err != nil && !(fuzzy && os.IsNotExist(err)) {
+		return "", fmt.Errorf("invalid content path %q: %w", contentPath, err)
+	}
+
+	return contentPath, nil
+}
+
 // buildVerifyOptions creates the verification options from the command flags
-func buildVerifyOptions(opts checkOptions, torrentPath, contentPath string) torrent.VerifyOptions {
+func buildVerifyOptions(opts checkOptions, torrentPath, contentPath string, maxReadRate int64) torrent.VerifyOptions {
 	return torrent.VerifyOptions{
-		TorrentPath: torrentPath,
-		ContentPath: contentPath,
-		Verbose:     opts.Verbose,
-		Quiet:       opts.Quiet,
-		Workers:     opts.Workers,
+		TorrentPath:           torrentPath,
+		ContentPath:           contentPath,
+		Verbose:               opts.Verbose,
+		Quiet:                 opts.Quiet,
+		Workers:               opts.Workers,
+		MaxReadBytesPerSecond: maxReadRate,
+		NoProgress:            noProgress,
+		RestoreAttrs:          opts.RestoreAttrs,
+		FuzzyPathMatch:        opts.Fuzzy,
+		IgnoreName:            opts.IgnoreName,
+		FindExtraFiles:        opts.Extraneous || opts.DeleteExtra,
 	}
 }
 
+// deleteExtraFiles asks for confirmation on stdin and removes the extra files
+// of result from its content path, returning how many were removed.
+func deleteExtraFiles(result *torrent.VerificationResult) (int, error) {
+	if len(result.ExtraFiles) == 0 {
+		return 0, nil
+	}
+
+	fmt.Fprintf(os.Stdout, "\nDelete %d extraneous files from %s? [y/N]: ", len(result.ExtraFiles), result.ContentPath)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(os.Stdout)
+		return 0, nil
+	}
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+		return 0, nil
+	}
+
+	removed := 0
+	for _, file := range result.ExtraFiles {
+		path := filepath.Join(result.ContentPath, filepath.FromSlash(file.Path))
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("could not delete extraneous file: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
 // displayCheckResults handles the display of verification results
 func displayCheckResults(display *torrent.Display, result *torrent.VerificationResult, duration time.Duration, opts checkOptions) {
 	display.SetQuiet(opts.Quiet)
@@ -88,22 +225,100 @@ func displayCheckResults(display *torrent.Display, result *torrent.VerificationR
 	}
 }
 
+// writeCheckReport writes report to a new file at path in the given format
+func writeCheckReport(path string, report *torrent.VerifyReport, format string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create report file: %w", err)
+	}
+	if err := torrent.WriteVerifyReport(f, report, format); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("could not write report file: %w", err)
+	}
+	return nil
+}
+
+// runCheckBatch verifies every torrent/content pair listed in the batch config
+func runCheckBatch(opts checkOptions, maxReadRate int64) error {
+	start := time.Now()
+
+	results, err := torrent.ProcessVerifyBatch(opts.Batch, torrent.VerifyBatchOptions{
+		Verbose:               opts.Verbose,
+		Quiet:                 opts.Quiet,
+		FailFast:              opts.FailFast,
+		Workers:               opts.Workers,
+		Concurrency:           opts.Parallel,
+		MaxReadBytesPerSecond: maxReadRate,
+	})
+	if err != nil {
+		return fmt.Errorf("batch verification failed: %w", err)
+	}
+
+	if opts.Quiet {
+		for _, result := range results {
+			switch {
+			case result.Skipped:
+				continue
+			case result.Error != nil:
+				fmt.Fprintf(os.Stderr, "FAILED: %s: %v\n", result.TorrentPath, result.Error)
+			default:
+				fmt.Printf("%.2f%% %s\n", result.Completion, result.TorrentPath)
+			}
+		}
+	} else {
+		display := torrent.NewDisplay(torrent.NewFormatter(opts.Verbose))
+		display.ShowVerifyBatchResults(results, time.Since(start))
+	}
+
+	return torrent.VerifyBatchError(results)
+}
+
 func runCheck(cmd *cobra.Command, args []string) error {
-	torrentPath, contentPath, err := validateCheckArgs(args)
+	maxReadRate, err := torrent.ParseByteRate(checkOpts.Throttle)
+	if err != nil {
+		return err
+	}
+
+	if checkOpts.Batch != "" {
+		return runCheckBatch(checkOpts, maxReadRate)
+	}
+
+	var reportFormat string
+	if checkOpts.Report != "" {
+		if reportFormat, err = torrent.ReportFormatFromPath(checkOpts.Report); err != nil {
+			return err
+		}
+	}
+
+	var torrentPath, contentPath string
+	if checkOpts.Pieces != "" {
+		// the piece export takes the place of the torrent file
+		contentPath, err = validatePiecesArgs(checkOpts.Pieces, args[0], checkOpts.Fuzzy)
+	} else {
+		torrentPath, contentPath, err = validateCheckArgs(args, checkOpts.AutoDetect, checkOpts.Fuzzy)
+	}
 	if err != nil {
 		return err
 	}
 
 	start := time.Now()
 
-	verifyOpts := buildVerifyOptions(checkOpts, torrentPath, contentPath)
+	verifyOpts := buildVerifyOptions(checkOpts, torrentPath, contentPath, maxReadRate)
+	verifyOpts.PiecesPath = checkOpts.Pieces
 	display := torrent.NewDisplay(torrent.NewFormatter(checkOpts.Verbose))
 
 	if !checkOpts.Quiet {
 		green := color.New(color.FgGreen).SprintFunc()
 		cyan := color.New(color.FgCyan).SprintFunc()
 		fmt.Fprintf(os.Stdout, "\n%s\n", green("Verifying:"))
-		fmt.Fprintf(os.Stdout, "  Torrent file: %s\n", cyan(torrentPath))
+		if checkOpts.Pieces != "" {
+			fmt.Fprintf(os.Stdout, "  Piece export: %s\n", cyan(checkOpts.Pieces))
+		} else {
+			fmt.Fprintf(os.Stdout, "  Torrent file: %s\n", cyan(torrentPath))
+		}
 		fmt.Fprintf(os.Stdout, "  Content: %s\n", cyan(contentPath))
 	}
 
@@ -115,6 +330,23 @@ func runCheck(cmd *cobra.Command, args []string) error {
 	duration := time.Since(start)
 	displayCheckResults(display, result, duration, checkOpts)
 
+	if checkOpts.DeleteExtra {
+		removed, err := deleteExtraFiles(result)
+		if removed > 0 && !checkOpts.Quiet {
+			display.ShowMessage(fmt.Sprintf("deleted %d extraneous files", removed))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if checkOpts.Report != "" {
+		report := torrent.NewVerifyReport(verifyOpts, result, start, duration)
+		if err := writeCheckReport(checkOpts.Report, report, reportFormat); err != nil {
+			return err
+		}
+	}
+
 	if result.BadPieces > 0 || len(result.MissingFiles) > 0 {
 		return fmt.Errorf("verification failed or incomplete")
 	}
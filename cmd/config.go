@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/mkbrr/internal/config"
+	"github.com/autobrr/mkbrr/internal/preset"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the global config",
+	Long: `Inspect the global config at ~/.config/mkbrr/config.yaml.
+
+The config sets defaults for the workers, output_dir, quiet, verbose, skip_prefix
+and preset keys. They apply to every command with the matching flag, so an
+explicit flag wins over a preset value, which wins over the config, which wins
+over the built-in default.`,
+	DisableFlagsInUseLine: true,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration and where each value came from",
+	Long: `Print the effective configuration and where each value came from.
+
+Pass the same flags as to create, e.g. --preset or --workers, to see how they
+combine with the preset and the global config.`,
+	Args:                  cobra.NoArgs,
+	RunE:                  runConfigShow,
+	DisableFlagsInUseLine: true,
+	SilenceUsage:          true,
+}
+
+var configShowOpts struct {
+	presetName string
+	presetFile string
+	outputDir  string
+	workers    int
+	quiet      bool
+	verbose    bool
+	skipPrefix bool
+}
+
+func init() {
+	configShowCmd.Flags().SortFlags = false
+	configShowCmd.Flags().StringVarP(&configShowOpts.presetName, "preset", "P", "", "use preset from config")
+	configShowCmd.Flags().StringVar(&configShowOpts.presetFile, "preset-file", "", "preset config file, \"-\" for stdin or an http(s) URL (default ~/.config/mkbrr/presets.yaml)")
+	configShowCmd.Flags().StringVar(&configShowOpts.outputDir, "output-dir", "", "output directory for created torrent")
+	configShowCmd.Flags().IntVar(&configShowOpts.workers, "workers", 0, "number of worker goroutines for hashing (0 for automatic)")
+	configShowCmd.Flags().BoolVarP(&configShowOpts.quiet, "quiet", "q", false, "reduced output mode")
+	configShowCmd.Flags().BoolVarP(&configShowOpts.verbose, "verbose", "v", false, "be verbose")
+	configShowCmd.Flags().BoolVar(&configShowOpts.skipPrefix, "skip-prefix", false, "don't add tracker domain prefix to output filename")
+
+	configCmd.AddCommand(configShowCmd)
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	globalConfig, path, err := loadGlobalConfig()
+	if err != nil {
+		return err
+	}
+
+	defaults := make(map[string]string)
+	flags := make(map[string]string)
+	for _, key := range config.Keys {
+		flag := cmd.Flags().Lookup(key.Flag)
+		defaults[key.Name] = flag.DefValue
+		if flag.Changed {
+			flags[key.Name] = flag.Value.String()
+		}
+	}
+
+	// the global config has already been applied, so the flag holds the effective preset name
+	presetValues, err := presetConfigValues(configShowOpts.presetName, configShowOpts.presetFile)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	switch _, statErr := os.Stat(path); {
+	case path == "":
+		fmt.Fprintln(out, "Config file: (no home directory)")
+	case os.IsNotExist(statErr):
+		fmt.Fprintf(out, "Config file: %s (not found)\n", path)
+	default:
+		fmt.Fprintf(out, "Config file: %s\n", path)
+	}
+
+	for _, setting := range config.Resolve(flags, presetValues, globalConfig.Values(), defaults) {
+		value := setting.Value
+		if value == "" {
+			value = `""`
+		}
+		fmt.Fprintf(out, "  %-12s %-30s (%s)\n", setting.Key, value, setting.Source)
+	}
+	return nil
+}
+
+// presetConfigValues returns the values of the named preset for the keys the
+// global config shares with presets, or nil when no preset is used.
+func presetConfigValues(name, presetFile string) (map[string]string, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	presetFilePath, err := preset.FindPresetFile(presetFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not find preset file: %w", err)
+	}
+	presetOpts, err := preset.LoadPresetOptions(presetFilePath, name)
+	if err != nil {
+		return nil, fmt.Errorf("could not load preset options: %w", err)
+	}
+
+	values := make(map[string]string)
+	if presetOpts.Workers != 0 {
+		values["workers"] = strconv.Itoa(presetOpts.Workers)
+	}
+	if presetOpts.OutputDir != "" {
+		values["output_dir"] = presetOpts.OutputDir
+	}
+	if presetOpts.SkipPrefix != nil {
+		values["skip_prefix"] = strconv.FormatBool(*presetOpts.SkipPrefix)
+	}
+	return values, nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/mkbrr/torrent"
+)
+
+// convertOptions encapsulates command-line flag values for the convert command
+type convertOptions struct {
+	output        string
+	stripMetadata bool
+	dryRun        bool
+	quiet         bool
+}
+
+var convertOpts convertOptions
+
+var convertCmd = &cobra.Command{
+	Use:   "convert <torrent-file>",
+	Short: "Re-encode a torrent file with normalized metadata",
+	Long: `Re-encodes a torrent file with normalized metadata. Strings are made valid UTF-8,
+with invalid bytes replaced by U+FFFD, NUL bytes are removed and comment line endings
+are normalized. Empty and duplicate trackers, empty announce tiers and duplicate web
+seeds are dropped. The info dictionary is not touched, so the info hash stays the same.
+
+The result is written next to the input as <name>.converted.torrent unless --output is given.`,
+	Args:                       cobra.ExactArgs(1),
+	RunE:                       runConvert,
+	DisableFlagsInUseLine:      true,
+	SuggestionsMinimumDistance: 1,
+	SilenceUsage:               true,
+}
+
+func init() {
+	convertCmd.Flags().SortFlags = false
+	convertCmd.Flags().StringVarP(&convertOpts.output, "output", "o", "", "output path of the converted torrent")
+	convertCmd.Flags().BoolVar(&convertOpts.stripMetadata, "strip-metadata", false, "remove the comment, creator and creation date")
+	convertCmd.Flags().BoolVarP(&convertOpts.dryRun, "dry-run", "n", false, "show what would change without writing the torrent")
+	convertCmd.Flags().BoolVarP(&convertOpts.quiet, "quiet", "q", false, "reduced output mode (prints only the final torrent path)")
+
+	convertCmd.SetUsageTemplate(`Usage:
+  {{.CommandPath}} <torrent-file> [flags]
+
+Flags:
+{{.LocalFlags.FlagUsages | trimTrailingWhitespaces}}
+`)
+}
+
+func runConvert(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+	display := torrent.NewDisplay(torrent.NewFormatter(false))
+	display.SetQuiet(convertOpts.quiet)
+
+	result, err := torrent.ConvertTorrent(args[0], torrent.ConvertOptions{
+		OutputPath:    convertOpts.output,
+		StripMetadata: convertOpts.stripMetadata,
+		DryRun:        convertOpts.dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	display.ShowConvertChanges(result.Changes)
+	if convertOpts.dryRun {
+		display.ShowMessage(fmt.Sprintf("dry run, %s was not written (info hash %s)", args[0], result.InfoHash))
+		return nil
+	}
+
+	if convertOpts.quiet {
+		fmt.Println("Wrote:", result.OutputPath)
+		return nil
+	}
+
+	display.ShowMessage(fmt.Sprintf("info hash unchanged %s", result.InfoHash))
+	display.ShowOutputPathWithTime(result.OutputPath, time.Since(start))
+	return nil
+}
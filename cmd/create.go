@@ -1,20 +1,33 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"maps"
+	"net/url"
 	"os"
 	"runtime/pprof"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
+	"github.com/autobrr/mkbrr/internal/configsource"
 	"github.com/autobrr/mkbrr/internal/preset"
 	"github.com/autobrr/mkbrr/internal/trackers"
 	"github.com/autobrr/mkbrr/torrent"
 )
 
+// environment variables used as a fallback when no tracker or source is given,
+// keeping announce URLs with passkeys out of shell history
+const (
+	trackerEnvVar = "MKBRR_TRACKER"
+	sourceEnvVar  = "MKBRR_SOURCE"
+)
+
 // createOptions encapsulates all command-line flag values for the create command
 type createOptions struct {
 	pieceLengthExp      *uint
@@ -25,14 +38,23 @@ type createOptions struct {
 	name                string
 	outputPath          string
 	outputDir           string
+	fileOrder           string
 	source              string
 	batchFile           string
 	presetName          string
+	presetNames         []string
 	presetFile          string
 	webSeeds            []string
+	httpSeeds           []string
 	excludePatterns     []string
+	excludeDirs         []string
 	includePatterns     []string
 	createWorkers       int
+	readRetries         int
+	verifyReused        int
+	reuseFrom           string
+	hashCache           string
+	throttle            string
 	isPrivate           bool
 	noDate              bool
 	noCreator           bool
@@ -42,6 +64,34 @@ type createOptions struct {
 	infoOnly            bool
 	skipPrefix          bool
 	failOnSeasonWarning bool
+	continueOnError     bool
+	sanitizeName        bool
+	asciiName           bool
+	showTree            bool
+	noSort              bool
+	skipHashing         bool
+	fat32Check          bool
+	strict              bool
+	noValidateTrackers  bool
+	webSeedMerge        bool
+	announceRandom      bool
+	strictContent       bool
+	minVideoSize        string
+	date                string
+	verify              bool
+	creator             string
+	meta                []string
+	failFast            bool
+	followDirSymlinks   bool
+	estimate            bool
+	json                bool
+	webSeedsFile        string
+	fetchTrackerRules   bool
+	preserveAttrs       bool
+	padToPieceBoundary  bool
+	caseSensitive       bool
+	skipHidden          bool
+	maxDepth            int
 }
 
 var options = createOptions{
@@ -54,7 +104,8 @@ var createCmd = &cobra.Command{
 	Long: `Create a new torrent file from a file or directory.
 Supports both single file/directory and batch mode using a YAML config file.
 Supports presets for commonly used settings.
-When a single tracker URL is provided, the output filename will use the tracker domain (without TLD) as prefix by default (e.g. "example_filename.torrent"). This behavior can be disabled with --skip-prefix. When multiple trackers are specified, no prefix is added.`,
+When a single tracker URL is provided, the output filename will use the tracker domain (without TLD) as prefix by default (e.g. "example_filename.torrent"). This behavior can be disabled with --skip-prefix. When multiple trackers are specified, no prefix is added.
+If no tracker or source is given by flag or preset, the MKBRR_TRACKER and MKBRR_SOURCE environment variables are used.`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) > 1 {
 			return fmt.Errorf("accepts at most one arg")
@@ -79,19 +130,43 @@ When a single tracker URL is provided, the output filename will use the tracker
 
 func init() {
 	createCmd.Flags().SortFlags = false
-	createCmd.Flags().StringVarP(&options.batchFile, "batch", "b", "", "batch config file (YAML)")
-
-	createCmd.Flags().StringVarP(&options.presetName, "preset", "P", "", "use preset from config")
-	createCmd.Flags().StringVar(&options.presetFile, "preset-file", "", "preset config file (default ~/.config/mkbrr/presets.yaml)")
+	createCmd.Flags().StringVarP(&options.batchFile, "batch", "b", "", "batch config file (YAML), \"-\" for stdin or an http(s) URL")
+	createCmd.Flags().BoolVar(&options.followDirSymlinks, "follow-dir-symlinks", false, "include the contents of symlinked directories, skipping directories already included so links cannot loop")
+	createCmd.Flags().BoolVar(&options.preserveAttrs, "preserve-attrs", false, "record the executable and hidden attributes of files (BEP 47), which changes the info hash")
+	createCmd.Flags().BoolVar(&options.padToPieceBoundary, "pad-to-piece-boundary", false, "start every file on a piece boundary by adding BEP 47 padding files, hashed as zeros and not written to disk; changes the info hash")
+	createCmd.Flags().BoolVar(&options.failFast, "fail-fast", false, "fail on unreadable paths such as broken symlinks instead of skipping them, and stop a batch at the first failed job")
+	createCmd.Flags().BoolVar(&options.continueOnError, "continue-on-error", false, "run the remaining batch jobs when some are invalid and exit successfully even if jobs fail")
+
+	createCmd.Flags().StringArrayVarP(&options.presetNames, "preset", "P", nil, "use preset from config; repeat to create a torrent for each preset, hashing the content once")
+	createCmd.Flags().StringVar(&options.presetFile, "preset-file", "", "preset config file, \"-\" for stdin or an http(s) URL (default ~/.config/mkbrr/presets.yaml)")
 	createCmd.Flags().StringArrayVarP(&options.trackers, "tracker", "t", nil, "tracker URLs (can be specified multiple times)")
+	createCmd.Flags().BoolVar(&options.announceRandom, "announce-random", false, "shuffle the order of the trackers in the announce list")
+	createCmd.Flags().BoolVar(&options.noValidateTrackers, "no-validate-trackers", false, "accept tracker URLs that fail validation")
+	createCmd.Flags().BoolVar(&options.fetchTrackerRules, "fetch-tracker-rules", false, "refresh piece sizes and limits from the tracker's published rules, if it has any, falling back to the built-in rules")
 	createCmd.Flags().StringArrayVarP(&options.webSeeds, "web-seed", "w", nil, "add web seed URLs")
+	createCmd.Flags().StringArrayVar(&options.httpSeeds, "http-seed", nil, "add BEP 17 http seed URLs, for clients without BEP 19 web seed support (can be specified multiple times)")
+	createCmd.Flags().StringVar(&options.webSeedsFile, "webseeds-file", "", "add web seed URLs from a file with one URL per line (# comments, @file includes another list)")
+	createCmd.Flags().BoolVar(&options.webSeedMerge, "web-seed-merge", false, "add --web-seed URLs to the preset web seeds instead of replacing them")
 	createCmd.Flags().BoolVarP(&options.isPrivate, "private", "p", true, "make torrent private")
 	createCmd.Flags().StringVarP(&options.comment, "comment", "c", "", "add comment")
 
 	var defaultPieceLength, defaultMaxPieceLength, defaultTargetPieceCount uint
 	createCmd.Flags().UintVarP(&defaultPieceLength, "piece-length", "l", 0, "set piece length to 2^n bytes (16-27, automatic if not specified)")
 	createCmd.Flags().UintVarP(&defaultMaxPieceLength, "max-piece-length", "m", 0, "limit maximum piece length to 2^n bytes (16-27, unlimited if not specified)")
-	createCmd.Flags().UintVar(&defaultTargetPieceCount, "target-piece-count", 0, "target approximate number of pieces (calculates optimal piece length)")
+	createCmd.Flags().UintVar(&defaultTargetPieceCount, "target-piece-count", 0, "target approximate number of pieces, choosing the piece length whose count is closest (alias --target-pieces)")
+	// --target-pieces is accepted as a shorter spelling of --target-piece-count,
+	// --max-read-rate as another name of --throttle
+	createCmd.Flags().SetNormalizeFunc(func(_ *pflag.FlagSet, name string) pflag.NormalizedName {
+		switch name {
+		case "target-pieces":
+			name = "target-piece-count"
+		case "max-read-rate":
+			name = "throttle"
+		case "created-by":
+			name = "creator"
+		}
+		return pflag.NormalizedName(name)
+	})
 	createCmd.PreRun = func(cmd *cobra.Command, args []string) {
 		if cmd.Flags().Changed("piece-length") {
 			options.pieceLengthExp = &defaultPieceLength
@@ -105,20 +180,45 @@ func init() {
 	}
 
 	createCmd.Flags().StringVar(&options.name, "name", "", "set torrent name (default: <filename>)")
+	createCmd.Flags().BoolVar(&options.sanitizeName, "sanitize-name", false, "normalize torrent name by removing characters that are invalid on some systems")
+	createCmd.Flags().BoolVar(&options.asciiName, "ascii", false, "transliterate non-ASCII characters in the torrent name (requires --sanitize-name)")
 	createCmd.Flags().StringVarP(&options.outputPath, "output", "o", "", "set output path (default: <filename>.torrent)")
 	createCmd.Flags().StringVar(&options.outputDir, "output-dir", "", "output directory for created torrent")
 	createCmd.Flags().StringVarP(&options.source, "source", "s", "", "add source string")
 	createCmd.Flags().BoolVarP(&options.noDate, "no-date", "d", false, "don't write creation date")
+	createCmd.Flags().StringVar(&options.date, "date", "", "write this creation date (unix seconds or RFC3339) instead of the current time, for reproducible torrents")
 	createCmd.Flags().BoolVarP(&options.noCreator, "no-creator", "", false, "don't write creator")
+	createCmd.Flags().StringVar(&options.creator, "creator", "", "write this creator instead of mkbrr and its version (--no-creator wins), alias --created-by")
+	createCmd.Flags().StringArrayVar(&options.meta, "meta", nil, "add a root-level metadata field as key=value, not part of the info hash (repeatable)")
 	createCmd.Flags().BoolVarP(&options.entropy, "entropy", "e", false, "randomize info hash by adding entropy field")
 	createCmd.Flags().BoolVarP(&options.verbose, "verbose", "v", false, "be verbose")
 	createCmd.Flags().BoolVarP(&options.quiet, "quiet", "q", false, "reduced output mode (prints only final torrent path)")
 	createCmd.Flags().BoolVarP(&options.infoOnly, "info-only", "i", false, "display only torrent info without progress (implies verbose)")
+	createCmd.Flags().BoolVar(&options.showTree, "tree", false, "show the file tree of the created torrent (implied by --verbose)")
 	createCmd.Flags().BoolVarP(&options.skipPrefix, "skip-prefix", "", false, "don't add tracker domain prefix to output filename")
 	createCmd.Flags().BoolVar(&options.failOnSeasonWarning, "fail-on-season-warning", false, "fail on season pack warning")
 	createCmd.Flags().StringArrayVarP(&options.excludePatterns, "exclude", "", nil, "exclude files matching these patterns (e.g., \"*.nfo,*.jpg\" or --exclude \"*.nfo\" --exclude \"*.jpg\")")
+	createCmd.Flags().StringArrayVar(&options.excludeDirs, "exclude-dir", nil, "exclude directories with these names and everything in them, case-insensitive (e.g., \"Sample,Proof\" or --exclude-dir Sample --exclude-dir .git)")
 	createCmd.Flags().StringArrayVarP(&options.includePatterns, "include", "", nil, "include only files matching these patterns (e.g., \"*.mkv,*.mp4\" or --include \"*.mkv\" --include \"*.mp4\")")
+	createCmd.Flags().BoolVar(&options.caseSensitive, "case-sensitive", false, "match --exclude and --include patterns case-sensitively")
+	createCmd.Flags().BoolVar(&options.skipHidden, "skip-hidden", false, "leave out files and directories whose name starts with a dot")
+	createCmd.Flags().IntVar(&options.maxDepth, "max-depth", -1, "walk at most this many directory levels below the content path, 0 for only its own files (-1 for no limit)")
+	createCmd.Flags().BoolVar(&options.fat32Check, "fat32-check", false, "warn about files larger than 4 GiB, which cannot be downloaded to FAT32 drives")
+	createCmd.Flags().BoolVar(&options.strict, "strict", false, "fail instead of warning when --fat32-check or sparse file detection finds a problem")
+	createCmd.Flags().BoolVar(&options.strictContent, "strict-content", false, "fail instead of warning about empty files and video files smaller than --min-video-size")
+	createCmd.Flags().StringVar(&options.minVideoSize, "min-video-size", "1MiB", "warn about video files smaller than this size, likely stubs of a bad copy (0 to disable)")
+	createCmd.Flags().BoolVar(&options.skipHashing, "skip-hashing", false, "write placeholder piece hashes to create a metadata-only template (not seedable)")
+	createCmd.Flags().BoolVar(&options.estimate, "estimate", false, "print the piece length, piece count and torrent size create would use, without hashing or writing anything")
+	createCmd.Flags().BoolVar(&options.json, "json", false, "print the result as one JSON object: the path, info hash, size, file and piece counts of the torrent, or the --estimate output")
 	createCmd.Flags().IntVar(&options.createWorkers, "workers", 0, "number of worker goroutines for hashing (0 for automatic)")
+	createCmd.Flags().StringVar(&options.reuseFrom, "reuse-from", "", "reuse piece hashes of unchanged files from an existing torrent (uses its piece length)")
+	createCmd.Flags().BoolVar(&options.verify, "verify", false, "verify the written torrent against the content and fail unless it is 100% complete")
+	createCmd.Flags().IntVar(&options.verifyReused, "verify-reused", 0, "rehash this many random reused pieces to catch files changed without a new mtime")
+	createCmd.Flags().StringVar(&options.hashCache, "hash-cache", "", "keep piece hashes in this directory and skip pieces of files unchanged since an earlier run")
+	createCmd.Flags().StringVar(&options.throttle, "throttle", "", "limit disk reads while hashing to this rate per second, e.g. 100MB (alias --max-read-rate, default unlimited)")
+	createCmd.Flags().IntVar(&options.readRetries, "read-retries", 0, "retry reading temporarily locked files this many times with backoff (0 to fail immediately)")
+	createCmd.Flags().StringVar(&options.fileOrder, "file-order", torrent.FileOrderPath, "order of files in the torrent: path, natural (track2 before track10), none (walk order), inode (on-disk order, unix only), size-desc (alias largest-first) or size-asc")
+	createCmd.Flags().BoolVar(&options.noSort, "no-sort", false, "keep the directory walk order, same as --file-order none")
 
 	createCmd.Flags().String("cpuprofile", "", "write cpu profile to file (development flag)")
 
@@ -156,179 +256,396 @@ func setupProfiling(cmd *cobra.Command) (cleanup func(), err error) {
 
 // processBatchMode handles processing multiple torrents using a batch configuration file
 func processBatchMode(opts createOptions, version string, startTime time.Time) error {
-	results, err := torrent.ProcessBatch(opts.batchFile, opts.verbose, opts.quiet, opts.infoOnly, version)
+	if opts.failFast && opts.continueOnError {
+		return fmt.Errorf("cannot use both --fail-fast and --continue-on-error")
+	}
+
+	results, err := torrent.ProcessBatchWithOptions(opts.batchFile, torrent.BatchOptions{
+		Verbose:          opts.verbose,
+		Quiet:            opts.quiet,
+		InfoOnly:         opts.infoOnly,
+		ContinueOnError:  opts.continueOnError,
+		FailFast:         opts.failFast,
+		FailFastOnAnyJob: opts.failFast,
+		Version:          version,
+	})
 	if err != nil {
 		return fmt.Errorf("batch processing failed: %w", err)
 	}
 
 	if opts.quiet {
-		for _, result := range results {
-			if result.Success {
-				fmt.Println("Wrote:", result.Info.Path)
-			}
-		}
+		torrent.WriteBatchQuietResults(os.Stdout, os.Stderr, results)
 	} else {
 		display := torrent.NewDisplay(torrent.NewFormatter(opts.verbose))
 		display.ShowBatchResults(results, time.Since(startTime))
 	}
-	return nil
+
+	return torrent.BatchExitError(results, opts.continueOnError)
 }
 
-// buildCreateOptions creates a torrent.CreateOptions struct from command-line options and presets
-func buildCreateOptions(cmd *cobra.Command, inputPath string, opts createOptions, version string) (torrent.CreateOptions, error) {
-	createOpts := torrent.CreateOptions{
-		Path:                    inputPath,
-		Name:                    opts.name,
-		TrackerURLs:             opts.trackers,
-		WebSeeds:                opts.webSeeds,
-		IsPrivate:               opts.isPrivate,
-		Comment:                 opts.comment,
-		PieceLengthExp:          opts.pieceLengthExp,
-		MaxPieceLength:          opts.maxPieceLengthExp,
-		TargetPieceCount:        opts.targetPieceCount,
-		Source:                  opts.source,
-		NoDate:                  opts.noDate,
-		NoCreator:               opts.noCreator,
-		Verbose:                 opts.verbose,
-		Version:                 version,
-		Entropy:                 opts.entropy,
-		Quiet:                   opts.quiet,
-		InfoOnly:                opts.infoOnly,
-		SkipPrefix:              opts.skipPrefix,
-		ExcludePatterns:         opts.excludePatterns,
-		IncludePatterns:         opts.includePatterns,
-		Workers:                 opts.createWorkers,
-		OutputDir:               opts.outputDir,
-		FailOnSeasonPackWarning: opts.failOnSeasonWarning,
+// buildTorrent creates a torrent.TorrentBuilder from command-line options and presets
+func buildTorrent(cmd *cobra.Command, inputPath string, opts createOptions, version string) (*torrent.TorrentBuilder, error) {
+	if opts.asciiName && !opts.sanitizeName {
+		return nil, fmt.Errorf("--ascii requires --sanitize-name")
+	}
+	if opts.verify && opts.skipHashing {
+		return nil, fmt.Errorf("cannot use both --verify and --skip-hashing")
+	}
+	if opts.json && opts.verify {
+		return nil, fmt.Errorf("cannot use both --json and --verify")
+	}
+	if opts.json && opts.infoOnly {
+		return nil, fmt.Errorf("cannot use both --json and --info-only")
+	}
+	if opts.estimate && opts.verify {
+		return nil, fmt.Errorf("cannot use both --estimate and --verify")
 	}
 
-	// If a preset is specified, load the preset options and merge with command-line flags
-	if opts.presetName != "" {
-		presetFilePath, err := preset.FindPresetFile(opts.presetFile)
-		if err != nil {
-			return createOpts, fmt.Errorf("could not find preset file: %w", err)
+	maxReadRate, err := torrent.ParseByteRate(opts.throttle)
+	if err != nil {
+		return nil, err
+	}
+
+	minVideoSize, err := torrent.ParseMinVideoSize(opts.minVideoSize)
+	if err != nil {
+		return nil, err
+	}
+
+	fileOrder := opts.fileOrder
+	if opts.noSort {
+		if cmd.Flags().Changed("file-order") && fileOrder != torrent.FileOrderNone {
+			return nil, fmt.Errorf("cannot use both --no-sort and --file-order %s", fileOrder)
 		}
+		fileOrder = torrent.FileOrderNone
+	}
 
-		presetOpts, err := preset.LoadPresetOptions(presetFilePath, opts.presetName)
+	builder := torrent.NewTorrentBuilder(inputPath).
+		WithContext(cmd.Context()).
+		WithName(opts.name).
+		WithPrivate(opts.isPrivate).
+		WithComment(opts.comment).
+		WithNoDate(opts.noDate).
+		WithNoCreator(opts.noCreator).
+		WithCreator(opts.creator).
+		WithFailFast(opts.failFast).
+		WithFollowDirSymlinks(opts.followDirSymlinks).
+		WithPreserveAttrs(opts.preserveAttrs).
+		WithPadToPieceBoundary(opts.padToPieceBoundary).
+		WithCaseSensitivePatterns(opts.caseSensitive).
+		WithSkipHidden(opts.skipHidden).
+		WithNoProgress(noProgress).
+		WithPresetName(opts.presetName).
+		WithVerbose(opts.verbose).
+		WithVersion(version).
+		WithEntropy(opts.entropy).
+		WithQuiet(opts.quiet).
+		WithInfoOnly(opts.infoOnly).
+		WithSkipPrefix(opts.skipPrefix).
+		WithWorkers(opts.createWorkers).
+		WithReadRetries(opts.readRetries).
+		WithMaxReadRate(maxReadRate).
+		WithOutputPath(opts.outputPath).
+		WithOutputDir(opts.outputDir).
+		WithFileOrder(fileOrder).
+		WithFailOnSeasonPackWarning(opts.failOnSeasonWarning).
+		WithShowTree(opts.showTree).
+		WithSkipHashing(opts.skipHashing).
+		WithFAT32Check(opts.fat32Check).
+		WithStrictFileChecks(opts.strict).
+		WithRandomizeAnnounceList(opts.announceRandom).
+		WithMinVideoSize(minVideoSize).
+		WithStrictContent(opts.strictContent)
+
+	if opts.sanitizeName {
+		builder.WithSanitizeName(opts.asciiName)
+	}
+
+	if opts.maxDepth < -1 {
+		return nil, fmt.Errorf("invalid --max-depth %d: must be 0 or more, or -1 for no limit", opts.maxDepth)
+	}
+	if opts.maxDepth >= 0 {
+		builder.WithMaxDepth(opts.maxDepth)
+	}
+
+	if opts.date != "" {
+		if opts.noDate {
+			return nil, fmt.Errorf("cannot use both --date and --no-date")
+		}
+		date, err := torrent.ParseCreationDate(opts.date)
 		if err != nil {
-			return createOpts, fmt.Errorf("could not load preset options: %w", err)
+			return nil, err
 		}
+		builder.WithCreationDate(date)
+	}
 
-		if len(presetOpts.Trackers) > 0 && !cmd.Flags().Changed("tracker") {
-			createOpts.TrackerURLs = presetOpts.Trackers
-		}
+	if opts.reuseFrom != "" {
+		builder.WithReuseFrom(opts.reuseFrom, opts.verifyReused)
+	}
 
-		if len(presetOpts.WebSeeds) > 0 && !cmd.Flags().Changed("web-seed") {
-			createOpts.WebSeeds = presetOpts.WebSeeds
+	if opts.hashCache != "" {
+		if opts.skipHashing {
+			return nil, fmt.Errorf("cannot use both --hash-cache and --skip-hashing")
 		}
+		builder.WithHashCache(opts.hashCache)
+	}
+
+	meta, err := torrent.ParseRootFields(opts.meta)
+	if err != nil {
+		return nil, err
+	}
 
-		if presetOpts.Private != nil && !cmd.Flags().Changed("private") {
-			createOpts.IsPrivate = *presetOpts.Private
+	// values below may still be replaced by the preset or environment variables
+	trackerURLs := opts.trackers
+	webSeeds := opts.webSeeds
+	webSeedsFile := opts.webSeedsFile
+	excludePatterns := opts.excludePatterns
+	includePatterns := opts.includePatterns
+	source := opts.source
+	sourceFromPreset := false
+	pieceLengthExp := opts.pieceLengthExp
+	maxPieceLength := opts.maxPieceLengthExp
+	targetPieceCount := opts.targetPieceCount
+
+	// If a preset is specified, load the preset options and merge with command-line flags
+	if opts.presetName != "" {
+		presetFilePath, err := preset.FindPresetFile(opts.presetFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not find preset file: %w", err)
 		}
 
-		if presetOpts.Comment != "" && !cmd.Flags().Changed("comment") {
-			createOpts.Comment = presetOpts.Comment
+		presetOpts, err := preset.LoadPresetOptions(presetFilePath, opts.presetName)
+		if err != nil {
+			return nil, fmt.Errorf("could not load preset options: %w", err)
 		}
 
-		if presetOpts.Source != "" && !cmd.Flags().Changed("source") {
-			createOpts.Source = presetOpts.Source
+		builder.WithPreset(presetOpts, cmd.Flags().Changed)
+
+		if len(presetOpts.Trackers) > 0 && !cmd.Flags().Changed("tracker") {
+			trackerURLs = presetOpts.Trackers
 		}
 
-		if presetOpts.OutputDir != "" && !cmd.Flags().Changed("output-dir") {
-			createOpts.OutputDir = presetOpts.OutputDir
+		if len(presetOpts.WebSeeds) > 0 {
+			if !cmd.Flags().Changed("web-seed") {
+				webSeeds = presetOpts.WebSeeds
+			} else if opts.webSeedMerge {
+				webSeeds = preset.MergeLists(presetOpts.WebSeeds, webSeeds)
+			}
 		}
 
-		if presetOpts.NoDate != nil && !cmd.Flags().Changed("no-date") {
-			createOpts.NoDate = *presetOpts.NoDate
+		if presetOpts.WebSeedsFile != "" && !cmd.Flags().Changed("webseeds-file") {
+			webSeedsFile = presetOpts.WebSeedsFile
 		}
 
-		if presetOpts.NoCreator != nil && !cmd.Flags().Changed("no-creator") {
-			createOpts.NoCreator = *presetOpts.NoCreator
+		if presetOpts.Source != "" && !cmd.Flags().Changed("source") {
+			source = presetOpts.Source
 		}
 
-		if presetOpts.SkipPrefix != nil && !cmd.Flags().Changed("skip-prefix") {
-			createOpts.SkipPrefix = *presetOpts.SkipPrefix
+		if presetOpts.SourceFromPreset != nil {
+			sourceFromPreset = *presetOpts.SourceFromPreset
 		}
 
 		// piece_length and target_piece_count are cross-flag aware:
-		// CLI --target-piece-count suppresses preset piece_length and vice versa
-		if presetOpts.PieceLength != 0 && !cmd.Flags().Changed("piece-length") && !cmd.Flags().Changed("target-piece-count") {
+		// CLI --target-piece-count or --max-piece-length suppresses preset piece_length
+		// and CLI --piece-length suppresses preset target_piece_count
+		if presetOpts.PieceLength != 0 && !cmd.Flags().Changed("piece-length") && !cmd.Flags().Changed("target-piece-count") && !cmd.Flags().Changed("max-piece-length") {
 			pieceLen := presetOpts.PieceLength
-			createOpts.PieceLengthExp = &pieceLen
+			pieceLengthExp = &pieceLen
 		}
 
 		if presetOpts.TargetPieceCount != 0 && !cmd.Flags().Changed("target-piece-count") && !cmd.Flags().Changed("piece-length") {
 			count := presetOpts.TargetPieceCount
-			createOpts.TargetPieceCount = &count
+			targetPieceCount = &count
 		}
 
 		if presetOpts.MaxPieceLength != 0 && !cmd.Flags().Changed("max-piece-length") {
 			maxPieceLen := presetOpts.MaxPieceLength
-			createOpts.MaxPieceLength = &maxPieceLen
-		}
-
-		if !cmd.Flags().Changed("entropy") && presetOpts.Entropy != nil {
-			createOpts.Entropy = *presetOpts.Entropy
-		}
-
-		if presetOpts.FailOnSeasonWarning != nil && !cmd.Flags().Changed("fail-on-season-warning") {
-			createOpts.FailOnSeasonPackWarning = *presetOpts.FailOnSeasonWarning
+			maxPieceLength = &maxPieceLen
 		}
 
 		if len(presetOpts.ExcludePatterns) > 0 {
 			if !cmd.Flags().Changed("exclude") {
-				createOpts.ExcludePatterns = slices.Clone(presetOpts.ExcludePatterns)
+				excludePatterns = slices.Clone(presetOpts.ExcludePatterns)
 			} else {
-				createOpts.ExcludePatterns = append(slices.Clone(presetOpts.ExcludePatterns), createOpts.ExcludePatterns...)
+				excludePatterns = append(slices.Clone(presetOpts.ExcludePatterns), excludePatterns...)
 			}
 		}
 
 		if len(presetOpts.IncludePatterns) > 0 {
 			if !cmd.Flags().Changed("include") {
-				createOpts.IncludePatterns = slices.Clone(presetOpts.IncludePatterns)
+				includePatterns = slices.Clone(presetOpts.IncludePatterns)
 			} else {
-				createOpts.IncludePatterns = append(slices.Clone(presetOpts.IncludePatterns), createOpts.IncludePatterns...)
+				includePatterns = append(slices.Clone(presetOpts.IncludePatterns), includePatterns...)
+			}
+		}
+
+		// --meta keys replace those of the preset with the same key
+		if len(presetOpts.Meta) > 0 {
+			merged := maps.Clone(presetOpts.Meta)
+			maps.Copy(merged, meta)
+			meta = merged
+		}
+	}
+
+	for key, value := range meta {
+		builder.WithRootField(key, value)
+	}
+
+	// URLs from the list file come after those given by flag or preset
+	if webSeedsFile != "" {
+		fileSeeds, warnings, err := torrent.ReadURLList(webSeedsFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read web seeds file: %w", err)
+		}
+		display := torrent.NewDisplay(torrent.NewFormatter(opts.verbose))
+		display.SetQuiet(opts.quiet)
+		for _, warning := range warnings {
+			display.ShowWarning(warning)
+		}
+		webSeeds = preset.MergeLists(webSeeds, fileSeeds)
+	}
+
+	// fall back to environment variables when neither flag nor preset provided a value
+	if len(trackerURLs) == 0 {
+		if envTracker := os.Getenv(trackerEnvVar); envTracker != "" {
+			trackerURLs = []string{envTracker}
+			if opts.verbose {
+				display := torrent.NewDisplay(torrent.NewFormatter(opts.verbose))
+				display.ShowMessage(fmt.Sprintf("using tracker from %s environment variable (%s)", trackerEnvVar, redactTrackerURL(envTracker)))
 			}
 		}
+	}
 
-		if presetOpts.Workers != 0 && !cmd.Flags().Changed("workers") {
-			createOpts.Workers = presetOpts.Workers
+	// refresh the tracker rules before anything reads them
+	if opts.fetchTrackerRules && len(trackerURLs) > 0 {
+		if err := trackers.FetchTrackerRules(trackerURLs[0], configsource.Timeout); err != nil {
+			display := torrent.NewDisplay(torrent.NewFormatter(opts.verbose))
+			display.SetQuiet(opts.quiet)
+			display.ShowWarning(fmt.Sprintf("using built-in tracker rules: %v", err))
+		}
+	}
+
+	if source == "" && !cmd.Flags().Changed("source") {
+		if envSource := os.Getenv(sourceEnvVar); envSource != "" {
+			source = envSource
 		}
 	}
 
 	// Check for tracker's default source only if no source is set by flag or preset
-	if createOpts.Source == "" && !cmd.Flags().Changed("source") && len(createOpts.TrackerURLs) > 0 {
-		if trackerSource, ok := trackers.GetTrackerDefaultSource(createOpts.TrackerURLs[0]); ok {
-			createOpts.Source = trackerSource
+	if source == "" && !cmd.Flags().Changed("source") && len(trackerURLs) > 0 {
+		if trackerSource, ok := trackers.GetTrackerDefaultSource(trackerURLs[0]); ok {
+			source = trackerSource
+		}
+	}
+
+	// source_from_preset is the last fallback, for presets named after their tracker
+	if source == "" && !cmd.Flags().Changed("source") && sourceFromPreset {
+		source = strings.ToUpper(opts.presetName)
+	}
+
+	if !opts.noValidateTrackers {
+		warnings, err := trackers.ValidateURLs(trackerURLs)
+		if err != nil {
+			return nil, err
+		}
+		display := torrent.NewDisplay(torrent.NewFormatter(opts.verbose))
+		display.SetQuiet(opts.quiet)
+		for _, warning := range warnings {
+			display.ShowWarning(warning)
 		}
 	}
 
 	// validate: piece_length and target_piece_count are mutually exclusive after all merging
-	if createOpts.PieceLengthExp != nil && createOpts.TargetPieceCount != nil {
-		return createOpts, fmt.Errorf("cannot use both --piece-length and --target-piece-count; use one or the other")
+	if pieceLengthExp != nil && targetPieceCount != nil {
+		return nil, fmt.Errorf("cannot use both --piece-length and --target-piece-count; use one or the other")
+	}
+
+	// max piece length only limits the automatic choice, so a preset max_piece_length
+	// gives way to an explicit piece length
+	if pieceLengthExp != nil && maxPieceLength != nil {
+		if cmd.Flags().Changed("max-piece-length") {
+			return nil, fmt.Errorf("cannot use both --piece-length and --max-piece-length; use one or the other")
+		}
+		maxPieceLength = nil
+	}
+
+	for _, trackerURL := range trackerURLs {
+		builder.WithTracker(trackerURL)
+	}
+	for _, webSeed := range webSeeds {
+		builder.WithWebSeed(webSeed)
+	}
+	for _, httpSeed := range opts.httpSeeds {
+		builder.WithHTTPSeed(httpSeed)
 	}
+	for _, pattern := range excludePatterns {
+		builder.WithExclude(pattern)
+	}
+	for _, pattern := range includePatterns {
+		builder.WithInclude(pattern)
+	}
+	for _, name := range opts.excludeDirs {
+		builder.WithExcludeDir(name)
+	}
+	builder.WithSource(source)
 
-	if opts.outputPath != "" {
-		createOpts.OutputPath = opts.outputPath
+	if pieceLengthExp != nil {
+		builder.WithPieceLength(*pieceLengthExp)
+	}
+	if maxPieceLength != nil {
+		builder.WithMaxPieceLength(*maxPieceLength)
+	}
+	if targetPieceCount != nil {
+		builder.WithTargetPieceCount(*targetPieceCount)
 	}
 
-	return createOpts, nil
+	return builder, nil
+}
+
+// redactTrackerURL returns only the scheme and host of a tracker URL so passkeys are never printed
+func redactTrackerURL(trackerURL string) string {
+	u, err := url.Parse(trackerURL)
+	if err != nil || u.Host == "" {
+		return "<redacted>"
+	}
+	return u.Scheme + "://" + u.Host
 }
 
 // createSingleTorrent handles creating a single torrent file
 func createSingleTorrent(cmd *cobra.Command, args []string, opts createOptions, version string, startTime time.Time) error {
 	inputPath := args[0]
 
-	createOpts, err := buildCreateOptions(cmd, inputPath, opts, version)
+	if len(opts.presetNames) > 1 {
+		return createTrackerSet(cmd, inputPath, opts, version, startTime)
+	}
+	if len(opts.presetNames) == 1 {
+		opts.presetName = opts.presetNames[0]
+	}
+
+	if opts.json {
+		// keep warnings out of the JSON on stdout
+		opts.quiet = true
+	}
+
+	builder, err := buildTorrent(cmd, inputPath, opts, version)
 	if err != nil {
 		return err
 	}
 
-	torrentInfo, err := torrent.Create(createOpts)
+	if opts.estimate {
+		return showEstimate(builder, opts)
+	}
+
+	torrentInfo, err := builder.Create()
 	if err != nil {
 		return err
 	}
 
+	if opts.json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(torrent.NewCreateSummary(torrentInfo))
+	}
+
 	if opts.quiet {
 		fmt.Println("Wrote:", torrentInfo.Path)
 	} else if !opts.infoOnly {
@@ -344,6 +661,116 @@ func createSingleTorrent(cmd *cobra.Command, args []string, opts createOptions,
 		display.ShowOutputPathWithTime(torrentInfo.Path, time.Since(startTime))
 	}
 
+	if opts.verify {
+		return verifyCreatedTorrent(torrentInfo.Path, inputPath, opts)
+	}
+	return nil
+}
+
+// createTrackerSet creates a torrent of the content for each preset, hashing
+// the content once for each piece length the presets end up with
+func createTrackerSet(cmd *cobra.Command, inputPath string, opts createOptions, version string, startTime time.Time) error {
+	switch {
+	case cmd.Flags().Changed("tracker"):
+		return fmt.Errorf("cannot use --tracker with more than one --preset; each preset sets its own trackers")
+	case opts.outputPath != "":
+		return fmt.Errorf("cannot use --output with more than one --preset; the torrents would overwrite each other")
+	case opts.skipPrefix:
+		return fmt.Errorf("cannot use --skip-prefix with more than one --preset; the torrents are told apart by their tracker prefix")
+	case opts.estimate:
+		return fmt.Errorf("--estimate is not supported with more than one --preset")
+	case opts.json:
+		return fmt.Errorf("--json is not supported with more than one --preset")
+	}
+
+	setOpts := make([]torrent.CreateOptions, 0, len(opts.presetNames))
+	for _, name := range opts.presetNames {
+		opts.presetName = name
+		builder, err := buildTorrent(cmd, inputPath, opts, version)
+		if err != nil {
+			return fmt.Errorf("preset %q: %w", name, err)
+		}
+		createOpts, err := builder.Options()
+		if err != nil {
+			return fmt.Errorf("preset %q: %w", name, err)
+		}
+		setOpts = append(setOpts, createOpts)
+	}
+
+	infos, err := torrent.CreateSet(setOpts)
+	if err != nil {
+		return err
+	}
+
+	display := torrent.NewDisplay(torrent.NewFormatter(opts.verbose))
+	for _, info := range infos {
+		if opts.quiet {
+			fmt.Println("Wrote:", info.Path)
+		} else {
+			display.ShowOutputPathWithTime(info.Path, time.Since(startTime))
+		}
+	}
+
+	if opts.verify {
+		for _, info := range infos {
+			if err := verifyCreatedTorrent(info.Path, inputPath, opts); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// showEstimate prints what the torrent would look like without hashing the content
+func showEstimate(builder *torrent.TorrentBuilder, opts createOptions) error {
+	estimate, err := builder.Estimate()
+	if err != nil {
+		return err
+	}
+
+	if opts.json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(estimate)
+	}
+
+	display := torrent.NewDisplay(torrent.NewFormatter(opts.verbose))
+	display.ShowEstimate(estimate)
+	return nil
+}
+
+// verifyCreatedTorrent checks the torrent just written against the content it
+// was created from, catching content that changed while it was hashed
+func verifyCreatedTorrent(torrentPath, contentPath string, opts createOptions) error {
+	maxReadRate, err := torrent.ParseByteRate(opts.throttle)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	result, err := torrent.VerifyData(torrent.VerifyOptions{
+		TorrentPath:           torrentPath,
+		ContentPath:           contentPath,
+		Verbose:               opts.verbose,
+		Quiet:                 opts.quiet || opts.infoOnly,
+		Workers:               opts.createWorkers,
+		MaxReadBytesPerSecond: maxReadRate,
+		NoProgress:            noProgress,
+	})
+	if err != nil {
+		return fmt.Errorf("verification failed: %w", err)
+	}
+
+	if opts.quiet {
+		fmt.Printf("Verified: %.2f%%\n", result.Completion)
+	} else {
+		display := torrent.NewDisplay(torrent.NewFormatter(opts.verbose))
+		display.ShowVerificationResult(result, time.Since(start))
+	}
+
+	if result.BadPieces > 0 || len(result.MissingFiles) > 0 {
+		return fmt.Errorf("created torrent does not match the content: %d bad pieces, %d missing files", result.BadPieces, len(result.MissingFiles))
+	}
 	return nil
 }
 
@@ -357,6 +784,18 @@ func runCreate(cmd *cobra.Command, args []string) error {
 	start := time.Now()
 
 	if options.batchFile != "" {
+		if options.verify {
+			return fmt.Errorf("--verify is not supported with --batch")
+		}
+		if options.estimate {
+			return fmt.Errorf("--estimate is not supported with --batch")
+		}
+		if options.json {
+			return fmt.Errorf("--json is not supported with --batch")
+		}
+		if options.fetchTrackerRules {
+			return fmt.Errorf("--fetch-tracker-rules is not supported with --batch")
+		}
 		return processBatchMode(options, version, start)
 	}
 
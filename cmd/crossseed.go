@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/mkbrr/torrent"
+)
+
+// crossSeedOptions encapsulates command-line flag values for the crossseed command
+type crossSeedOptions struct {
+	trackers   []string
+	source     string
+	comment    string
+	outputDir  string
+	workers    int
+	entropy    bool
+	skipPrefix bool
+	noDate     bool
+	force      bool
+	noValidate bool
+	verbose    bool
+	quiet      bool
+}
+
+var crossSeedOpts crossSeedOptions
+
+var crossSeedCmd = &cobra.Command{
+	Use:   "crossseed <source.torrent> <content-path>",
+	Short: "Create a torrent for another tracker from an existing torrent and verified data",
+	Long: `Verifies the content against an existing torrent and, if it matches completely,
+writes a copy for a new tracker. Name, piece length, pieces and the file list are kept
+verbatim so the data can be seeded immediately; only the trackers, source, comment and
+entropy change. The output filename uses the tracker domain as prefix like create does.
+
+Use "mkbrr crossseed check" to only test whether content can be cross-seeded.`,
+	Args:                       cobra.ExactArgs(2),
+	RunE:                       runCrossSeed,
+	DisableFlagsInUseLine:      true,
+	SuggestionsMinimumDistance: 1,
+	SilenceUsage:               true,
+}
+
+func init() {
+	crossSeedCmd.Flags().SortFlags = false
+	crossSeedCmd.Flags().StringArrayVarP(&crossSeedOpts.trackers, "tracker", "t", nil, "tracker URL of the target tracker (can be specified multiple times)")
+	crossSeedCmd.Flags().BoolVar(&crossSeedOpts.noValidate, "no-validate-trackers", false, "accept tracker URLs that fail validation")
+	crossSeedCmd.Flags().StringVarP(&crossSeedOpts.source, "source", "s", "", "set source string (default keeps the original)")
+	crossSeedCmd.Flags().StringVarP(&crossSeedOpts.comment, "comment", "c", "", "set comment (default keeps the original)")
+	crossSeedCmd.Flags().BoolVarP(&crossSeedOpts.entropy, "entropy", "e", false, "randomize info hash by adding entropy field")
+	crossSeedCmd.Flags().StringVar(&crossSeedOpts.outputDir, "output-dir", "", "output directory for the new torrent")
+	crossSeedCmd.Flags().BoolVar(&crossSeedOpts.skipPrefix, "skip-prefix", false, "don't add tracker domain prefix to output filename")
+	crossSeedCmd.Flags().BoolVarP(&crossSeedOpts.noDate, "no-date", "d", false, "don't update creation date")
+	crossSeedCmd.Flags().IntVar(&crossSeedOpts.workers, "workers", 0, "number of worker goroutines for verification (0 for automatic)")
+	crossSeedCmd.Flags().BoolVar(&crossSeedOpts.force, "force", false, "write the torrent even if the content does not match completely")
+	crossSeedCmd.Flags().BoolVarP(&crossSeedOpts.verbose, "verbose", "v", false, "be verbose")
+	crossSeedCmd.Flags().BoolVarP(&crossSeedOpts.quiet, "quiet", "q", false, "reduced output mode (prints only the final torrent path)")
+	_ = crossSeedCmd.MarkFlagRequired("tracker")
+
+	crossSeedCmd.SetUsageTemplate(`Usage:
+  {{.CommandPath}} <source.torrent> <content-path> --tracker <url> [flags]
+
+Flags:
+{{.LocalFlags.FlagUsages | trimTrailingWhitespaces}}
+`)
+
+	crossSeedCheckCmd.Flags().SortFlags = false
+	crossSeedCheckCmd.Flags().BoolVar(&crossSeedCheckOpts.auto, "auto", false, "check every entry of a directory with the size of torrent A")
+	crossSeedCheckCmd.Flags().IntVar(&crossSeedCheckOpts.workers, "workers", 0, "number of worker goroutines for verification (0 for automatic)")
+	crossSeedCheckCmd.Flags().BoolVarP(&crossSeedCheckOpts.verbose, "verbose", "v", false, "be verbose")
+	crossSeedCheckCmd.Flags().BoolVarP(&crossSeedCheckOpts.quiet, "quiet", "q", false, "reduced output mode (prints only the verdict, or the compatible paths with --auto)")
+
+	crossSeedCheckCmd.SetUsageTemplate(`Usage:
+  {{.CommandPath}} <torrent-a> <torrent-b> <content-b> [flags]
+  {{.CommandPath}} <torrent-a> <dir> --auto [flags]
+
+Flags:
+{{.LocalFlags.FlagUsages | trimTrailingWhitespaces}}
+`)
+	crossSeedCmd.AddCommand(crossSeedCheckCmd)
+}
+
+// crossSeedCheckOptions encapsulates command-line flag values for the crossseed check command
+type crossSeedCheckOptions struct {
+	auto    bool
+	workers int
+	verbose bool
+	quiet   bool
+}
+
+var crossSeedCheckOpts crossSeedCheckOptions
+
+var crossSeedCheckCmd = &cobra.Command{
+	Use:   "check <torrent-a> <torrent-b> <content-b>",
+	Short: "Check whether content can be cross-seeded with another torrent",
+	Long: `Checks whether the content of torrent B can be seeded with torrent A. Both torrents
+must have the same piece length and total size, and every piece of torrent A must
+match the content of B. Trackers, source and other metadata may differ.
+
+With --auto, pass torrent A and a directory instead: every entry of the directory with
+the total size of torrent A is checked as a candidate.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if crossSeedCheckOpts.auto {
+			return cobra.ExactArgs(2)(cmd, args)
+		}
+		return cobra.ExactArgs(3)(cmd, args)
+	},
+	RunE:                       runCrossSeedCheck,
+	DisableFlagsInUseLine:      true,
+	SuggestionsMinimumDistance: 1,
+	SilenceUsage:               true,
+}
+
+func runCrossSeedCheck(cmd *cobra.Command, args []string) error {
+	display := torrent.NewDisplay(torrent.NewFormatter(crossSeedCheckOpts.verbose))
+	display.SetQuiet(crossSeedCheckOpts.quiet)
+
+	check := func(otherTorrentPath, contentPath string) (*torrent.CrossSeedCheckResult, error) {
+		start := time.Now()
+		result, err := torrent.CheckCrossSeed(torrent.CrossSeedCheckOptions{
+			TorrentPath:      args[0],
+			OtherTorrentPath: otherTorrentPath,
+			ContentPath:      contentPath,
+			Workers:          crossSeedCheckOpts.workers,
+			Verbose:          crossSeedCheckOpts.verbose,
+			Quiet:            crossSeedCheckOpts.quiet,
+			NoProgress:       noProgress,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !crossSeedCheckOpts.quiet {
+			if result.Verification != nil {
+				display.ShowVerificationResult(result.Verification, time.Since(start))
+			}
+			display.ShowCrossSeedCheck(result)
+		}
+		return result, nil
+	}
+
+	if !crossSeedCheckOpts.auto {
+		result, err := check(args[1], args[2])
+		if err != nil {
+			return err
+		}
+		if crossSeedCheckOpts.quiet {
+			if result.Compatible {
+				fmt.Println("compatible")
+			} else {
+				fmt.Println("incompatible")
+			}
+		}
+		if !result.Compatible {
+			return fmt.Errorf("content %s is not compatible with %s", args[2], args[0])
+		}
+		return nil
+	}
+
+	candidates, err := torrent.FindCrossSeedCandidates(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("no content in %s has the size of %s", args[1], args[0])
+	}
+
+	var compatible int
+	for _, candidate := range candidates {
+		result, err := check("", candidate)
+		if err != nil {
+			return err
+		}
+		if result.Compatible {
+			compatible++
+			if crossSeedCheckOpts.quiet {
+				fmt.Println(candidate)
+			}
+		}
+	}
+	if compatible == 0 {
+		return fmt.Errorf("none of %d candidates in %s is compatible with %s", len(candidates), args[1], args[0])
+	}
+	return nil
+}
+
+func runCrossSeed(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+	display := torrent.NewDisplay(torrent.NewFormatter(crossSeedOpts.verbose))
+	display.SetQuiet(crossSeedOpts.quiet)
+
+	result, err := torrent.CrossSeed(torrent.CrossSeedOptions{
+		TorrentPath:        args[0],
+		ContentPath:        args[1],
+		TrackerURLs:        crossSeedOpts.trackers,
+		Source:             crossSeedOpts.source,
+		Comment:            crossSeedOpts.comment,
+		OutputDir:          crossSeedOpts.outputDir,
+		Version:            version,
+		Workers:            crossSeedOpts.workers,
+		Entropy:            crossSeedOpts.entropy,
+		SkipPrefix:         crossSeedOpts.skipPrefix,
+		NoDate:             crossSeedOpts.noDate,
+		Force:              crossSeedOpts.force,
+		NoValidateTrackers: crossSeedOpts.noValidate,
+		Verbose:            crossSeedOpts.verbose,
+		Quiet:              crossSeedOpts.quiet,
+		NoProgress:         noProgress,
+	})
+	if result != nil && result.Verification != nil {
+		display.ShowVerificationResult(result.Verification, time.Since(start))
+	}
+	if err != nil {
+		if errors.Is(err, torrent.ErrIncompleteContent) {
+			return fmt.Errorf("%w (use --force to write the torrent anyway)", err)
+		}
+		return err
+	}
+
+	if crossSeedOpts.quiet {
+		fmt.Println("Wrote:", result.OutputPath)
+		return nil
+	}
+
+	display.ShowMessage(fmt.Sprintf("new info hash %s", result.InfoHash))
+	display.ShowOutputPathWithTime(result.OutputPath, time.Since(start))
+	return nil
+}
@@ -1,20 +1,33 @@
 package cmd
 
 import (
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/anacrolix/torrent/bencode"
 	"github.com/anacrolix/torrent/metainfo"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
+	"github.com/autobrr/mkbrr/internal/trackers"
 	"github.com/autobrr/mkbrr/torrent"
 )
 
 // inspectOptions encapsulates command-line flag values for the inspect command
 type inspectOptions struct {
-	verbose bool
+	extractPieces string
+	exportPieces  string
+	piecesFormat  string
+	dumpInfo      string
+	format        string
+	dumpInfoHex   bool
+	verbose       bool
+	tree          bool
+	stripPasskeys bool
+	validate      bool
 }
 
 var (
@@ -24,9 +37,14 @@ var (
 )
 
 var inspectCmd = &cobra.Command{
-	Use:                        "inspect [flags] [torrent files...]",
-	Short:                      "Inspect torrent files",
-	Long:                       "Inspect torrent files",
+	Use:   "inspect [flags] [torrent files...]",
+	Short: "Inspect torrent files",
+	Long: `Inspect torrent files.
+
+Use --format with a Go template to print selected fields, one line per torrent and nothing
+else. The fields are Name, InfoHash, Size, PieceLength, PieceCount, Announce, AnnounceList,
+Private, Source, Comment, CreationDate (unix seconds), CreatedBy, Files (each with Path and
+Size) and WebSeeds, e.g. --format "{{.Name}} {{.InfoHash}}".`,
 	Args:                       cobra.MinimumNArgs(1),
 	RunE:                       runInspect,
 	DisableFlagsInUseLine:      true,
@@ -36,7 +54,17 @@ var inspectCmd = &cobra.Command{
 
 func init() {
 	inspectCmd.Flags().SortFlags = false
-	inspectCmd.Flags().BoolVarP(&inspectOpts.verbose, "verbose", "v", false, "show all metadata fields")
+	inspectCmd.Flags().StringVar(&inspectOpts.format, "format", "", "print only this Go template per torrent, e.g. \"{{.Name}} {{.InfoHash}}\"")
+	inspectCmd.Flags().BoolVarP(&inspectOpts.verbose, "verbose", "v", false, "show all metadata fields, piece statistics and the file tree")
+	inspectCmd.Flags().BoolVar(&inspectOpts.tree, "tree", false, "show the file tree of multi-file torrents without the other verbose output")
+	inspectCmd.Flags().BoolVar(&inspectOpts.validate, "validate", false, "check the torrent for corrupt or tampered fields and fail if any check fails")
+	inspectCmd.Flags().BoolVar(&inspectOpts.stripPasskeys, "strip-passkeys", false, "remove passkeys from the displayed tracker URLs and magnet link")
+	inspectCmd.Flags().StringVar(&inspectOpts.extractPieces, "extract-pieces", "", "write all piece hashes to this file (\"-\" for stdout)")
+	inspectCmd.Flags().StringVar(&inspectOpts.piecesFormat, "pieces-format", torrent.PiecesFormatHex, "format of extracted piece hashes: hex, base64 or binary")
+	inspectCmd.Flags().StringVar(&inspectOpts.exportPieces, "export-pieces", "", "write the piece hashes and file layout to this file (\"-\" for stdout), for mkbrr check --pieces")
+	inspectCmd.Flags().StringVar(&inspectOpts.dumpInfo, "dump-info", "", "write the raw bencoded info dictionary to stdout, or to a file with --dump-info=<file>")
+	inspectCmd.Flags().Lookup("dump-info").NoOptDefVal = "-"
+	inspectCmd.Flags().BoolVar(&inspectOpts.dumpInfoHex, "dump-info-hex", false, "print a hex view of the raw info dictionary to stdout")
 	inspectCmd.SetUsageTemplate(`Usage:
   {{.CommandPath}} [flags] [torrent files...]
 
@@ -65,15 +93,59 @@ func loadTorrentData(filePath string) (mi *metainfo.MetaInfo, info *metainfo.Inf
 	return mi, &parsedInfo, rawBytes, nil
 }
 
-// displayStandardInfo shows the core information about the torrent
-func displayStandardInfo(display *torrent.Display, mi *metainfo.MetaInfo, info *metainfo.Info) {
-	t := &torrent.Torrent{MetaInfo: mi}
+// displayStandardInfo shows the core information about the torrent. With
+// stripPasskeys the tracker URLs are shown without passkeys; otherwise a
+// warning is shown when they look like they contain one.
+func displayStandardInfo(display *torrent.Display, rawBytes []byte, mi *metainfo.MetaInfo, info *metainfo.Info, stripPasskeys bool) {
+	if stripPasskeys {
+		mi = stripAnnouncePasskeys(mi)
+	} else if hasAnnouncePasskey(mi) {
+		display.ShowWarning("announce URL may contain a passkey. Use --strip-passkeys to display sanitized output.")
+	}
+	if strings.Contains(mi.Announce, ",") {
+		display.ShowWarning("announce field holds several comma-separated URLs, which most clients do not support (mkbrr modify moves them to the announce list)")
+	}
+
+	t := &torrent.Torrent{MetaInfo: mi, HTTPSeeds: torrent.ParseHTTPSeeds(rawBytes)}
 	display.ShowTorrentInfo(t, info)
+	display.ShowFileAttrs(info)
+}
+
+// hasAnnouncePasskey reports whether any announce URL of mi looks like it contains a passkey
+func hasAnnouncePasskey(mi *metainfo.MetaInfo) bool {
+	for _, tier := range append(metainfo.AnnounceList{{mi.Announce}}, mi.AnnounceList...) {
+		for _, trackerURL := range tier {
+			if found, _ := trackers.DetectPasskey(trackerURL); found {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stripAnnouncePasskeys returns a copy of mi with its announce URLs reduced to
+// trackers.CanonicalURL, for display only.
+func stripAnnouncePasskeys(mi *metainfo.MetaInfo) *metainfo.MetaInfo {
+	stripped := *mi
+	if stripped.Announce != "" {
+		stripped.Announce = trackers.CanonicalURL(stripped.Announce)
+	}
+	if len(mi.AnnounceList) == 0 {
+		return &stripped
+	}
+	stripped.AnnounceList = make(metainfo.AnnounceList, len(mi.AnnounceList))
+	for i, tier := range mi.AnnounceList {
+		stripped.AnnounceList[i] = make([]string, len(tier))
+		for j, trackerURL := range tier {
+			stripped.AnnounceList[i][j] = trackers.CanonicalURL(trackerURL)
+		}
+	}
+	return &stripped
 }
 
 // displayVerboseInfo shows additional metadata fields found in the torrent file
-func displayVerboseInfo(rawBytes []byte, mi *metainfo.MetaInfo) {
-	fmt.Printf("%s\n", cyan("Additional metadata:"))
+func displayVerboseInfo(w io.Writer, rawBytes []byte, mi *metainfo.MetaInfo) {
+	fmt.Fprintf(w, "%s\n", cyan("Additional metadata:"))
 
 	// Display extra root-level fields
 	rootMap := make(map[string]interface{})
@@ -81,12 +153,12 @@ func displayVerboseInfo(rawBytes []byte, mi *metainfo.MetaInfo) {
 		standardRoot := map[string]bool{
 			"announce": true, "announce-list": true, "comment": true,
 			"created by": true, "creation date": true, "info": true,
-			"url-list": true, "nodes": true,
+			"url-list": true, "httpseeds": true, "nodes": true,
 		}
 
 		for k, v := range rootMap {
 			if !standardRoot[k] {
-				fmt.Printf("  %-13s %v\n", label(k+":"), v)
+				fmt.Fprintf(w, "  %-13s %v\n", label(k+":"), v)
 			}
 		}
 	}
@@ -103,11 +175,11 @@ func displayVerboseInfo(rawBytes []byte, mi *metainfo.MetaInfo) {
 
 		for k, v := range infoMap {
 			if !standardInfo[k] {
-				fmt.Printf("  %-13s %v\n", label("info."+k+":"), v)
+				fmt.Fprintf(w, "  %-13s %v\n", label("info."+k+":"), v)
 			}
 		}
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // displayFileTreeIfNeeded shows the file tree if the torrent contains multiple files
@@ -117,21 +189,191 @@ func displayFileTreeIfNeeded(display *torrent.Display, info *metainfo.Info) {
 	}
 }
 
+// extractPieces writes the piece hashes of info to path, or to stdout for "-",
+// and reports the number of pieces written.
+func extractPieces(display *torrent.Display, info *metainfo.Info, path, format string) error {
+	var w io.Writer = os.Stdout
+	dest := "stdout"
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("error creating pieces file: %w", err)
+		}
+		defer f.Close()
+		w = f
+		dest = path
+	}
+
+	count, err := torrent.WritePieceHashes(w, info, format)
+	if err != nil {
+		return err
+	}
+
+	if format == "" {
+		format = torrent.PiecesFormatHex
+	}
+	display.ShowPiecesExtracted(count, format, dest)
+	return nil
+}
+
+// exportPieces writes the piece export of info to path, or to stdout for "-".
+func exportPieces(display *torrent.Display, info *metainfo.Info, path string) error {
+	if path == "-" {
+		return torrent.WritePiecesExport(os.Stdout, info)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating piece export: %w", err)
+	}
+	defer f.Close()
+
+	if err := torrent.WritePiecesExport(f, info); err != nil {
+		return err
+	}
+	display.ShowMessage(fmt.Sprintf("exported %d piece hashes to %s", len(info.Pieces)/20, path))
+	return nil
+}
+
+// dumpInfo writes the info dictionary exactly as stored in the torrent file,
+// without re-encoding, to path or to stdout for "-".
+func dumpInfo(display *torrent.Display, mi *metainfo.MetaInfo, path string) error {
+	if path == "-" {
+		_, err := os.Stdout.Write(mi.InfoBytes)
+		return err
+	}
+
+	if err := os.WriteFile(path, mi.InfoBytes, 0644); err != nil {
+		return fmt.Errorf("error writing info dictionary: %w", err)
+	}
+	display.ShowMessage(fmt.Sprintf("wrote %d byte info dictionary to %s", len(mi.InfoBytes), path))
+	return nil
+}
+
+// validateInspectArgs checks that the data output flags do not conflict.
+func validateInspectArgs(args []string) error {
+	if inspectOpts.format != "" {
+		if inspectOpts.verbose || inspectOpts.tree || inspectOpts.validate || inspectOpts.extractPieces != "" ||
+			inspectOpts.exportPieces != "" || inspectOpts.dumpInfo != "" || inspectOpts.dumpInfoHex {
+			return fmt.Errorf("--format cannot be combined with other output flags")
+		}
+	}
+
+	stdoutWriters := 0
+	for _, toStdout := range []bool{inspectOpts.extractPieces == "-", inspectOpts.exportPieces == "-", inspectOpts.dumpInfo == "-", inspectOpts.dumpInfoHex} {
+		if toStdout {
+			stdoutWriters++
+		}
+	}
+	if stdoutWriters > 1 {
+		return fmt.Errorf("only one of --extract-pieces -, --export-pieces -, --dump-info and --dump-info-hex can write to stdout")
+	}
+
+	dataOutput := inspectOpts.extractPieces != "" || inspectOpts.exportPieces != "" || inspectOpts.dumpInfo != "" || inspectOpts.dumpInfoHex
+	if dataOutput && len(args) > 1 {
+		return fmt.Errorf("--extract-pieces, --export-pieces and --dump-info accept a single torrent file, got %d", len(args))
+	}
+
+	return torrent.ValidatePiecesFormat(inspectOpts.piecesFormat)
+}
+
+// runInspectFormat prints only the --format template for each torrent. The
+// template is parsed before the first torrent is loaded.
+func runInspectFormat(args []string) error {
+	tmpl, err := torrent.ParseInspectFormat(inspectOpts.format)
+	if err != nil {
+		return err
+	}
+	for _, path := range args {
+		mi, info, _, err := loadTorrentData(path)
+		if err != nil {
+			return err
+		}
+		if inspectOpts.stripPasskeys {
+			mi = stripAnnouncePasskeys(mi)
+		}
+		if err := torrent.FormatInspect(os.Stdout, tmpl, mi, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func runInspect(cmd *cobra.Command, args []string) error {
+	if err := validateInspectArgs(args); err != nil {
+		return err
+	}
+
+	if inspectOpts.format != "" {
+		return runInspectFormat(args)
+	}
+
 	display := torrent.NewDisplay(torrent.NewFormatter(inspectOpts.verbose))
+	var out io.Writer = os.Stdout
+	if inspectOpts.extractPieces == "-" || inspectOpts.exportPieces == "-" || inspectOpts.dumpInfo == "-" || inspectOpts.dumpInfoHex {
+		// keep stdout for the raw data
+		out = os.Stderr
+		display.SetOutput(out)
+	}
+
+	var invalid int
 	for _, path := range args {
 		mi, info, rawBytes, err := loadTorrentData(path)
 		if err != nil {
 			return err
 		}
 
-		displayStandardInfo(display, mi, info)
+		displayStandardInfo(display, rawBytes, mi, info, inspectOpts.stripPasskeys)
 
 		if inspectOpts.verbose {
-			displayVerboseInfo(rawBytes, mi)
+			displayVerboseInfo(out, rawBytes, mi)
+			display.ShowPieceStats(info)
+		}
+
+		// --tree shows the tree without the rest of the verbose output
+		if inspectOpts.verbose || inspectOpts.tree {
 			displayFileTreeIfNeeded(display, info)
 		}
+
+		if inspectOpts.validate {
+			errs := torrent.ValidateTorrent(mi, info)
+			display.ShowValidation(errs)
+			if len(errs) > 0 {
+				invalid++
+			}
+		}
+
+		if inspectOpts.extractPieces != "" {
+			if err := extractPieces(display, info, inspectOpts.extractPieces, inspectOpts.piecesFormat); err != nil {
+				return err
+			}
+		}
+
+		if inspectOpts.exportPieces != "" {
+			if err := exportPieces(display, info, inspectOpts.exportPieces); err != nil {
+				return err
+			}
+		}
+
+		if inspectOpts.dumpInfo != "" {
+			if err := dumpInfo(display, mi, inspectOpts.dumpInfo); err != nil {
+				return err
+			}
+		}
+
+		if inspectOpts.dumpInfoHex {
+			dumper := hex.Dumper(os.Stdout)
+			if _, err := dumper.Write(mi.InfoBytes); err != nil {
+				return err
+			}
+			if err := dumper.Close(); err != nil {
+				return err
+			}
+		}
 	}
 
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d torrent(s) failed validation", invalid, len(args))
+	}
 	return nil
 }
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/mkbrr/torrent"
+)
+
+// lintOptions encapsulates command-line flag values for the lint command
+type lintOptions struct {
+	fix    bool
+	output string
+	quiet  bool
+}
+
+var lintOpts lintOptions
+
+var lintCmd = &cobra.Command{
+	Use:   "lint <torrent-file>...",
+	Short: "Check torrent files for structural problems",
+	Long: `Checks torrent files for structural problems that clients handle differently or
+reject, such as a pieces field that does not match the content size, negative lengths,
+file paths escaping the torrent directory, duplicate file paths, a private flag other
+than 0 or 1, or a passkey announce URL without the private flag. The torrent is read as
+raw bencode, so problems that would stop other commands from loading it are reported too.
+
+Errors make clients reject or misread the torrent, warnings point at something that is
+likely not intended. The command fails when any torrent has errors.
+
+Rules: ` + strings.Join(torrent.LintRules(), ", ") + `
+
+--fix rewrites torrents with the fixable issues fixed: duplicate and empty trackers and
+empty tiers are dropped from the announce list, and path components holding a path
+separator are split. Splitting path components changes the info hash.`,
+	Args:                       cobra.MinimumNArgs(1),
+	RunE:                       runLint,
+	DisableFlagsInUseLine:      true,
+	SuggestionsMinimumDistance: 1,
+	SilenceUsage:               true,
+}
+
+func init() {
+	lintCmd.Flags().SortFlags = false
+	lintCmd.Flags().BoolVar(&lintOpts.fix, "fix", false, "fix the fixable issues and write the torrent back")
+	lintCmd.Flags().StringVarP(&lintOpts.output, "output", "o", "", "with --fix, write the fixed torrent here instead (single torrent only)")
+	lintCmd.Flags().BoolVarP(&lintOpts.quiet, "quiet", "q", false, "reduced output mode (prints one line per issue)")
+
+	lintCmd.SetUsageTemplate(`Usage:
+  {{.CommandPath}} <torrent-file>... [flags]
+
+Flags:
+{{.LocalFlags.FlagUsages | trimTrailingWhitespaces}}
+`)
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	if lintOpts.output != "" {
+		if !lintOpts.fix {
+			return fmt.Errorf("--output requires --fix")
+		}
+		if len(args) > 1 {
+			return fmt.Errorf("--output can only be used with a single torrent")
+		}
+	}
+
+	display := torrent.NewDisplay(torrent.NewFormatter(false))
+	display.SetQuiet(lintOpts.quiet)
+
+	failed := 0
+	for _, path := range args {
+		result, err := torrent.LintTorrent(path, torrent.LintOptions{
+			Fix:        lintOpts.fix,
+			OutputPath: lintOpts.output,
+		})
+		if err != nil {
+			if lintOpts.quiet {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			display.ShowError(err.Error())
+			failed++
+			continue
+		}
+		if lintOpts.quiet {
+			for _, issue := range result.Issues {
+				fmt.Printf("%s: %s %s: %s\n", path, issue.Severity, issue.Rule, issue.Message)
+			}
+		}
+		display.ShowLintResult(result)
+		if result.HasErrors() {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d torrent(s) have errors", failed, len(args))
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -11,24 +13,43 @@ import (
 
 // modifyOptions encapsulates command-line flag values for the modify command
 type modifyOptions struct {
-	PresetName string
-	PresetFile string
-	Name       string
-	OutputDir  string
-	Output     string
-	Trackers   []string
-	Comment    string
-	Source     string
-	WebSeeds   []string
-	DryRun     bool
-	NoDate     bool
-	NoCreator  bool
-	Verbose    bool
-	Quiet      bool
-	SkipPrefix bool
-	Private    bool
-	NoPrivate  bool
-	Entropy    bool
+	PresetName     string
+	PresetFile     string
+	Name           string
+	OutputDir      string
+	Output         string
+	Trackers       []string
+	AddTrackers    []string
+	RemoveTrackers []string
+	SetPrimary     bool
+	Comment        string
+	Source         string
+	WebSeeds       []string
+	DryRun         bool
+	NoDate         bool
+	Date           string
+	NoCreator      bool
+	Verbose        bool
+	Quiet          bool
+	SkipPrefix     bool
+	Private        bool
+	NoPrivate      bool
+	Entropy        bool
+
+	SkipIfSourceMatches  bool
+	SkipIfTrackerMatches bool
+	NoValidateTrackers   bool
+	AnnounceRandom       bool
+
+	Strip         []string
+	StripKeys     []string
+	StripInfoKeys []string
+	Creator       string
+
+	CopyFrom   string
+	CopyFields []string
+
+	NormalizeName bool
 }
 
 var modifyOpts = modifyOptions{
@@ -53,23 +74,38 @@ Note: All unnecessary metadata will be stripped.`,
 func init() {
 	modifyCmd.Flags().SortFlags = false
 	modifyCmd.Flags().StringVarP(&modifyOpts.PresetName, "preset", "P", "", "use preset from config")
-	modifyCmd.Flags().StringVar(&modifyOpts.PresetFile, "preset-file", "", "preset config file (default: ~/.config/mkbrr/presets.yaml)")
-	modifyCmd.Flags().StringVar(&modifyOpts.Name, "name", "", "set the torrent's internal name")
+	modifyCmd.Flags().StringVar(&modifyOpts.PresetFile, "preset-file", "", "preset config file, \"-\" for stdin or an http(s) URL (default: ~/.config/mkbrr/presets.yaml)")
+	modifyCmd.Flags().StringVar(&modifyOpts.Name, "name", "", "set the torrent's internal name, which changes the info hash and the default output filename")
+	modifyCmd.Flags().BoolVar(&modifyOpts.NormalizeName, "normalize-name", false, "trim spaces, collapse repeated whitespace and remove trailing dots in the (new) name")
 	modifyCmd.Flags().StringVar(&modifyOpts.OutputDir, "output-dir", "", "output directory for modified files")
 	modifyCmd.Flags().StringVarP(&modifyOpts.Output, "output", "o", "", "custom output filename (without extension)")
 	modifyCmd.Flags().BoolVarP(&modifyOpts.NoDate, "no-date", "d", false, "don't update creation date")
+	modifyCmd.Flags().StringVar(&modifyOpts.Date, "date", "", "set this creation date (unix seconds or RFC3339) instead of the current time")
 	modifyCmd.Flags().BoolVarP(&modifyOpts.NoCreator, "no-creator", "", false, "don't write creator")
+	modifyCmd.Flags().StringVar(&modifyOpts.Creator, "creator", "", "replace the creator with this string (--no-creator wins)")
 	modifyCmd.Flags().StringArrayVarP(&modifyOpts.Trackers, "tracker", "t", nil, "tracker URLs (can be specified multiple times)")
+	modifyCmd.Flags().StringArrayVar(&modifyOpts.AddTrackers, "add-tracker", nil, "append a tracker URL to the existing ones, skipping duplicates (can be specified multiple times)")
+	modifyCmd.Flags().StringArrayVar(&modifyOpts.RemoveTrackers, "remove-tracker", nil, "remove every tracker URL containing this text, before --add-tracker (can be specified multiple times)")
+	modifyCmd.Flags().BoolVar(&modifyOpts.SetPrimary, "set-primary", false, "make the first --add-tracker URL the primary announce URL")
+	modifyCmd.Flags().BoolVar(&modifyOpts.AnnounceRandom, "announce-random", false, "shuffle the order of the trackers in the announce list")
+	modifyCmd.Flags().BoolVar(&modifyOpts.NoValidateTrackers, "no-validate-trackers", false, "accept tracker URLs that fail validation")
 	modifyCmd.Flags().StringArrayVarP(&modifyOpts.WebSeeds, "web-seed", "w", nil, "add web seed URLs")
 	modifyCmd.Flags().BoolVarP(&modifyOpts.Private, "private", "p", true, "make torrent private")
 	modifyCmd.Flags().BoolVar(&modifyOpts.NoPrivate, "no-private", false, "remove private flag entirely")
 	modifyCmd.Flags().StringVarP(&modifyOpts.Comment, "comment", "c", "", "set comment (use empty string to remove)")
 	modifyCmd.Flags().StringVarP(&modifyOpts.Source, "source", "s", "", "set source string (use empty string to remove)")
+	modifyCmd.Flags().StringArrayVar(&modifyOpts.Strip, "strip", nil, "remove a field: "+strings.Join(torrent.StripFields, ", ")+" (can be specified multiple times)")
+	modifyCmd.Flags().StringArrayVar(&modifyOpts.StripKeys, "strip-key", nil, "remove a key from the root dictionary (can be specified multiple times)")
+	modifyCmd.Flags().StringArrayVar(&modifyOpts.StripInfoKeys, "strip-info-key", nil, "remove a key from the info dictionary, changing the info hash (can be specified multiple times)")
+	modifyCmd.Flags().StringVar(&modifyOpts.CopyFrom, "copy-from", "", "torrent to copy the --copy-field info fields from, changing the info hash")
+	modifyCmd.Flags().StringArrayVar(&modifyOpts.CopyFields, "copy-field", nil, "info field to copy from the --copy-from torrent (can be specified multiple times)")
 	modifyCmd.Flags().BoolVarP(&modifyOpts.Entropy, "entropy", "e", false, "randomize info hash by adding entropy field")
 	modifyCmd.Flags().BoolVarP(&modifyOpts.Verbose, "verbose", "v", false, "be verbose")
 	modifyCmd.Flags().BoolVarP(&modifyOpts.Quiet, "quiet", "q", false, "reduced output mode (prints only final torrent paths)")
 	modifyCmd.Flags().BoolVarP(&modifyOpts.SkipPrefix, "skip-prefix", "", false, "don't add tracker domain prefix to output filename")
 	modifyCmd.Flags().BoolVarP(&modifyOpts.DryRun, "dry-run", "n", false, "show what would be modified without making changes")
+	modifyCmd.Flags().BoolVar(&modifyOpts.SkipIfSourceMatches, "skip-if-source-matches", false, "skip torrents whose source already matches the new source")
+	modifyCmd.Flags().BoolVar(&modifyOpts.SkipIfTrackerMatches, "skip-if-tracker-matches", false, "skip torrents that already announce to one of the new trackers")
 
 	modifyCmd.SetUsageTemplate(`Usage:
   {{.CommandPath}} [flags] [torrent files...]
@@ -80,7 +116,7 @@ Flags:
 }
 
 // buildTorrentOptions creates a torrent.ModifyOptions struct from command-line flags
-func buildTorrentOptions(cmd *cobra.Command, opts modifyOptions) torrent.ModifyOptions {
+func buildTorrentOptions(cmd *cobra.Command, opts modifyOptions) (torrent.ModifyOptions, error) {
 	torrentOpts := torrent.ModifyOptions{
 		PresetName:    opts.PresetName,
 		PresetFile:    opts.PresetFile,
@@ -98,6 +134,40 @@ func buildTorrentOptions(cmd *cobra.Command, opts modifyOptions) torrent.ModifyO
 		Source:        opts.Source,
 		Version:       version,
 		SkipPrefix:    opts.SkipPrefix,
+
+		SkipIfSourceMatches:  opts.SkipIfSourceMatches,
+		SkipIfTrackerMatches: opts.SkipIfTrackerMatches,
+		NoValidateTrackers:   opts.NoValidateTrackers,
+
+		RandomizeAnnounceList: opts.AnnounceRandom,
+
+		Strip:         opts.Strip,
+		StripKeys:     opts.StripKeys,
+		StripInfoKeys: opts.StripInfoKeys,
+		Creator:       opts.Creator,
+
+		CopyInfoFieldsFrom: opts.CopyFrom,
+		InfoFieldNames:     opts.CopyFields,
+
+		NormalizeName: opts.NormalizeName,
+
+		AddTrackerURLs: opts.AddTrackers,
+		SetPrimary:     opts.SetPrimary,
+		RemoveTrackers: opts.RemoveTrackers,
+	}
+
+	if opts.SetPrimary && len(opts.AddTrackers) == 0 {
+		return torrentOpts, fmt.Errorf("--set-primary requires --add-tracker")
+	}
+	if slices.Contains(opts.RemoveTrackers, "") {
+		return torrentOpts, fmt.Errorf("--remove-tracker cannot be empty, it would remove every tracker")
+	}
+
+	if err := torrent.ValidateStrip(torrentOpts); err != nil {
+		return torrentOpts, err
+	}
+	if err := torrent.ValidateCopyInfoFields(torrentOpts); err != nil {
+		return torrentOpts, err
 	}
 
 	if cmd.Flags().Changed("private") {
@@ -121,26 +191,62 @@ func buildTorrentOptions(cmd *cobra.Command, opts modifyOptions) torrent.ModifyO
 		torrentOpts.Entropy = &opts.Entropy
 	}
 
-	return torrentOpts
+	if opts.Date != "" {
+		if opts.NoDate {
+			return torrentOpts, fmt.Errorf("cannot use both --date and --no-date")
+		}
+		date, err := torrent.ParseCreationDate(opts.Date)
+		if err != nil {
+			return torrentOpts, err
+		}
+		torrentOpts.CreationDate = date
+	}
+
+	return torrentOpts, nil
+}
+
+// showRemovedTrackers reports how many tracker URLs --remove-tracker dropped
+// from the torrent of result
+func showRemovedTrackers(display *torrent.Display, result *torrent.Result, opts modifyOptions) {
+	if len(opts.RemoveTrackers) > 0 {
+		display.ShowMessage(fmt.Sprintf("Removed %d tracker URL(s) from %s", result.RemovedTrackers, result.Path))
+	}
 }
 
 // displayModifyResults handles showing the results of torrent modification
 func displayModifyResults(results []*torrent.Result, opts modifyOptions, display *torrent.Display, startTime time.Time) int {
 	successCount := 0
+	skippedCount := 0
+	failedCount := 0
+	defer func() {
+		if len(results) > 1 {
+			display.ShowModifyResults(successCount, skippedCount, failedCount, time.Since(startTime))
+		}
+	}()
 
 	for _, result := range results {
 		if result.Error != nil {
 			display.ShowError(fmt.Sprintf("Error processing %s: %v", result.Path, result.Error))
+			failedCount++
+			continue
+		}
+
+		if result.SkipReason != "" {
+			display.ShowMessage(fmt.Sprintf("Skipping %s (%s)", result.Path, result.SkipReason))
+			skippedCount++
 			continue
 		}
 
 		if !result.WasModified {
 			display.ShowMessage(fmt.Sprintf("Skipping %s (no changes needed)", result.Path))
+			skippedCount++
 			continue
 		}
 
 		if opts.DryRun {
 			display.ShowMessage(fmt.Sprintf("Would modify %s", result.Path))
+			showRemovedTrackers(display, result, opts)
+			display.ShowStripped(result.Stripped, result.NewInfoHash)
 			continue
 		}
 
@@ -153,8 +259,14 @@ func displayModifyResults(results []*torrent.Result, opts modifyOptions, display
 					display.ShowTorrentInfo(mi, &info)
 				}
 			}
+			// stripped fields report a new info hash themselves
+			if len(result.Stripped) == 0 {
+				display.ShowInfoHashChanged(result.NewInfoHash)
+			}
 		}
 
+		showRemovedTrackers(display, result, opts)
+		display.ShowStripped(result.Stripped, result.NewInfoHash)
 		if opts.Quiet {
 			fmt.Println("Wrote:", result.OutputPath)
 		} else {
@@ -174,7 +286,10 @@ func runModify(cmd *cobra.Command, args []string) error {
 	display.ShowMessage(fmt.Sprintf("Modifying %d torrent files...", len(args)))
 
 	// Build torrent options from command-line flags
-	torrentOpts := buildTorrentOptions(cmd, modifyOpts)
+	torrentOpts, err := buildTorrentOptions(cmd, modifyOpts)
+	if err != nil {
+		return err
+	}
 
 	// Process the torrent files
 	results, err := torrent.ProcessTorrents(args, torrentOpts)
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/mkbrr/torrent"
+)
+
+// repieceOptions encapsulates command-line flag values for the repiece command
+type repieceOptions struct {
+	pieceLength uint
+	output      string
+	workers     int
+	skipVerify  bool
+	force       bool
+	noDate      bool
+	verbose     bool
+	quiet       bool
+}
+
+var repieceOpts repieceOptions
+
+var repieceCmd = &cobra.Command{
+	Use:   "repiece <torrent-file> <content-path>",
+	Short: "Re-hash an existing torrent at a new piece length",
+	Long: `Re-hashes the content of an existing torrent at a new piece length and writes a copy
+with the new pieces. The content is verified against the original first, unless
+--skip-verify is given. Everything else is kept: name, file list and order, trackers,
+source, private flag, comment and custom info keys. The info hash changes.
+
+The result is written next to the input as <name>.repiece.torrent unless --output is given.`,
+	Args:                       cobra.ExactArgs(2),
+	RunE:                       runRepiece,
+	DisableFlagsInUseLine:      true,
+	SuggestionsMinimumDistance: 1,
+	SilenceUsage:               true,
+}
+
+func init() {
+	repieceCmd.Flags().SortFlags = false
+	repieceCmd.Flags().UintVarP(&repieceOpts.pieceLength, "piece-length", "l", 0, "new piece length as 2^n bytes (16-27)")
+	repieceCmd.Flags().StringVarP(&repieceOpts.output, "output", "o", "", "output path of the new torrent")
+	repieceCmd.Flags().BoolVar(&repieceOpts.skipVerify, "skip-verify", false, "don't verify the content against the original torrent first")
+	repieceCmd.Flags().BoolVar(&repieceOpts.force, "force", false, "write the torrent even if the content does not match the original completely")
+	repieceCmd.Flags().BoolVarP(&repieceOpts.noDate, "no-date", "d", false, "don't write creation date")
+	repieceCmd.Flags().IntVar(&repieceOpts.workers, "workers", 0, "number of worker goroutines for hashing (0 for automatic)")
+	repieceCmd.Flags().BoolVarP(&repieceOpts.verbose, "verbose", "v", false, "be verbose")
+	repieceCmd.Flags().BoolVarP(&repieceOpts.quiet, "quiet", "q", false, "reduced output mode (prints only the final torrent path)")
+	_ = repieceCmd.MarkFlagRequired("piece-length")
+
+	repieceCmd.SetUsageTemplate(`Usage:
+  {{.CommandPath}} <torrent-file> <content-path> --piece-length <n> [flags]
+
+Flags:
+{{.LocalFlags.FlagUsages | trimTrailingWhitespaces}}
+`)
+}
+
+func runRepiece(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+	display := torrent.NewDisplay(torrent.NewFormatter(repieceOpts.verbose))
+	display.SetQuiet(repieceOpts.quiet)
+
+	result, err := torrent.Repiece(torrent.RepieceOptions{
+		TorrentPath:    args[0],
+		ContentPath:    args[1],
+		PieceLengthExp: repieceOpts.pieceLength,
+		OutputPath:     repieceOpts.output,
+		Workers:        repieceOpts.workers,
+		SkipVerify:     repieceOpts.skipVerify,
+		Force:          repieceOpts.force,
+		NoDate:         repieceOpts.noDate,
+		Verbose:        repieceOpts.verbose,
+		Quiet:          repieceOpts.quiet,
+		NoProgress:     noProgress,
+	})
+	if result != nil && result.Verification != nil {
+		display.ShowVerificationResult(result.Verification, time.Since(start))
+	}
+	if err != nil {
+		if errors.Is(err, torrent.ErrIncompleteContent) {
+			return fmt.Errorf("%w (use --force to repiece anyway)", err)
+		}
+		return err
+	}
+
+	if repieceOpts.quiet {
+		fmt.Println("Wrote:", result.OutputPath)
+		return nil
+	}
+
+	display.ShowMessage(fmt.Sprintf("%d pieces of %d KiB, new info hash %s", result.NumPieces, result.PieceLength>>10, result.InfoHash))
+	display.ShowOutputPathWithTime(result.OutputPath, time.Since(start))
+	return nil
+}
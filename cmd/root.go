@@ -1,7 +1,16 @@
 package cmd
 
 import (
+	"log/slog"
+	"os"
+	"strings"
+
 	"github.com/spf13/cobra"
+
+	"github.com/autobrr/mkbrr/internal/config"
+	"github.com/autobrr/mkbrr/internal/configsource"
+	"github.com/autobrr/mkbrr/internal/trackers"
+	"github.com/autobrr/mkbrr/torrent"
 )
 
 const banner = `         __   ___.                 
@@ -12,21 +21,107 @@ const banner = `         __   ___.
       \/     \/    \/              `
 
 var rootCmd = &cobra.Command{
-	Use:   "mkbrr",
-	Short: "A tool to inspect and create torrent files",
-	Long:  banner + "\n\nmkbrr is a tool to create and inspect torrent files.",
+	Use:               "mkbrr",
+	Short:             "A tool to inspect and create torrent files",
+	Long:              banner + "\n\nmkbrr is a tool to create and inspect torrent files.",
+	PersistentPreRunE: setupCommand,
 }
 
+var (
+	logLevel   string
+	logJSON    bool
+	noProgress bool
+	siUnits    bool
+)
+
 func init() {
 	cobra.EnableCommandSorting = false
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "warn", "diagnostic log level: "+strings.Join(torrent.LogLevels, ", "))
+	rootCmd.PersistentFlags().BoolVar(&logJSON, "log-json", false, "write diagnostic logs to stderr as JSON")
+	rootCmd.PersistentFlags().BoolVar(&siUnits, "si", false, "show sizes in decimal units (kB, MB, GB) instead of binary units (KiB, MiB, GiB)")
+	rootCmd.PersistentFlags().BoolVar(&noProgress, "no-progress", false, "print hashing progress as periodic lines instead of a progress bar (the default when output is not a terminal)")
 	rootCmd.AddCommand(createCmd)
 	rootCmd.AddCommand(checkCmd)
 	rootCmd.AddCommand(inspectCmd)
 	rootCmd.AddCommand(modifyCmd)
+	rootCmd.AddCommand(crossSeedCmd)
+	rootCmd.AddCommand(repieceCmd)
+	rootCmd.AddCommand(convertCmd)
+	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(benchCmd)
+	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(updateCmd)
 	rootCmd.AddCommand(versionCmd)
 }
 
+// trackerConfigURLEnv names the environment variable with the URL of a shared
+// tracker config. It takes precedence over tracker_config_url in the global config.
+const trackerConfigURLEnv = "MKBRR_TRACKER_CONFIG_URL"
+
+// setupCommand runs before every command: it sets up logging, applies the
+// global config to the flags that were not given on the command line and
+// registers the shared tracker config if one is configured.
+func setupCommand(cmd *cobra.Command, args []string) error {
+	if err := setupLogging(cmd, args); err != nil {
+		return err
+	}
+	if siUnits {
+		torrent.SetDefaultUnits(torrent.UnitsDecimal)
+	}
+
+	globalConfig, _, err := loadGlobalConfig()
+	if err != nil {
+		return err
+	}
+	if err := globalConfig.Apply(cmd.Flags()); err != nil {
+		return err
+	}
+	return loadRemoteTrackerConfig(globalConfig)
+}
+
+// loadRemoteTrackerConfig fetches and registers the tracker config from
+// MKBRR_TRACKER_CONFIG_URL or the global config, if either sets a URL.
+func loadRemoteTrackerConfig(globalConfig *config.Config) error {
+	url := os.Getenv(trackerConfigURLEnv)
+	if url == "" && globalConfig.TrackerConfigURL != nil {
+		url = *globalConfig.TrackerConfigURL
+	}
+	if url == "" {
+		return nil
+	}
+
+	if globalConfig.TrackerConfigTTL != nil {
+		trackers.RemoteCacheTTL = *globalConfig.TrackerConfigTTL
+	}
+	return trackers.FetchAndRegisterTrackerConfig(url, configsource.Timeout)
+}
+
+// loadGlobalConfig loads ~/.config/mkbrr/config.yaml and returns its path.
+// A missing file yields an empty config.
+func loadGlobalConfig() (*config.Config, string, error) {
+	path, err := config.DefaultPath()
+	if err != nil {
+		// without a home directory there is no global config to apply
+		return &config.Config{}, "", nil
+	}
+	globalConfig, err := config.Load(path)
+	if err != nil {
+		return nil, path, err
+	}
+	return globalConfig, path, nil
+}
+
+// setupLogging sets the default logger used for diagnostic messages
+// such as files skipped during the walk. User-facing output is unaffected.
+func setupLogging(cmd *cobra.Command, args []string) error {
+	level, err := torrent.ParseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	torrent.SetDefaultLogger(slog.New(torrent.NewLogHandler(os.Stderr, level, logJSON)))
+	return nil
+}
+
 func Execute() error {
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 	rootCmd.SilenceUsage = false
@@ -0,0 +1,179 @@
+// Package config loads global defaults for command flags from
+// ~/.config/mkbrr/config.yaml. They replace the built-in flag defaults, so
+// explicit flags and preset values still take precedence over them.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the global defaults. Keys left out of the file are nil and keep
+// the built-in default.
+type Config struct {
+	Workers    *int    `yaml:"workers"`
+	OutputDir  *string `yaml:"output_dir"`
+	Quiet      *bool   `yaml:"quiet"`
+	Verbose    *bool   `yaml:"verbose"`
+	SkipPrefix *bool   `yaml:"skip_prefix"`
+	Preset     *string `yaml:"preset"`
+
+	// TrackerConfigURL and TrackerConfigTTL locate a shared tracker config and
+	// how long its download is cached. They have no matching flag.
+	TrackerConfigURL *string        `yaml:"tracker_config_url"`
+	TrackerConfigTTL *time.Duration `yaml:"tracker_config_ttl"`
+}
+
+// Key pairs a config file key with the flag it provides the default for.
+type Key struct {
+	Name string
+	Flag string
+}
+
+// Keys lists the supported config keys in display order.
+var Keys = []Key{
+	{Name: "workers", Flag: "workers"},
+	{Name: "output_dir", Flag: "output-dir"},
+	{Name: "quiet", Flag: "quiet"},
+	{Name: "verbose", Flag: "verbose"},
+	{Name: "skip_prefix", Flag: "skip-prefix"},
+	{Name: "preset", Flag: "preset"},
+}
+
+// Source names where an effective value came from.
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceConfig  Source = "config"
+	SourcePreset  Source = "preset"
+	SourceFlag    Source = "flag"
+)
+
+// Setting is an effective value and its source.
+type Setting struct {
+	Key    string
+	Value  string
+	Source Source
+}
+
+// DefaultPath returns ~/.config/mkbrr/config.yaml.
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "mkbrr", "config.yaml"), nil
+}
+
+// Load reads the config file at path. A missing file is not an error and
+// yields an empty Config; unknown keys are rejected to catch typos.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read config: %w", err)
+	}
+
+	var config Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("could not parse config %s: %w", path, err)
+	}
+
+	if config.Workers != nil && *config.Workers < 0 {
+		return nil, fmt.Errorf("config %s: workers must not be negative, got %d", path, *config.Workers)
+	}
+
+	if config.TrackerConfigTTL != nil && *config.TrackerConfigTTL < 0 {
+		return nil, fmt.Errorf("config %s: tracker_config_ttl must not be negative, got %s", path, *config.TrackerConfigTTL)
+	}
+
+	return &config, nil
+}
+
+// Values returns the keys set in the config as flag value strings.
+func (c *Config) Values() map[string]string {
+	values := make(map[string]string)
+	if c.Workers != nil {
+		values["workers"] = strconv.Itoa(*c.Workers)
+	}
+	if c.OutputDir != nil {
+		values["output_dir"] = *c.OutputDir
+	}
+	if c.Quiet != nil {
+		values["quiet"] = strconv.FormatBool(*c.Quiet)
+	}
+	if c.Verbose != nil {
+		values["verbose"] = strconv.FormatBool(*c.Verbose)
+	}
+	if c.SkipPrefix != nil {
+		values["skip_prefix"] = strconv.FormatBool(*c.SkipPrefix)
+	}
+	if c.Preset != nil {
+		values["preset"] = *c.Preset
+	}
+	return values
+}
+
+// Apply sets the flags in fs that were not given on the command line to the
+// config values. The flags are not marked as changed, so preset merging that
+// checks Changed still overrides them. Flags fs does not define are skipped.
+func (c *Config) Apply(fs *pflag.FlagSet) error {
+	values := c.Values()
+	for _, key := range Keys {
+		value, ok := values[key.Name]
+		if !ok {
+			continue
+		}
+		flag := fs.Lookup(key.Flag)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if err := flag.Value.Set(value); err != nil {
+			return fmt.Errorf("invalid config value for %s: %w", key.Name, err)
+		}
+	}
+	return nil
+}
+
+// Resolve returns the effective value of every key in Keys, taken from the
+// first source that sets it: flags, then the preset, then the global config,
+// then the built-in defaults. Maps are keyed by config key name.
+func Resolve(flags, preset, global, defaults map[string]string) []Setting {
+	sources := []struct {
+		values map[string]string
+		source Source
+	}{
+		{flags, SourceFlag},
+		{preset, SourcePreset},
+		{global, SourceConfig},
+		{defaults, SourceDefault},
+	}
+
+	settings := make([]Setting, 0, len(Keys))
+	for _, key := range Keys {
+		setting := Setting{Key: key.Name, Source: SourceDefault}
+		for _, s := range sources {
+			if value, ok := s.values[key.Name]; ok {
+				setting.Value = value
+				setting.Source = s.source
+				break
+			}
+		}
+		settings = append(settings, setting)
+	}
+	return settings
+}
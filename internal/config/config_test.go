@@ -0,0 +1,266 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/autobrr/mkbrr/internal/preset"
+	"github.com/autobrr/mkbrr/torrent"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `
+workers: 4
+output_dir: /data/torrents
+skip_prefix: true
+preset: ptp
+`)
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	values := config.Values()
+	want := map[string]string{
+		"workers":     "4",
+		"output_dir":  "/data/torrents",
+		"skip_prefix": "true",
+		"preset":      "ptp",
+	}
+	if len(values) != len(want) {
+		t.Errorf("Values() = %v, want %v", values, want)
+	}
+	for key, value := range want {
+		if values[key] != value {
+			t.Errorf("Values()[%q] = %q, want %q", key, values[key], value)
+		}
+	}
+}
+
+func TestLoad_TrackerConfig(t *testing.T) {
+	path := writeConfig(t, `
+tracker_config_url: https://config.example/trackers.yaml
+tracker_config_ttl: 12h
+`)
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if config.TrackerConfigURL == nil || *config.TrackerConfigURL != "https://config.example/trackers.yaml" {
+		t.Errorf("TrackerConfigURL = %v, want the configured url", config.TrackerConfigURL)
+	}
+	if config.TrackerConfigTTL == nil || *config.TrackerConfigTTL != 12*time.Hour {
+		t.Errorf("TrackerConfigTTL = %v, want 12h", config.TrackerConfigTTL)
+	}
+	// the tracker config keys have no flag, so they are not flag values
+	if len(config.Values()) != 0 {
+		t.Errorf("Values() = %v, want no flag values", config.Values())
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	config, err := Load(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatalf("expected a missing config to be ignored, got %v", err)
+	}
+	if len(config.Values()) != 0 {
+		t.Errorf("expected an empty config, got %v", config.Values())
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "unknown key", content: "worker: 4\n", wantErr: "field worker not found"},
+		{name: "wrong type", content: "quiet: sometimes\n", wantErr: "could not parse config"},
+		{name: "negative workers", content: "workers: -1\n", wantErr: "workers must not be negative"},
+		{name: "invalid ttl", content: "tracker_config_ttl: daily\n", wantErr: "could not parse config"},
+		{name: "negative ttl", content: "tracker_config_ttl: -1h\n", wantErr: "tracker_config_ttl must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	var workers int
+	var outputDir string
+	var quiet bool
+	fs := pflag.NewFlagSet("create", pflag.ContinueOnError)
+	fs.IntVar(&workers, "workers", 0, "")
+	fs.StringVar(&outputDir, "output-dir", "", "")
+	fs.BoolVar(&quiet, "quiet", false, "")
+
+	if err := fs.Parse([]string{"--workers", "8"}); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+
+	config, err := Load(writeConfig(t, "workers: 2\noutput_dir: /data\nquiet: true\nverbose: true\n"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := config.Apply(fs); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	if workers != 8 {
+		t.Errorf("workers = %d, want the flag value 8", workers)
+	}
+	if outputDir != "/data" {
+		t.Errorf("outputDir = %q, want the config value %q", outputDir, "/data")
+	}
+	if !quiet {
+		t.Error("expected quiet from the config")
+	}
+	// config values are defaults, so presets can still override them
+	if fs.Changed("output-dir") || fs.Changed("quiet") {
+		t.Error("expected flags set from the config to stay unchanged")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	defaults := map[string]string{"workers": "0", "output_dir": "", "quiet": "false", "verbose": "false", "skip_prefix": "false", "preset": ""}
+	global := map[string]string{"workers": "2", "output_dir": "/global", "quiet": "true", "skip_prefix": "true"}
+	preset := map[string]string{"workers": "4", "output_dir": "/preset"}
+	flags := map[string]string{"workers": "8"}
+
+	want := map[string]Setting{
+		"workers":     {Key: "workers", Value: "8", Source: SourceFlag},
+		"output_dir":  {Key: "output_dir", Value: "/preset", Source: SourcePreset},
+		"quiet":       {Key: "quiet", Value: "true", Source: SourceConfig},
+		"skip_prefix": {Key: "skip_prefix", Value: "true", Source: SourceConfig},
+		"verbose":     {Key: "verbose", Value: "false", Source: SourceDefault},
+		"preset":      {Key: "preset", Value: "", Source: SourceDefault},
+	}
+
+	settings := Resolve(flags, preset, global, defaults)
+	if len(settings) != len(Keys) {
+		t.Fatalf("expected %d settings, got %d", len(Keys), len(settings))
+	}
+	for i, setting := range settings {
+		if setting.Key != Keys[i].Name {
+			t.Errorf("settings[%d].Key = %q, want %q", i, setting.Key, Keys[i].Name)
+		}
+		if setting != want[setting.Key] {
+			t.Errorf("setting %q = %+v, want %+v", setting.Key, setting, want[setting.Key])
+		}
+	}
+}
+
+// TestPrecedence runs the steps of create from parsed flags to the create
+// options: Apply fills in the global config, then the preset overrides every
+// flag that was not given.
+func TestPrecedence(t *testing.T) {
+	skipPrefix := false
+	fullPreset := &preset.Options{Workers: 4, OutputDir: "/preset", SkipPrefix: &skipPrefix}
+	fullConfig := "workers: 2\noutput_dir: /config\nskip_prefix: true\n"
+
+	tests := []struct {
+		name           string
+		args           []string
+		preset         *preset.Options
+		config         string
+		wantWorkers    int
+		wantOutputDir  string
+		wantSkipPrefix bool
+	}{
+		{
+			name:           "flag over preset",
+			args:           []string{"--workers", "8", "--output-dir", "/flag", "--skip-prefix"},
+			preset:         fullPreset,
+			config:         fullConfig,
+			wantWorkers:    8,
+			wantOutputDir:  "/flag",
+			wantSkipPrefix: true,
+		},
+		{
+			name:           "preset over global config",
+			preset:         fullPreset,
+			config:         fullConfig,
+			wantWorkers:    4,
+			wantOutputDir:  "/preset",
+			wantSkipPrefix: false,
+		},
+		{
+			name:           "global config over default",
+			preset:         &preset.Options{},
+			config:         fullConfig,
+			wantWorkers:    2,
+			wantOutputDir:  "/config",
+			wantSkipPrefix: true,
+		},
+		{
+			name:   "default",
+			preset: &preset.Options{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var workers int
+			var outputDir string
+			var skipPrefix bool
+			fs := pflag.NewFlagSet("create", pflag.ContinueOnError)
+			fs.IntVar(&workers, "workers", 0, "")
+			fs.StringVar(&outputDir, "output-dir", "", "")
+			fs.BoolVar(&skipPrefix, "skip-prefix", false, "")
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatalf("failed to parse flags: %v", err)
+			}
+
+			config, err := Load(writeConfig(t, tt.config))
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if err := config.Apply(fs); err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+
+			opts, err := torrent.NewTorrentBuilder("content").
+				WithWorkers(workers).
+				WithOutputDir(outputDir).
+				WithSkipPrefix(skipPrefix).
+				WithPreset(tt.preset, fs.Changed).
+				Options()
+			if err != nil {
+				t.Fatalf("Options failed: %v", err)
+			}
+
+			if opts.Workers != tt.wantWorkers {
+				t.Errorf("Workers = %d, want %d", opts.Workers, tt.wantWorkers)
+			}
+			if opts.OutputDir != tt.wantOutputDir {
+				t.Errorf("OutputDir = %q, want %q", opts.OutputDir, tt.wantOutputDir)
+			}
+			if opts.SkipPrefix != tt.wantSkipPrefix {
+				t.Errorf("SkipPrefix = %v, want %v", opts.SkipPrefix, tt.wantSkipPrefix)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Package configsource reads YAML configs (batch files, presets) from a local
+// file, standard input ("-") or an HTTP(S) URL.
+package configsource
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stdin is the location that reads the config from standard input.
+const Stdin = "-"
+
+var (
+	// MaxSize caps the size of configs read from stdin or a URL.
+	MaxSize int64 = 1 << 20
+
+	// Timeout bounds the whole HTTP request, including reading the body.
+	Timeout = 10 * time.Second
+)
+
+// stdin can only be consumed once, so its content is kept for later reads
+// (e.g. modify loading the preset once per torrent)
+var stdinCache struct {
+	sync.Mutex
+	file *os.File
+	data []byte
+}
+
+// IsRemote reports whether location is an HTTP(S) URL.
+func IsRemote(location string) bool {
+	lower := strings.ToLower(location)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// IsFile reports whether location refers to a local file rather than stdin or a URL.
+// Relative paths inside configs that are not files are resolved against the
+// current working directory.
+func IsFile(location string) bool {
+	return location != Stdin && !IsRemote(location)
+}
+
+// Read returns the content of the config at location.
+func Read(location string) ([]byte, error) {
+	switch {
+	case location == Stdin:
+		return readStdin()
+	case IsRemote(location):
+		return Fetch(location, Timeout)
+	default:
+		return os.ReadFile(location)
+	}
+}
+
+func readStdin() ([]byte, error) {
+	stdinCache.Lock()
+	defer stdinCache.Unlock()
+
+	if stdinCache.file == os.Stdin && stdinCache.data != nil {
+		return stdinCache.data, nil
+	}
+
+	data, err := readLimited(os.Stdin, "stdin")
+	if err != nil {
+		return nil, err
+	}
+	stdinCache.file = os.Stdin
+	stdinCache.data = data
+	return data, nil
+}
+
+// Fetch downloads the config at url, failing on a non-200 response, a body
+// larger than MaxSize or when the request takes longer than timeout.
+func Fetch(url string, timeout time.Duration) ([]byte, error) {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not fetch %s: unexpected status %s", url, resp.Status)
+	}
+
+	return readLimited(resp.Body, url)
+}
+
+// readLimited reads r fully, failing if it holds more than MaxSize bytes.
+func readLimited(r io.Reader, name string) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("could not read config from %s: %w", name, err)
+	}
+	if int64(len(data)) > MaxSize {
+		return nil, fmt.Errorf("config from %s exceeds the %d byte limit", name, MaxSize)
+	}
+	return data, nil
+}
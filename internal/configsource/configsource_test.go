@@ -0,0 +1,109 @@
+package configsource
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// pipeStdin replaces os.Stdin with a pipe holding content for the duration of the test.
+func pipeStdin(t *testing.T, content string) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	if _, err := w.WriteString(content); err != nil {
+		t.Fatalf("failed to write to pipe: %v", err)
+	}
+	w.Close()
+
+	orig := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = orig
+		r.Close()
+	})
+}
+
+func TestIsFile(t *testing.T) {
+	tests := []struct {
+		location string
+		want     bool
+	}{
+		{location: "batch.yaml", want: true},
+		{location: "/etc/mkbrr/presets.yaml", want: true},
+		{location: "-", want: false},
+		{location: "https://example.com/batch.yaml", want: false},
+		{location: "HTTP://example.com/batch.yaml", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := IsFile(tt.location); got != tt.want {
+			t.Errorf("IsFile(%q) = %v, want %v", tt.location, got, tt.want)
+		}
+	}
+}
+
+func TestRead_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("version: 1\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	data, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if string(data) != "version: 1\n" {
+		t.Errorf("Read() = %q", data)
+	}
+}
+
+func TestRead_Stdin(t *testing.T) {
+	pipeStdin(t, "version: 1\n")
+
+	for i := 0; i < 2; i++ {
+		data, err := Read(Stdin)
+		if err != nil {
+			t.Fatalf("Read(stdin) error = %v", err)
+		}
+		if string(data) != "version: 1\n" {
+			t.Errorf("Read(stdin) call %d = %q", i+1, data)
+		}
+	}
+}
+
+func TestRead_URL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/batch.yaml":
+			w.Write([]byte("version: 1\n"))
+		case "/large.yaml":
+			w.Write([]byte(strings.Repeat("#", int(MaxSize)+1)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	data, err := Read(server.URL + "/batch.yaml")
+	if err != nil {
+		t.Fatalf("Read(url) error = %v", err)
+	}
+	if string(data) != "version: 1\n" {
+		t.Errorf("Read(url) = %q", data)
+	}
+
+	if _, err := Read(server.URL + "/large.yaml"); err == nil || !strings.Contains(err.Error(), "byte limit") {
+		t.Errorf("expected size limit error, got %v", err)
+	}
+
+	if _, err := Read(server.URL + "/missing.yaml"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected status error, got %v", err)
+	}
+}
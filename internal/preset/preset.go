@@ -3,14 +3,21 @@ package preset
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/anacrolix/torrent/bencode"
 	"github.com/anacrolix/torrent/metainfo"
+	"github.com/dustin/go-humanize"
 	"gopkg.in/yaml.v3"
+
+	"github.com/autobrr/mkbrr/internal/configsource"
+	"github.com/autobrr/mkbrr/internal/sanitize"
+	"github.com/autobrr/mkbrr/internal/trackers"
 )
 
 // ErrPresetFileNotFound is returned when no preset file can be found in known locations
@@ -37,16 +44,29 @@ type Options struct {
 	Version             string   `json:"-"` // used for creator string, not exposed to frontend
 	Trackers            []string `yaml:"trackers" json:"trackers,omitempty"`
 	WebSeeds            []string `yaml:"webseeds" json:"webSeeds,omitempty"`
+	WebSeedsFile        string   `yaml:"webseeds_file" json:"webSeedsFile,omitempty"` // URL list file, see torrent.ReadURLList
 	ExcludePatterns     []string `yaml:"exclude_patterns" json:"excludePatterns,omitempty"`
 	IncludePatterns     []string `yaml:"include_patterns" json:"includePatterns,omitempty"`
 	PieceLength         uint     `yaml:"piece_length" json:"pieceLength,omitempty"`
 	MaxPieceLength      uint     `yaml:"max_piece_length" json:"maxPieceLength,omitempty"`
 	TargetPieceCount    uint     `yaml:"target_piece_count" json:"targetPieceCount,omitempty"`
 	Workers             int      `yaml:"workers" json:"workers,omitempty"`
+	SourceFromPreset    *bool    `yaml:"source_from_preset" json:"sourceFromPreset,omitempty"` // uppercased preset name as source when none is set
+	SkipHidden          *bool    `yaml:"skip_hidden" json:"skipHidden,omitempty"`
+	Creator             string   `yaml:"creator" json:"creator,omitempty"` // replaces the default created by field
+
+	// Meta holds additional root-level string fields, e.g. publisher; a
+	// preset's keys are added to those of the defaults
+	Meta map[string]string `yaml:"meta" json:"meta,omitempty"`
 }
 
-// FindPresetFile searches for a preset file in known locations
+// FindPresetFile searches for a preset file in known locations.
+// An explicit stdin ("-") or HTTP(S) location is returned as-is.
 func FindPresetFile(explicitPath string) (string, error) {
+	if explicitPath != "" && !configsource.IsFile(explicitPath) {
+		return explicitPath, nil
+	}
+
 	// check known locations in order
 	locations := []string{
 		explicitPath,   // explicitly specified file
@@ -71,9 +91,9 @@ func FindPresetFile(explicitPath string) (string, error) {
 	return "", ErrPresetFileNotFound
 }
 
-// Load loads presets from a config file
+// Load loads presets from a config file, stdin ("-") or an HTTP(S) URL
 func Load(configPath string) (*Config, error) {
-	data, err := os.ReadFile(configPath)
+	data, err := configsource.Read(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("could not read preset config: %w", err)
 	}
@@ -174,15 +194,14 @@ func (c *Config) GetPreset(name string) (*Options, error) {
 	defaultPrivate := true
 	defaultNoDate := false
 	defaultNoCreator := false
-	defaultSkipPrefix := false
 	defaultWorkers := 0 // auto
 
+	// skip_prefix has no hardcoded default so a global config value still applies
 	merged := Options{
-		Private:    &defaultPrivate,
-		NoDate:     &defaultNoDate,
-		NoCreator:  &defaultNoCreator,
-		SkipPrefix: &defaultSkipPrefix,
-		Workers:    defaultWorkers,
+		Private:   &defaultPrivate,
+		NoDate:    &defaultNoDate,
+		NoCreator: &defaultNoCreator,
+		Workers:   defaultWorkers,
 	}
 
 	// if we have defaults in config, use those instead
@@ -204,6 +223,7 @@ func (c *Config) GetPreset(name string) (*Options, error) {
 		}
 		merged.Trackers = c.Default.Trackers
 		merged.WebSeeds = c.Default.WebSeeds
+		merged.WebSeedsFile = c.Default.WebSeedsFile
 		merged.Comment = c.Default.Comment
 		merged.Source = c.Default.Source
 		merged.OutputDir = c.Default.OutputDir
@@ -220,6 +240,14 @@ func (c *Config) GetPreset(name string) (*Options, error) {
 		if c.Default.Entropy != nil {
 			merged.Entropy = c.Default.Entropy
 		}
+		if c.Default.SourceFromPreset != nil {
+			merged.SourceFromPreset = c.Default.SourceFromPreset
+		}
+		if c.Default.SkipHidden != nil {
+			merged.SkipHidden = c.Default.SkipHidden
+		}
+		merged.Creator = c.Default.Creator
+		merged.Meta = maps.Clone(c.Default.Meta)
 	}
 
 	// override with preset values if they are set
@@ -229,6 +257,9 @@ func (c *Config) GetPreset(name string) (*Options, error) {
 	if len(preset.WebSeeds) > 0 {
 		merged.WebSeeds = preset.WebSeeds
 	}
+	if preset.WebSeedsFile != "" {
+		merged.WebSeedsFile = preset.WebSeedsFile
+	}
 	if preset.Comment != "" {
 		merged.Comment = preset.Comment
 	}
@@ -276,13 +307,62 @@ func (c *Config) GetPreset(name string) (*Options, error) {
 	if preset.FailOnSeasonWarning != nil {
 		merged.FailOnSeasonWarning = preset.FailOnSeasonWarning
 	}
+	if preset.SourceFromPreset != nil {
+		merged.SourceFromPreset = preset.SourceFromPreset
+	}
+	if preset.SkipHidden != nil {
+		merged.SkipHidden = preset.SkipHidden
+	}
+	if preset.Creator != "" {
+		merged.Creator = preset.Creator
+	}
+	if len(preset.Meta) > 0 {
+		if merged.Meta == nil {
+			merged.Meta = make(map[string]string, len(preset.Meta))
+		}
+		maps.Copy(merged.Meta, preset.Meta)
+	}
+
+	if err := merged.validatePieceLength(name); err != nil {
+		return nil, err
+	}
 
 	return &merged, nil
 }
 
-// ApplyToMetaInfo applies preset options to a MetaInfo object.
+// validatePieceLength checks piece_length and max_piece_length against the
+// limit of the preset's first tracker, so a misconfigured preset fails when it
+// is loaded rather than after the content has been walked and hashed.
+func (o *Options) validatePieceLength(presetName string) error {
+	if len(o.Trackers) == 0 || o.Trackers[0] == "" {
+		return nil
+	}
+
+	maxExp, ok := trackers.GetTrackerMaxPieceLength(o.Trackers[0])
+	if !ok {
+		return nil
+	}
+
+	for _, field := range []struct {
+		name string
+		exp  uint
+	}{
+		{name: "piece_length", exp: o.PieceLength},
+		{name: "max_piece_length", exp: o.MaxPieceLength},
+	} {
+		if field.exp > maxExp {
+			return fmt.Errorf("preset %q: %s %d (%s) exceeds the maximum of %d (%s) for %s",
+				presetName, field.name, field.exp, humanize.IBytes(1<<field.exp), maxExp, humanize.IBytes(1<<maxExp), o.Trackers[0])
+		}
+	}
+
+	return nil
+}
+
+// ApplyToMetaInfo applies preset options to a MetaInfo object, dating it at
+// when NoDate is false.
 // Info-level changes are applied via raw map to preserve custom keys (e.g. entropy).
-func (o *Options) ApplyToMetaInfo(mi *metainfo.MetaInfo) (bool, error) {
+func (o *Options) ApplyToMetaInfo(mi *metainfo.MetaInfo, at time.Time) (bool, error) {
 	wasModified := false
 
 	// track info-level changes to apply via raw map at the end
@@ -341,7 +421,7 @@ func (o *Options) ApplyToMetaInfo(mi *metainfo.MetaInfo) (bool, error) {
 		if *o.NoDate {
 			mi.CreationDate = 0
 		} else {
-			mi.CreationDate = time.Now().Unix()
+			mi.CreationDate = at.Unix()
 		}
 		wasModified = true
 	}
@@ -417,6 +497,30 @@ func GetDomainPrefix(trackerURL string) string {
 	return "modified"
 }
 
+// ExpandOutputDirAt expands template variables in an output directory, with
+// the date variables taken from t.
+// Supported variables are {year}, {month}, {day}, {weekday} and {tracker},
+// e.g. "/torrents/{tracker}/{year}/{month}" becomes "/torrents/example/2024/01".
+func ExpandOutputDirAt(tmpl string, trackerURL string, t time.Time) string {
+	if !strings.Contains(tmpl, "{") {
+		return tmpl
+	}
+
+	var tracker string
+	if trackerURL != "" {
+		tracker = GetDomainPrefix(trackerURL)
+	}
+
+	replacer := strings.NewReplacer(
+		"{year}", t.Format("2006"),
+		"{month}", t.Format("01"),
+		"{day}", t.Format("02"),
+		"{weekday}", t.Format("Monday"),
+		"{tracker}", tracker,
+	)
+	return replacer.Replace(tmpl)
+}
+
 // GenerateOutputPath generates an output path for a modified torrent file
 func GenerateOutputPath(originalPath, outputDir, presetName string, outputPattern string, trackerURL string, metaInfoName string, skipPrefix bool) string {
 	dir := filepath.Dir(originalPath)
@@ -450,6 +554,21 @@ func GenerateOutputPath(originalPath, outputDir, presetName string, outputPatter
 	return filepath.Join(dir, prefix+"_"+name+ext)
 }
 
+// MergeLists returns base followed by the entries of extra that are not in it
+// yet. Order is kept and only the first occurrence of a duplicate survives.
+func MergeLists(base, extra []string) []string {
+	merged := make([]string, 0, len(base)+len(extra))
+	seen := make(map[string]bool, len(base)+len(extra))
+	for _, entry := range slices.Concat(base, extra) {
+		if seen[entry] {
+			continue
+		}
+		seen[entry] = true
+		merged = append(merged, entry)
+	}
+	return merged
+}
+
 // LoadPresetOptions loads and returns preset options from a file by name.
 // It handles the full process of loading the presets file and resolving the named preset,
 // including applying any default settings.
@@ -479,20 +598,11 @@ func LoadPresetOptions(presetFilePath string, presetName string) (*Options, erro
 	return presetOpts, nil
 }
 
-// sanitizeFilename removes characters that are invalid in filenames
+// sanitizeFilename removes characters that are invalid in filenames.
+// Spaces are also replaced since the result is used as a filename prefix.
 func sanitizeFilename(input string) string {
-	// replace characters that are problematic in filenames
-	replacer := strings.NewReplacer(
-		"/", "_",
-		"\\", "_",
-		":", "_",
-		"*", "_",
-		"?", "_",
-		"\"", "_",
-		"<", "_",
-		">", "_",
-		"|", "_",
-		" ", "_",
-	)
-	return replacer.Replace(input)
+	if input == "" {
+		return ""
+	}
+	return strings.ReplaceAll(sanitize.Name(input, false), " ", "_")
 }
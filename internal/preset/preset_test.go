@@ -1,10 +1,15 @@
 package preset
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestOutputDirMerging(t *testing.T) {
@@ -258,3 +263,320 @@ func TestSaveFixesExistingPresetFilePermissions(t *testing.T) {
 		t.Fatalf("preset dir mode = %o, want 700", got)
 	}
 }
+
+func TestExpandOutputDirAt(t *testing.T) {
+	at := time.Date(2024, time.January, 5, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		tmpl       string
+		trackerURL string
+		want       string
+	}{
+		{
+			name: "no variables",
+			tmpl: "/output/torrents",
+			want: "/output/torrents",
+		},
+		{
+			name: "year and month",
+			tmpl: "/output/{year}/{month}",
+			want: "/output/2024/01",
+		},
+		{
+			name: "day and weekday",
+			tmpl: "/output/{day}-{weekday}",
+			want: "/output/05-Friday",
+		},
+		{
+			name:       "tracker",
+			tmpl:       "/output/{tracker}/{year}",
+			trackerURL: "https://tracker.example.com/announce/abc",
+			want:       "/output/example/2024",
+		},
+		{
+			name: "tracker without url",
+			tmpl: "/output/{tracker}",
+			want: "/output/",
+		},
+		{
+			name: "empty template",
+			tmpl: "",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExpandOutputDirAt(tt.tmpl, tt.trackerURL, at)
+			if got != tt.want {
+				t.Errorf("ExpandOutputDirAt(%q, %q) = %q, want %q", tt.tmpl, tt.trackerURL, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPresetOptionsFromURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/presets.yaml":
+			w.Write([]byte("version: 1\npresets:\n  ptp:\n    source: PTP\n"))
+		case "/v2.yaml":
+			w.Write([]byte("version: 2\npresets:\n  ptp:\n    source: PTP\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	location, err := FindPresetFile(server.URL + "/presets.yaml")
+	if err != nil {
+		t.Fatalf("FindPresetFile() error = %v", err)
+	}
+	if location != server.URL+"/presets.yaml" {
+		t.Errorf("FindPresetFile() = %q, want the URL unchanged", location)
+	}
+
+	opts, err := LoadPresetOptions(location, "ptp")
+	if err != nil {
+		t.Fatalf("LoadPresetOptions() error = %v", err)
+	}
+	if opts.Source != "PTP" {
+		t.Errorf("Source = %q, want %q", opts.Source, "PTP")
+	}
+
+	if _, err := LoadPresetOptions(server.URL+"/v2.yaml", "ptp"); err == nil {
+		t.Error("expected error for unsupported config version")
+	}
+}
+
+func TestGetPresetValidatesPieceLengthAgainstTracker(t *testing.T) {
+	config := &Config{
+		Version: 1,
+		Default: &Options{MaxPieceLength: 26},
+		Presets: map[string]Options{
+			"ptp-ok":      {Trackers: []string{"https://passthepopcorn.me/announce"}, PieceLength: 24, MaxPieceLength: 24},
+			"ptp-piece":   {Trackers: []string{"https://passthepopcorn.me/announce"}, PieceLength: 25, MaxPieceLength: 24},
+			"ptp-default": {Trackers: []string{"https://passthepopcorn.me/announce"}},
+			"unknown":     {Trackers: []string{"https://tracker.example/announce"}, PieceLength: 27},
+			"no-tracker":  {PieceLength: 27},
+		},
+	}
+
+	tests := []struct {
+		preset  string
+		wantErr string
+	}{
+		{preset: "ptp-ok"},
+		{preset: "ptp-piece", wantErr: `preset "ptp-piece": piece_length 25 (32 MiB) exceeds the maximum of 24 (16 MiB)`},
+		{preset: "ptp-default", wantErr: `preset "ptp-default": max_piece_length 26`},
+		{preset: "unknown"},
+		{preset: "no-tracker"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset, func(t *testing.T) {
+			_, err := config.GetPreset(tt.preset)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("GetPreset(%q) unexpected error: %v", tt.preset, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("GetPreset(%q) error = %v, want it to contain %q", tt.preset, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMergeLists(t *testing.T) {
+	tests := []struct {
+		name  string
+		base  []string
+		extra []string
+		want  []string
+	}{
+		{
+			name:  "extra entries are appended",
+			base:  []string{"https://cdn.example/"},
+			extra: []string{"https://mirror.example/"},
+			want:  []string{"https://cdn.example/", "https://mirror.example/"},
+		},
+		{
+			name:  "duplicates keep the first position",
+			base:  []string{"https://cdn.example/", "https://mirror.example/"},
+			extra: []string{"https://other.example/", "https://cdn.example/", "https://other.example/"},
+			want:  []string{"https://cdn.example/", "https://mirror.example/", "https://other.example/"},
+		},
+		{
+			name:  "empty base",
+			extra: []string{"https://mirror.example/"},
+			want:  []string{"https://mirror.example/"},
+		},
+		{
+			name: "both empty",
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MergeLists(tt.base, tt.extra); !slices.Equal(got, tt.want) {
+				t.Errorf("MergeLists(%v, %v) = %v, want %v", tt.base, tt.extra, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebSeedsFileMerging(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "presets.yaml")
+	testConfig := `version: 1
+default:
+  webseeds_file: "default-seeds.txt"
+
+presets:
+  own_file:
+    webseeds_file: "preset-seeds.txt"
+  inherited:
+    source: "TEST"
+`
+	if err := os.WriteFile(configPath, []byte(testConfig), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	config, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load test config: %v", err)
+	}
+
+	for name, want := range map[string]string{
+		"own_file":  "preset-seeds.txt",
+		"inherited": "default-seeds.txt",
+	} {
+		opts, err := config.GetPreset(name)
+		if err != nil {
+			t.Fatalf("Failed to get preset %q: %v", name, err)
+		}
+		if opts.WebSeedsFile != want {
+			t.Errorf("preset %q: WebSeedsFile = %q, want %q", name, opts.WebSeedsFile, want)
+		}
+	}
+}
+
+func TestSourceFromPresetMerging(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "presets.yaml")
+	testConfig := `version: 1
+default:
+  source_from_preset: true
+
+presets:
+  inherited:
+    private: true
+  disabled:
+    source_from_preset: false
+`
+	if err := os.WriteFile(configPath, []byte(testConfig), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	config, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load test config: %v", err)
+	}
+
+	for name, want := range map[string]bool{
+		"inherited": true,
+		"disabled":  false,
+	} {
+		opts, err := config.GetPreset(name)
+		if err != nil {
+			t.Fatalf("Failed to get preset %q: %v", name, err)
+		}
+		if opts.SourceFromPreset == nil || *opts.SourceFromPreset != want {
+			t.Errorf("preset %q: SourceFromPreset = %v, want %v", name, opts.SourceFromPreset, want)
+		}
+	}
+}
+
+func TestSkipHiddenMerging(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "presets.yaml")
+	testConfig := `version: 1
+default:
+  skip_hidden: true
+
+presets:
+  inherited:
+    private: true
+  disabled:
+    skip_hidden: false
+`
+	if err := os.WriteFile(configPath, []byte(testConfig), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	config, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load test config: %v", err)
+	}
+
+	for name, want := range map[string]bool{
+		"inherited": true,
+		"disabled":  false,
+	} {
+		opts, err := config.GetPreset(name)
+		if err != nil {
+			t.Fatalf("Failed to get preset %q: %v", name, err)
+		}
+		if opts.SkipHidden == nil || *opts.SkipHidden != want {
+			t.Errorf("preset %q: SkipHidden = %v, want %v", name, opts.SkipHidden, want)
+		}
+	}
+}
+
+func TestCreatorAndMetaMerging(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "presets.yaml")
+	testConfig := `version: 1
+default:
+  creator: "Group toolkit"
+  meta:
+    publisher: "Group"
+    publisher-url: "https://group.example"
+
+presets:
+  inherited:
+    private: true
+  override:
+    creator: "Other toolkit"
+    meta:
+      publisher: "Other"
+`
+	if err := os.WriteFile(configPath, []byte(testConfig), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	config, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load test config: %v", err)
+	}
+
+	opts, err := config.GetPreset("override")
+	if err != nil {
+		t.Fatalf("Failed to get preset: %v", err)
+	}
+	if opts.Creator != "Other toolkit" {
+		t.Errorf("Creator = %q, want %q", opts.Creator, "Other toolkit")
+	}
+	if opts.Meta["publisher"] != "Other" || opts.Meta["publisher-url"] != "https://group.example" {
+		t.Errorf("Meta = %v, want the preset publisher and the default publisher-url", opts.Meta)
+	}
+
+	// the preset's keys must not leak into the defaults
+	opts, err = config.GetPreset("inherited")
+	if err != nil {
+		t.Fatalf("Failed to get preset: %v", err)
+	}
+	if opts.Creator != "Group toolkit" || opts.Meta["publisher"] != "Group" {
+		t.Errorf("inherited preset: Creator = %q, Meta = %v", opts.Creator, opts.Meta)
+	}
+}
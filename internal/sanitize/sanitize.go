@@ -0,0 +1,107 @@
+package sanitize
+
+import (
+	"strings"
+	"unicode"
+)
+
+// reservedChars are replaced with an underscore; they are either path separators
+// or characters Windows does not allow in file names.
+const reservedChars = `<>:"/\|?*`
+
+// reservedNames are device names Windows does not allow as a file name,
+// regardless of extension (e.g. "CON" or "con.txt").
+var reservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// transliterations maps common non-ASCII letters to ASCII replacements.
+// Characters without an entry are dropped in ASCII mode.
+var transliterations = map[rune]string{
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Ä': "A", 'Å': "A", 'Æ': "AE",
+	'Ç': "C", 'È': "E", 'É': "E", 'Ê': "E", 'Ë': "E",
+	'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I", 'Ð': "D", 'Ñ': "N",
+	'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ö': "O", 'Ø': "O",
+	'Ù': "U", 'Ú': "U", 'Û': "U", 'Ü': "U", 'Ý': "Y", 'Þ': "TH", 'ß': "ss",
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae",
+	'ç': "c", 'è': "e", 'é': "e", 'ê': "e", 'ë': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ð': "d", 'ñ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ý': "y", 'þ': "th", 'ÿ': "y",
+	'Ą': "A", 'ą': "a", 'Ć': "C", 'ć': "c", 'Č': "C", 'č': "c", 'Ď': "D", 'ď': "d",
+	'Đ': "D", 'đ': "d", 'Ę': "E", 'ę': "e", 'Ě': "E", 'ě': "e", 'Ğ': "G", 'ğ': "g",
+	'İ': "I", 'ı': "i", 'Ł': "L", 'ł': "l", 'Ń': "N", 'ń': "n", 'Ň': "N", 'ň': "n",
+	'Ő': "O", 'ő': "o", 'Œ': "OE", 'œ': "oe", 'Ř': "R", 'ř': "r", 'Ś': "S", 'ś': "s",
+	'Ş': "S", 'ş': "s", 'Š': "S", 'š': "s", 'Ť': "T", 'ť': "t", 'Ů': "U", 'ů': "u",
+	'Ű': "U", 'ű': "u", 'Ź': "Z", 'ź': "z", 'Ż': "Z", 'ż': "z", 'Ž': "Z", 'ž': "z",
+	'‘': "'", '’': "'", '“': `"`, '”': `"`, '–': "-", '—': "-", '…': "...",
+}
+
+// Name normalizes a torrent or file name so it is usable on all common platforms:
+//   - control characters are removed
+//   - path separators and Windows-reserved characters are replaced with "_"
+//   - trailing dots and spaces are trimmed
+//   - Windows device names such as "CON" get a "_" suffix
+//
+// When ascii is true, non-ASCII characters are transliterated where possible
+// and dropped otherwise. Name is idempotent.
+func Name(name string, ascii bool) string {
+	if name == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case unicode.IsControl(r):
+			continue
+		case strings.ContainsRune(reservedChars, r):
+			b.WriteByte('_')
+		case ascii && r > unicode.MaxASCII:
+			if repl, ok := transliterations[r]; ok {
+				// transliterations may themselves contain reserved characters (e.g. `"`)
+				for _, c := range repl {
+					if strings.ContainsRune(reservedChars, c) {
+						b.WriteByte('_')
+					} else {
+						b.WriteRune(c)
+					}
+				}
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	result := strings.TrimRight(b.String(), ". ")
+
+	stem, ext, _ := strings.Cut(result, ".")
+	if reservedNames[strings.ToUpper(stem)] {
+		result = stem + "_"
+		if ext != "" {
+			result += "." + ext
+		}
+	}
+
+	if result == "" {
+		return "_"
+	}
+
+	return result
+}
+
+// IsValid reports whether name is already safe, i.e. Name would not change it.
+func IsValid(name string) bool {
+	return Name(name, false) == name
+}
+
+// Tidy cleans up the formatting of a name: surrounding spaces are trimmed,
+// runs of whitespace become a single space and trailing dots are removed.
+// Unlike Name it keeps every other character. Tidy is idempotent.
+func Tidy(name string) string {
+	return strings.TrimRight(strings.Join(strings.Fields(name), " "), ". ")
+}
@@ -0,0 +1,141 @@
+package sanitize
+
+import "testing"
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		ascii bool
+		want  string
+	}{
+		{
+			name:  "already valid",
+			input: "Show.S01.1080p.WEB-DL",
+			want:  "Show.S01.1080p.WEB-DL",
+		},
+		{
+			name:  "path separators",
+			input: "AC/DC\\Live",
+			want:  "AC_DC_Live",
+		},
+		{
+			name:  "windows reserved characters",
+			input: `What? <Really>: "Yes" | No*`,
+			want:  "What_ _Really__ _Yes_ _ No_",
+		},
+		{
+			name:  "control characters",
+			input: "Name\twith\x00control\x1fchars",
+			want:  "Namewithcontrolchars",
+		},
+		{
+			name:  "trailing dots and spaces",
+			input: "Album Name. . ",
+			want:  "Album Name",
+		},
+		{
+			name:  "reserved device name",
+			input: "CON",
+			want:  "CON_",
+		},
+		{
+			name:  "reserved device name is case insensitive",
+			input: "lpt1",
+			want:  "lpt1_",
+		},
+		{
+			name:  "reserved device name with extension",
+			input: "nul.txt",
+			want:  "nul_.txt",
+		},
+		{
+			name:  "reserved name as part of a longer name",
+			input: "CONCERT",
+			want:  "CONCERT",
+		},
+		{
+			name:  "unicode kept by default",
+			input: "Amélie 🎬 東京",
+			want:  "Amélie 🎬 東京",
+		},
+		{
+			name:  "unicode transliterated in ascii mode",
+			input: "Amélie Poulain – Straße",
+			ascii: true,
+			want:  "Amelie Poulain - Strasse",
+		},
+		{
+			name:  "unknown unicode dropped in ascii mode",
+			input: "Movie 🎬 東京",
+			ascii: true,
+			want:  "Movie",
+		},
+		{
+			name:  "only invalid characters",
+			input: "...",
+			want:  "_",
+		},
+		{
+			name:  "empty",
+			input: "",
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Name(tt.input, tt.ascii)
+			if got != tt.want {
+				t.Errorf("Name(%q, %v) = %q, want %q", tt.input, tt.ascii, got, tt.want)
+			}
+
+			if again := Name(got, tt.ascii); again != got {
+				t.Errorf("Name is not idempotent: Name(%q) = %q", got, again)
+			}
+		})
+	}
+}
+
+func TestIsValid(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{input: "Show.S01.1080p", want: true},
+		{input: "Amélie", want: true},
+		{input: "AUX", want: false},
+		{input: "trailing.", want: false},
+		{input: "a/b", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := IsValid(tt.input); got != tt.want {
+			t.Errorf("IsValid(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTidy(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "Show.S01.1080p", want: "Show.S01.1080p"},
+		{input: "  Show  S01 \t 1080p ", want: "Show S01 1080p"},
+		{input: "Show.S01...", want: "Show.S01"},
+		{input: "Show S01 . . ", want: "Show S01"},
+		{input: "Amélie: Director's Cut?", want: "Amélie: Director's Cut?"},
+		{input: " ... ", want: ""},
+	}
+
+	for _, tt := range tests {
+		got := Tidy(tt.input)
+		if got != tt.want {
+			t.Errorf("Tidy(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+		if again := Tidy(got); again != got {
+			t.Errorf("Tidy is not idempotent for %q: %q then %q", tt.input, got, again)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package trackers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"gopkg.in/yaml.v3"
+
+	"github.com/autobrr/mkbrr/internal/configsource"
+)
+
+// RemoteCacheTTL is how long a downloaded tracker config is used before it is
+// fetched again. An outdated copy is still used when the download fails.
+var RemoteCacheTTL = 24 * time.Hour
+
+// remoteLogger receives the diagnostic messages of remote configs and rules,
+// such as an outdated cache being used. nil uses slog.Default().
+var remoteLogger *slog.Logger
+
+// SetLogger sets the logger for diagnostic messages of remote configs and
+// rules, so they follow the configured level and format. nil restores
+// slog.Default().
+func SetLogger(l *slog.Logger) {
+	remoteLogger = l
+}
+
+func logger() *slog.Logger {
+	if remoteLogger != nil {
+		return remoteLogger
+	}
+	return slog.Default()
+}
+
+// remoteCacheHeader starts the cache file and records the URL it was fetched
+// from, so changing the URL never picks up a cache of another config.
+const remoteCacheHeader = "# mkbrr tracker config cache of "
+
+// trackerConfigFile is the YAML layout of a tracker config:
+//
+//	trackers:
+//	  - urls: [tracker.example.org]
+//	    default_source: EXAMPLE
+//	    max_piece_length: 24
+//	    max_torrent_size: 250KiB
+//	    use_default_ranges: true
+//	    rules_url: https://tracker.example.org/mkbrr-rules.yaml
+//	    piece_size_ranges:
+//	      - max_size: 1GiB
+//	        piece_exp: 20
+type trackerConfigFile struct {
+	Trackers []trackerConfigEntry `yaml:"trackers"`
+}
+
+type trackerConfigEntry struct {
+	DefaultSource    string                `yaml:"default_source"`
+	URLs             []string              `yaml:"urls"`
+	PieceSizeRanges  []pieceSizeRangeEntry `yaml:"piece_size_ranges"`
+	MaxPieceLength   uint                  `yaml:"max_piece_length"`
+	MaxTorrentSize   byteSize              `yaml:"max_torrent_size"`
+	UseDefaultRanges bool                  `yaml:"use_default_ranges"`
+	RulesURL         string                `yaml:"rules_url"`
+}
+
+type pieceSizeRangeEntry struct {
+	MaxSize  byteSize `yaml:"max_size"`
+	PieceExp uint     `yaml:"piece_exp"`
+}
+
+// byteSize accepts a plain number of bytes or a size such as "4GiB".
+type byteSize uint64
+
+func (b *byteSize) UnmarshalYAML(value *yaml.Node) error {
+	var n uint64
+	if err := value.Decode(&n); err == nil {
+		*b = byteSize(n)
+		return nil
+	}
+	n, err := humanize.ParseBytes(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid size %q", value.Line, value.Value)
+	}
+	*b = byteSize(n)
+	return nil
+}
+
+// ParseTrackerConfigs parses a YAML tracker config. Unknown keys are rejected
+// and every entry is validated, so a config is either used whole or not at all.
+func ParseTrackerConfigs(data []byte) ([]TrackerConfig, error) {
+	var file trackerConfigFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("could not parse tracker config: %w", err)
+	}
+	if len(file.Trackers) == 0 {
+		return nil, fmt.Errorf("tracker config defines no trackers")
+	}
+
+	configs := make([]TrackerConfig, 0, len(file.Trackers))
+	for _, entry := range file.Trackers {
+		config := TrackerConfig{
+			DefaultSource:    entry.DefaultSource,
+			URLs:             entry.URLs,
+			MaxPieceLength:   entry.MaxPieceLength,
+			MaxTorrentSize:   uint64(entry.MaxTorrentSize),
+			UseDefaultRanges: entry.UseDefaultRanges,
+			RulesURL:         entry.RulesURL,
+		}
+		for _, r := range entry.PieceSizeRanges {
+			config.PieceSizeRanges = append(config.PieceSizeRanges, PieceSizeRange{MaxSize: uint64(r.MaxSize), PieceExp: r.PieceExp})
+		}
+		if err := validateTrackerConfig(config); err != nil {
+			return nil, err
+		}
+		configs = append(configs, config)
+	}
+	return configs, nil
+}
+
+// FetchAndRegisterTrackerConfig downloads the YAML tracker config at url and
+// registers its trackers. The download is cached in
+// ~/.cache/mkbrr/trackers-remote.yaml for RemoteCacheTTL; when it fails, an
+// outdated cache of the same url is used instead.
+func FetchAndRegisterTrackerConfig(url string, timeout time.Duration) error {
+	if !isHTTPS(url) {
+		return fmt.Errorf("tracker config url must start with https://, got %q", url)
+	}
+
+	cachePath, err := RemoteCachePath()
+	if err != nil {
+		logger().Debug("tracker config will not be cached", "error", err)
+	}
+
+	configs, err := fetchTrackerConfigs(url, cachePath, timeout)
+	if err != nil {
+		return err
+	}
+	return registerTrackerConfigs(configs)
+}
+
+// fetchTrackerConfigs returns the tracker configs at url, read from the cache
+// at cachePath while it is fresh and downloaded otherwise. An outdated cache
+// of the same url is used when the download fails; an empty cachePath
+// disables caching.
+func fetchTrackerConfigs(url, cachePath string, timeout time.Duration) ([]TrackerConfig, error) {
+	cached, cachedAt, cacheErr := readRemoteCache(cachePath, url)
+	if cacheErr == nil && time.Since(cachedAt) < RemoteCacheTTL {
+		if configs, err := ParseTrackerConfigs(cached); err == nil {
+			logger().Debug("using cached tracker config", "url", url, "path", cachePath)
+			return configs, nil
+		}
+	}
+
+	data, err := configsource.Fetch(url, timeout)
+	var configs []TrackerConfig
+	if err == nil {
+		configs, err = ParseTrackerConfigs(data)
+	}
+	if err != nil {
+		if cacheErr != nil {
+			return nil, fmt.Errorf("could not load tracker config: %w", err)
+		}
+		staleConfigs, parseErr := ParseTrackerConfigs(cached)
+		if parseErr != nil {
+			return nil, fmt.Errorf("could not load tracker config: %w", err)
+		}
+		logger().Warn("using outdated tracker config cache", "url", url, "cached_at", cachedAt, "error", err)
+		return staleConfigs, nil
+	}
+
+	if cachePath != "" {
+		if err := writeRemoteCache(cachePath, url, data); err != nil {
+			logger().Warn("could not cache tracker config", "path", cachePath, "error", err)
+		}
+	}
+	return configs, nil
+}
+
+// isHTTPS reports whether url uses https, which tracker configs and rules
+// must, as they decide what ends up in the torrents mkbrr creates.
+func isHTTPS(url string) bool {
+	return strings.HasPrefix(strings.ToLower(url), "https://")
+}
+
+// RemoteCachePath returns ~/.cache/mkbrr/trackers-remote.yaml.
+func RemoteCachePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".cache", "mkbrr", "trackers-remote.yaml"), nil
+}
+
+func registerTrackerConfigs(configs []TrackerConfig) error {
+	// register in reverse so the first entry of the file takes precedence
+	for i := len(configs) - 1; i >= 0; i-- {
+		if err := RegisterTrackerConfig(configs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readRemoteCache returns the cached config and when it was written, failing
+// when there is no cache or it was fetched from a different url.
+func readRemoteCache(path, url string) ([]byte, time.Time, error) {
+	if path == "" {
+		return nil, time.Time{}, os.ErrNotExist
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	header, body, _ := bytes.Cut(data, []byte("\n"))
+	if source, ok := strings.CutPrefix(string(header), remoteCacheHeader); !ok || source != url {
+		return nil, time.Time{}, fmt.Errorf("cache %s is for a different url", path)
+	}
+	return body, info.ModTime(), nil
+}
+
+func writeRemoteCache(path, url string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so concurrent runs never read a partial cache
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.WriteString(remoteCacheHeader + url + "\n")
+	if err == nil {
+		_, err = tmp.Write(data)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
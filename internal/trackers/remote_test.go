@@ -0,0 +1,231 @@
+package trackers
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const remoteTrackerConfig = `trackers:
+  - urls: [tracker.remote.example]
+    default_source: REMOTE
+    max_piece_length: 22
+    max_torrent_size: 100KiB
+    piece_size_ranges:
+      - max_size: 1GiB
+        piece_exp: 18
+      - max_size: 8589934592
+        piece_exp: 21
+  - urls: [anthelion.me]
+    default_source: ANT-REMOTE
+`
+
+// isolateTrackerConfigs gives the test its own home directory and restores the
+// registered trackers, fetched rules and cache TTL when it finishes.
+func isolateTrackerConfigs(t *testing.T) string {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	trackerConfigsMu.Lock()
+	orig := trackerConfigs
+	trackerConfigsMu.Unlock()
+	origTTL := RemoteCacheTTL
+	rulesFetchedMu.Lock()
+	origFetched := rulesFetched
+	rulesFetched = map[string]bool{}
+	rulesFetchedMu.Unlock()
+	t.Cleanup(func() {
+		trackerConfigsMu.Lock()
+		trackerConfigs = orig
+		trackerConfigsMu.Unlock()
+		RemoteCacheTTL = origTTL
+		rulesFetchedMu.Lock()
+		rulesFetched = origFetched
+		rulesFetchedMu.Unlock()
+	})
+	return home
+}
+
+// serveTrackerConfig serves body over https, or a 500 while failing is set,
+// and counts requests. The default transport trusts the server until the
+// test finishes.
+func serveTrackerConfig(t *testing.T, body string, failing *atomic.Bool) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failing != nil && failing.Load() {
+			http.Error(w, "unavailable", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	origTransport := http.DefaultTransport
+	http.DefaultTransport = srv.Client().Transport
+	t.Cleanup(func() { http.DefaultTransport = origTransport })
+	return srv, &requests
+}
+
+func TestFetchAndRegisterTrackerConfig(t *testing.T) {
+	home := isolateTrackerConfigs(t)
+	srv, requests := serveTrackerConfig(t, remoteTrackerConfig, nil)
+
+	if err := FetchAndRegisterTrackerConfig(srv.URL+"/trackers.yaml", time.Second); err != nil {
+		t.Fatalf("FetchAndRegisterTrackerConfig failed: %v", err)
+	}
+
+	trackerURL := "https://tracker.remote.example/announce?passkey=abc"
+	tests := []struct {
+		contentSize uint64
+		wantExp     uint
+	}{
+		{contentSize: 500 << 20, wantExp: 18},
+		{contentSize: 4 << 30, wantExp: 21},
+		{contentSize: 100 << 30, wantExp: 21},
+	}
+	for _, tt := range tests {
+		exp, found := GetTrackerPieceSizeExp(trackerURL, tt.contentSize)
+		if !found || exp != tt.wantExp {
+			t.Errorf("GetTrackerPieceSizeExp(%d) = %d, %v, want %d, true", tt.contentSize, exp, found, tt.wantExp)
+		}
+	}
+	if maxExp, ok := GetTrackerMaxPieceLength(trackerURL); !ok || maxExp != 22 {
+		t.Errorf("GetTrackerMaxPieceLength() = %d, %v, want 22, true", maxExp, ok)
+	}
+	if size, ok := GetTrackerMaxTorrentSize(trackerURL); !ok || size != 100<<10 {
+		t.Errorf("GetTrackerMaxTorrentSize() = %d, %v, want %d, true", size, ok, 100<<10)
+	}
+	// remote entries take precedence over the built-in configs
+	if source, _ := GetTrackerDefaultSource("https://anthelion.me/announce"); source != "ANT-REMOTE" {
+		t.Errorf("GetTrackerDefaultSource() = %q, want %q", source, "ANT-REMOTE")
+	}
+
+	cachePath := filepath.Join(home, ".cache", "mkbrr", "trackers-remote.yaml")
+	if _, err := os.Stat(cachePath); err != nil {
+		t.Fatalf("expected the config to be cached: %v", err)
+	}
+
+	// a fresh cache is used without downloading again
+	if err := FetchAndRegisterTrackerConfig(srv.URL+"/trackers.yaml", time.Second); err != nil {
+		t.Fatalf("FetchAndRegisterTrackerConfig from cache failed: %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+}
+
+func TestFetchAndRegisterTrackerConfig_StaleCache(t *testing.T) {
+	isolateTrackerConfigs(t)
+	var failing atomic.Bool
+	srv, requests := serveTrackerConfig(t, remoteTrackerConfig, &failing)
+	url := srv.URL + "/trackers.yaml"
+
+	if err := FetchAndRegisterTrackerConfig(url, time.Second); err != nil {
+		t.Fatalf("FetchAndRegisterTrackerConfig failed: %v", err)
+	}
+
+	RemoteCacheTTL = 0
+	failing.Store(true)
+	if err := FetchAndRegisterTrackerConfig(url, time.Second); err != nil {
+		t.Fatalf("expected the outdated cache to be used, got %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("expected an expired cache to be refreshed, got %d requests", n)
+	}
+	if source, _ := GetTrackerDefaultSource("https://tracker.remote.example/announce"); source != "REMOTE" {
+		t.Errorf("GetTrackerDefaultSource() = %q, want %q", source, "REMOTE")
+	}
+
+	// the cache belongs to the url it was fetched from
+	if err := FetchAndRegisterTrackerConfig(srv.URL+"/other.yaml", time.Second); err == nil {
+		t.Error("expected an error without a cache for the url")
+	}
+}
+
+func TestFetchAndRegisterTrackerConfig_Logger(t *testing.T) {
+	isolateTrackerConfigs(t)
+	var failing atomic.Bool
+	srv, _ := serveTrackerConfig(t, remoteTrackerConfig, &failing)
+	url := srv.URL + "/trackers.yaml"
+
+	var buf bytes.Buffer
+	SetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	t.Cleanup(func() { SetLogger(nil) })
+
+	if err := FetchAndRegisterTrackerConfig(url, time.Second); err != nil {
+		t.Fatalf("FetchAndRegisterTrackerConfig failed: %v", err)
+	}
+	RemoteCacheTTL = 0
+	failing.Store(true)
+	if err := FetchAndRegisterTrackerConfig(url, time.Second); err != nil {
+		t.Fatalf("expected the outdated cache to be used, got %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "using outdated tracker config cache") {
+		t.Errorf("expected the warning in the configured logger, got %q", buf.String())
+	}
+}
+
+func TestFetchAndRegisterTrackerConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		body    string
+		wantErr string
+	}{
+		{name: "not a url", url: "/etc/mkbrr/trackers.yaml", wantErr: "must start with https://"},
+		{name: "plain http", url: "http://tracker.example/trackers.yaml", wantErr: "must start with https://"},
+		{name: "unknown key", body: "trackers:\n  - urls: [a.example]\n    max_piece: 20\n", wantErr: "field max_piece not found"},
+		{name: "no trackers", body: "trackers: []\n", wantErr: "defines no trackers"},
+		{name: "missing urls", body: "trackers:\n  - default_source: X\n", wantErr: "at least one url"},
+		{name: "invalid size", body: "trackers:\n  - urls: [a.example]\n    max_torrent_size: huge\n", wantErr: "invalid size"},
+		{name: "unsorted ranges", body: "trackers:\n  - urls: [a.example]\n    piece_size_ranges:\n      - {max_size: 2GiB, piece_exp: 20}\n      - {max_size: 1GiB, piece_exp: 19}\n", wantErr: "sorted by increasing max size"},
+		{name: "piece exponent out of range", body: "trackers:\n  - urls: [a.example]\n    max_piece_length: 30\n", wantErr: "between 14 and 27"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateTrackerConfigs(t)
+			url := tt.url
+			if url == "" {
+				srv, _ := serveTrackerConfig(t, tt.body, nil)
+				url = srv.URL
+			}
+
+			err := FetchAndRegisterTrackerConfig(url, time.Second)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if _, ok := GetTrackerDefaultSource("https://a.example/announce"); ok {
+				t.Error("expected nothing to be registered from an invalid config")
+			}
+		})
+	}
+}
+
+func TestRegisterTrackerConfig(t *testing.T) {
+	isolateTrackerConfigs(t)
+
+	if err := RegisterTrackerConfig(TrackerConfig{}); err == nil {
+		t.Error("expected an error for a config without urls")
+	}
+
+	if err := RegisterTrackerConfig(TrackerConfig{URLs: []string{"local.example"}, UseDefaultRanges: true}); err != nil {
+		t.Fatalf("RegisterTrackerConfig failed: %v", err)
+	}
+	if exp, ok := GetTrackerPieceSizeExp("udp://local.example:1337/announce", 3<<30); !ok || exp != 21 {
+		t.Errorf("GetTrackerPieceSizeExp() = %d, %v, want 21, true", exp, ok)
+	}
+}
@@ -0,0 +1,75 @@
+package trackers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// rulesFetchedMu guards rulesFetched
+var rulesFetchedMu sync.Mutex
+
+// rulesFetched holds the rules URLs already registered by this process, so
+// each one is fetched at most once per run
+var rulesFetched = map[string]bool{}
+
+// FetchTrackerRules refreshes the rules of the tracker announcing at trackerURL
+// from its RulesURL, which serves a tracker config as ParseTrackerConfigs
+// reads it. Only entries covering trackerURL are registered. Trackers without
+// a RulesURL are left alone, so nothing is downloaded unless a tracker opts in.
+//
+// The download is cached in ~/.cache/mkbrr/tracker-rules for RemoteCacheTTL,
+// and an outdated cache is used when it fails. Without a usable cache the
+// error is returned and the static config stays in effect.
+func FetchTrackerRules(trackerURL string, timeout time.Duration) error {
+	config := findTrackerConfig(trackerURL)
+	if config == nil || config.RulesURL == "" {
+		return nil
+	}
+	rulesURL, name := config.RulesURL, config.URLs[0]
+
+	rulesFetchedMu.Lock()
+	defer rulesFetchedMu.Unlock()
+	if rulesFetched[rulesURL] {
+		return nil
+	}
+
+	cachePath, err := RulesCachePath(name)
+	if err != nil {
+		logger().Debug("tracker rules will not be cached", "error", err)
+	}
+	configs, err := fetchTrackerConfigs(rulesURL, cachePath, timeout)
+	if err != nil {
+		return fmt.Errorf("could not fetch rules for %s: %w", name, err)
+	}
+
+	// rules from one tracker never change the config of another
+	var covering []TrackerConfig
+	for _, c := range configs {
+		if c.matches(trackerURL) {
+			c.RulesURL = ""
+			covering = append(covering, c)
+		}
+	}
+	if len(covering) == 0 {
+		return fmt.Errorf("rules at %s do not cover %s", rulesURL, name)
+	}
+	if err := registerTrackerConfigs(covering); err != nil {
+		return err
+	}
+	rulesFetched[rulesURL] = true
+	logger().Debug("registered tracker rules", "tracker", name, "url", rulesURL)
+	return nil
+}
+
+// RulesCachePath returns ~/.cache/mkbrr/tracker-rules/<domain>.yaml for the
+// tracker with the given domain.
+func RulesCachePath(domain string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".cache", "mkbrr", "tracker-rules", filepath.Base(domain)+".yaml"), nil
+}
@@ -0,0 +1,114 @@
+package trackers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const trackerRules = `trackers:
+  - urls: [rules.example]
+    max_piece_length: 22
+    default_source: RULES
+  - urls: [other.example]
+    default_source: OTHER
+`
+
+func TestFetchTrackerRules(t *testing.T) {
+	home := isolateTrackerConfigs(t)
+	srv, requests := serveTrackerConfig(t, trackerRules, nil)
+	if err := RegisterTrackerConfig(TrackerConfig{URLs: []string{"rules.example"}, MaxPieceLength: 20, RulesURL: srv.URL + "/rules.yaml"}); err != nil {
+		t.Fatalf("RegisterTrackerConfig failed: %v", err)
+	}
+
+	trackerURL := "https://rules.example/announce/abc"
+	if err := FetchTrackerRules(trackerURL, time.Second); err != nil {
+		t.Fatalf("FetchTrackerRules failed: %v", err)
+	}
+	if maxExp, _ := GetTrackerMaxPieceLength(trackerURL); maxExp != 22 {
+		t.Errorf("GetTrackerMaxPieceLength() = %d, want the fetched 22", maxExp)
+	}
+	if source, _ := GetTrackerDefaultSource(trackerURL); source != "RULES" {
+		t.Errorf("GetTrackerDefaultSource() = %q, want %q", source, "RULES")
+	}
+	// entries for other trackers are ignored
+	if _, ok := GetTrackerDefaultSource("https://other.example/announce"); ok {
+		t.Error("rules of rules.example registered a config for other.example")
+	}
+	if _, err := os.Stat(filepath.Join(home, ".cache", "mkbrr", "tracker-rules", "rules.example.yaml")); err != nil {
+		t.Errorf("expected the rules to be cached: %v", err)
+	}
+
+	// the rules are fetched once per run
+	if err := FetchTrackerRules(trackerURL, time.Second); err != nil {
+		t.Fatalf("second FetchTrackerRules failed: %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+}
+
+func TestFetchTrackerRules_Fallback(t *testing.T) {
+	isolateTrackerConfigs(t)
+
+	// trackers without a rules url never download anything
+	if err := FetchTrackerRules("https://anthelion.me/announce", time.Second); err != nil {
+		t.Errorf("FetchTrackerRules without rules url = %v, want nil", err)
+	}
+
+	var failing atomic.Bool
+	failing.Store(true)
+	srv, _ := serveTrackerConfig(t, trackerRules, &failing)
+	if err := RegisterTrackerConfig(TrackerConfig{URLs: []string{"rules.example"}, MaxPieceLength: 20, RulesURL: srv.URL + "/rules.yaml"}); err != nil {
+		t.Fatalf("RegisterTrackerConfig failed: %v", err)
+	}
+
+	trackerURL := "https://rules.example/announce"
+	if err := FetchTrackerRules(trackerURL, time.Second); err == nil {
+		t.Fatal("expected an error when the rules cannot be fetched")
+	}
+	if maxExp, _ := GetTrackerMaxPieceLength(trackerURL); maxExp != 20 {
+		t.Errorf("GetTrackerMaxPieceLength() = %d, want the static 20", maxExp)
+	}
+
+	// an outdated cache is used when the download fails
+	failing.Store(false)
+	RemoteCacheTTL = 0
+	if err := FetchTrackerRules(trackerURL, time.Second); err != nil {
+		t.Fatalf("FetchTrackerRules failed: %v", err)
+	}
+	rulesFetchedMu.Lock()
+	rulesFetched = map[string]bool{}
+	rulesFetchedMu.Unlock()
+	failing.Store(true)
+	if err := FetchTrackerRules(trackerURL, time.Second); err != nil {
+		t.Fatalf("FetchTrackerRules with outdated cache failed: %v", err)
+	}
+}
+
+func TestFetchTrackerRules_NotCovering(t *testing.T) {
+	isolateTrackerConfigs(t)
+	srv, _ := serveTrackerConfig(t, trackerRules, nil)
+	if err := RegisterTrackerConfig(TrackerConfig{URLs: []string{"elsewhere.example"}, RulesURL: srv.URL}); err != nil {
+		t.Fatalf("RegisterTrackerConfig failed: %v", err)
+	}
+
+	err := FetchTrackerRules("https://elsewhere.example/announce", time.Second)
+	if err == nil || !strings.Contains(err.Error(), "do not cover") {
+		t.Errorf("FetchTrackerRules = %v, want a do not cover error", err)
+	}
+}
+
+func TestRegisterTrackerConfig_RulesURL(t *testing.T) {
+	isolateTrackerConfigs(t)
+
+	for _, rulesURL := range []string{"ftp://rules.example/rules.yaml", "http://rules.example/rules.yaml"} {
+		err := RegisterTrackerConfig(TrackerConfig{URLs: []string{"rules.example"}, RulesURL: rulesURL})
+		if err == nil || !strings.Contains(err.Error(), "must start with https://") {
+			t.Errorf("RegisterTrackerConfig(%q) = %v, want a rules url error", rulesURL, err)
+		}
+	}
+}
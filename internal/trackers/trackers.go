@@ -1,6 +1,10 @@
 package trackers
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
 
 // TrackerConfig holds tracker-specific configuration
 type TrackerConfig struct {
@@ -10,6 +14,7 @@ type TrackerConfig struct {
 	MaxPieceLength   uint             // maximum piece length exponent (2^n). default is 24 (16 MiB) from create.go
 	MaxTorrentSize   uint64           // maximum .torrent file size in bytes (0 means no limit)
 	UseDefaultRanges bool             // whether to use default piece size ranges when content size is outside custom ranges
+	RulesURL         string           // optional https URL where the tracker publishes its current rules, see FetchTrackerRules
 }
 
 // PieceSizeRange defines a range of content sizes and their corresponding piece size exponent
@@ -18,6 +23,9 @@ type PieceSizeRange struct {
 	PieceExp uint   // piece size exponent (2^n)
 }
 
+// trackerConfigsMu guards trackerConfigs against registrations during lookups
+var trackerConfigsMu sync.RWMutex
+
 // trackerConfigs maps known tracker base URLs to their configurations
 var trackerConfigs = []TrackerConfig{
 	{
@@ -293,13 +301,71 @@ var trackerConfigs = []TrackerConfig{
 	},
 }
 
-// findTrackerConfig returns the config for a given tracker URL
+// findTrackerConfig returns the config for a given tracker URL. URLs are matched
+// by host, so passkeys in the query or path never affect the lookup; URLs
+// without a host fall back to a substring match.
 func findTrackerConfig(trackerURL string) *TrackerConfig {
+	trackerConfigsMu.RLock()
+	defer trackerConfigsMu.RUnlock()
+
 	for i := range trackerConfigs {
-		for _, url := range trackerConfigs[i].URLs {
-			if strings.Contains(trackerURL, url) {
-				return &trackerConfigs[i]
-			}
+		if trackerConfigs[i].matches(trackerURL) {
+			return &trackerConfigs[i]
+		}
+	}
+	return nil
+}
+
+// matches reports whether the config applies to trackerURL, see findTrackerConfig.
+func (c *TrackerConfig) matches(trackerURL string) bool {
+	host := trackerHost(trackerURL)
+	for _, domain := range c.URLs {
+		if host != "" && matchesDomain(host, domain) {
+			return true
+		}
+		if host == "" && strings.Contains(trackerURL, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterTrackerConfig adds config to the known trackers. It takes precedence
+// over the built-in configs and earlier registrations for the same domains.
+func RegisterTrackerConfig(config TrackerConfig) error {
+	if err := validateTrackerConfig(config); err != nil {
+		return err
+	}
+
+	trackerConfigsMu.Lock()
+	defer trackerConfigsMu.Unlock()
+	trackerConfigs = append([]TrackerConfig{config}, trackerConfigs...)
+	return nil
+}
+
+func validateTrackerConfig(config TrackerConfig) error {
+	if len(config.URLs) == 0 {
+		return fmt.Errorf("tracker config needs at least one url")
+	}
+	for _, domain := range config.URLs {
+		if strings.TrimSpace(domain) == "" {
+			return fmt.Errorf("tracker config has an empty url")
+		}
+	}
+
+	name := config.URLs[0]
+	if config.RulesURL != "" && !isHTTPS(config.RulesURL) {
+		return fmt.Errorf("tracker %s: rules url must start with https://, got %q", name, config.RulesURL)
+	}
+	if config.MaxPieceLength != 0 && (config.MaxPieceLength < 14 || config.MaxPieceLength > 27) {
+		return fmt.Errorf("tracker %s: max piece length must be between 14 and 27, got %d", name, config.MaxPieceLength)
+	}
+	for i, r := range config.PieceSizeRanges {
+		if r.PieceExp < 14 || r.PieceExp > 27 {
+			return fmt.Errorf("tracker %s: piece exponent must be between 14 and 27, got %d", name, r.PieceExp)
+		}
+		if i > 0 && r.MaxSize <= config.PieceSizeRanges[i-1].MaxSize {
+			return fmt.Errorf("tracker %s: piece size ranges must be sorted by increasing max size", name)
 		}
 	}
 	return nil
@@ -377,6 +443,12 @@ func GetTrackerMaxTorrentSize(trackerURL string) (uint64, bool) {
 	return 0, false
 }
 
+// IsKnownTracker reports whether trackerURL belongs to one of the known
+// trackers, all of which are private
+func IsKnownTracker(trackerURL string) bool {
+	return findTrackerConfig(trackerURL) != nil
+}
+
 // GetTrackerDefaultSource returns the default source for a tracker if defined
 func GetTrackerDefaultSource(trackerURL string) (string, bool) {
 	if config := findTrackerConfig(trackerURL); config != nil && config.DefaultSource != "" {
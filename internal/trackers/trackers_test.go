@@ -260,6 +260,25 @@ func Test_GetTrackerMaxTorrentSize(t *testing.T) {
 	}
 }
 
+func Test_IsKnownTracker(t *testing.T) {
+	tests := []struct {
+		trackerURL string
+		want       bool
+	}{
+		{trackerURL: "https://anthelion.me/announce?passkey=123", want: true},
+		{trackerURL: "https://tracker.hdbits.org/announce", want: true},
+		{trackerURL: "https://unknown.tracker/announce", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.trackerURL, func(t *testing.T) {
+			if got := IsKnownTracker(tt.trackerURL); got != tt.want {
+				t.Errorf("IsKnownTracker() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_trackerConfigConsistency(t *testing.T) {
 	for _, config := range trackerConfigs {
 		// Skip empty configs
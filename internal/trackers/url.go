@@ -0,0 +1,163 @@
+package trackers
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// announceSchemes lists the URL schemes BitTorrent clients can announce to
+var announceSchemes = []string{"http", "https", "udp", "ws", "wss"}
+
+// passkeyMinLength is the shortest path segment treated as a passkey by CanonicalURL
+const passkeyMinLength = 16
+
+// passkeyParams are query parameters trackers use for a user's passkey
+var passkeyParams = []string{"passkey", "authkey", "torrent_pass", "pid"}
+
+// Passkey patterns reported by DetectPasskey
+const (
+	PasskeyParam       = "passkey parameter"        // a query parameter named like passkey or authkey
+	PasskeyPathSegment = "passkey path segment"     // a long letter and digit token in the path
+	PasskeyQueryValue  = "passkey-like query value" // a long letter and digit token in another query parameter
+)
+
+// ValidateURL checks that trackerURL is a usable announce URL: it must parse,
+// use one of the http, https, udp, ws or wss schemes and have a host. http(s)
+// URLs also need a path and udp URLs a port. A non-empty warning is returned for
+// valid URLs that look unusual, such as an http(s) path without "announce".
+func ValidateURL(trackerURL string) (warning string, err error) {
+	u, err := url.Parse(trackerURL)
+	if err != nil {
+		return "", fmt.Errorf("could not parse URL: %w", err)
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme == "" {
+		return "", fmt.Errorf("missing scheme (must be one of %s)", strings.Join(announceSchemes, ", "))
+	}
+	if !slices.Contains(announceSchemes, scheme) {
+		return "", fmt.Errorf("unsupported scheme %q (must be one of %s)", u.Scheme, strings.Join(announceSchemes, ", "))
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("missing host")
+	}
+
+	switch scheme {
+	case "http", "https":
+		if u.Path == "" || u.Path == "/" {
+			return "", fmt.Errorf("missing announce path")
+		}
+		if !strings.Contains(strings.ToLower(u.Path), "announce") {
+			return fmt.Sprintf("path %q does not contain \"announce\"", u.Path), nil
+		}
+	case "udp":
+		if u.Port() == "" {
+			return "", fmt.Errorf("missing port (udp trackers have no default port)")
+		}
+	}
+
+	return "", nil
+}
+
+// ValidateURLs validates each of trackerURLs with ValidateURL. Errors and warnings
+// name the offending URL and its 1-based position in the list.
+func ValidateURLs(trackerURLs []string) (warnings []string, err error) {
+	for i, trackerURL := range trackerURLs {
+		warning, err := ValidateURL(trackerURL)
+		if err != nil {
+			return warnings, fmt.Errorf("invalid tracker URL #%d %q: %w", i+1, trackerURL, err)
+		}
+		if warning != "" {
+			warnings = append(warnings, fmt.Sprintf("tracker URL #%d %q: %s", i+1, trackerURL, warning))
+		}
+	}
+	return warnings, nil
+}
+
+// CanonicalURL returns trackerURL without passkeys so URLs of the same tracker
+// compare equal: credentials, the query string, the fragment and path segments
+// that look like passkeys are removed and the scheme and host are lowercased.
+// URLs that do not parse or have no host are returned unchanged.
+func CanonicalURL(trackerURL string) string {
+	u, err := url.Parse(trackerURL)
+	if err != nil || u.Host == "" {
+		return trackerURL
+	}
+
+	segments := strings.Split(u.Path, "/")
+	kept := segments[:0]
+	for _, segment := range segments {
+		if !isPasskey(segment) {
+			kept = append(kept, segment)
+		}
+	}
+
+	canonical := url.URL{
+		Scheme: strings.ToLower(u.Scheme),
+		Host:   strings.ToLower(u.Host),
+		Path:   strings.Join(kept, "/"),
+	}
+	return canonical.String()
+}
+
+// DetectPasskey reports whether trackerURL looks like it contains a passkey,
+// and which of the Passkey* patterns matched: a query parameter such as
+// passkey, authkey or pid, or a path segment or query value of at least 16
+// letters and digits. URLs that do not parse are reported as not containing one.
+func DetectPasskey(trackerURL string) (bool, string) {
+	u, err := url.Parse(trackerURL)
+	if err != nil {
+		return false, ""
+	}
+
+	query := u.Query()
+	for name, values := range query {
+		if slices.Contains(passkeyParams, strings.ToLower(name)) && slices.ContainsFunc(values, func(v string) bool { return v != "" }) {
+			return true, PasskeyParam
+		}
+	}
+	if slices.ContainsFunc(strings.Split(u.Path, "/"), isPasskey) {
+		return true, PasskeyPathSegment
+	}
+	for _, values := range query {
+		if slices.ContainsFunc(values, isPasskey) {
+			return true, PasskeyQueryValue
+		}
+	}
+	return false, ""
+}
+
+// trackerHost returns the lowercase host name of trackerURL, or "" when it has none.
+func trackerHost(trackerURL string) string {
+	u, err := url.Parse(CanonicalURL(trackerURL))
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// matchesDomain reports whether host is domain or one of its subdomains.
+func matchesDomain(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// isPasskey reports whether a path segment looks like a passkey: a long run of
+// letters and digits such as a hex or base62 token.
+func isPasskey(segment string) bool {
+	if len(segment) < passkeyMinLength {
+		return false
+	}
+	hasDigit := false
+	for _, r := range segment {
+		switch {
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		default:
+			return false
+		}
+	}
+	return hasDigit
+}
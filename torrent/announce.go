@@ -0,0 +1,147 @@
+package torrent
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// shuffleAnnounceList randomizes the order of the tiers of mi.AnnounceList and
+// of the URLs within each tier, then sets mi.Announce to the new first URL so
+// clients without announce-list support use the same tracker.
+func shuffleAnnounceList(mi *metainfo.MetaInfo) error {
+	if len(mi.AnnounceList) == 0 {
+		return nil
+	}
+
+	for _, tier := range mi.AnnounceList {
+		if err := cryptoShuffle(len(tier), func(i, j int) { tier[i], tier[j] = tier[j], tier[i] }); err != nil {
+			return err
+		}
+	}
+	tiers := mi.AnnounceList
+	if err := cryptoShuffle(len(tiers), func(i, j int) { tiers[i], tiers[j] = tiers[j], tiers[i] }); err != nil {
+		return err
+	}
+
+	for _, tier := range mi.AnnounceList {
+		if len(tier) > 0 {
+			mi.Announce = tier[0]
+			break
+		}
+	}
+	return nil
+}
+
+// cryptoShuffle is a Fisher-Yates shuffle of n elements drawing from crypto/rand.
+func cryptoShuffle(n int, swap func(i, j int)) error {
+	for i := n - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return fmt.Errorf("could not shuffle announce list: %w", err)
+		}
+		swap(i, int(j.Int64()))
+	}
+	return nil
+}
+
+// addTrackers appends each URL of urls that mi does not announce to yet as a
+// tier of its own, keeping the existing tiers and primary announce URL. With
+// setPrimary the first of urls becomes the primary announce URL, moved to a
+// tier of its own at the front. It reports whether mi changed.
+func addTrackers(mi *metainfo.MetaInfo, urls []string, setPrimary bool) bool {
+	if len(urls) == 0 {
+		return false
+	}
+
+	tiers := slices.Clone(mi.AnnounceList)
+	if len(tiers) == 0 && mi.Announce != "" {
+		tiers = metainfo.AnnounceList{{mi.Announce}}
+	}
+	known := make(map[string]bool)
+	for _, tier := range tiers {
+		for _, url := range tier {
+			known[url] = true
+		}
+	}
+
+	changed := false
+	for _, url := range urls {
+		if !known[url] {
+			known[url] = true
+			tiers = append(tiers, []string{url})
+			changed = true
+		}
+	}
+
+	primary := mi.Announce
+	if setPrimary {
+		primary = urls[0]
+	} else if primary == "" && len(tiers[0]) > 0 {
+		primary = tiers[0][0]
+	}
+	if setPrimary && (len(tiers[0]) != 1 || tiers[0][0] != primary) {
+		for i, tier := range tiers {
+			if j := slices.Index(tier, primary); j >= 0 {
+				tiers[i] = slices.Delete(slices.Clone(tier), j, j+1)
+				if len(tiers[i]) == 0 {
+					tiers = slices.Delete(tiers, i, i+1)
+				}
+				break
+			}
+		}
+		tiers = slices.Insert(tiers, 0, []string{primary})
+		changed = true
+	}
+
+	if !changed && primary == mi.Announce {
+		return false
+	}
+	mi.Announce = primary
+	mi.AnnounceList = tiers
+	return true
+}
+
+// removeTrackers drops every announce URL of mi containing one of substrings
+// from mi.Announce and mi.AnnounceList, leaving out tiers that end up empty.
+// A removed primary announce URL is replaced by the first remaining one, or
+// cleared when none is left. It returns the number of distinct URLs removed.
+func removeTrackers(mi *metainfo.MetaInfo, substrings []string) int {
+	if len(substrings) == 0 {
+		return 0
+	}
+	matches := func(url string) bool {
+		return slices.ContainsFunc(substrings, func(sub string) bool { return strings.Contains(url, sub) })
+	}
+
+	removed := make(map[string]bool)
+	var tiers metainfo.AnnounceList
+	for _, tier := range mi.AnnounceList {
+		kept := slices.DeleteFunc(slices.Clone(tier), func(url string) bool {
+			if matches(url) {
+				removed[url] = true
+				return true
+			}
+			return false
+		})
+		if len(kept) > 0 {
+			tiers = append(tiers, kept)
+		}
+	}
+	if mi.Announce != "" && matches(mi.Announce) {
+		removed[mi.Announce] = true
+		mi.Announce = ""
+		if len(tiers) > 0 {
+			mi.Announce = tiers[0][0]
+		}
+	}
+
+	if len(removed) > 0 {
+		mi.AnnounceList = tiers
+	}
+	return len(removed)
+}
@@ -0,0 +1,341 @@
+package torrent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+func TestCreate_RandomizeAnnounceList(t *testing.T) {
+	tmpDir := t.TempDir()
+	contentPath := filepath.Join(tmpDir, "content.bin")
+	if err := os.WriteFile(contentPath, make([]byte, 1<<16), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+
+	trackerURLs := make([]string, 5)
+	for i := range trackerURLs {
+		trackerURLs[i] = fmt.Sprintf("https://tracker%d.example/announce", i)
+	}
+
+	// positions[tracker] holds every tier index the tracker was seen at
+	positions := make(map[string]map[int]bool)
+	for run := range 10 {
+		result, err := Create(CreateOptions{
+			Path:                  contentPath,
+			OutputPath:            filepath.Join(tmpDir, fmt.Sprintf("run%d.torrent", run)),
+			TrackerURLs:           trackerURLs,
+			RandomizeAnnounceList: true,
+			Quiet:                 true,
+		})
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+
+		mi, err := LoadFromFile(result.Path)
+		if err != nil {
+			t.Fatalf("failed to load torrent: %v", err)
+		}
+		if len(mi.AnnounceList) != len(trackerURLs) {
+			t.Fatalf("expected %d tiers, got %v", len(trackerURLs), mi.AnnounceList)
+		}
+		if mi.Announce != mi.AnnounceList[0][0] {
+			t.Errorf("Announce = %q, want the first tracker %q", mi.Announce, mi.AnnounceList[0][0])
+		}
+
+		var seen []string
+		for i, tier := range mi.AnnounceList {
+			for _, tracker := range tier {
+				if positions[tracker] == nil {
+					positions[tracker] = make(map[int]bool)
+				}
+				positions[tracker][i] = true
+				seen = append(seen, tracker)
+			}
+		}
+		slices.Sort(seen)
+		if !slices.Equal(seen, trackerURLs) {
+			t.Fatalf("shuffled trackers %v do not match %v", seen, trackerURLs)
+		}
+	}
+
+	for _, tracker := range trackerURLs {
+		if len(positions[tracker]) < 2 {
+			t.Errorf("tracker %s was always at tier %v", tracker, positions[tracker])
+		}
+	}
+}
+
+func TestShuffleAnnounceList_KeepsTiers(t *testing.T) {
+	tiers := [][]string{{"a1", "a2", "a3"}, {"b1"}, {"c1", "c2"}}
+	mi := &metainfo.MetaInfo{Announce: "a1", AnnounceList: [][]string{{"a1", "a2", "a3"}, {"b1"}, {"c1", "c2"}}}
+
+	if err := shuffleAnnounceList(mi); err != nil {
+		t.Fatalf("shuffleAnnounceList failed: %v", err)
+	}
+
+	if mi.Announce != mi.AnnounceList[0][0] {
+		t.Errorf("Announce = %q, want %q", mi.Announce, mi.AnnounceList[0][0])
+	}
+	// trackers stay in their tier, only the order changes
+	for _, tier := range mi.AnnounceList {
+		sorted := slices.Sorted(slices.Values(tier))
+		if !slices.ContainsFunc(tiers, func(want []string) bool { return slices.Equal(sorted, want) }) {
+			t.Errorf("tier %v does not match any original tier", tier)
+		}
+	}
+}
+
+func TestModifyTorrent_RandomizeAnnounceList(t *testing.T) {
+	tmpDir := t.TempDir()
+	contentPath := filepath.Join(tmpDir, "content.bin")
+	if err := os.WriteFile(contentPath, make([]byte, 1<<16), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+	torrentPath := filepath.Join(tmpDir, "content.torrent")
+	trackerURLs := []string{"https://tracker-a.example/announce", "https://tracker-b.example/announce", "https://tracker-c.example/announce"}
+	if _, err := Create(CreateOptions{Path: contentPath, OutputPath: torrentPath, TrackerURLs: trackerURLs, Quiet: true}); err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+
+	result, err := ModifyTorrent(torrentPath, ModifyOptions{
+		OutputDir:             filepath.Join(tmpDir, "out"),
+		RandomizeAnnounceList: true,
+		Quiet:                 true,
+	})
+	if err != nil {
+		t.Fatalf("ModifyTorrent failed: %v", err)
+	}
+	if !result.WasModified {
+		t.Error("expected shuffling to count as a modification")
+	}
+
+	mi, err := LoadFromFile(result.OutputPath)
+	if err != nil {
+		t.Fatalf("failed to load modified torrent: %v", err)
+	}
+	if mi.Announce != mi.AnnounceList[0][0] {
+		t.Errorf("Announce = %q, want %q", mi.Announce, mi.AnnounceList[0][0])
+	}
+	if got := slices.Sorted(slices.Values(mi.AnnounceList.DistinctValues())); !slices.Equal(got, trackerURLs) {
+		t.Errorf("trackers = %v, want %v", got, trackerURLs)
+	}
+}
+
+func TestAddTrackers(t *testing.T) {
+	tests := []struct {
+		name        string
+		mi          metainfo.MetaInfo
+		urls        []string
+		setPrimary  bool
+		wantChanged bool
+		wantPrimary string
+		wantTiers   metainfo.AnnounceList
+	}{
+		{
+			name:        "appends new trackers as tiers",
+			mi:          metainfo.MetaInfo{Announce: "a", AnnounceList: metainfo.AnnounceList{{"a", "b"}}},
+			urls:        []string{"c", "b", "c"},
+			wantChanged: true,
+			wantPrimary: "a",
+			wantTiers:   metainfo.AnnounceList{{"a", "b"}, {"c"}},
+		},
+		{
+			name:        "single announce without a list",
+			mi:          metainfo.MetaInfo{Announce: "a"},
+			urls:        []string{"b"},
+			wantChanged: true,
+			wantPrimary: "a",
+			wantTiers:   metainfo.AnnounceList{{"a"}, {"b"}},
+		},
+		{
+			name:        "no trackers yet",
+			urls:        []string{"a", "b"},
+			wantChanged: true,
+			wantPrimary: "a",
+			wantTiers:   metainfo.AnnounceList{{"a"}, {"b"}},
+		},
+		{
+			name:        "already announced",
+			mi:          metainfo.MetaInfo{Announce: "a", AnnounceList: metainfo.AnnounceList{{"a"}, {"b"}}},
+			urls:        []string{"b"},
+			wantPrimary: "a",
+			wantTiers:   metainfo.AnnounceList{{"a"}, {"b"}},
+		},
+		{
+			name:        "set primary",
+			mi:          metainfo.MetaInfo{Announce: "a", AnnounceList: metainfo.AnnounceList{{"a", "b"}}},
+			urls:        []string{"c"},
+			setPrimary:  true,
+			wantChanged: true,
+			wantPrimary: "c",
+			wantTiers:   metainfo.AnnounceList{{"c"}, {"a", "b"}},
+		},
+		{
+			name:        "set primary to a known tracker",
+			mi:          metainfo.MetaInfo{Announce: "a", AnnounceList: metainfo.AnnounceList{{"a"}, {"b"}}},
+			urls:        []string{"b"},
+			setPrimary:  true,
+			wantChanged: true,
+			wantPrimary: "b",
+			wantTiers:   metainfo.AnnounceList{{"b"}, {"a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mi := tt.mi
+			if changed := addTrackers(&mi, tt.urls, tt.setPrimary); changed != tt.wantChanged {
+				t.Errorf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if mi.Announce != tt.wantPrimary {
+				t.Errorf("Announce = %q, want %q", mi.Announce, tt.wantPrimary)
+			}
+			if tt.wantChanged && !reflect.DeepEqual(mi.AnnounceList, tt.wantTiers) {
+				t.Errorf("AnnounceList = %v, want %v", mi.AnnounceList, tt.wantTiers)
+			}
+		})
+	}
+}
+
+func TestModifyTorrent_AddTrackers(t *testing.T) {
+	tmpDir := t.TempDir()
+	contentPath := filepath.Join(tmpDir, "content.bin")
+	if err := os.WriteFile(contentPath, make([]byte, 1<<16), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+	torrentPath := filepath.Join(tmpDir, "content.torrent")
+	trackerURLs := []string{"https://tracker-a.example/announce", "https://tracker-b.example/announce"}
+	if _, err := Create(CreateOptions{Path: contentPath, OutputPath: torrentPath, TrackerURLs: trackerURLs, Quiet: true}); err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+
+	result, err := ModifyTorrent(torrentPath, ModifyOptions{
+		OutputDir:      filepath.Join(tmpDir, "out"),
+		AddTrackerURLs: []string{"https://tracker-b.example/announce", "https://tracker-c.example/announce"},
+		Quiet:          true,
+	})
+	if err != nil {
+		t.Fatalf("ModifyTorrent failed: %v", err)
+	}
+	if !result.WasModified {
+		t.Fatal("expected adding a tracker to count as a modification")
+	}
+
+	mi, err := LoadFromFile(result.OutputPath)
+	if err != nil {
+		t.Fatalf("failed to load modified torrent: %v", err)
+	}
+	if mi.Announce != trackerURLs[0] {
+		t.Errorf("Announce = %q, want %q", mi.Announce, trackerURLs[0])
+	}
+	want := append(slices.Clone(trackerURLs), "https://tracker-c.example/announce")
+	if got := slices.Concat(mi.AnnounceList...); !slices.Equal(got, want) {
+		t.Errorf("trackers = %v, want %v", got, want)
+	}
+}
+
+func TestRemoveTrackers(t *testing.T) {
+	tests := []struct {
+		name        string
+		mi          metainfo.MetaInfo
+		substrings  []string
+		wantRemoved int
+		wantPrimary string
+		wantTiers   metainfo.AnnounceList
+	}{
+		{
+			name:        "removes from every tier",
+			mi:          metainfo.MetaInfo{Announce: "https://a/1", AnnounceList: metainfo.AnnounceList{{"https://a/1", "https://dead/1"}, {"https://dead/2"}}},
+			substrings:  []string{"dead"},
+			wantRemoved: 2,
+			wantPrimary: "https://a/1",
+			wantTiers:   metainfo.AnnounceList{{"https://a/1"}},
+		},
+		{
+			name:        "removed primary is replaced",
+			mi:          metainfo.MetaInfo{Announce: "https://dead/1", AnnounceList: metainfo.AnnounceList{{"https://dead/1"}, {"https://b/1"}}},
+			substrings:  []string{"dead"},
+			wantRemoved: 1,
+			wantPrimary: "https://b/1",
+			wantTiers:   metainfo.AnnounceList{{"https://b/1"}},
+		},
+		{
+			name:        "nothing left",
+			mi:          metainfo.MetaInfo{Announce: "https://dead/1", AnnounceList: metainfo.AnnounceList{{"https://dead/1"}, {"https://gone/1"}}},
+			substrings:  []string{"dead", "gone"},
+			wantRemoved: 2,
+		},
+		{
+			name:        "announce without a list",
+			mi:          metainfo.MetaInfo{Announce: "https://dead/1"},
+			substrings:  []string{"dead"},
+			wantRemoved: 1,
+		},
+		{
+			name:        "no match",
+			mi:          metainfo.MetaInfo{Announce: "https://a/1", AnnounceList: metainfo.AnnounceList{{"https://a/1"}}},
+			substrings:  []string{"dead"},
+			wantPrimary: "https://a/1",
+			wantTiers:   metainfo.AnnounceList{{"https://a/1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mi := tt.mi
+			if removed := removeTrackers(&mi, tt.substrings); removed != tt.wantRemoved {
+				t.Errorf("removed = %d, want %d", removed, tt.wantRemoved)
+			}
+			if mi.Announce != tt.wantPrimary {
+				t.Errorf("Announce = %q, want %q", mi.Announce, tt.wantPrimary)
+			}
+			if !reflect.DeepEqual(mi.AnnounceList, tt.wantTiers) {
+				t.Errorf("AnnounceList = %v, want %v", mi.AnnounceList, tt.wantTiers)
+			}
+		})
+	}
+}
+
+func TestModifyTorrent_RemoveTrackers(t *testing.T) {
+	tmpDir := t.TempDir()
+	contentPath := filepath.Join(tmpDir, "content.bin")
+	if err := os.WriteFile(contentPath, make([]byte, 1<<16), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+	torrentPath := filepath.Join(tmpDir, "content.torrent")
+	trackerURLs := []string{"https://dead.example/announce", "https://tracker-b.example/announce"}
+	if _, err := Create(CreateOptions{Path: contentPath, OutputPath: torrentPath, TrackerURLs: trackerURLs, Quiet: true}); err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+
+	result, err := ModifyTorrent(torrentPath, ModifyOptions{
+		OutputDir:      filepath.Join(tmpDir, "out"),
+		RemoveTrackers: []string{"dead.example"},
+		AddTrackerURLs: []string{"https://tracker-c.example/announce"},
+		Quiet:          true,
+	})
+	if err != nil {
+		t.Fatalf("ModifyTorrent failed: %v", err)
+	}
+	if !result.WasModified || result.RemovedTrackers != 1 {
+		t.Fatalf("expected one removed tracker, got modified=%v removed=%d", result.WasModified, result.RemovedTrackers)
+	}
+
+	mi, err := LoadFromFile(result.OutputPath)
+	if err != nil {
+		t.Fatalf("failed to load modified torrent: %v", err)
+	}
+	if mi.Announce != trackerURLs[1] {
+		t.Errorf("Announce = %q, want %q", mi.Announce, trackerURLs[1])
+	}
+	want := []string{trackerURLs[1], "https://tracker-c.example/announce"}
+	if got := slices.Concat(mi.AnnounceList...); !slices.Equal(got, want) {
+		t.Errorf("trackers = %v, want %v", got, want)
+	}
+}
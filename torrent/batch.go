@@ -1,13 +1,18 @@
 package torrent
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/autobrr/mkbrr/internal/configsource"
 	"github.com/autobrr/mkbrr/internal/preset"
 )
 
@@ -20,8 +25,9 @@ type BatchConfig struct {
 // BatchJob represents a single torrent creation job within a batch
 type BatchJob struct {
 	Output              string   `yaml:"output"`
+	OutputDir           string   `yaml:"output_dir"`
 	Path                string   `yaml:"path"`
-	Name                string   `yaml:"-"`
+	Name                string   `yaml:"name"`
 	Comment             string   `yaml:"comment"`
 	Source              string   `yaml:"source"`
 	Trackers            []string `yaml:"trackers"`
@@ -35,6 +41,65 @@ type BatchJob struct {
 	SkipPrefix          bool     `yaml:"skip_prefix"`
 	Entropy             bool     `yaml:"entropy"`
 	FailOnSeasonWarning bool     `yaml:"fail_on_season_warning"`
+	Creator             string   `yaml:"creator"`
+
+	// Meta holds additional root-level string fields, see
+	// CreateOptions.ExtraRootFields
+	Meta map[string]string `yaml:"meta"`
+
+	// MaxRetries retries creating the torrent after transient errors, waiting
+	// RetryDelaySeconds before the first retry and twice as long after each one.
+	MaxRetries        int `yaml:"max_retries"`
+	RetryDelaySeconds int `yaml:"retry_delay_seconds"`
+
+	// TrackerSets creates a torrent of the content for each set instead of
+	// one, hashing it once for each piece length, see CreateTorrentSet.
+	TrackerSets []BatchTrackerSet `yaml:"tracker_sets"`
+}
+
+// BatchTrackerSet is one of the torrents of a BatchJob with tracker sets. Its
+// fields replace those of the job when set.
+type BatchTrackerSet struct {
+	Trackers    []string `yaml:"trackers"`
+	Source      string   `yaml:"source"`
+	Comment     string   `yaml:"comment"`
+	Private     *bool    `yaml:"private"`
+	Entropy     *bool    `yaml:"entropy"`
+	PieceLength uint     `yaml:"piece_length"`
+}
+
+// trackerSetJobs returns a job for each tracker set of j, or j itself when it
+// has none.
+func (j BatchJob) trackerSetJobs() []BatchJob {
+	if len(j.TrackerSets) == 0 {
+		return []BatchJob{j}
+	}
+	jobs := make([]BatchJob, len(j.TrackerSets))
+	for i, set := range j.TrackerSets {
+		job := j
+		job.TrackerSets = nil
+		if len(set.Trackers) > 0 {
+			job.Trackers = set.Trackers
+		}
+		if set.Source != "" {
+			job.Source = set.Source
+		}
+		if set.Comment != "" {
+			job.Comment = set.Comment
+		}
+		if set.Private != nil {
+			job.Private = *set.Private
+		}
+		if set.Entropy != nil {
+			job.Entropy = *set.Entropy
+		}
+		if set.PieceLength != 0 {
+			job.PieceLength = set.PieceLength
+			job.TargetPieceCount = 0
+		}
+		jobs[i] = job
+	}
+	return jobs
 }
 
 // ToCreateOptions converts a BatchJob to CreateOptions
@@ -57,6 +122,8 @@ func (j *BatchJob) ToCreateOptions(verbose bool, quiet bool, infoOnly bool, vers
 		ExcludePatterns:         j.ExcludePatterns,
 		IncludePatterns:         j.IncludePatterns,
 		FailOnSeasonPackWarning: j.FailOnSeasonWarning,
+		Creator:                 j.Creator,
+		ExtraRootFields:         j.Meta,
 	}
 
 	if j.PieceLength != 0 {
@@ -78,14 +145,48 @@ type BatchResult struct {
 	Info     *TorrentInfo
 	Trackers []string
 	Job      BatchJob
+	Attempts int // times the torrent was created, more than 1 after retries
 	Success  bool
+	Skipped  bool // not run, or cancelled, because another job failed with FailFastOnAnyJob set
+}
+
+// BatchOptions holds options for ProcessBatchWithOptions
+type BatchOptions struct {
+	Verbose         bool
+	Quiet           bool
+	InfoOnly        bool
+	ContinueOnError bool // record invalid jobs as failed results instead of failing the batch
+	FailFast        bool // set CreateOptions.FailFast for every job
+	// FailFastOnAnyJob cancels the remaining jobs, including running ones,
+	// once a job fails. They are returned with Skipped set.
+	FailFastOnAnyJob bool
+	Version          string
 }
 
+// createTorrent is replaced in tests to simulate transient failures.
+var createTorrent = CreateTorrent
+
 // ProcessBatch processes a batch configuration file and creates multiple torrents.
 // It reads a YAML configuration file containing multiple torrent creation jobs
 // and processes them in parallel for efficient batch operations.
-func ProcessBatch(configPath string, verbose bool, quiet bool, infoOnly bool, version string) ([]BatchResult, error) {
-	data, err := os.ReadFile(configPath)
+// configPath may also be "-" for stdin or an HTTP(S) URL; relative job paths
+// are resolved against the current working directory.
+// An invalid job fails the whole batch unless continueOnError is set, in which
+// case it is recorded as a failed result and the remaining jobs still run.
+func ProcessBatch(configPath string, verbose bool, quiet bool, infoOnly bool, continueOnError bool, version string) ([]BatchResult, error) {
+	return ProcessBatchWithOptions(configPath, BatchOptions{
+		Verbose:         verbose,
+		Quiet:           quiet,
+		InfoOnly:        infoOnly,
+		ContinueOnError: continueOnError,
+		Version:         version,
+	})
+}
+
+// ProcessBatchWithOptions is ProcessBatch with the options in a struct, which
+// also allows stopping the batch at the first failed job.
+func ProcessBatchWithOptions(configPath string, opts BatchOptions) ([]BatchResult, error) {
+	data, err := configsource.Read(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read batch config: %w", err)
 	}
@@ -103,33 +204,84 @@ func ProcessBatch(configPath string, verbose bool, quiet bool, infoOnly bool, ve
 		return nil, fmt.Errorf("no jobs defined in batch config")
 	}
 
-	// validate all jobs before processing
+	// a job with tracker sets has a result for each torrent, which are
+	// created together
+	var setJobs []BatchJob
+	var groups [][]int // indices into setJobs of the torrents of each job
 	for _, job := range config.Jobs {
+		var group []int
+		for _, setJob := range job.trackerSetJobs() {
+			group = append(group, len(setJobs))
+			setJobs = append(setJobs, setJob)
+		}
+		groups = append(groups, group)
+	}
+	results := make([]BatchResult, len(setJobs))
+
+	// validate all jobs before processing
+	valid := make([]int, 0, len(config.Jobs))
+	for i, job := range config.Jobs {
 		if err := validateJob(job); err != nil {
-			return nil, fmt.Errorf("invalid job configuration: %w", err)
+			err = fmt.Errorf("invalid job configuration: %w", err)
+			if !opts.ContinueOnError {
+				return nil, err
+			}
+			for _, idx := range groups[i] {
+				results[idx] = BatchResult{Job: setJobs[idx], Trackers: setJobs[idx].Trackers, Error: err}
+			}
+			defaultLogger.Debug("skipping invalid batch job", "path", job.Path, "error", err)
+			continue
 		}
+		valid = append(valid, i)
 	}
 
-	results := make([]BatchResult, len(config.Jobs))
 	var wg sync.WaitGroup
 
+	// cancelled when a job fails with FailFastOnAnyJob set
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	// process jobs in parallel with a worker pool
-	workers := min(len(config.Jobs), 4) // limit concurrent jobs
-	jobs := make(chan int, len(config.Jobs))
+	workers := min(len(valid), 4) // limit concurrent jobs
+	jobs := make(chan int, len(valid))
 
 	// start workers
 	for i := 0; i < workers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for idx := range jobs {
-				results[idx] = processJob(config.Jobs[idx], verbose, quiet, infoOnly, version)
+			for i := range jobs {
+				group := groups[i]
+				if ctx.Err() != nil {
+					for _, idx := range group {
+						results[idx] = BatchResult{Job: setJobs[idx], Trackers: setJobs[idx].Trackers, Skipped: true}
+					}
+					continue
+				}
+
+				var jobResults []BatchResult
+				if len(group) == 1 {
+					jobResults = []BatchResult{processJob(ctx, setJobs[group[0]], opts)}
+				} else {
+					jobResults = processJobSet(ctx, setJobs[group[0]:group[0]+len(group)], opts)
+				}
+				for j, idx := range group {
+					results[idx] = jobResults[j]
+					switch {
+					case results[idx].Success:
+					case ctx.Err() != nil:
+						// cancelled because another job failed
+						results[idx] = BatchResult{Job: setJobs[idx], Trackers: setJobs[idx].Trackers, Skipped: true}
+					case opts.FailFastOnAnyJob:
+						cancel()
+					}
+				}
 			}
 		}()
 	}
 
 	// send jobs to workers
-	for i := range config.Jobs {
+	for _, i := range valid {
 		jobs <- i
 	}
 	close(jobs)
@@ -147,8 +299,12 @@ func validateJob(job BatchJob) error {
 		return fmt.Errorf("invalid path %q: %w", job.Path, err)
 	}
 
-	if job.Output == "" {
-		return fmt.Errorf("output is required")
+	if job.Output == "" && job.OutputDir == "" {
+		return fmt.Errorf("output or output_dir is required")
+	}
+
+	if job.Output != "" && job.OutputDir != "" {
+		return fmt.Errorf("cannot set both output and output_dir; use one or the other")
 	}
 
 	if job.PieceLength != 0 && (job.PieceLength < 14 || job.PieceLength > 24) {
@@ -159,23 +315,145 @@ func validateJob(job BatchJob) error {
 		return fmt.Errorf("cannot set both piece_length and target_piece_count; use one or the other")
 	}
 
+	if job.MaxRetries < 0 {
+		return fmt.Errorf("max_retries must not be negative")
+	}
+
+	if job.RetryDelaySeconds < 0 {
+		return fmt.Errorf("retry_delay_seconds must not be negative")
+	}
+
+	if err := validateRootFields(job.Meta); err != nil {
+		return err
+	}
+
+	for _, field := range []struct{ name, text string }{
+		{name: "comment", text: job.Comment},
+		{name: "output", text: job.Output},
+		{name: "output_dir", text: job.OutputDir},
+	} {
+		if err := checkTemplate(field.name, field.text); err != nil {
+			return err
+		}
+	}
+
+	if len(job.TrackerSets) > 0 {
+		// the torrents of the sets are told apart by their tracker prefix
+		if job.OutputDir == "" || job.SkipPrefix {
+			return fmt.Errorf("tracker_sets require output_dir without skip_prefix, so each torrent is named after its tracker")
+		}
+		for i, setJob := range job.trackerSetJobs() {
+			if err := validateJob(setJob); err != nil {
+				return fmt.Errorf("tracker set %d: %w", i+1, err)
+			}
+		}
+	}
+
 	return nil
 }
 
-func processJob(job BatchJob, verbose bool, quiet bool, infoOnly bool, version string) BatchResult {
+func processJob(ctx context.Context, job BatchJob, opts BatchOptions) BatchResult {
 	result := BatchResult{
 		Job:      job,
 		Trackers: job.Trackers,
 	}
 
+	// convert job to CreateOptions
+	createOpts := job.ToCreateOptions(opts.Verbose, opts.Quiet, opts.InfoOnly, opts.Version)
+	createOpts.FailFast = opts.FailFast
+	createOpts.Context = ctx
+
+	// create the torrent
+	defaultLogger.Debug("processing batch job", "path", job.Path, "output", job.Output, "output_dir", job.OutputDir)
+	var mi *Torrent
+	retryDelay := time.Duration(job.RetryDelaySeconds) * time.Second
+	attempts, err := retryBatchJob(ctx, job.MaxRetries, retryDelay, func() error {
+		var err error
+		mi, err = createTorrent(createOpts)
+		if err != nil && job.MaxRetries > 0 {
+			defaultLogger.Debug("batch job attempt failed", "path", job.Path, "error", err, "retryable", IsRetryable(err))
+		}
+		return err
+	})
+	result.Attempts = attempts
+	if err != nil {
+		result.Error = fmt.Errorf("failed to create torrent: %w", err)
+		defaultLogger.Debug("batch job failed", "path", job.Path, "error", result.Error)
+		return result
+	}
+
+	writeJobTorrent(&result, createOpts, mi)
+	return result
+}
+
+// processJobSet creates the torrents of the tracker sets of a job together,
+// see CreateTorrentSet. jobs are the jobs of its tracker sets, which share
+// the retry settings of the job.
+func processJobSet(ctx context.Context, jobs []BatchJob, opts BatchOptions) []BatchResult {
+	results := make([]BatchResult, len(jobs))
+	createOpts := make([]CreateOptions, len(jobs))
+	for i, job := range jobs {
+		results[i] = BatchResult{Job: job, Trackers: job.Trackers}
+		createOpts[i] = job.ToCreateOptions(opts.Verbose, opts.Quiet, opts.InfoOnly, opts.Version)
+		createOpts[i].FailFast = opts.FailFast
+		createOpts[i].Context = ctx
+	}
+
+	job := jobs[0]
+	defaultLogger.Debug("processing batch job", "path", job.Path, "output_dir", job.OutputDir, "tracker_sets", len(jobs))
+	var torrents []*Torrent
+	retryDelay := time.Duration(job.RetryDelaySeconds) * time.Second
+	attempts, err := retryBatchJob(ctx, job.MaxRetries, retryDelay, func() error {
+		var err error
+		torrents, err = CreateTorrentSet(createOpts)
+		if err != nil && job.MaxRetries > 0 {
+			defaultLogger.Debug("batch job attempt failed", "path", job.Path, "error", err, "retryable", IsRetryable(err))
+		}
+		return err
+	})
+	for i := range results {
+		results[i].Attempts = attempts
+		if err != nil {
+			results[i].Error = fmt.Errorf("failed to create torrent: %w", err)
+			continue
+		}
+		writeJobTorrent(&results[i], createOpts[i], torrents[i])
+	}
+	if err != nil {
+		defaultLogger.Debug("batch job failed", "path", job.Path, "error", err)
+	}
+	return results
+}
+
+// writeJobTorrent writes the torrent mi created for result.Job and records it
+// in result.
+func writeJobTorrent(result *BatchResult, createOpts CreateOptions, mi *Torrent) {
+	job := result.Job
+
 	var trackerURL string
 	if len(job.Trackers) > 0 {
 		trackerURL = job.Trackers[0]
 	}
 
-	output := job.Output
+	// output and output_dir may use templates such as {{.InfoHash}}, known only
+	// after hashing; validateJob checked them before any job ran
+	data := templateData(mi, createOpts)
+	output, err := expandTemplate("output", job.Output, data)
+	if err != nil {
+		result.Error = err
+		return
+	}
+	outputDir, err := expandTemplate("output_dir", job.OutputDir, data)
+	if err != nil {
+		result.Error = err
+		return
+	}
+
 	if output == "" {
-		baseName := filepath.Base(filepath.Clean(job.Path))
+		baseName := job.Name
+		if baseName == "" {
+			baseName = filepath.Base(filepath.Clean(job.Path))
+		}
 
 		if trackerURL != "" && !job.SkipPrefix {
 			prefix := preset.GetDomainPrefix(trackerURL)
@@ -190,38 +468,237 @@ func processJob(job BatchJob, verbose bool, quiet bool, infoOnly bool, version s
 		output += ".torrent"
 	}
 
-	// convert job to CreateOptions
-	opts := job.ToCreateOptions(verbose, quiet, infoOnly, version)
-
-	// create the torrent
-	mi, err := CreateTorrent(opts)
-	if err != nil {
-		result.Error = fmt.Errorf("failed to create torrent: %w", err)
-		return result
+	// output dir may contain template variables such as {year} or {tracker}
+	if outputDir := preset.ExpandOutputDirAt(outputDir, trackerURL, now()); outputDir != "" {
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			result.Error = fmt.Errorf("failed to create output directory %q: %w", outputDir, err)
+			return
+		}
+		output = filepath.Join(outputDir, output)
 	}
 
 	// write the torrent file
 	f, err := os.Create(output)
 	if err != nil {
 		result.Error = fmt.Errorf("failed to create output file: %w", err)
-		return result
+		return
 	}
 	defer f.Close()
 
 	if err := mi.Write(f); err != nil {
 		result.Error = fmt.Errorf("failed to write torrent file: %w", err)
-		return result
+		return
 	}
 
 	// collect torrent info
 	info := mi.GetInfo()
 	result.Success = true
 	result.Info = &TorrentInfo{
-		Path:     output,
-		Size:     info.TotalLength(),
-		InfoHash: mi.HashInfoBytes().String(),
-		Files:    len(info.Files),
+		Path:        output,
+		Size:        info.TotalLength(),
+		InfoHash:    mi.HashInfoBytes().String(),
+		Files:       len(info.Files),
+		PieceLength: info.PieceLength,
+		PieceCount:  info.NumPieces(),
+		Warnings:    mi.Warnings,
 	}
+}
 
-	return result
+// BatchError returns an error summarizing the failed jobs in results,
+// or nil when every job succeeded.
+func BatchError(results []BatchResult) error {
+	failed, skipped := 0, 0
+	for _, result := range results {
+		switch {
+		case result.Skipped:
+			skipped++
+		case !result.Success:
+			failed++
+		}
+	}
+
+	if failed == 0 {
+		return nil
+	}
+
+	if skipped > 0 {
+		return fmt.Errorf("%d of %d batch jobs failed, %d skipped", failed, len(results), skipped)
+	}
+	return fmt.Errorf("%d of %d batch jobs failed", failed, len(results))
+}
+
+// BatchExitError returns the error a batch create run ends with: the
+// BatchError of results, or nil with continueOnError so failed jobs are
+// only reported.
+func BatchExitError(results []BatchResult, continueOnError bool) error {
+	if continueOnError {
+		return nil
+	}
+	return BatchError(results)
+}
+
+// WriteBatchQuietResults writes a line for each job of a batch create run
+// in quiet mode: "Wrote: <output>" to stdout for each torrent created, and
+// "SKIPPED: <path>" or "FAILED: <path>: <error>" to stderr for the others.
+func WriteBatchQuietResults(stdout, stderr io.Writer, results []BatchResult) {
+	for _, result := range results {
+		switch {
+		case result.Success:
+			fmt.Fprintln(stdout, "Wrote:", result.Info.Path)
+		case result.Skipped:
+			fmt.Fprintf(stderr, "SKIPPED: %s\n", result.Job.Path)
+		default:
+			fmt.Fprintf(stderr, "FAILED: %s: %v\n", result.Job.Path, result.Error)
+		}
+	}
+}
+
+// VerifyBatchConfig represents the YAML configuration for batch verification
+type VerifyBatchConfig struct {
+	Jobs    []VerifyBatchJob `yaml:"jobs"`
+	Version int              `yaml:"version"`
+}
+
+// VerifyBatchJob represents a single verification job within a batch
+type VerifyBatchJob struct {
+	TorrentPath string `yaml:"torrent_path"`
+	ContentPath string `yaml:"content_path"`
+}
+
+// VerifyBatchOptions holds options for batch verification
+type VerifyBatchOptions struct {
+	Verbose     bool
+	Quiet       bool
+	FailFast    bool   // stop after the first job with bad pieces, missing files or an error
+	Workers     int    // worker goroutines per job (0 for automatic)
+	Concurrency int    // number of jobs verified at once (0 or 1 verifies sequentially)
+	Logger      Logger // receives diagnostic messages, the logger set with SetDefaultLogger if nil
+	// MaxReadBytesPerSecond limits the read rate of each job; 0 disables throttling
+	MaxReadBytesPerSecond int64
+}
+
+// VerifyBatchResult represents the result of a single job in a verification batch
+type VerifyBatchResult struct {
+	VerificationResult
+	Error       error
+	TorrentPath string
+	ContentPath string
+	Skipped     bool // not verified because an earlier job failed with FailFast set
+}
+
+// Failed reports whether the job could not be verified or the content is incomplete.
+func (r VerifyBatchResult) Failed() bool {
+	if r.Skipped {
+		return false
+	}
+	return r.Error != nil || r.BadPieces > 0 || len(r.MissingFiles) > 0
+}
+
+// ProcessVerifyBatch reads a YAML configuration file of torrent/content pairs
+// and verifies each of them, optionally running several jobs concurrently.
+// Like ProcessBatch, configPath may be a file, "-" for stdin or an HTTP(S) URL.
+// Results are returned in the order the jobs appear in the config.
+func ProcessVerifyBatch(configPath string, opts VerifyBatchOptions) ([]VerifyBatchResult, error) {
+	data, err := configsource.Read(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch config: %w", err)
+	}
+
+	var config VerifyBatchConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse batch config: %w", err)
+	}
+
+	if config.Version != 1 {
+		return nil, fmt.Errorf("unsupported batch config version: %d", config.Version)
+	}
+
+	if len(config.Jobs) == 0 {
+		return nil, fmt.Errorf("no jobs defined in batch config")
+	}
+
+	for i, job := range config.Jobs {
+		if job.TorrentPath == "" {
+			return nil, fmt.Errorf("invalid job configuration: job %d: torrent_path is required", i+1)
+		}
+		if job.ContentPath == "" {
+			return nil, fmt.Errorf("invalid job configuration: job %d: content_path is required", i+1)
+		}
+	}
+
+	results := make([]VerifyBatchResult, len(config.Jobs))
+	for i, job := range config.Jobs {
+		results[i] = VerifyBatchResult{TorrentPath: job.TorrentPath, ContentPath: job.ContentPath}
+	}
+
+	workers := min(len(config.Jobs), max(opts.Concurrency, 1))
+	jobs := make(chan int, len(config.Jobs))
+	var stop atomic.Bool
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				if stop.Load() {
+					results[idx].Skipped = true
+					continue
+				}
+
+				verifyJob(&results[idx], opts, workers > 1)
+				if opts.FailFast && results[idx].Failed() {
+					stop.Store(true)
+				}
+			}
+		}()
+	}
+
+	for i := range config.Jobs {
+		jobs <- i
+	}
+	close(jobs)
+
+	wg.Wait()
+	return results, nil
+}
+
+// verifyJob verifies a single batch job and stores the outcome in result.
+// Progress output is suppressed when jobs run concurrently.
+func verifyJob(result *VerifyBatchResult, opts VerifyBatchOptions, concurrent bool) {
+	logger := resolveLogger(opts.Logger)
+	logDebug(logger, "verifying batch job", "torrent", result.TorrentPath, "content", result.ContentPath)
+
+	verification, err := VerifyData(VerifyOptions{
+		TorrentPath:           result.TorrentPath,
+		ContentPath:           result.ContentPath,
+		Verbose:               opts.Verbose,
+		Quiet:                 opts.Quiet || concurrent,
+		Workers:               opts.Workers,
+		Logger:                opts.Logger,
+		MaxReadBytesPerSecond: opts.MaxReadBytesPerSecond,
+	})
+	if err != nil {
+		result.Error = err
+		logDebug(logger, "batch job failed", "torrent", result.TorrentPath, "error", err)
+		return
+	}
+	result.VerificationResult = *verification
+}
+
+// VerifyBatchError returns an error summarizing the failed verification jobs
+// in results, or nil when every verified job passed.
+func VerifyBatchError(results []VerifyBatchResult) error {
+	failed := 0
+	for _, result := range results {
+		if result.Failed() {
+			failed++
+		}
+	}
+
+	if failed == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%d of %d verify jobs failed", failed, len(results))
 }
@@ -1,10 +1,21 @@
 package torrent
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/autobrr/mkbrr/internal/preset"
 )
 
 func TestProcessBatch(t *testing.T) {
@@ -63,25 +74,48 @@ jobs:
     webseeds:
       - https://example.com/files/
     comment: "Test batch torrent"
+  - output_dir: %s
+    path: %s
+    name: "Renamed Directory"
+    trackers:
+      - udp://tracker.example.com:1337/announce
+  - output_dir: %s
+    path: %s
+    skip_prefix: true
 `,
 		filepath.Join(tmpDir, "file1.torrent"),
 		filepath.Join(tmpDir, "file1.txt"),
 		filepath.Join(tmpDir, "dir1.torrent"),
-		filepath.Join(tmpDir, "dir1")))
+		filepath.Join(tmpDir, "dir1"),
+		filepath.Join(tmpDir, "out", "{tracker}"),
+		filepath.Join(tmpDir, "dir1"),
+		filepath.Join(tmpDir, "out"),
+		filepath.Join(tmpDir, "file1.txt")))
 
 	if err := os.WriteFile(configPath, configContent, 0644); err != nil {
 		t.Fatalf("Failed to write config file: %v", err)
 	}
 
 	// process batch
-	results, err := ProcessBatch(configPath, true, false, false, "test-version")
+	results, err := ProcessBatch(configPath, true, false, false, false, "test-version")
 	if err != nil {
 		t.Fatalf("ProcessBatch failed: %v", err)
 	}
 
 	// verify results
-	if len(results) != 2 {
-		t.Errorf("Expected 2 results, got %d", len(results))
+	if len(results) != 4 {
+		t.Errorf("Expected 4 results, got %d", len(results))
+	}
+
+	prefix := preset.GetDomainPrefix("udp://tracker.example.com:1337/announce")
+	expected := []struct {
+		path string
+		name string
+	}{
+		{path: filepath.Join(tmpDir, "file1.torrent"), name: "Test File 1"},
+		{path: filepath.Join(tmpDir, "dir1.torrent"), name: "Test Directory"},
+		{path: filepath.Join(tmpDir, "out", prefix, prefix+"_Renamed Directory.torrent"), name: "Renamed Directory"},
+		{path: filepath.Join(tmpDir, "out", "file1.txt.torrent"), name: "file1.txt"},
 	}
 
 	for i, result := range results {
@@ -95,9 +129,19 @@ jobs:
 			continue
 		}
 
-		// verify torrent files were created
-		if _, err := os.Stat(result.Info.Path); err != nil {
+		// verify torrent files were created where the job asked for them
+		if result.Info.Path != expected[i].path {
+			t.Errorf("Job %d wrote %s, want %s", i, result.Info.Path, expected[i].path)
+		}
+		mi, err := LoadFromFile(expected[i].path)
+		if err != nil {
 			t.Errorf("Job %d torrent file not created: %v", i, err)
+			continue
+		}
+		if info, err := mi.UnmarshalInfo(); err != nil {
+			t.Errorf("Job %d has invalid info: %v", i, err)
+		} else if info.Name != expected[i].name {
+			t.Errorf("Job %d info.Name = %q, want %q", i, info.Name, expected[i].name)
 		}
 
 		// basic validation of torrent info
@@ -115,7 +159,7 @@ jobs:
 			if result.Info.Files != 0 {
 				t.Errorf("Expected single file torrent, got %d files", result.Info.Files)
 			}
-		case 1: // dir1
+		case 1, 2: // dir1
 			if result.Info.Files != 2 {
 				t.Errorf("Expected 2 files in directory torrent, got %d", result.Info.Files)
 			}
@@ -158,7 +202,7 @@ jobs:
 		t.Fatalf("Failed to write config file: %v", err)
 	}
 
-	results, err := ProcessBatch(configPath, false, false, false, "test-version")
+	results, err := ProcessBatch(configPath, false, false, false, false, "test-version")
 	if err != nil {
 		t.Fatalf("ProcessBatch failed: %v", err)
 	}
@@ -207,6 +251,15 @@ jobs:
   - path: test.txt`,
 			expectError: true,
 		},
+		{
+			name: "output and output_dir conflict",
+			config: `version: 1
+jobs:
+  - output: test.torrent
+    output_dir: torrents
+    path: test.txt`,
+			expectError: true,
+		},
 		{
 			name: "invalid piece length",
 			config: `version: 1
@@ -232,6 +285,14 @@ jobs:
     target_piece_count: 1000`,
 			expectError: true,
 		},
+		{
+			name: "unknown template variable",
+			config: `version: 1
+jobs:
+  - output: "{{.Hash}}.torrent"
+    path: test.txt`,
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -247,7 +308,7 @@ jobs:
 				t.Fatalf("Failed to write config file: %v", err)
 			}
 
-			_, err = ProcessBatch(configPath, false, false, false, "test-version")
+			_, err = ProcessBatch(configPath, false, false, false, false, "test-version")
 			if tt.expectError && err == nil {
 				t.Error("Expected error but got nil")
 			}
@@ -257,3 +318,745 @@ jobs:
 		})
 	}
 }
+
+func TestBatchPartialFailure(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "mkbrr-batch-failure")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	goodFile := filepath.Join(tmpDir, "good.txt")
+	if err := os.WriteFile(goodFile, []byte("good content"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	badFile := filepath.Join(tmpDir, "bad.txt")
+	if err := os.WriteFile(badFile, []byte("bad content"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	// the second job writes into a directory that does not exist, so it fails after validation
+	configPath := filepath.Join(tmpDir, "batch.yaml")
+	configContent := []byte(fmt.Sprintf(`version: 1
+jobs:
+  - output: %s
+    path: %s
+  - output: %s
+    path: %s
+`,
+		filepath.Join(tmpDir, "good.torrent"),
+		goodFile,
+		filepath.Join(tmpDir, "nonexistent", "bad.torrent"),
+		badFile))
+
+	if err := os.WriteFile(configPath, configContent, 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	results, err := ProcessBatch(configPath, false, false, false, false, "test-version")
+	if err != nil {
+		t.Fatalf("ProcessBatch failed: %v", err)
+	}
+
+	if !results[0].Success {
+		t.Errorf("Expected job 0 to succeed, got error: %v", results[0].Error)
+	}
+	if results[1].Success {
+		t.Fatal("Expected job 1 to fail")
+	}
+
+	batchErr := BatchError(results)
+	if batchErr == nil {
+		t.Fatal("Expected BatchError to report the failed job")
+	}
+	if !strings.Contains(batchErr.Error(), "1 of 2") {
+		t.Errorf("Expected failure count in error, got: %v", batchErr)
+	}
+
+	// failed jobs are listed in the summary even without verbose output
+	var buf bytes.Buffer
+	display := NewDisplay(NewFormatter(false))
+	display.output = &buf
+	display.ShowBatchResults(results, time.Second)
+
+	output := stripAnsiCodes(buf.String())
+	if !strings.Contains(output, "Failed jobs:") {
+		t.Errorf("Expected failed jobs section in output, got:\n%s", output)
+	}
+	if !strings.Contains(output, badFile) {
+		t.Errorf("Expected failed job path %q in output, got:\n%s", badFile, output)
+	}
+	if strings.Contains(output, goodFile+":") {
+		t.Errorf("Did not expect successful job in failed list, got:\n%s", output)
+	}
+}
+
+func TestBatchErrorNonexistentPath(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "mkbrr-batch-missing")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	configPath := filepath.Join(tmpDir, "batch.yaml")
+	configContent := []byte(fmt.Sprintf(`version: 1
+jobs:
+  - output: %s
+    path: %s
+`,
+		filepath.Join(tmpDir, "missing.torrent"),
+		filepath.Join(tmpDir, "does-not-exist")))
+
+	if err := os.WriteFile(configPath, configContent, 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	if _, err := ProcessBatch(configPath, false, false, false, false, "test-version"); err == nil {
+		t.Error("Expected error for job with nonexistent path")
+	}
+}
+
+func TestProcessBatchContinueOnError(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	contentPath := filepath.Join(tmpDir, "content.bin")
+	if err := os.WriteFile(contentPath, []byte("test content"), 0644); err != nil {
+		t.Fatalf("Failed to write content file: %v", err)
+	}
+
+	configPath := filepath.Join(tmpDir, "batch.yaml")
+	configContent := []byte(fmt.Sprintf(`version: 1
+jobs:
+  - output: %s
+    path: %s
+  - output: %s
+    path: %s
+`,
+		filepath.Join(tmpDir, "missing.torrent"),
+		filepath.Join(tmpDir, "does-not-exist"),
+		filepath.Join(tmpDir, "content.torrent"),
+		contentPath))
+
+	if err := os.WriteFile(configPath, configContent, 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	results, err := ProcessBatch(configPath, false, true, false, true, "test-version")
+	if err != nil {
+		t.Fatalf("ProcessBatch failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	if results[0].Success || results[0].Error == nil {
+		t.Errorf("Expected the invalid job to fail, got %+v", results[0])
+	} else if !strings.Contains(results[0].Error.Error(), "invalid job configuration") {
+		t.Errorf("Unexpected error for invalid job: %v", results[0].Error)
+	}
+	if results[0].Job.Path != filepath.Join(tmpDir, "does-not-exist") {
+		t.Errorf("Expected the failed result to keep its job, got path %q", results[0].Job.Path)
+	}
+
+	if !results[1].Success {
+		t.Fatalf("Expected the valid job to succeed, got error: %v", results[1].Error)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "content.torrent")); err != nil {
+		t.Errorf("Expected torrent for the valid job: %v", err)
+	}
+
+	if err := BatchError(results); err == nil || err.Error() != "1 of 2 batch jobs failed" {
+		t.Errorf("BatchError() = %v", err)
+	}
+}
+
+func TestBatchErrorAllSuccessful(t *testing.T) {
+	results := []BatchResult{{Success: true}, {Success: true}}
+	if err := BatchError(results); err != nil {
+		t.Errorf("Expected nil error when all jobs succeed, got: %v", err)
+	}
+}
+
+// writeFailingJobBatch writes a batch config with a job for a nonexistent
+// path followed by a valid one, returning the config path, the missing path
+// and the output of the valid job.
+func writeFailingJobBatch(t *testing.T) (string, string, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	contentPath := filepath.Join(tmpDir, "content.bin")
+	if err := os.WriteFile(contentPath, []byte("test content"), 0644); err != nil {
+		t.Fatalf("Failed to write content file: %v", err)
+	}
+	missingPath := filepath.Join(tmpDir, "does-not-exist")
+	goodOutput := filepath.Join(tmpDir, "content.torrent")
+
+	configPath := filepath.Join(tmpDir, "batch.yaml")
+	configContent := fmt.Sprintf("version: 1\njobs:\n  - output: %s\n    path: %s\n  - output: %s\n    path: %s\n",
+		filepath.Join(tmpDir, "missing.torrent"), missingPath, goodOutput, contentPath)
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return configPath, missingPath, goodOutput
+}
+
+func TestBatchExitError_FailedJob(t *testing.T) {
+	configPath, _, _ := writeFailingJobBatch(t)
+
+	// without continue-on-error the invalid job fails the run before any job starts
+	if _, err := ProcessBatchWithOptions(configPath, BatchOptions{Quiet: true}); err == nil || !strings.Contains(err.Error(), "invalid job configuration") {
+		t.Errorf("expected the run to fail on the invalid job, got %v", err)
+	}
+
+	results, err := ProcessBatchWithOptions(configPath, BatchOptions{Quiet: true, ContinueOnError: true})
+	if err != nil {
+		t.Fatalf("ProcessBatchWithOptions failed: %v", err)
+	}
+	if err := BatchExitError(results, true); err != nil {
+		t.Errorf("expected no error with continue-on-error, got %v", err)
+	}
+	if err := BatchExitError(results, false); err == nil || err.Error() != "1 of 2 batch jobs failed" {
+		t.Errorf("BatchExitError() = %v, want 1 of 2 batch jobs failed", err)
+	}
+
+	// a job that fails while it runs fails the run unless errors are tolerated
+	origCreate := createTorrent
+	createTorrent = func(opts CreateOptions) (*Torrent, error) {
+		return nil, errors.New("disk on fire")
+	}
+	defer func() { createTorrent = origCreate }()
+
+	configPath, _, _ = writeFailingJobBatch(t)
+	results, err = ProcessBatchWithOptions(configPath, BatchOptions{Quiet: true, ContinueOnError: true})
+	if err != nil {
+		t.Fatalf("ProcessBatchWithOptions failed: %v", err)
+	}
+	if err := BatchExitError(results, false); err == nil || err.Error() != "2 of 2 batch jobs failed" {
+		t.Errorf("BatchExitError() = %v, want 2 of 2 batch jobs failed", err)
+	}
+	if err := BatchExitError(results, true); err != nil {
+		t.Errorf("expected no error with continue-on-error, got %v", err)
+	}
+}
+
+func TestWriteBatchQuietResults(t *testing.T) {
+	configPath, missingPath, goodOutput := writeFailingJobBatch(t)
+
+	results, err := ProcessBatchWithOptions(configPath, BatchOptions{Quiet: true, ContinueOnError: true})
+	if err != nil {
+		t.Fatalf("ProcessBatchWithOptions failed: %v", err)
+	}
+	results = append(results, BatchResult{Job: BatchJob{Path: "/data/skipped"}, Skipped: true})
+
+	var stdout, stderr bytes.Buffer
+	WriteBatchQuietResults(&stdout, &stderr, results)
+
+	if want := "Wrote: " + goodOutput + "\n"; stdout.String() != want {
+		t.Errorf("stdout = %q, want %q", stdout.String(), want)
+	}
+	lines := strings.Split(strings.TrimSuffix(stderr.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines on stderr, got %q", stderr.String())
+	}
+	if prefix := "FAILED: " + missingPath + ": invalid job configuration"; !strings.HasPrefix(lines[0], prefix) {
+		t.Errorf("stderr line = %q, want prefix %q", lines[0], prefix)
+	}
+	if lines[1] != "SKIPPED: /data/skipped" {
+		t.Errorf("stderr line = %q, want %q", lines[1], "SKIPPED: /data/skipped")
+	}
+}
+
+// createVerifyBatchFixture creates count torrent/content pairs in dir and
+// writes a verify batch config listing them. It returns the config path and
+// the content paths in job order.
+func createVerifyBatchFixture(t *testing.T, dir string, count int) (string, []string) {
+	t.Helper()
+
+	var config strings.Builder
+	config.WriteString("version: 1\njobs:\n")
+
+	contentPaths := make([]string, count)
+	for i := range count {
+		contentPath := filepath.Join(dir, fmt.Sprintf("content%d.bin", i))
+		data := bytes.Repeat([]byte{byte('a' + i)}, 4<<16)
+		if err := os.WriteFile(contentPath, data, 0644); err != nil {
+			t.Fatalf("Failed to write content file: %v", err)
+		}
+
+		pieceLength := uint(16)
+		torrentPath := filepath.Join(dir, fmt.Sprintf("content%d.torrent", i))
+		if _, err := Create(CreateOptions{
+			Path:           contentPath,
+			OutputPath:     torrentPath,
+			PieceLengthExp: &pieceLength,
+			NoDate:         true,
+			Quiet:          true,
+		}); err != nil {
+			t.Fatalf("Failed to create torrent: %v", err)
+		}
+
+		fmt.Fprintf(&config, "  - torrent_path: %s\n    content_path: %s\n", torrentPath, contentPath)
+		contentPaths[i] = contentPath
+	}
+
+	configPath := filepath.Join(dir, "verify.yaml")
+	if err := os.WriteFile(configPath, []byte(config.String()), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	return configPath, contentPaths
+}
+
+// corruptFile overwrites the first bytes of path so its first piece no longer matches.
+func corruptFile(t *testing.T, path string) {
+	t.Helper()
+
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("Failed to open file for corruption: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteAt([]byte("corrupted"), 0); err != nil {
+		t.Fatalf("Failed to corrupt file: %v", err)
+	}
+}
+
+func TestProcessVerifyBatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath, contentPaths := createVerifyBatchFixture(t, tmpDir, 3)
+	corruptFile(t, contentPaths[1])
+
+	results, err := ProcessVerifyBatch(configPath, VerifyBatchOptions{Quiet: true, Concurrency: 2})
+	if err != nil {
+		t.Fatalf("ProcessVerifyBatch failed: %v", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+
+	failed := 0
+	for i, result := range results {
+		if result.Error != nil {
+			t.Errorf("Job %d returned error: %v", i, result.Error)
+		}
+		if result.Failed() {
+			failed++
+			if result.BadPieces == 0 {
+				t.Errorf("Expected failed job %d to have bad pieces", i)
+			}
+			if result.ContentPath != contentPaths[1] {
+				t.Errorf("Expected job for %s to fail, got %s", contentPaths[1], result.ContentPath)
+			}
+		}
+	}
+	if failed != 1 {
+		t.Errorf("Expected exactly 1 failed job, got %d", failed)
+	}
+
+	if err := VerifyBatchError(results); err == nil || !strings.Contains(err.Error(), "1 of 3") {
+		t.Errorf("Expected VerifyBatchError to report 1 of 3 failed jobs, got: %v", err)
+	}
+
+	var buf bytes.Buffer
+	display := NewDisplay(NewFormatter(false))
+	display.output = &buf
+	display.ShowVerifyBatchResults(results, time.Second)
+
+	output := strings.Join(strings.Fields(stripAnsiCodes(buf.String())), " ")
+	for _, want := range []string{"Total jobs: 3", "Failed: 1", "Good pieces: 11", "Bad pieces: 1"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in summary, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestProcessVerifyBatchFailFast(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath, contentPaths := createVerifyBatchFixture(t, tmpDir, 3)
+	corruptFile(t, contentPaths[0])
+
+	results, err := ProcessVerifyBatch(configPath, VerifyBatchOptions{Quiet: true, FailFast: true})
+	if err != nil {
+		t.Fatalf("ProcessVerifyBatch failed: %v", err)
+	}
+
+	if !results[0].Failed() {
+		t.Error("Expected job 0 to fail")
+	}
+	for i, result := range results[1:] {
+		if !result.Skipped {
+			t.Errorf("Expected job %d to be skipped after the first failure", i+1)
+		}
+	}
+}
+
+func TestProcessVerifyBatchValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{
+			name: "missing torrent_path",
+			config: `version: 1
+jobs:
+  - content_path: data`,
+		},
+		{
+			name: "missing content_path",
+			config: `version: 1
+jobs:
+  - torrent_path: test.torrent`,
+		},
+		{
+			name: "empty jobs",
+			config: `version: 1
+jobs: []`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "verify.yaml")
+			if err := os.WriteFile(configPath, []byte(tt.config), 0644); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			if _, err := ProcessVerifyBatch(configPath, VerifyBatchOptions{Quiet: true}); err == nil {
+				t.Error("Expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestProcessVerifyBatchFromURL(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath, _ := createVerifyBatchFixture(t, tmpDir, 2)
+	config, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/verify.yaml":
+			w.Write(config)
+		case "/index.html":
+			w.Write([]byte("<html><body>not a config</body></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	results, err := ProcessVerifyBatch(server.URL+"/verify.yaml", VerifyBatchOptions{Quiet: true})
+	if err != nil {
+		t.Fatalf("ProcessVerifyBatch failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	for i, result := range results {
+		if result.Failed() {
+			t.Errorf("Expected job %d to pass, got error %v", i, result.Error)
+		}
+	}
+
+	if _, err := ProcessVerifyBatch(server.URL+"/index.html", VerifyBatchOptions{Quiet: true}); err == nil {
+		t.Error("Expected error for a non-YAML response")
+	}
+	if _, err := ProcessVerifyBatch(server.URL+"/missing.yaml", VerifyBatchOptions{Quiet: true}); err == nil {
+		t.Error("Expected error for a missing config")
+	}
+}
+
+func TestProcessBatchFromStdin(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "content.bin"), bytes.Repeat([]byte("x"), 1<<16), 0644); err != nil {
+		t.Fatalf("Failed to write content file: %v", err)
+	}
+	// relative job paths in a config read from stdin resolve against the working directory
+	t.Chdir(tmpDir)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	w.WriteString("version: 1\njobs:\n  - path: content.bin\n    output: content.torrent\n")
+	w.Close()
+	origStdin := os.Stdin
+	os.Stdin = r
+	defer func() {
+		os.Stdin = origStdin
+		r.Close()
+	}()
+
+	results, err := ProcessBatch("-", false, true, false, false, "test")
+	if err != nil {
+		t.Fatalf("ProcessBatch failed: %v", err)
+	}
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("Expected 1 successful job, got %+v", results)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "content.torrent")); err != nil {
+		t.Errorf("Expected output in the working directory: %v", err)
+	}
+}
+
+func TestProcessBatchReportsContentWarnings(t *testing.T) {
+	tmpDir := t.TempDir()
+	stubDir := writeStubRelease(t)
+
+	cleanPath := filepath.Join(tmpDir, "clean.bin")
+	if err := os.WriteFile(cleanPath, []byte("test content"), 0644); err != nil {
+		t.Fatalf("Failed to write content file: %v", err)
+	}
+
+	configPath := filepath.Join(tmpDir, "batch.yaml")
+	configContent := []byte(fmt.Sprintf(`version: 1
+jobs:
+  - output: %s
+    path: %s
+  - output: %s
+    path: %s
+`,
+		filepath.Join(tmpDir, "stub.torrent"), stubDir,
+		filepath.Join(tmpDir, "clean.torrent"), cleanPath))
+	if err := os.WriteFile(configPath, configContent, 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	results, err := ProcessBatch(configPath, false, true, false, false, "test-version")
+	if err != nil {
+		t.Fatalf("ProcessBatch failed: %v", err)
+	}
+	for _, result := range results {
+		if !result.Success {
+			t.Fatalf("Expected job %s to succeed, got %v", result.Job.Path, result.Error)
+		}
+	}
+
+	if len(results[0].Info.Warnings) != 2 {
+		t.Errorf("Expected 2 warnings for the stub release, got %q", results[0].Info.Warnings)
+	}
+	if len(results[1].Info.Warnings) != 0 {
+		t.Errorf("Expected no warnings for the clean job, got %q", results[1].Info.Warnings)
+	}
+
+	var buf bytes.Buffer
+	display := NewDisplay(NewFormatter(false))
+	display.output = &buf
+	display.ShowBatchResults(results, time.Second)
+	output := stripAnsiCodes(buf.String())
+	if !strings.Contains(output, "Jobs with warnings:\n  "+stubDir+":\n") || !strings.Contains(output, "episode.mkv is empty") {
+		t.Errorf("Expected the warnings to be listed under the stub job, got:\n%s", output)
+	}
+}
+
+func TestProcessBatchRetries(t *testing.T) {
+	tmpDir := t.TempDir()
+	flakyPath := filepath.Join(tmpDir, "flaky.bin")
+	brokenPath := filepath.Join(tmpDir, "broken.bin")
+	for _, path := range []string{flakyPath, brokenPath} {
+		if err := os.WriteFile(path, []byte("batch retry content"), 0644); err != nil {
+			t.Fatalf("failed to write content: %v", err)
+		}
+	}
+
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	var delays []time.Duration
+	origCreate, origSleep := createTorrent, sleep
+	createTorrent = func(opts CreateOptions) (*Torrent, error) {
+		mu.Lock()
+		calls[opts.Path]++
+		n := calls[opts.Path]
+		mu.Unlock()
+
+		switch {
+		case opts.Path == brokenPath:
+			return nil, retryableErr{retryable: false}
+		case n <= 2:
+			return nil, fmt.Errorf("failed to read file: %w", &os.PathError{Op: "read", Path: opts.Path, Err: errors.New("input/output error")})
+		}
+		return origCreate(opts)
+	}
+	sleep = func(_ context.Context, d time.Duration) error {
+		mu.Lock()
+		delays = append(delays, d)
+		mu.Unlock()
+		return nil
+	}
+	defer func() { createTorrent, sleep = origCreate, origSleep }()
+
+	configPath := filepath.Join(tmpDir, "batch.yaml")
+	config := fmt.Sprintf(`version: 1
+jobs:
+  - output: %s
+    path: %s
+    max_retries: 3
+    retry_delay_seconds: 5
+  - output: %s
+    path: %s
+    max_retries: 3
+    retry_delay_seconds: 5
+`, filepath.Join(tmpDir, "flaky.torrent"), flakyPath, filepath.Join(tmpDir, "broken.torrent"), brokenPath)
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	results, err := ProcessBatch(configPath, false, true, false, false, "test-version")
+	if err != nil {
+		t.Fatalf("ProcessBatch failed: %v", err)
+	}
+
+	flaky := results[0]
+	if !flaky.Success || flaky.Attempts != 3 {
+		t.Errorf("flaky job: Success = %v, Attempts = %d, want true, 3 (error: %v)", flaky.Success, flaky.Attempts, flaky.Error)
+	}
+	if want := []time.Duration{5 * time.Second, 10 * time.Second}; !slices.Equal(delays, want) {
+		t.Errorf("delays = %v, want %v", delays, want)
+	}
+
+	// permanent errors are not retried
+	broken := results[1]
+	if broken.Success || broken.Attempts != 1 {
+		t.Errorf("broken job: Success = %v, Attempts = %d, want false, 1", broken.Success, broken.Attempts)
+	}
+}
+
+func TestProcessBatchFailFastOnAnyJob(t *testing.T) {
+	tmpDir := t.TempDir()
+	var config strings.Builder
+	config.WriteString("version: 1\njobs:\n")
+	var paths []string
+	for i := range 6 {
+		path := filepath.Join(tmpDir, fmt.Sprintf("job%d.bin", i))
+		if err := os.WriteFile(path, []byte("fail fast content"), 0644); err != nil {
+			t.Fatalf("failed to write content: %v", err)
+		}
+		paths = append(paths, path)
+		fmt.Fprintf(&config, "  - output: %s\n    path: %s\n", filepath.Join(tmpDir, fmt.Sprintf("job%d.torrent", i)), path)
+	}
+	configPath := filepath.Join(tmpDir, "batch.yaml")
+	if err := os.WriteFile(configPath, []byte(config.String()), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	// the first job fails and the others wait until they are cancelled,
+	// so none of them can finish before the batch stops
+	origCreate := createTorrent
+	createTorrent = func(opts CreateOptions) (*Torrent, error) {
+		if !opts.FailFast {
+			t.Errorf("expected FailFast to be passed to job %s", opts.Path)
+		}
+		if opts.Path == paths[0] {
+			return nil, errors.New("broken symlink")
+		}
+		<-opts.Context.Done()
+		return nil, opts.Context.Err()
+	}
+	defer func() { createTorrent = origCreate }()
+
+	results, err := ProcessBatchWithOptions(configPath, BatchOptions{Quiet: true, FailFast: true, FailFastOnAnyJob: true})
+	if err != nil {
+		t.Fatalf("ProcessBatchWithOptions failed: %v", err)
+	}
+
+	if results[0].Success || results[0].Skipped || results[0].Error == nil {
+		t.Errorf("first job: Success = %v, Skipped = %v, Error = %v, want a failure", results[0].Success, results[0].Skipped, results[0].Error)
+	}
+	for i, result := range results[1:] {
+		if !result.Skipped || result.Success || result.Error != nil {
+			t.Errorf("job %d: Success = %v, Skipped = %v, Error = %v, want it skipped", i+1, result.Success, result.Skipped, result.Error)
+		}
+	}
+
+	err = BatchError(results)
+	if err == nil || err.Error() != "1 of 6 batch jobs failed, 5 skipped" {
+		t.Errorf("BatchError() = %v", err)
+	}
+}
+
+func TestProcessBatch_TrackerSets(t *testing.T) {
+	tmpDir := t.TempDir()
+	contentPath := filepath.Join(tmpDir, "content.bin")
+	if err := os.WriteFile(contentPath, bytes.Repeat([]byte("c"), 3<<16), 0644); err != nil {
+		t.Fatalf("Failed to write content: %v", err)
+	}
+	outDir := filepath.Join(tmpDir, "out")
+
+	writeConfig := func(job string) string {
+		configPath := filepath.Join(t.TempDir(), "batch.yaml")
+		config := fmt.Sprintf("version: 1\njobs:\n  - path: %s\n    output_dir: %s\n    piece_length: 16\n    private: true\n%s", contentPath, outDir, job)
+		if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+		return configPath
+	}
+
+	opens := countContentOpens(t)
+	if _, err := ProcessBatch(writeConfig("    trackers:\n      - https://single.example/announce\n"), false, true, false, false, "test-version"); err != nil {
+		t.Fatalf("ProcessBatch failed: %v", err)
+	}
+	onePass := opens.Load()
+
+	opens.Store(0)
+	results, err := ProcessBatch(writeConfig(`    source: A
+    tracker_sets:
+      - trackers:
+          - https://tracker-a.example/announce
+      - trackers:
+          - https://tracker-b.example/announce
+        source: B
+        entropy: true
+`), false, true, false, false, "test-version")
+	if err != nil {
+		t.Fatalf("ProcessBatch failed: %v", err)
+	}
+	if opens.Load() != onePass {
+		t.Errorf("content files opened %d times, want %d for a single hashing pass", opens.Load(), onePass)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected a result for each tracker set, got %d", len(results))
+	}
+
+	for i, tracker := range []string{"https://tracker-a.example/announce", "https://tracker-b.example/announce"} {
+		if !results[i].Success {
+			t.Fatalf("Tracker set %d failed: %v", i, results[i].Error)
+		}
+		want := filepath.Join(outDir, preset.GetDomainPrefix(tracker)+"_content.bin.torrent")
+		if results[i].Info.Path != want {
+			t.Errorf("Tracker set %d written to %q, want %q", i, results[i].Info.Path, want)
+		}
+		mi, err := LoadFromFile(want)
+		if err != nil {
+			t.Fatalf("Failed to load torrent: %v", err)
+		}
+		info, err := mi.UnmarshalInfo()
+		if err != nil {
+			t.Fatalf("Failed to unmarshal info: %v", err)
+		}
+		if mi.Announce != tracker || info.Source != []string{"A", "B"}[i] {
+			t.Errorf("Tracker set %d has announce %q and source %q", i, mi.Announce, info.Source)
+		}
+	}
+	if results[0].Info.InfoHash == results[1].Info.InfoHash {
+		t.Error("Expected the tracker sets to have different info hashes")
+	}
+}
+
+func TestValidateJob_TrackerSets(t *testing.T) {
+	tmpDir := t.TempDir()
+	sets := []BatchTrackerSet{{Trackers: []string{"https://a.example/announce"}}, {PieceLength: 30}}
+
+	err := validateJob(BatchJob{Path: tmpDir, Output: "out.torrent", TrackerSets: sets[:1]})
+	if err == nil || !strings.Contains(err.Error(), "output_dir") {
+		t.Errorf("Expected an output_dir error, got: %v", err)
+	}
+	err = validateJob(BatchJob{Path: tmpDir, OutputDir: tmpDir, TrackerSets: sets})
+	if err == nil || !strings.Contains(err.Error(), "tracker set 2") {
+		t.Errorf("Expected an error for tracker set 2, got: %v", err)
+	}
+}
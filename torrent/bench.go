@@ -0,0 +1,181 @@
+package torrent
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"time"
+)
+
+// DefaultBenchSize is the size of the test file Bench hashes when
+// BenchOptions.Size is 0.
+const DefaultBenchSize int64 = 1 << 30
+
+// DefaultBenchWorkers are the worker counts Bench measures when
+// BenchOptions.Workers is empty, 0 standing for the automatic choice.
+var DefaultBenchWorkers = []int{1, 2, 4, 8, 0}
+
+// BenchOptions configures Bench.
+type BenchOptions struct {
+	Dir            string // directory the test file is written to, os.TempDir() when empty
+	Size           int64  // size of the test file, DefaultBenchSize when 0
+	ContentPath    string // existing file or directory to hash instead of a test file; Dir and Size are ignored
+	PieceLengthExp uint   // piece length as 2^n bytes, 2^20 when 0
+	Workers        []int  // worker counts to measure, DefaultBenchWorkers when empty
+}
+
+// BenchRun is the measurement of one worker count.
+type BenchRun struct {
+	Workers        int // as requested, 0 for automatic
+	UsedWorkers    int // the count hashing ran with, resolved for automatic
+	Duration       time.Duration
+	BytesPerSecond float64
+}
+
+// BenchResult holds the measurements of one directory, or of the content
+// at BenchOptions.ContentPath.
+type BenchResult struct {
+	Dir  string
+	Size int64
+	Runs []BenchRun
+	Best int // index of the fastest run
+}
+
+// Bench writes a test file to opts.Dir and hashes it once per worker count,
+// measuring the throughput of each. The file is written with data rather than
+// left sparse, so the reads reach the storage instead of returning zeros;
+// when it fits in memory they may still be served from the page cache. The
+// file is removed before Bench returns.
+//
+// With opts.ContentPath set, the files at that path are hashed in place
+// instead, and nothing is written. Only the first run may read them from the
+// storage; later runs are likely served from the page cache.
+func Bench(opts BenchOptions) (*BenchResult, error) {
+	pieceLenExp := opts.PieceLengthExp
+	if pieceLenExp == 0 {
+		pieceLenExp = 20
+	}
+	if pieceLenExp < 14 || pieceLenExp > 27 {
+		return nil, fmt.Errorf("piece length exponent must be between 14 and 27, got %d", pieceLenExp)
+	}
+	workers := opts.Workers
+	if len(workers) == 0 {
+		workers = DefaultBenchWorkers
+	}
+
+	var (
+		dir   string
+		size  int64
+		files []fileEntry
+	)
+	if opts.ContentPath != "" {
+		var err error
+		if files, size, err = benchContentFiles(opts.ContentPath); err != nil {
+			return nil, err
+		}
+		dir = opts.ContentPath
+	} else {
+		dir = opts.Dir
+		if dir == "" {
+			dir = os.TempDir()
+		}
+		size = opts.Size
+		if size == 0 {
+			size = DefaultBenchSize
+		}
+		if size < 0 {
+			return nil, fmt.Errorf("invalid benchmark size %d", size)
+		}
+
+		path, err := writeBenchFile(dir, size)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(path)
+		files = []fileEntry{{path: path, length: size}}
+	}
+
+	pieceLen := int64(1) << pieceLenExp
+	numPieces := int((size + pieceLen - 1) / pieceLen)
+
+	result := &BenchResult{Dir: dir, Size: size}
+	for _, n := range workers {
+		hasher := NewPieceHasher(files, pieceLen, numPieces, &callbackDisplayer{}, false)
+		hasher.skipSeasonCheck = true
+		used := n
+		if used <= 0 {
+			_, used = hasher.optimizeForWorkload()
+		}
+		used = max(min(used, numPieces), 1)
+
+		start := time.Now()
+		if err := hasher.hashPieces(used); err != nil {
+			return nil, fmt.Errorf("failed to hash %s with %d workers: %w", dir, used, err)
+		}
+		duration := time.Since(start)
+
+		run := BenchRun{Workers: n, UsedWorkers: used, Duration: duration}
+		if duration > 0 {
+			run.BytesPerSecond = float64(size) / duration.Seconds()
+		}
+		result.Runs = append(result.Runs, run)
+		if run.BytesPerSecond > result.Runs[result.Best].BytesPerSecond {
+			result.Best = len(result.Runs) - 1
+		}
+	}
+	return result, nil
+}
+
+// writeBenchFile writes size bytes of pseudo-random data to a new temporary
+// file in dir and returns its path.
+func writeBenchFile(dir string, size int64) (string, error) {
+	f, err := os.CreateTemp(dir, "mkbrr-bench-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create benchmark file: %w", err)
+	}
+
+	buf := make([]byte, 1<<20)
+	rng := rand.NewChaCha8([32]byte{})
+	for written := int64(0); written < size; {
+		chunk := buf[:min(int64(len(buf)), size-written)]
+		_, _ = rng.Read(chunk)
+		if _, err := f.Write(chunk); err != nil {
+			f.Close()
+			os.Remove(f.Name())
+			return "", fmt.Errorf("failed to write benchmark file: %w", err)
+		}
+		written += int64(len(chunk))
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write benchmark file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// benchContentFiles returns the regular files at path, in walk order and laid
+// out back to back as in a torrent, and their total size.
+func benchContentFiles(path string) ([]fileEntry, int64, error) {
+	var (
+		files  []fileEntry
+		offset int64
+	)
+	err := walkLong(path, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		files = append(files, fileEntry{path: filePath, length: info.Size(), offset: offset})
+		offset += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not read content %q: %w", path, err)
+	}
+	if offset == 0 {
+		return nil, 0, fmt.Errorf("no content to benchmark in %q", path)
+	}
+	return files, offset, nil
+}
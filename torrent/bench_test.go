@@ -0,0 +1,94 @@
+package torrent
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBench(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping benchmark run in short mode")
+	}
+	dir := t.TempDir()
+
+	result, err := Bench(BenchOptions{Dir: dir, Size: 100 << 20})
+	if err != nil {
+		t.Fatalf("Bench failed: %v", err)
+	}
+	if len(result.Runs) != len(DefaultBenchWorkers) {
+		t.Fatalf("got %d runs, want %d", len(result.Runs), len(DefaultBenchWorkers))
+	}
+	for _, run := range result.Runs {
+		if run.UsedWorkers < 1 {
+			t.Errorf("run with %d workers used %d", run.Workers, run.UsedWorkers)
+		}
+		if run.BytesPerSecond <= 0 {
+			t.Errorf("run with %d workers reported throughput %f", run.Workers, run.BytesPerSecond)
+		}
+	}
+	best := result.Runs[result.Best]
+	for _, run := range result.Runs {
+		if run.BytesPerSecond > best.BytesPerSecond {
+			t.Errorf("best run %+v is slower than %+v", best, run)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("benchmark left %d files behind", len(entries))
+	}
+}
+
+func TestBench_InvalidOptions(t *testing.T) {
+	if _, err := Bench(BenchOptions{Dir: t.TempDir(), Size: -1}); err == nil {
+		t.Error("expected an error for a negative size")
+	}
+	if _, err := Bench(BenchOptions{Dir: t.TempDir(), Size: 1 << 20, PieceLengthExp: 30}); err == nil {
+		t.Error("expected an error for an out of range piece length")
+	}
+}
+
+func TestBench_ContentPath(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]int{"a.bin": 3 << 16, "sub/b.bin": 1<<16 + 100, "empty": 0}
+	for name, size := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, bytes.Repeat([]byte("x"), size), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	result, err := Bench(BenchOptions{ContentPath: dir, PieceLengthExp: 16, Workers: []int{1, 2}})
+	if err != nil {
+		t.Fatalf("Bench failed: %v", err)
+	}
+	if result.Dir != dir {
+		t.Errorf("Dir = %q, want %q", result.Dir, dir)
+	}
+	if want := int64(4<<16 + 100); result.Size != want {
+		t.Errorf("Size = %d, want %d", result.Size, want)
+	}
+	if len(result.Runs) != 2 {
+		t.Fatalf("got %d runs, want 2", len(result.Runs))
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read dir: %v", err)
+	}
+	if len(entries) != 3 { // a.bin, empty and sub
+		t.Errorf("benchmark changed the content directory: %d entries", len(entries))
+	}
+
+	if _, err := Bench(BenchOptions{ContentPath: filepath.Join(dir, "empty")}); err == nil {
+		t.Error("expected an error for empty content")
+	}
+}
@@ -0,0 +1,521 @@
+package torrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/autobrr/mkbrr/internal/preset"
+)
+
+// TorrentBuilder assembles CreateOptions through method chaining and checks
+// that the combination is valid before creating the torrent:
+//
+//	t, err := torrent.NewTorrentBuilder("/data/Show.S01").
+//		WithTracker("https://tracker.example/announce").
+//		WithSource("EXAMPLE").
+//		WithPrivate(true).
+//		Build()
+//
+// Setters for single values overwrite earlier calls, setters for lists append.
+type TorrentBuilder struct {
+	opts CreateOptions
+}
+
+// NewTorrentBuilder returns a builder for a torrent of the file or directory at
+// contentPath. Torrents are private by default, like on the command line.
+func NewTorrentBuilder(contentPath string) *TorrentBuilder {
+	return &TorrentBuilder{
+		opts: CreateOptions{
+			Path:      contentPath,
+			IsPrivate: true,
+		},
+	}
+}
+
+// WithName sets the torrent name, the base name of the content path by default.
+func (b *TorrentBuilder) WithName(name string) *TorrentBuilder {
+	b.opts.Name = name
+	return b
+}
+
+// WithTracker adds an announce URL; the first one is used as announce and
+// tracker-specific rules.
+func (b *TorrentBuilder) WithTracker(url string) *TorrentBuilder {
+	b.opts.TrackerURLs = append(b.opts.TrackerURLs, url)
+	return b
+}
+
+// WithRandomizeAnnounceList shuffles the tracker order, e.g. to spread load across trackers.
+func (b *TorrentBuilder) WithRandomizeAnnounceList(randomize bool) *TorrentBuilder {
+	b.opts.RandomizeAnnounceList = randomize
+	return b
+}
+
+// WithWebSeed adds a web seed URL.
+func (b *TorrentBuilder) WithWebSeed(url string) *TorrentBuilder {
+	b.opts.WebSeeds = append(b.opts.WebSeeds, url)
+	return b
+}
+
+// WithHTTPSeed adds a BEP 17 http seed URL, for clients that do not support BEP 19 web seeds.
+func (b *TorrentBuilder) WithHTTPSeed(url string) *TorrentBuilder {
+	b.opts.HTTPSeeds = append(b.opts.HTTPSeeds, url)
+	return b
+}
+
+// WithComment sets the comment.
+func (b *TorrentBuilder) WithComment(comment string) *TorrentBuilder {
+	b.opts.Comment = comment
+	return b
+}
+
+// WithSource sets the source tag.
+func (b *TorrentBuilder) WithSource(src string) *TorrentBuilder {
+	b.opts.Source = src
+	return b
+}
+
+// WithPrivate sets the private flag.
+func (b *TorrentBuilder) WithPrivate(p bool) *TorrentBuilder {
+	b.opts.IsPrivate = p
+	return b
+}
+
+// WithPieceLength sets the piece length to 2^exp bytes instead of choosing it automatically.
+func (b *TorrentBuilder) WithPieceLength(exp uint) *TorrentBuilder {
+	b.opts.PieceLengthExp = &exp
+	return b
+}
+
+// WithMaxPieceLength limits the automatically chosen piece length to 2^exp bytes.
+func (b *TorrentBuilder) WithMaxPieceLength(exp uint) *TorrentBuilder {
+	b.opts.MaxPieceLength = &exp
+	return b
+}
+
+// WithTargetPieceCount chooses the piece length that gets closest to count pieces.
+func (b *TorrentBuilder) WithTargetPieceCount(count uint) *TorrentBuilder {
+	b.opts.TargetPieceCount = &count
+	return b
+}
+
+// WithExclude adds a glob pattern for files to leave out.
+func (b *TorrentBuilder) WithExclude(pattern string) *TorrentBuilder {
+	b.opts.ExcludePatterns = append(b.opts.ExcludePatterns, pattern)
+	return b
+}
+
+// WithExcludeDir leaves out every directory with the given name, including
+// everything below it. Names are matched case-insensitively.
+func (b *TorrentBuilder) WithExcludeDir(name string) *TorrentBuilder {
+	b.opts.ExcludeDirs = append(b.opts.ExcludeDirs, name)
+	return b
+}
+
+// WithInclude adds a glob pattern; when any are set only matching files are included.
+func (b *TorrentBuilder) WithInclude(pattern string) *TorrentBuilder {
+	b.opts.IncludePatterns = append(b.opts.IncludePatterns, pattern)
+	return b
+}
+
+// WithCaseSensitivePatterns matches the include and exclude patterns without
+// ignoring case.
+func (b *TorrentBuilder) WithCaseSensitivePatterns(caseSensitive bool) *TorrentBuilder {
+	b.opts.CaseSensitivePatterns = caseSensitive
+	return b
+}
+
+// WithMaxDepth walks at most depth directory levels below the content path.
+func (b *TorrentBuilder) WithMaxDepth(depth int) *TorrentBuilder {
+	b.opts.MaxDepth = &depth
+	return b
+}
+
+// WithSkipHidden leaves hidden files and directories out of the torrent.
+func (b *TorrentBuilder) WithSkipHidden(skip bool) *TorrentBuilder {
+	b.opts.SkipHidden = skip
+	return b
+}
+
+// WithFileOrder sets the order of files in the torrent, one of the FileOrder* constants.
+func (b *TorrentBuilder) WithFileOrder(order string) *TorrentBuilder {
+	b.opts.FileOrder = order
+	return b
+}
+
+// WithWorkers sets the number of hashing goroutines, 0 picks a number automatically.
+func (b *TorrentBuilder) WithWorkers(n int) *TorrentBuilder {
+	b.opts.Workers = n
+	return b
+}
+
+// WithReadRetries retries failed file reads n times with backoff.
+func (b *TorrentBuilder) WithReadRetries(n int) *TorrentBuilder {
+	b.opts.ReadRetries = n
+	return b
+}
+
+// WithMaxReadRate limits the combined read rate while hashing, 0 disables throttling.
+func (b *TorrentBuilder) WithMaxReadRate(bytesPerSecond int64) *TorrentBuilder {
+	b.opts.MaxReadBytesPerSecond = bytesPerSecond
+	return b
+}
+
+// WithReuseFrom copies piece hashes of unchanged files from an existing torrent
+// and rehashes verifyCount randomly chosen reused pieces as a spot-check.
+func (b *TorrentBuilder) WithReuseFrom(torrentPath string, verifyCount int) *TorrentBuilder {
+	b.opts.ReuseFrom = torrentPath
+	b.opts.VerifyReused = verifyCount
+	return b
+}
+
+// WithSkipHashing writes placeholder piece hashes; the torrent cannot be seeded.
+func (b *TorrentBuilder) WithSkipHashing(skip bool) *TorrentBuilder {
+	b.opts.SkipHashing = skip
+	return b
+}
+
+// WithFAT32Check warns about files larger than FAT32 can store.
+func (b *TorrentBuilder) WithFAT32Check(check bool) *TorrentBuilder {
+	b.opts.FAT32Check = check
+	return b
+}
+
+// WithStrictFileChecks fails instead of warning about oversized and sparse files.
+func (b *TorrentBuilder) WithStrictFileChecks(strict bool) *TorrentBuilder {
+	b.opts.StrictFileChecks = strict
+	return b
+}
+
+// WithMinVideoSize reports video files smaller than size bytes as likely stubs;
+// 0 keeps the 1 MiB default and a negative size disables the check.
+func (b *TorrentBuilder) WithMinVideoSize(size int64) *TorrentBuilder {
+	b.opts.MinVideoSize = size
+	return b
+}
+
+// WithStrictContent fails instead of warning about empty and stub files.
+func (b *TorrentBuilder) WithStrictContent(strict bool) *TorrentBuilder {
+	b.opts.StrictContent = strict
+	return b
+}
+
+// WithSanitizeName normalizes the torrent name so it is valid on all platforms,
+// transliterating non-ASCII characters when ascii is set.
+func (b *TorrentBuilder) WithSanitizeName(ascii bool) *TorrentBuilder {
+	b.opts.SanitizeName = true
+	b.opts.ASCIIName = ascii
+	return b
+}
+
+// WithEntropy randomizes the info hash with an entropy field.
+func (b *TorrentBuilder) WithEntropy(entropy bool) *TorrentBuilder {
+	b.opts.Entropy = entropy
+	return b
+}
+
+// WithNoDate leaves out the creation date.
+func (b *TorrentBuilder) WithNoDate(noDate bool) *TorrentBuilder {
+	b.opts.NoDate = noDate
+	return b
+}
+
+// WithCreationDate writes a fixed creation date instead of the current time;
+// together with WithNoCreator this makes the output reproducible.
+func (b *TorrentBuilder) WithCreationDate(date time.Time) *TorrentBuilder {
+	b.opts.CreationDate = date
+	return b
+}
+
+// WithNoCreator leaves out the created by field.
+func (b *TorrentBuilder) WithNoCreator(noCreator bool) *TorrentBuilder {
+	b.opts.NoCreator = noCreator
+	return b
+}
+
+// WithCreator replaces the default created by field; WithNoCreator still
+// leaves it out.
+func (b *TorrentBuilder) WithCreator(creator string) *TorrentBuilder {
+	b.opts.Creator = creator
+	return b
+}
+
+// WithHashCache keeps piece hashes in a cache in dir, so pieces of unchanged
+// files are not hashed again the next time a torrent is created from them.
+func (b *TorrentBuilder) WithHashCache(dir string) *TorrentBuilder {
+	b.opts.HashCacheDir = dir
+	return b
+}
+
+// WithRootField adds a string key to the root dictionary of the torrent, such
+// as "publisher". It does not change the info hash; keys of standard fields
+// such as announce or info are rejected.
+func (b *TorrentBuilder) WithRootField(key, value string) *TorrentBuilder {
+	if b.opts.ExtraRootFields == nil {
+		b.opts.ExtraRootFields = make(map[string]string)
+	}
+	b.opts.ExtraRootFields[key] = value
+	return b
+}
+
+// WithFailFast fails on paths that cannot be read, such as broken symlinks,
+// instead of skipping them with a warning.
+func (b *TorrentBuilder) WithFailFast(failFast bool) *TorrentBuilder {
+	b.opts.FailFast = failFast
+	return b
+}
+
+// WithNoProgress prints hashing progress as periodic lines instead of a progress bar.
+func (b *TorrentBuilder) WithNoProgress(noProgress bool) *TorrentBuilder {
+	b.opts.NoProgress = noProgress
+	return b
+}
+
+// WithFollowDirSymlinks includes the contents of symlinked directories.
+func (b *TorrentBuilder) WithFollowDirSymlinks(follow bool) *TorrentBuilder {
+	b.opts.FollowDirSymlinks = follow
+	return b
+}
+
+// WithPreserveAttrs records the executable and hidden attributes of files.
+func (b *TorrentBuilder) WithPreserveAttrs(preserve bool) *TorrentBuilder {
+	b.opts.PreserveAttrs = preserve
+	return b
+}
+
+// WithPadToPieceBoundary starts every file on a piece boundary with BEP 47
+// padding files.
+func (b *TorrentBuilder) WithPadToPieceBoundary(pad bool) *TorrentBuilder {
+	b.opts.PadToPieceBoundary = pad
+	return b
+}
+
+// WithPresetName sets the preset name available to templates as {{.Preset}}.
+func (b *TorrentBuilder) WithPresetName(name string) *TorrentBuilder {
+	b.opts.PresetName = name
+	return b
+}
+
+// WithPreset applies the settings of preset p that map to a single option,
+// such as the comment, output dir or workers. changed reports whether a create
+// flag was given on the command line, e.g. pflag.FlagSet.Changed; those flags
+// keep their value, so an explicit flag wins over the preset, which wins over
+// the global config and the built-in defaults the builder already holds.
+func (b *TorrentBuilder) WithPreset(p *preset.Options, changed func(flag string) bool) *TorrentBuilder {
+	if p.Private != nil && !changed("private") {
+		b.WithPrivate(*p.Private)
+	}
+	if p.Comment != "" && !changed("comment") {
+		b.WithComment(p.Comment)
+	}
+	if p.OutputDir != "" && !changed("output-dir") {
+		b.WithOutputDir(p.OutputDir)
+	}
+	// --date also decides the creation date, so it overrides no_date as well
+	if p.NoDate != nil && !changed("no-date") && !changed("date") {
+		b.WithNoDate(*p.NoDate)
+	}
+	if p.NoCreator != nil && !changed("no-creator") {
+		b.WithNoCreator(*p.NoCreator)
+	}
+	if p.SkipPrefix != nil && !changed("skip-prefix") {
+		b.WithSkipPrefix(*p.SkipPrefix)
+	}
+	if p.Entropy != nil && !changed("entropy") {
+		b.WithEntropy(*p.Entropy)
+	}
+	if p.FailOnSeasonWarning != nil && !changed("fail-on-season-warning") {
+		b.WithFailOnSeasonPackWarning(*p.FailOnSeasonWarning)
+	}
+	if p.SkipHidden != nil && !changed("skip-hidden") {
+		b.WithSkipHidden(*p.SkipHidden)
+	}
+	if p.Workers != 0 && !changed("workers") {
+		b.WithWorkers(p.Workers)
+	}
+	if p.Creator != "" && !changed("creator") {
+		b.WithCreator(p.Creator)
+	}
+	return b
+}
+
+// WithVersion sets the mkbrr version written to the created by field.
+func (b *TorrentBuilder) WithVersion(version string) *TorrentBuilder {
+	b.opts.Version = version
+	return b
+}
+
+// WithFailOnSeasonPackWarning fails instead of warning about incomplete season packs.
+func (b *TorrentBuilder) WithFailOnSeasonPackWarning(fail bool) *TorrentBuilder {
+	b.opts.FailOnSeasonPackWarning = fail
+	return b
+}
+
+// WithSkipSeasonPackCheck skips the season pack analysis.
+func (b *TorrentBuilder) WithSkipSeasonPackCheck(skip bool) *TorrentBuilder {
+	b.opts.SkipSeasonPackCheck = skip
+	return b
+}
+
+// WithOutputPath sets the path Create writes the torrent to.
+func (b *TorrentBuilder) WithOutputPath(path string) *TorrentBuilder {
+	b.opts.OutputPath = path
+	return b
+}
+
+// WithOutputDir sets the directory Create writes the torrent to; it may contain
+// template variables and takes precedence over the output path.
+func (b *TorrentBuilder) WithOutputDir(dir string) *TorrentBuilder {
+	b.opts.OutputDir = dir
+	return b
+}
+
+// WithSkipPrefix leaves the tracker domain prefix out of the output filename.
+func (b *TorrentBuilder) WithSkipPrefix(skip bool) *TorrentBuilder {
+	b.opts.SkipPrefix = skip
+	return b
+}
+
+// WithVerbose enables verbose output.
+func (b *TorrentBuilder) WithVerbose(verbose bool) *TorrentBuilder {
+	b.opts.Verbose = verbose
+	return b
+}
+
+// WithQuiet suppresses progress and informational output.
+func (b *TorrentBuilder) WithQuiet(quiet bool) *TorrentBuilder {
+	b.opts.Quiet = quiet
+	return b
+}
+
+// WithInfoOnly shows torrent information without a progress bar.
+func (b *TorrentBuilder) WithInfoOnly(infoOnly bool) *TorrentBuilder {
+	b.opts.InfoOnly = infoOnly
+	return b
+}
+
+// WithShowTree prints the file tree of multi-file torrents after creation.
+func (b *TorrentBuilder) WithShowTree(show bool) *TorrentBuilder {
+	b.opts.ShowTree = show
+	return b
+}
+
+// WithProgressCallback reports hashing progress to callback instead of the terminal.
+func (b *TorrentBuilder) WithProgressCallback(callback ProgressCallback) *TorrentBuilder {
+	b.opts.ProgressCallback = callback
+	return b
+}
+
+// WithLogger sends diagnostic messages to l instead of the default logger.
+func (b *TorrentBuilder) WithLogger(l Logger) *TorrentBuilder {
+	b.opts.Logger = l
+	return b
+}
+
+// WithContext cancels the directory walk and hashing when ctx is done.
+func (b *TorrentBuilder) WithContext(ctx context.Context) *TorrentBuilder {
+	b.opts.Context = ctx
+	return b
+}
+
+// Options validates the builder and returns the assembled CreateOptions.
+func (b *TorrentBuilder) Options() (CreateOptions, error) {
+	if err := b.validate(); err != nil {
+		return CreateOptions{}, err
+	}
+	return b.opts, nil
+}
+
+// Build validates the builder and creates the torrent in memory.
+func (b *TorrentBuilder) Build() (*Torrent, error) {
+	opts, err := b.Options()
+	if err != nil {
+		return nil, err
+	}
+	return CreateTorrent(opts)
+}
+
+// Estimate validates the builder and estimates the torrent without hashing like EstimateTorrent.
+func (b *TorrentBuilder) Estimate() (*Estimate, error) {
+	opts, err := b.Options()
+	if err != nil {
+		return nil, err
+	}
+	return EstimateTorrent(opts)
+}
+
+// Create validates the builder, creates the torrent and writes it to disk like Create.
+func (b *TorrentBuilder) Create() (*TorrentInfo, error) {
+	opts, err := b.Options()
+	if err != nil {
+		return nil, err
+	}
+	return Create(opts)
+}
+
+// validate reports all invalid option combinations at once.
+func (b *TorrentBuilder) validate() error {
+	opts := b.opts
+	var errs []error
+
+	if opts.Path == "" {
+		errs = append(errs, fmt.Errorf("content path is required"))
+	}
+	for _, url := range opts.TrackerURLs {
+		if url == "" {
+			errs = append(errs, fmt.Errorf("tracker URL must not be empty"))
+			break
+		}
+	}
+	for _, url := range opts.WebSeeds {
+		if url == "" {
+			errs = append(errs, fmt.Errorf("web seed URL must not be empty"))
+			break
+		}
+	}
+	for _, url := range opts.HTTPSeeds {
+		if url == "" {
+			errs = append(errs, fmt.Errorf("http seed URL must not be empty"))
+			break
+		}
+	}
+
+	if err := validateRootFields(opts.ExtraRootFields); err != nil {
+		errs = append(errs, err)
+	}
+
+	if opts.NoDate && !opts.CreationDate.IsZero() {
+		errs = append(errs, fmt.Errorf("cannot set both a creation date and no date"))
+	}
+
+	if opts.PieceLengthExp != nil && opts.MaxPieceLength != nil {
+		errs = append(errs, fmt.Errorf("cannot set both piece length and max piece length; max piece length only limits the automatic choice"))
+	}
+	if opts.PieceLengthExp != nil && opts.TargetPieceCount != nil {
+		errs = append(errs, fmt.Errorf("cannot set both piece length and target piece count; use one or the other"))
+	}
+	if opts.TargetPieceCount != nil && *opts.TargetPieceCount == 0 {
+		errs = append(errs, fmt.Errorf("target piece count must be greater than zero"))
+	}
+
+	if opts.Workers < 0 {
+		errs = append(errs, fmt.Errorf("workers must not be negative, got %d", opts.Workers))
+	}
+	if opts.ReadRetries < 0 {
+		errs = append(errs, fmt.Errorf("read retries must not be negative, got %d", opts.ReadRetries))
+	}
+	if opts.MaxReadBytesPerSecond < 0 {
+		errs = append(errs, fmt.Errorf("max read rate must not be negative, got %d", opts.MaxReadBytesPerSecond))
+	}
+	if opts.VerifyReused > 0 && opts.ReuseFrom == "" {
+		errs = append(errs, fmt.Errorf("verifying reused pieces requires a torrent to reuse from"))
+	}
+
+	if err := validateFileOrder(opts.FileOrder); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
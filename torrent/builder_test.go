@@ -0,0 +1,262 @@
+package torrent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeBuilderContent(t *testing.T) string {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "Release")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create content dir: %v", err)
+	}
+	files := map[string]int{
+		"release.mkv": 3 << 16,
+		"sample.mkv":  1 << 16,
+		"release.nfo": 100,
+	}
+	for name, size := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestTorrentBuilder_Build(t *testing.T) {
+	contentDir := writeBuilderContent(t)
+
+	mi, err := NewTorrentBuilder(contentDir).
+		WithName("Custom.Name").
+		WithTracker("https://tracker-a.example/announce").
+		WithTracker("https://tracker-b.example/announce").
+		WithWebSeed("https://seed.example/files/").
+		WithComment("built with the builder").
+		WithSource("EXAMPLE").
+		WithPrivate(false).
+		WithPieceLength(16).
+		WithExclude("*.nfo").
+		WithWorkers(2).
+		WithNoDate(true).
+		WithNoCreator(true).
+		WithQuiet(true).
+		WithContext(context.Background()).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	info := mi.GetInfo()
+	if info.Name != "Custom.Name" {
+		t.Errorf("Name = %q, want %q", info.Name, "Custom.Name")
+	}
+	if info.Source != "EXAMPLE" {
+		t.Errorf("Source = %q, want %q", info.Source, "EXAMPLE")
+	}
+	if info.Private == nil || *info.Private {
+		t.Errorf("expected the private flag to be false, got %v", info.Private)
+	}
+	if info.PieceLength != 1<<16 {
+		t.Errorf("PieceLength = %d, want %d", info.PieceLength, 1<<16)
+	}
+	if len(info.Files) != 2 {
+		t.Errorf("expected 2 files after excluding *.nfo, got %d", len(info.Files))
+	}
+	for _, f := range info.Files {
+		if strings.HasSuffix(f.DisplayPath(info), ".nfo") {
+			t.Errorf("excluded file %q is in the torrent", f.DisplayPath(info))
+		}
+	}
+
+	if mi.Announce != "https://tracker-a.example/announce" {
+		t.Errorf("Announce = %q", mi.Announce)
+	}
+	wantAnnounceList := [][]string{{"https://tracker-a.example/announce"}, {"https://tracker-b.example/announce"}}
+	if len(mi.AnnounceList) != len(wantAnnounceList) {
+		t.Fatalf("AnnounceList = %v, want %v", mi.AnnounceList, wantAnnounceList)
+	}
+	for i := range wantAnnounceList {
+		if !slices.Equal(mi.AnnounceList[i], wantAnnounceList[i]) {
+			t.Errorf("AnnounceList[%d] = %v, want %v", i, mi.AnnounceList[i], wantAnnounceList[i])
+		}
+	}
+	if !slices.Equal([]string(mi.UrlList), []string{"https://seed.example/files/"}) {
+		t.Errorf("UrlList = %v", mi.UrlList)
+	}
+	if mi.Comment != "built with the builder" {
+		t.Errorf("Comment = %q", mi.Comment)
+	}
+	if mi.CreationDate != 0 {
+		t.Errorf("expected no creation date, got %d", mi.CreationDate)
+	}
+	if mi.CreatedBy != "" {
+		t.Errorf("expected no creator, got %q", mi.CreatedBy)
+	}
+}
+
+func TestTorrentBuilder_Create(t *testing.T) {
+	contentDir := writeBuilderContent(t)
+	outputDir := t.TempDir()
+
+	info, err := NewTorrentBuilder(contentDir).
+		WithTracker("https://tracker.example/announce").
+		WithOutputDir(outputDir).
+		WithVersion("test").
+		WithQuiet(true).
+		Create()
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	if want := filepath.Join(outputDir, "tracker_Release.torrent"); info.Path != want {
+		t.Errorf("Path = %q, want %q", info.Path, want)
+	}
+	mi, err := LoadFromFile(info.Path)
+	if err != nil {
+		t.Fatalf("failed to load written torrent: %v", err)
+	}
+	if mi.CreatedBy == "" || !strings.Contains(mi.CreatedBy, "mkbrr/test") {
+		t.Errorf("CreatedBy = %q", mi.CreatedBy)
+	}
+	if parsed, _ := mi.UnmarshalInfo(); parsed.Private == nil || !*parsed.Private {
+		t.Error("expected torrents to be private by default")
+	}
+}
+
+func TestTorrentBuilder_Creator(t *testing.T) {
+	contentDir := writeBuilderContent(t)
+
+	tests := []struct {
+		name      string
+		creator   string
+		noCreator bool
+		want      string
+	}{
+		{name: "default", want: "mkbrr/test (https://github.com/autobrr/mkbrr)"},
+		{name: "custom", creator: "qBittorrent v4.6.0", want: "qBittorrent v4.6.0"},
+		{name: "no creator wins", creator: "qBittorrent v4.6.0", noCreator: true, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mi, err := NewTorrentBuilder(contentDir).
+				WithVersion("test").
+				WithCreator(tt.creator).
+				WithNoCreator(tt.noCreator).
+				WithQuiet(true).
+				Build()
+			if err != nil {
+				t.Fatalf("Build failed: %v", err)
+			}
+			if mi.CreatedBy != tt.want {
+				t.Errorf("CreatedBy = %q, want %q", mi.CreatedBy, tt.want)
+			}
+		})
+	}
+}
+
+func TestTorrentBuilder_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *TorrentBuilder
+		wantErr []string
+	}{
+		{
+			name:    "missing path",
+			builder: NewTorrentBuilder(""),
+			wantErr: []string{"content path is required"},
+		},
+		{
+			name:    "piece length with max piece length",
+			builder: NewTorrentBuilder("content").WithPieceLength(20).WithMaxPieceLength(22),
+			wantErr: []string{"cannot set both piece length and max piece length"},
+		},
+		{
+			name:    "piece length with target piece count",
+			builder: NewTorrentBuilder("content").WithPieceLength(20).WithTargetPieceCount(1000),
+			wantErr: []string{"cannot set both piece length and target piece count"},
+		},
+		{
+			name:    "zero target piece count",
+			builder: NewTorrentBuilder("content").WithTargetPieceCount(0),
+			wantErr: []string{"target piece count must be greater than zero"},
+		},
+		{
+			name:    "creation date without date",
+			builder: NewTorrentBuilder("content").WithNoDate(true).WithCreationDate(time.Unix(1704207845, 0)),
+			wantErr: []string{"cannot set both a creation date and no date"},
+		},
+		{
+			name:    "empty tracker",
+			builder: NewTorrentBuilder("content").WithTracker(""),
+			wantErr: []string{"tracker URL must not be empty"},
+		},
+		{
+			name:    "invalid file order",
+			builder: NewTorrentBuilder("content").WithFileOrder("random"),
+			wantErr: []string{`invalid file order "random"`},
+		},
+		{
+			name:    "every problem is reported",
+			builder: NewTorrentBuilder("content").WithPieceLength(20).WithMaxPieceLength(22).WithWorkers(-1).WithReadRetries(-2),
+			wantErr: []string{"max piece length", "workers must not be negative", "read retries must not be negative"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+			if err == nil {
+				t.Fatal("expected a validation error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+
+	if _, err := NewTorrentBuilder("content").WithMaxPieceLength(22).WithTargetPieceCount(1000).Options(); err != nil {
+		t.Errorf("max piece length with target piece count should be valid, got %v", err)
+	}
+}
+
+func TestTorrentBuilder_ContextCanceled(t *testing.T) {
+	contentDir := writeBuilderContent(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewTorrentBuilder(contentDir).WithContext(ctx).WithQuiet(true).Build()
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestHashPieces_ContextCanceled(t *testing.T) {
+	const size = 4 << 16
+	path := filepath.Join(t.TempDir(), "content.bin")
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	const pieceLen = 1 << 16
+	files := []fileEntry{{path: path, length: size}}
+	hasher := NewPieceHasher(files, pieceLen, size/pieceLen, &mockDisplay{}, false)
+	hasher.ctx = ctx
+
+	if err := hasher.hashPieces(1); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package torrent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// ConvertOptions holds options for re-encoding a torrent with normalized metadata
+type ConvertOptions struct {
+	OutputPath    string // defaults to <input>.converted.torrent next to the input
+	StripMetadata bool   // clear the comment, creator and creation date
+	DryRun        bool   // report the changes without writing the torrent
+}
+
+// ConvertResult holds the outcome of ConvertTorrent
+type ConvertResult struct {
+	Path       string
+	OutputPath string // empty for a dry run
+	InfoHash   string
+	Changes    []string // what was normalized, empty when the torrent was already clean
+}
+
+// ConvertTorrent loads the torrent at path, normalizes the metadata outside the
+// info dictionary and writes it again. Strings are made valid UTF-8, with
+// invalid bytes replaced by U+FFFD, and NUL bytes are removed; comment line
+// endings become "\n"; empty and duplicate trackers, empty announce tiers and
+// duplicate web seeds are dropped. The info dictionary is kept byte for byte,
+// so the info hash never changes.
+func ConvertTorrent(path string, opts ConvertOptions) (*ConvertResult, error) {
+	loaded, err := LoadFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	mi := loaded.MetaInfo
+
+	result := &ConvertResult{
+		Path:     path,
+		InfoHash: mi.HashInfoBytes().String(),
+	}
+	changed := func(field, change string) {
+		result.Changes = append(result.Changes, field+": "+change)
+	}
+
+	if opts.StripMetadata {
+		if mi.Comment != "" {
+			mi.Comment = ""
+			changed("comment", "removed")
+		}
+		if mi.CreatedBy != "" {
+			mi.CreatedBy = ""
+			changed("created by", "removed")
+		}
+		if mi.CreationDate != 0 {
+			mi.CreationDate = 0
+			changed("creation date", "removed")
+		}
+	}
+
+	var change string
+	if mi.Comment, change = normalizeMetaString(mi.Comment, true); change != "" {
+		changed("comment", change)
+	}
+	if mi.CreatedBy, change = normalizeMetaString(mi.CreatedBy, false); change != "" {
+		changed("created by", change)
+	}
+	if mi.Encoding, change = normalizeMetaString(mi.Encoding, false); change != "" {
+		changed("encoding", change)
+	}
+	if mi.Announce, change = normalizeURL(mi.Announce); change != "" {
+		changed("announce", change)
+	}
+
+	var announceChanges []string
+	mi.AnnounceList, announceChanges = normalizeAnnounceList(mi.AnnounceList)
+	for _, change := range announceChanges {
+		changed("announce list", change)
+	}
+	if mi.Announce == "" && len(mi.AnnounceList) > 0 {
+		mi.Announce = mi.AnnounceList[0][0]
+		changed("announce", "set to the first tracker of the announce list")
+	}
+
+	var seedChanges []string
+	mi.UrlList, seedChanges = normalizeURLs(mi.UrlList)
+	for _, change := range seedChanges {
+		changed("web seeds", change)
+	}
+	loaded.HTTPSeeds, seedChanges = normalizeURLs(loaded.HTTPSeeds)
+	for _, change := range seedChanges {
+		changed("http seeds", change)
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	outPath := opts.OutputPath
+	if outPath == "" {
+		outPath = strings.TrimSuffix(path, filepath.Ext(path)) + ".converted.torrent"
+	}
+
+	f, err := os.Create(outPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not create output file: %w", err)
+	}
+	defer f.Close()
+
+	if err := loaded.Write(f); err != nil {
+		return nil, fmt.Errorf("could not write output file: %w", err)
+	}
+	result.OutputPath = outPath
+	return result, nil
+}
+
+// normalizeMetaString makes s valid UTF-8 without NUL bytes and, for multiline
+// text, with "\n" line endings. It returns the new string and a description of
+// the change, or "" when s was already normalized.
+func normalizeMetaString(s string, multiline bool) (string, string) {
+	var changes []string
+	normalized := strings.ToValidUTF8(s, "\uFFFD")
+	if normalized != s {
+		changes = append(changes, "replaced invalid UTF-8")
+	}
+	if strings.ContainsRune(normalized, 0) {
+		normalized = strings.ReplaceAll(normalized, "\x00", "")
+		changes = append(changes, "removed NUL bytes")
+	}
+	if multiline && strings.Contains(normalized, "\r") {
+		normalized = strings.ReplaceAll(normalized, "\r\n", "\n")
+		normalized = strings.ReplaceAll(normalized, "\r", "\n")
+		changes = append(changes, "normalized line endings")
+	}
+	return normalized, strings.Join(changes, ", ")
+}
+
+// normalizeURL is normalizeMetaString for a URL, which also loses surrounding whitespace.
+func normalizeURL(url string) (string, string) {
+	trimmed := strings.TrimSpace(url)
+	normalized, change := normalizeMetaString(trimmed, false)
+	if trimmed != url {
+		change = strings.TrimPrefix(change+", trimmed whitespace", ", ")
+	}
+	return normalized, change
+}
+
+// normalizeAnnounceList normalizes every tracker URL and drops empty URLs,
+// trackers already listed in an earlier tier and tiers left empty.
+func normalizeAnnounceList(list metainfo.AnnounceList) (metainfo.AnnounceList, []string) {
+	var changes []string
+	var normalized metainfo.AnnounceList
+	seen := make(map[string]bool)
+	var empty, duplicates, emptyTiers int
+
+	for _, tier := range list {
+		var urls []string
+		for _, url := range tier {
+			url, change := normalizeURL(url)
+			if change != "" {
+				changes = append(changes, fmt.Sprintf("%s in %q", change, url))
+			}
+			if url == "" {
+				empty++
+				continue
+			}
+			if seen[url] {
+				duplicates++
+				continue
+			}
+			seen[url] = true
+			urls = append(urls, url)
+		}
+		if len(urls) == 0 {
+			emptyTiers++
+			continue
+		}
+		normalized = append(normalized, urls)
+	}
+
+	if empty > 0 {
+		changes = append(changes, fmt.Sprintf("removed %d empty tracker(s)", empty))
+	}
+	if duplicates > 0 {
+		changes = append(changes, fmt.Sprintf("removed %d duplicate tracker(s)", duplicates))
+	}
+	if emptyTiers > 0 {
+		changes = append(changes, fmt.Sprintf("removed %d empty tier(s)", emptyTiers))
+	}
+	return normalized, changes
+}
+
+// normalizeURLs normalizes every URL and drops empty and duplicate ones.
+func normalizeURLs(urls []string) ([]string, []string) {
+	var changes []string
+	var normalized []string
+	seen := make(map[string]bool)
+	var removed int
+
+	for _, url := range urls {
+		url, change := normalizeURL(url)
+		if change != "" {
+			changes = append(changes, fmt.Sprintf("%s in %q", change, url))
+		}
+		if url == "" || seen[url] {
+			removed++
+			continue
+		}
+		seen[url] = true
+		normalized = append(normalized, url)
+	}
+
+	if removed > 0 {
+		changes = append(changes, fmt.Sprintf("removed %d empty or duplicate URL(s)", removed))
+	}
+	return normalized, changes
+}
@@ -0,0 +1,156 @@
+package torrent
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// writeMalformedTorrent creates a torrent and rewrites it with invalid UTF-8 in
+// the comment and a messy announce list.
+func writeMalformedTorrent(t *testing.T) (string, metainfo.Hash) {
+	t.Helper()
+
+	dir := t.TempDir()
+	contentPath := filepath.Join(dir, "content.bin")
+	if err := os.WriteFile(contentPath, make([]byte, 1<<16), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+	torrentPath := filepath.Join(dir, "malformed.torrent")
+	if _, err := Create(CreateOptions{Path: contentPath, OutputPath: torrentPath, TrackerURLs: []string{"https://a.example/announce"}, Quiet: true}); err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+
+	loaded, err := LoadFromFile(torrentPath)
+	if err != nil {
+		t.Fatalf("failed to load torrent: %v", err)
+	}
+	loaded.Comment = "caf\xe9 \xff\xfe\r\nsecond line\x00"
+	loaded.CreatedBy = "tool\x00 1.0"
+	loaded.AnnounceList = metainfo.AnnounceList{
+		{"https://a.example/announce", " https://b.example/announce "},
+		{},
+		{"https://a.example/announce", ""},
+		{"https://c.example/announce"},
+	}
+	loaded.UrlList = []string{"https://seed.example/", "https://seed.example/"}
+
+	f, err := os.Create(torrentPath)
+	if err != nil {
+		t.Fatalf("failed to create torrent file: %v", err)
+	}
+	defer f.Close()
+	if err := loaded.Write(f); err != nil {
+		t.Fatalf("failed to write torrent: %v", err)
+	}
+	return torrentPath, loaded.HashInfoBytes()
+}
+
+func TestConvertTorrent(t *testing.T) {
+	torrentPath, infoHash := writeMalformedTorrent(t)
+
+	result, err := ConvertTorrent(torrentPath, ConvertOptions{})
+	if err != nil {
+		t.Fatalf("ConvertTorrent failed: %v", err)
+	}
+	wantPath := filepath.Join(filepath.Dir(torrentPath), "malformed.converted.torrent")
+	if result.OutputPath != wantPath {
+		t.Errorf("OutputPath = %q, want %q", result.OutputPath, wantPath)
+	}
+
+	converted, err := LoadFromFile(result.OutputPath)
+	if err != nil {
+		t.Fatalf("failed to load converted torrent: %v", err)
+	}
+	if converted.HashInfoBytes() != infoHash || result.InfoHash != infoHash.String() {
+		t.Errorf("info hash changed from %s to %s", infoHash, converted.HashInfoBytes())
+	}
+	if want := "caf\uFFFD \uFFFD\nsecond line"; converted.Comment != want {
+		t.Errorf("Comment = %q, want %q", converted.Comment, want)
+	}
+	if converted.CreatedBy != "tool 1.0" {
+		t.Errorf("CreatedBy = %q, want %q", converted.CreatedBy, "tool 1.0")
+	}
+	if converted.CreationDate == 0 {
+		t.Error("expected the creation date to be kept")
+	}
+
+	wantTiers := metainfo.AnnounceList{
+		{"https://a.example/announce", "https://b.example/announce"},
+		{"https://c.example/announce"},
+	}
+	if !slices.EqualFunc(converted.AnnounceList, wantTiers, slices.Equal) {
+		t.Errorf("AnnounceList = %q, want %q", converted.AnnounceList, wantTiers)
+	}
+	if !slices.Equal(converted.UrlList, []string{"https://seed.example/"}) {
+		t.Errorf("UrlList = %q, want one web seed", converted.UrlList)
+	}
+
+	// converting again finds nothing left to normalize
+	again, err := ConvertTorrent(result.OutputPath, ConvertOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("ConvertTorrent of the converted torrent failed: %v", err)
+	}
+	if len(again.Changes) != 0 {
+		t.Errorf("expected no changes, got %q", again.Changes)
+	}
+}
+
+func TestConvertTorrent_DryRun(t *testing.T) {
+	torrentPath, _ := writeMalformedTorrent(t)
+
+	result, err := ConvertTorrent(torrentPath, ConvertOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("ConvertTorrent failed: %v", err)
+	}
+	if result.OutputPath != "" {
+		t.Errorf("expected no output path for a dry run, got %q", result.OutputPath)
+	}
+	for _, want := range []string{
+		"comment: replaced invalid UTF-8, removed NUL bytes, normalized line endings",
+		"created by: removed NUL bytes",
+		"announce list: removed 1 duplicate tracker(s)",
+		"announce list: removed 2 empty tier(s)",
+	} {
+		if !slices.Contains(result.Changes, want) {
+			t.Errorf("Changes = %q, missing %q", result.Changes, want)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(torrentPath))
+	if err != nil {
+		t.Fatalf("failed to read directory: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.Name() == "malformed.converted.torrent" {
+			t.Error("dry run wrote the converted torrent")
+		}
+	}
+}
+
+func TestConvertTorrent_StripMetadata(t *testing.T) {
+	torrentPath, infoHash := writeMalformedTorrent(t)
+	outputPath := filepath.Join(t.TempDir(), "clean.torrent")
+
+	if _, err := ConvertTorrent(torrentPath, ConvertOptions{OutputPath: outputPath, StripMetadata: true}); err != nil {
+		t.Fatalf("ConvertTorrent failed: %v", err)
+	}
+
+	converted, err := LoadFromFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to load converted torrent: %v", err)
+	}
+	if converted.Comment != "" || converted.CreatedBy != "" || converted.CreationDate != 0 {
+		t.Errorf("expected metadata to be removed, got comment %q, created by %q, date %d",
+			converted.Comment, converted.CreatedBy, converted.CreationDate)
+	}
+	if converted.HashInfoBytes() != infoHash {
+		t.Errorf("info hash changed from %s to %s", infoHash, converted.HashInfoBytes())
+	}
+	if converted.Announce != "https://a.example/announce" {
+		t.Errorf("Announce = %q, want the trackers to be kept", converted.Announce)
+	}
+}
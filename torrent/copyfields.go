@@ -0,0 +1,70 @@
+package torrent
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/anacrolix/torrent/bencode"
+)
+
+// layoutInfoKeys describe the content itself, so copying them from another
+// torrent would break the torrent rather than tag it
+var layoutInfoKeys = append(slices.Clone(requiredInfoKeys), "file tree", "meta version")
+
+// copiedInfoField is an info dictionary field taken from another torrent,
+// kept as raw bencode so it is copied byte for byte
+type copiedInfoField struct {
+	key   string
+	value bencode.Bytes
+}
+
+// ValidateCopyInfoFields checks CopyInfoFieldsFrom and InfoFieldNames of opts,
+// which ModifyTorrent also does before changing anything.
+func ValidateCopyInfoFields(opts ModifyOptions) error {
+	if opts.CopyInfoFieldsFrom == "" {
+		if len(opts.InfoFieldNames) > 0 {
+			return fmt.Errorf("info fields to copy need a source torrent")
+		}
+		return nil
+	}
+	if len(opts.InfoFieldNames) == 0 {
+		return fmt.Errorf("no info fields to copy from %s", opts.CopyInfoFieldsFrom)
+	}
+	for _, name := range opts.InfoFieldNames {
+		if slices.Contains(layoutInfoKeys, name) {
+			return fmt.Errorf("cannot copy info field %q, it describes the content", name)
+		}
+	}
+	return nil
+}
+
+// loadCopiedInfoFields reads the fields named in opts.InfoFieldNames from the
+// info dictionary of the torrent at opts.CopyInfoFieldsFrom.
+func loadCopiedInfoFields(opts ModifyOptions) ([]copiedInfoField, error) {
+	if opts.CopyInfoFieldsFrom == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(opts.CopyInfoFieldsFrom)
+	if err != nil {
+		return nil, fmt.Errorf("could not read torrent to copy from: %w", err)
+	}
+	var root, info map[string]bencode.Bytes
+	if err := bencode.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("could not decode torrent to copy from: %w", err)
+	}
+	if err := bencode.Unmarshal(root["info"], &info); err != nil {
+		return nil, fmt.Errorf("could not decode info of torrent to copy from: %w", err)
+	}
+
+	var fields []copiedInfoField
+	for _, name := range opts.InfoFieldNames {
+		value, ok := info[name]
+		if !ok {
+			return nil, fmt.Errorf("info field %q not found in %s", name, opts.CopyInfoFieldsFrom)
+		}
+		fields = append(fields, copiedInfoField{key: name, value: value})
+	}
+	return fields, nil
+}
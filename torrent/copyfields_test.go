@@ -0,0 +1,92 @@
+package torrent
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestModifyTorrent_CopyInfoFields(t *testing.T) {
+	dir := t.TempDir()
+	contentPath := filepath.Join(dir, "content.bin")
+	if err := os.WriteFile(contentPath, []byte("content to cross-seed"), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+	create := func(name string, entropy bool) string {
+		torrentPath := filepath.Join(dir, name)
+		if _, err := Create(CreateOptions{Path: contentPath, OutputPath: torrentPath, Entropy: entropy, Source: "SRC", Quiet: true}); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+		return torrentPath
+	}
+	pathA := create("a.torrent", true)
+	pathB := create("b.torrent", false)
+
+	_, infoA := rawDicts(t, pathA)
+	if _, ok := infoA["entropy"]; !ok {
+		t.Fatal("torrent A has no entropy field")
+	}
+	original, err := LoadFromFile(pathB)
+	if err != nil {
+		t.Fatalf("failed to load torrent B: %v", err)
+	}
+
+	logger := &capturingLogger{}
+	result, err := ModifyTorrent(pathB, ModifyOptions{
+		CopyInfoFieldsFrom: pathA,
+		InfoFieldNames:     []string{"entropy", "source"},
+		OutputDir:          t.TempDir(),
+		Quiet:              true,
+		Logger:             logger,
+	})
+	if err != nil {
+		t.Fatalf("ModifyTorrent failed: %v", err)
+	}
+
+	_, infoB := rawDicts(t, result.OutputPath)
+	if string(infoB["entropy"]) != string(infoA["entropy"]) {
+		t.Errorf("entropy = %s, want %s", infoB["entropy"], infoA["entropy"])
+	}
+	// source was already set, entropy was not
+	if len(logger.records) != 1 || logger.records[0].level != "warn" || !slices.Contains(logger.records[0].args, any("source")) {
+		t.Errorf("expected one warning about overwriting source, got %+v", logger.records)
+	}
+	modified, err := LoadFromFile(result.OutputPath)
+	if err != nil {
+		t.Fatalf("failed to load modified torrent: %v", err)
+	}
+	if modified.HashInfoBytes() == original.HashInfoBytes() {
+		t.Error("info hash did not change")
+	}
+	if result.NewInfoHash != modified.HashInfoBytes().String() {
+		t.Errorf("NewInfoHash = %q, want %s", result.NewInfoHash, modified.HashInfoBytes())
+	}
+}
+
+func TestModifyTorrent_CopyInfoFieldsValidation(t *testing.T) {
+	torrentPath := writeStripTestTorrent(t)
+
+	tests := []struct {
+		name    string
+		opts    ModifyOptions
+		wantErr string
+	}{
+		{name: "fields without torrent", opts: ModifyOptions{InfoFieldNames: []string{"entropy"}}, wantErr: "need a source torrent"},
+		{name: "torrent without fields", opts: ModifyOptions{CopyInfoFieldsFrom: torrentPath}, wantErr: "no info fields"},
+		{name: "layout field", opts: ModifyOptions{CopyInfoFieldsFrom: torrentPath, InfoFieldNames: []string{"pieces"}}, wantErr: "describes the content"},
+		{name: "missing field", opts: ModifyOptions{CopyInfoFieldsFrom: torrentPath, InfoFieldNames: []string{"entropy"}}, wantErr: `"entropy" not found`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.OutputDir = t.TempDir()
+			tt.opts.Quiet = true
+			_, err := ModifyTorrent(torrentPath, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ModifyTorrent error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
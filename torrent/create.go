@@ -1,20 +1,26 @@
 package torrent
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
+	"io"
+	"io/fs"
+	"maps"
 	"math/bits"
 	"os"
 	"path/filepath"
-	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/anacrolix/torrent/bencode"
 	"github.com/anacrolix/torrent/metainfo"
+	"github.com/dustin/go-humanize"
 	"github.com/fatih/color"
 
 	"github.com/autobrr/mkbrr/internal/preset"
+	"github.com/autobrr/mkbrr/internal/sanitize"
 	"github.com/autobrr/mkbrr/internal/trackers"
 )
 
@@ -27,8 +33,29 @@ func formatPieceSize(exp uint) string {
 	return fmt.Sprintf("%d KiB", size)
 }
 
-// calculatePieceLengthFromTarget derives a piece length exponent from a target piece count.
-// The result is clamped to [minExp, maxExp] where maxExp considers tracker and user constraints.
+// ParseCreationDate parses a fixed creation date given as unix seconds or as an
+// RFC3339 time such as "2024-01-02T15:04:05Z".
+func ParseCreationDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if seconds, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if seconds < 0 {
+			return time.Time{}, fmt.Errorf("invalid date %q: unix time must not be negative", s)
+		}
+		return time.Unix(seconds, 0), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: must be unix seconds or an RFC3339 time such as 2024-01-02T15:04:05Z", s)
+	}
+	if t.Unix() < 0 {
+		return time.Time{}, fmt.Errorf("invalid date %q: must not be before 1970", s)
+	}
+	return t, nil
+}
+
+// calculatePieceLengthFromTarget derives a piece length exponent from a target piece count,
+// choosing the power of two whose piece count is closest to it. The result is clamped to [minExp, maxExp] where maxExp considers tracker and user constraints.
 func calculatePieceLengthFromTarget(totalSize int64, targetCount uint, maxPieceLength *uint, trackerURLs []string, verbose bool) uint {
 	minExp := uint(16) // 64 KiB minimum
 	maxExp := uint(24) // default max 16 MiB, same as auto-calc
@@ -67,8 +94,20 @@ func calculatePieceLengthFromTarget(totalSize int64, targetCount uint, maxPieceL
 	if ratio == 0 {
 		exp = minExp
 	} else {
-		// floor(log2(ratio)) via bit length
+		// floor(log2(ratio)) via bit length gives at least targetCount pieces;
+		// the next power of two gives at most targetCount, so take whichever
+		// lands closer
 		exp = uint(bits.Len64(ratio)) - 1
+		countDiff := func(e uint) uint64 {
+			count := (uint64(totalSize) + (1 << e) - 1) >> e
+			if count > uint64(targetCount) {
+				return count - uint64(targetCount)
+			}
+			return uint64(targetCount) - count
+		}
+		if countDiff(exp+1) < countDiff(exp) {
+			exp++
+		}
 	}
 
 	// clamp to bounds
@@ -173,6 +212,77 @@ func generateRandomString() (string, error) {
 // Returns a Torrent struct containing the metainfo.
 // This is the lower-level function; use Create() for a higher-level interface.
 func CreateTorrent(opts CreateOptions) (*Torrent, error) {
+	return createTorrentFrom(opts, nil, nil)
+}
+
+// EstimateTorrent walks and filters the content and picks the piece length
+// exactly as CreateTorrent would, but stops before hashing. The torrent size is
+// an estimate of the bencoded .torrent file; everything else matches what
+// CreateTorrent produces for the same options.
+func EstimateTorrent(opts CreateOptions) (*Estimate, error) {
+	estimate := &Estimate{}
+	if _, err := createTorrentFrom(opts, nil, estimate); err != nil {
+		return nil, err
+	}
+	return estimate, nil
+}
+
+// CreateTorrentFromFS is CreateTorrent for content read from fsys, such as an
+// embedded filesystem or a zip archive. root is the fs path of the file or
+// directory to create the torrent from, and opts.Path is ignored. Reusing
+// hashes, inode file order and sparse file checks need the OS filesystem and
+// are not supported.
+func CreateTorrentFromFS(fsys fs.FS, root string, opts CreateOptions) (*Torrent, error) {
+	if err := validateFSRoot(root, opts); err != nil {
+		return nil, err
+	}
+	opts.Path = root
+	return createTorrentFrom(opts, fsys, nil)
+}
+
+// validateFSRoot checks root and the options that cannot be used with an fs.FS.
+func validateFSRoot(root string, opts CreateOptions) error {
+	if !fs.ValidPath(root) {
+		return fmt.Errorf("invalid fs path %q", root)
+	}
+	if root == "." && opts.Name == "" {
+		return fmt.Errorf("a torrent name is required when creating from the root of a filesystem")
+	}
+	if opts.ReuseFrom != "" {
+		return fmt.Errorf("reusing piece hashes is not supported for content read from an fs.FS")
+	}
+	if opts.FileOrder == FileOrderInode {
+		return fmt.Errorf("file order %q is not supported for content read from an fs.FS", FileOrderInode)
+	}
+	if opts.HashCacheDir != "" {
+		return fmt.Errorf("a hash cache is not supported for content read from an fs.FS")
+	}
+	return nil
+}
+
+// now returns the current time for creation dates, templates and output dirs,
+// replaceable in tests
+var now = time.Now
+
+// openContent opens the content files for hashing instead of os.Open when
+// set. It is replaced in tests to count how often the content is read.
+var openContent func(name string) (io.ReadSeekCloser, error)
+
+// createTorrentFrom creates the torrent from content in fsys, or from the OS
+// filesystem when fsys is nil. When estimate is not nil, it is filled in once
+// the piece length is known and no torrent is created.
+func createTorrentFrom(opts CreateOptions, fsys fs.FS, estimate *Estimate) (*Torrent, error) {
+	// fail on a template typo before hashing, see expandComment
+	if err := checkTemplates(opts); err != nil {
+		return nil, err
+	}
+	if opts.MaxDepth != nil && *opts.MaxDepth < 0 {
+		return nil, fmt.Errorf("max depth must not be negative, got: %d", *opts.MaxDepth)
+	}
+	if err := validateRootFields(opts.ExtraRootFields); err != nil {
+		return nil, err
+	}
+
 	path := filepath.ToSlash(opts.Path)
 	name := opts.Name
 	if name == "" {
@@ -180,6 +290,20 @@ func CreateTorrent(opts CreateOptions) (*Torrent, error) {
 		name = filepath.Base(filepath.Clean(path))
 	}
 
+	if opts.SanitizeName {
+		name = sanitize.Name(name, opts.ASCIIName)
+	} else if !sanitize.IsValid(name) {
+		display := NewDisplay(NewFormatter(opts.Verbose))
+		display.SetQuiet(opts.Quiet)
+		display.ShowWarning(fmt.Sprintf("torrent name %q contains characters that are invalid on some systems (use --sanitize-name to normalize it)", name))
+	}
+
+	if opts.SkipHashing {
+		display := NewDisplay(NewFormatter(opts.Verbose))
+		display.SetQuiet(opts.Quiet)
+		display.ShowWarning("skipping hashing, the torrent will contain placeholder pieces and cannot be seeded")
+	}
+
 	mi := &metainfo.MetaInfo{
 		Comment: opts.Comment,
 	}
@@ -195,13 +319,25 @@ func CreateTorrent(opts CreateOptions) (*Torrent, error) {
 			mi.AnnounceList = announceList
 		}
 	}
+	NormalizeAnnounce(mi)
+	if opts.RandomizeAnnounceList {
+		if err := shuffleAnnounceList(mi); err != nil {
+			return nil, err
+		}
+	}
 
 	if !opts.NoCreator {
-		mi.CreatedBy = fmt.Sprintf("mkbrr/%s (https://github.com/autobrr/mkbrr)", opts.Version)
+		mi.CreatedBy = opts.Creator
+		if mi.CreatedBy == "" {
+			mi.CreatedBy = fmt.Sprintf("mkbrr/%s (https://github.com/autobrr/mkbrr)", opts.Version)
+		}
 	}
 
 	if !opts.NoDate {
-		mi.CreationDate = time.Now().Unix()
+		mi.CreationDate = now().Unix()
+		if !opts.CreationDate.IsZero() {
+			mi.CreationDate = opts.CreationDate.Unix()
+		}
 	}
 
 	files := make([]fileEntry, 0, 1)
@@ -209,7 +345,13 @@ func CreateTorrent(opts CreateOptions) (*Torrent, error) {
 	var baseDir string
 	originalPaths := make(map[string]string) // map resolved path -> original path for metainfo
 
-	inputInfo, err := os.Stat(path)
+	var inputInfo fs.FileInfo
+	var err error
+	if fsys != nil {
+		inputInfo, err = fs.Stat(fsys, path)
+	} else {
+		inputInfo, err = os.Stat(longPath(path))
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error checking path: %w", err)
 	}
@@ -221,109 +363,290 @@ func CreateTorrent(opts CreateOptions) (*Torrent, error) {
 		matchBasePath = filepath.Dir(cleanBasePath)
 	}
 
-	err = filepath.Walk(path, func(currentPath string, walkInfo os.FileInfo, walkErr error) error {
-		if walkErr != nil {
-			// check if the error is due to a broken symlink during walk
-			// if lstat works but stat fails, it's likely a broken link we might handle later
-			if _, lerr := os.Lstat(currentPath); lerr == nil {
-				// we can lstat it, maybe it's a broken link we can ignore?
-				// for now, let's return the original error to maintain behavior.
-				// consider adding verbose logging here if needed.
-			}
-			return walkErr
+	logger := resolveLogger(opts.Logger)
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// files and directories left out of the torrent, listed in verbose mode
+	var skipped []skippedFile
+	skipFile := func(path, reason string) {
+		if opts.Verbose {
+			skipped = append(skipped, skippedFile{path: path, reason: reason})
 		}
+	}
 
-		lstatInfo, err := os.Lstat(currentPath)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not lstat %q: %v\n", currentPath, err)
+	// rule sets from .mkbrrignore files of the directories on the current walk path;
+	// filepath.Walk is depth-first, so sets for directories we have left are popped
+	var ignoreRules []*IgnoreRuleSet
+
+	if fsys != nil {
+		files, totalSize, err = walkFS(ctx, fsys, path, inputInfo.IsDir(), opts, skipFile)
+		if inputInfo.IsDir() {
+			baseDir = path
+		}
+	} else {
+		// unreadable skips a path that cannot be read with a warning, or fails
+		// the walk when FailFast is set
+		unreadable := func(msg, entryPath string, err error, args ...any) error {
+			if opts.FailFast {
+				return fmt.Errorf("%s %q: %w", msg, entryPath, err)
+			}
+			logger.Warn(msg+", skipping", append(append([]any{"path", entryPath}, args...), "error", err)...)
 			return nil
 		}
 
-		resolvedPath := currentPath
-		resolvedInfo := lstatInfo
+		// identities of the included files and of the directories walked, so
+		// content reached again through a symlink is included only once and
+		// followed directory symlinks cannot loop
+		type includedFile struct {
+			index   int  // in files
+			viaLink bool // reached through a symlink rather than its own path
+		}
+		includedFiles := make(map[fileID]includedFile)
+		walkedDirs := make(map[fileID]bool)
+		followDepth := 0 // number of followed directory symlinks the walk is inside
+
+		var walkFn filepath.WalkFunc
+		walkFn = func(currentPath string, walkInfo os.FileInfo, walkErr error) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if walkErr != nil {
+				logDebug(logger, "error walking path", "path", currentPath, "error", walkErr)
+				return walkErr
+			}
 
-		// check if it's a symlink
-		if lstatInfo.Mode()&os.ModeSymlink != 0 {
-			linkTarget, err := os.Readlink(currentPath)
+			lstatInfo, err := os.Lstat(longPath(currentPath))
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: could not readlink %q: %v\n", currentPath, err)
-				return nil
+				return unreadable("could not lstat path", currentPath, err)
 			}
-			// if link is relative, resolve it based on the link's directory
-			if !filepath.IsAbs(linkTarget) {
-				linkTarget = filepath.Join(filepath.Dir(currentPath), linkTarget)
+
+			resolvedPath := currentPath
+			resolvedInfo := lstatInfo
+
+			// check if it's a symlink, or a directory junction, which os.Lstat
+			// does not report as one on Windows
+			var linkTarget string
+			if lstatInfo.Mode()&os.ModeSymlink != 0 {
+				linkTarget, err = os.Readlink(longPath(currentPath))
+				if err != nil {
+					return unreadable("could not read symlink", currentPath, err)
+				}
+			} else if !lstatInfo.Mode().IsRegular() && currentPath != path {
+				junction, target, err := isJunction(longPath(currentPath))
+				if err != nil {
+					return unreadable("could not read junction", currentPath, err)
+				}
+				if junction {
+					linkTarget = target
+				}
 			}
-			resolvedPath = filepath.Clean(linkTarget)
+			if linkTarget != "" {
+				// if link is relative, resolve it based on the link's directory
+				if !filepath.IsAbs(linkTarget) {
+					linkTarget = filepath.Join(filepath.Dir(currentPath), linkTarget)
+				}
+				resolvedPath = filepath.Clean(linkTarget)
 
-			// stat target
-			statInfo, err := os.Stat(resolvedPath)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: could not stat symlink target %q for link %q: %v\n", resolvedPath, currentPath, err)
-				return nil // skip broken link or inaccessible target
+				// stat target
+				statInfo, err := os.Stat(longPath(resolvedPath))
+				if err != nil {
+					// broken link or inaccessible target
+					return unreadable("could not stat symlink target", currentPath, err, "target", resolvedPath)
+				}
+				resolvedInfo = statInfo
 			}
-			resolvedInfo = statInfo
-		}
 
-		// Compute relative path from torrent root for glob matching
-		relPath, err := filepath.Rel(matchBasePath, currentPath)
-		if err != nil {
-			return fmt.Errorf("error calculating relative path for %q: %w", currentPath, err)
-		}
-		// Handle the root directory case
-		if relPath == "." {
-			relPath = ""
-		}
+			// Compute relative path from torrent root for glob matching
+			relPath, err := filepath.Rel(matchBasePath, currentPath)
+			if err != nil {
+				return fmt.Errorf("error calculating relative path for %q: %w", currentPath, err)
+			}
+			// Handle the root directory case
+			if relPath == "." {
+				relPath = ""
+			}
 
-		if resolvedInfo.IsDir() {
-			// Check hardcoded directory ignores (safety net)
-			if shouldIgnoreDir(currentPath) || shouldIgnoreDir(resolvedPath) {
-				return filepath.SkipDir
+			for len(ignoreRules) > 0 {
+				if _, ok := ignoreRules[len(ignoreRules)-1].relativePath(currentPath); ok {
+					break
+				}
+				ignoreRules = ignoreRules[:len(ignoreRules)-1]
 			}
 
-			// Check user-defined exclude/include patterns for directories
-			if relPath != "" {
-				shouldSkip, err := shouldIgnoreEntry(relPath, true, opts.ExcludePatterns, opts.IncludePatterns)
+			if resolvedInfo.IsDir() {
+				// returning SkipDir for a symlink would skip the rest of its parent directory
+				skipDir := filepath.SkipDir
+				if linkTarget != "" {
+					skipDir = nil
+				}
+
+				// Check hardcoded directory ignores (safety net)
+				if shouldIgnoreDir(currentPath) || shouldIgnoreDir(resolvedPath) {
+					skipFile(currentPath+string(filepath.Separator), ignoreReasonBuiltin)
+					return skipDir
+				}
+
+				// Check user-defined exclude/include patterns for directories
+				if relPath != "" {
+					if beyondMaxDepth(relPath, opts.MaxDepth) {
+						skipFile(currentPath+string(filepath.Separator), ignoreReasonMaxDepth)
+						return skipDir
+					}
+					if opts.SkipHidden && (isHiddenName(filepath.Base(currentPath)) || isHiddenFile(longPath(currentPath))) {
+						skipFile(currentPath+string(filepath.Separator), ignoreReasonHidden)
+						return skipDir
+					}
+					if isExcludedDir(currentPath, opts.ExcludeDirs) {
+						skipFile(currentPath+string(filepath.Separator), ignoreReasonExcludeDir)
+						return skipDir
+					}
+
+					reason, err := ignoreEntryReason(relPath, true, opts.ExcludePatterns, opts.IncludePatterns, opts.CaseSensitivePatterns)
+					if err != nil {
+						return fmt.Errorf("error processing directory patterns for %q: %w", currentPath, err)
+					}
+					if reason == "" {
+						reason, err = ignoreRuleSetsReason(currentPath, true, ignoreRules)
+						if err != nil {
+							return err
+						}
+					}
+					if reason != "" {
+						skipFile(currentPath+string(filepath.Separator), reason)
+						return skipDir
+					}
+				}
+
+				if linkTarget != "" && !opts.FollowDirSymlinks {
+					skipFile(currentPath+string(filepath.Separator), skipReasonDirSymlink)
+					return nil
+				}
+				if id, ok := fileIdentity(resolvedPath, resolvedInfo); ok {
+					if walkedDirs[id] {
+						logger.Warn("directory already included through another path, skipping", "path", currentPath, "target", resolvedPath)
+						skipFile(currentPath+string(filepath.Separator), skipReasonSymlinkLoop)
+						return skipDir
+					}
+					if linkTarget == "" {
+						walkedDirs[id] = true
+					}
+				}
+				if linkTarget != "" {
+					// with a trailing separator the OS resolves the link as part
+					// of the path, so the entries below keep paths under the link
+					followDepth++
+					err := walkLong(currentPath+string(filepath.Separator), walkFn)
+					followDepth--
+					return err
+				}
+
+				ruleSet, err := loadIgnoreRuleSet(currentPath)
 				if err != nil {
-					return fmt.Errorf("error processing directory patterns for %q: %w", currentPath, err)
+					return err
 				}
-				if shouldSkip {
-					return filepath.SkipDir
+				if ruleSet != nil {
+					ignoreRules = append(ignoreRules, ruleSet)
+				}
+
+				if baseDir == "" && currentPath == path { // only set baseDir for the initial path if it's a dir
+					baseDir = currentPath
 				}
+				return nil
 			}
 
-			if baseDir == "" && currentPath == path { // only set baseDir for the initial path if it's a dir
-				baseDir = currentPath
+			// it's a file (or a link pointing to one)
+			if inputInfo.IsDir() && filepath.Base(currentPath) == ignoreFileName {
+				return nil
+			}
+			if inputInfo.IsDir() && opts.SkipHidden && (isHiddenName(filepath.Base(currentPath)) || isHiddenFile(longPath(currentPath))) {
+				skipFile(currentPath, ignoreReasonHidden)
+				return nil
 			}
-			return nil
-		}
 
-		// it's a file (or a link pointing to one)
-		shouldIgnore, err := shouldIgnoreEntry(relPath, false, opts.ExcludePatterns, opts.IncludePatterns)
-		if err != nil {
-			return fmt.Errorf("error processing file patterns for %q: %w", currentPath, err)
-		}
-		if shouldIgnore {
+			reason, err := ignoreEntryReason(relPath, false, opts.ExcludePatterns, opts.IncludePatterns, opts.CaseSensitivePatterns)
+			if err != nil {
+				return fmt.Errorf("error processing file patterns for %q: %w", currentPath, err)
+			}
+			if reason == "" {
+				reason, err = ignoreRuleSetsReason(currentPath, false, ignoreRules)
+				if err != nil {
+					return err
+				}
+			}
+			if reason != "" {
+				skipFile(currentPath, reason)
+				return nil
+			}
+
+			// content reached through a symlink as well as another path is
+			// included once, under its own path when it was reached that way;
+			// hard links without symlinks involved stay separate files
+			viaLink := linkTarget != "" || followDepth > 0
+			id, hasID := fileIdentity(resolvedPath, resolvedInfo)
+			if included, ok := includedFiles[id]; hasID && ok && (viaLink || included.viaLink) {
+				skipPath := currentPath
+				if included.viaLink && !viaLink {
+					// keep this path instead of the link included before
+					old := files[included.index].path
+					skipPath = originalPaths[old]
+					delete(originalPaths, old)
+					files[included.index].path = resolvedPath
+					originalPaths[resolvedPath] = currentPath
+					includedFiles[id] = includedFile{index: included.index}
+				}
+				logger.Warn("file already included through another path, skipping", "path", skipPath, "target", resolvedPath)
+				skipFile(skipPath, skipReasonDuplicate)
+				return nil
+			}
+			if hasID {
+				includedFiles[id] = includedFile{index: len(files), viaLink: viaLink}
+			}
+
+			// add the file using the resolved path for hashing, but store the original path for metainfo
+			files = append(files, fileEntry{
+				path:   resolvedPath, // use the actual content path for hashing
+				length: resolvedInfo.Size(),
+				offset: totalSize,
+			})
+			originalPaths[resolvedPath] = currentPath
+			totalSize += resolvedInfo.Size()
 			return nil
 		}
-
-		// add the file using the resolved path for hashing, but store the original path for metainfo
-		files = append(files, fileEntry{
-			path:   resolvedPath, // use the actual content path for hashing
-			length: resolvedInfo.Size(),
-			offset: totalSize,
-		})
-		originalPaths[resolvedPath] = currentPath
-		totalSize += resolvedInfo.Size()
-		return nil
-	})
+		err = walkLong(path, walkFn)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error walking path: %w", err)
 	}
 
-	// sort files to ensure consistent order
-	sort.Slice(files, func(i, j int) bool {
-		return files[i].path < files[j].path
-	})
+	if len(skipped) > 0 {
+		skipDisplay := NewDisplay(NewFormatter(opts.Verbose))
+		skipDisplay.SetQuiet(opts.Quiet)
+		skipDisplay.ShowSkippedFiles(skipped)
+	}
+
+	// torrentPath returns the slash-separated path of a file in the torrent,
+	// the path of the link rather than its target for symlinks
+	torrentPath := func(f fileEntry) string {
+		originalFilepath := originalPaths[f.path]
+		if originalFilepath == "" {
+			originalFilepath = f.path
+		}
+		if baseDir == "" {
+			return filepath.ToSlash(filepath.Base(originalFilepath))
+		}
+		relPath, _ := filepath.Rel(baseDir, originalFilepath)
+		return filepath.ToSlash(relPath)
+	}
+
+	// sort files to ensure consistent order, by their path in the torrent so
+	// the order does not depend on the OS path separator
+	if err := sortFiles(files, opts.FileOrder, torrentPath); err != nil {
+		return nil, err
+	}
 
 	// recalculate offsets based on the sorted file order
 	// context: https://github.com/autobrr/mkbrr/issues/64
@@ -337,10 +660,84 @@ func CreateTorrent(opts CreateOptions) (*Torrent, error) {
 		return nil, fmt.Errorf("input path %q contains no files or only empty files, cannot create torrent", path)
 	}
 
+	if opts.PadToPieceBoundary {
+		if opts.ReuseFrom != "" {
+			return nil, fmt.Errorf("cannot reuse piece hashes when padding files to piece boundaries")
+		}
+		for _, f := range files {
+			if p := torrentPath(f); baseDir != "" && strings.HasPrefix(p, padDir+"/") {
+				return nil, fmt.Errorf("content file %q is in the %s directory used for padding files", p, padDir)
+			}
+		}
+	}
+
+	checkDisplay := NewDisplay(NewFormatter(opts.Verbose))
+	checkDisplay.SetQuiet(opts.Quiet)
+	warnings, err := checkFiles(files, opts, fsys, checkDisplay)
+	if err != nil {
+		return nil, err
+	}
+
+	// a reused torrent's piece length is set below but not forced by the caller
+	pieceLengthForced := opts.PieceLengthExp != nil
+
+	var reuse *reuseSource
+	var reusePaths []string
+	if opts.ReuseFrom != "" {
+		reuse, err = loadReuseSource(opts.ReuseFrom)
+		if err != nil {
+			return nil, err
+		}
+
+		// hashes can only be reused with the same piece length
+		if opts.PieceLengthExp == nil && opts.TargetPieceCount == nil {
+			exp := uint(bits.TrailingZeros64(uint64(reuse.pieceLen)))
+			opts.PieceLengthExp = &exp
+		}
+	}
+	var cache *hashCache
+	if opts.HashCacheDir != "" && !opts.SkipHashing && estimate == nil {
+		if cache, err = loadHashCache(opts.HashCacheDir); err != nil {
+			return nil, err
+		}
+	}
+	if reuse != nil || len(opts.setSources) > 0 {
+		// torrent paths of the files, matching how they are written to the info dict below
+		reusePaths = make([]string, len(files))
+		if inputInfo.IsDir() {
+			for i, f := range files {
+				originalFilepath := originalPaths[f.path]
+				if originalFilepath == "" {
+					originalFilepath = f.path
+				}
+				relPath, _ := filepath.Rel(baseDir, originalFilepath)
+				reusePaths[i] = filepath.ToSlash(relPath)
+			}
+		}
+	}
+
+	// expandComment expands the comment template once the info hash is known;
+	// the comment is outside the info dictionary, so the hash stays the same
+	expandComment := func(t *Torrent) (*Torrent, error) {
+		comment, err := expandTemplate("comment", t.Comment, templateData(t, opts))
+		if err != nil {
+			return nil, err
+		}
+		t.Comment = comment
+		return t, nil
+	}
+
 	// Function to create torrent with given piece length
 	createWithPieceLength := func(pieceLength uint) (*Torrent, error) {
 		pieceLenInt := int64(1) << pieceLength
-		numPieces := (totalSize + pieceLenInt - 1) / pieceLenInt
+
+		// padding depends on the piece length, so it is added for each attempt
+		hashFiles := files
+		if opts.PadToPieceBoundary && inputInfo.IsDir() {
+			hashFiles = padFileEntries(files, pieceLenInt)
+		}
+		last := hashFiles[len(hashFiles)-1]
+		numPieces := (last.offset + last.length + pieceLenInt - 1) / pieceLenInt
 
 		var display Displayer
 		if opts.ProgressCallback != nil {
@@ -350,16 +747,82 @@ func CreateTorrent(opts CreateOptions) (*Torrent, error) {
 			// Use default display when no callback is provided
 			defaultDisplay := NewDisplay(NewFormatter(opts.Verbose || opts.InfoOnly))
 			defaultDisplay.SetQuiet(opts.Quiet || opts.InfoOnly)
+			defaultDisplay.SetPlainProgress(opts.NoProgress)
 			display = defaultDisplay
 		}
 
 		var pieceHashes [][]byte
-		hasher := NewPieceHasher(files, pieceLenInt, int(numPieces), display, opts.FailOnSeasonPackWarning)
-		// Pass the specified or default worker count from opts
-		if err := hasher.hashPieces(opts.Workers); err != nil {
-			return nil, err
+		var seasonInfo *SeasonPackInfo
+		if opts.SkipHashing {
+			// nil hashes are written as all-zero placeholders below
+			pieceHashes = make([][]byte, numPieces)
+			if !opts.SkipSeasonPackCheck {
+				seasonInfo = AnalyzeSeasonPack(files)
+			}
+		} else {
+			hasher := NewPieceHasher(hashFiles, pieceLenInt, int(numPieces), display, opts.FailOnSeasonPackWarning)
+			hasher.skipSeasonCheck = opts.SkipSeasonPackCheck
+			hasher.readRetries = opts.ReadRetries
+			hasher.throttle = newReadThrottle(opts.MaxReadBytesPerSecond)
+			hasher.ctx = ctx
+			if fsys != nil {
+				hasher.open = fsOpener(fsys)
+			} else if openContent != nil {
+				hasher.open = openContent
+			}
+
+			// a torrent of the same set with this piece length stands in
+			// for a torrent to reuse, unless padding moved the files
+			source := reuse
+			if source == nil && len(hashFiles) == len(files) {
+				source = opts.setSources[pieceLenInt]
+			}
+			reusedPieces := 0
+			if source != nil {
+				reusedPieces = source.planReuse(hasher, reusePaths)
+			}
+			var plan *cachePlan
+			if cache != nil {
+				p, err := cache.plan(hasher)
+				if err != nil {
+					return nil, err
+				}
+				plan = p
+			}
+
+			// Pass the specified or default worker count from opts
+			if err := hasher.hashPieces(opts.Workers); err != nil {
+				return nil, err
+			}
+
+			if source != nil {
+				if err := checkReusedPieces(hasher, source, reusedPieces, opts); err != nil {
+					return nil, err
+				}
+			}
+			if cache != nil {
+				if err := cache.store(hasher, plan); err != nil {
+					return nil, err
+				}
+				cacheDisplay := NewDisplay(NewFormatter(opts.Verbose))
+				cacheDisplay.SetQuiet(opts.Quiet)
+				cacheDisplay.ShowMessage(fmt.Sprintf("found %d of %d piece hashes in the hash cache %s", plan.hits, hasher.numPieces, opts.HashCacheDir))
+			}
+			pieceHashes = hasher.pieces
+			seasonInfo = hasher.seasonInfo
+
+			if opts.Verbose {
+				implDisplay := NewDisplay(NewFormatter(opts.Verbose))
+				implDisplay.SetQuiet(opts.Quiet)
+				implDisplay.ShowMessage("hashed with " + SHA1Implementation())
+			}
+			if opts.Verbose && hasher.fileReopens > 0 {
+				reopenDisplay := NewDisplay(NewFormatter(opts.Verbose))
+				reopenDisplay.SetQuiet(opts.Quiet)
+				reopenDisplay.ShowMessage(fmt.Sprintf("reopened files %d times to stay within %d open files per worker",
+					hasher.fileReopens, maxOpenFilesPerWorker))
+			}
 		}
-		pieceHashes = hasher.pieces
 
 		info := &metainfo.Info{
 			Name:        name,
@@ -377,13 +840,7 @@ func CreateTorrent(opts CreateOptions) (*Torrent, error) {
 		}
 
 		if len(files) == 1 {
-			// check if the input path is a directory
-			pathInfo, err := os.Stat(path)
-			if err != nil {
-				return nil, fmt.Errorf("error checking path: %w", err)
-			}
-
-			if pathInfo.IsDir() {
+			if inputInfo.IsDir() {
 				// if it's a directory, use the folder structure even for single files
 				info.Files = make([]metainfo.FileInfo, 1)
 				// Use the original path for calculating relative path in metainfo
@@ -418,6 +875,22 @@ func CreateTorrent(opts CreateOptions) (*Torrent, error) {
 			}
 		}
 
+		if opts.PreserveAttrs {
+			attrs, err := fileAttrs(files, originalPaths, fsys)
+			if err != nil {
+				return nil, fmt.Errorf("could not read file attributes: %w", err)
+			}
+			if len(info.Files) == 0 && len(attrs) == 1 {
+				info.Attr = attrs[0]
+			}
+			for i := range info.Files {
+				info.Files[i].Attr = attrs[i]
+			}
+		}
+		if len(hashFiles) != len(files) {
+			info.Files = padInfoFiles(info.Files, pieceLenInt)
+		}
+
 		infoBytes, err := bencode.Marshal(info)
 		if err != nil {
 			return nil, fmt.Errorf("error encoding info: %w", err)
@@ -442,7 +915,7 @@ func CreateTorrent(opts CreateOptions) (*Torrent, error) {
 			mi.UrlList = opts.WebSeeds
 		}
 
-		return &Torrent{mi}, nil
+		return &Torrent{MetaInfo: mi, HTTPSeeds: opts.HTTPSeeds, ExtraFields: maps.Clone(opts.ExtraRootFields), Warnings: warnings, SeasonPack: seasonInfo}, nil
 	}
 
 	// validate mutual exclusion at the API level (CLI validates this too, but exported callers may not)
@@ -527,6 +1000,88 @@ func CreateTorrent(opts CreateOptions) (*Torrent, error) {
 		}
 	}
 
+	// estimate the .torrent size before hashing, so a forced piece length that
+	// cannot meet the tracker's size limit fails right away
+	var relPaths []string
+	if inputInfo.IsDir() {
+		relPaths = make([]string, len(files))
+		for i, f := range files {
+			originalFilepath := originalPaths[f.path]
+			if originalFilepath == "" {
+				originalFilepath = f.path
+			}
+			relPath, _ := filepath.Rel(baseDir, originalFilepath)
+			relPaths[i] = filepath.ToSlash(relPath)
+		}
+	}
+	overhead := estimateMetadataSize(mi, opts, name, relPaths)
+	torrentSize := estimateTorrentSize(totalSize, pieceLength, overhead)
+	if opts.Verbose {
+		display := NewDisplay(NewFormatter(opts.Verbose))
+		display.SetQuiet(opts.Quiet)
+		display.ShowMessage(fmt.Sprintf("estimated torrent size: %s with %s pieces", formatKiB(torrentSize), formatPieceSize(pieceLength)))
+	}
+
+	// a forced piece length far below the automatic choice needlessly inflates
+	// the .torrent; trackers with piece size ranges are compared against above
+	if pieceLengthForced {
+		trackerRange := false
+		if len(opts.TrackerURLs) > 0 && opts.TrackerURLs[0] != "" {
+			_, trackerRange = trackers.GetTrackerPieceSizeExp(opts.TrackerURLs[0], uint64(totalSize))
+		}
+		if recommended := calculatePieceLength(totalSize, nil, opts.TrackerURLs, false); !trackerRange && pieceLength+2 <= recommended {
+			pieceLenInt := int64(1) << pieceLength
+			warning := fmt.Sprintf("piece length %s is well below the %s recommended for %s of content, giving %d pieces and an estimated torrent size of %s; consider a piece length exponent of %d",
+				formatPieceSize(pieceLength), formatPieceSize(recommended), humanize.IBytes(uint64(totalSize)),
+				(totalSize+pieceLenInt-1)/pieceLenInt, formatKiB(torrentSize), recommended)
+			checkDisplay.ShowWarning(warning)
+			warnings = append(warnings, warning)
+		}
+	}
+	if pieceLengthForced && len(opts.TrackerURLs) > 0 && opts.TrackerURLs[0] != "" {
+		if maxSize, ok := trackers.GetTrackerMaxTorrentSize(opts.TrackerURLs[0]); ok && uint64(torrentSize) > maxSize {
+			maxExp := uint(27)
+			if trackerMaxExp, ok := trackers.GetTrackerMaxPieceLength(opts.TrackerURLs[0]); ok {
+				maxExp = trackerMaxExp
+			}
+			if exp, ok := minPieceExpForSize(totalSize, overhead, maxSize, pieceLength+1, maxExp); ok {
+				return nil, fmt.Errorf("piece length %s gives an estimated torrent size of %s, over the tracker limit of %s; use a piece length exponent of at least %d (%s)",
+					formatPieceSize(pieceLength), formatKiB(torrentSize), formatKiB(int64(maxSize)), exp, formatPieceSize(exp))
+			}
+			return nil, fmt.Errorf("piece length %s gives an estimated torrent size of %s, over the tracker limit of %s even with the maximum piece length",
+				formatPieceSize(pieceLength), formatKiB(torrentSize), formatKiB(int64(maxSize)))
+		}
+	}
+
+	if estimate != nil {
+		// follow the size limit loop below using the estimated size instead of the real one
+		if len(opts.TrackerURLs) > 0 && opts.TrackerURLs[0] != "" {
+			if maxSize, ok := trackers.GetTrackerMaxTorrentSize(opts.TrackerURLs[0]); ok {
+				ceiling := maxPieceLengthCeiling(opts)
+				for uint64(torrentSize) > maxSize && pieceLength < ceiling {
+					pieceLength++
+					torrentSize = estimateTorrentSize(totalSize, pieceLength, overhead)
+				}
+				if uint64(torrentSize) > maxSize {
+					return nil, fmt.Errorf("unable to create torrent under size limit (%.1f KiB) even with maximum piece length",
+						float64(maxSize)/(1<<10))
+				}
+			}
+		}
+
+		pieceLenInt := int64(1) << pieceLength
+		*estimate = Estimate{
+			Name:           name,
+			Files:          len(files),
+			TotalSize:      totalSize,
+			PieceLength:    pieceLenInt,
+			PieceLengthExp: pieceLength,
+			Pieces:         (totalSize + pieceLenInt - 1) / pieceLenInt,
+			TorrentSize:    torrentSize,
+		}
+		return nil, nil
+	}
+
 	// Check for tracker size limits and adjust piece length if needed
 	if len(opts.TrackerURLs) > 0 && opts.TrackerURLs[0] != "" {
 		if maxSize, ok := trackers.GetTrackerMaxTorrentSize(opts.TrackerURLs[0]); ok {
@@ -537,32 +1092,15 @@ func CreateTorrent(opts CreateOptions) (*Torrent, error) {
 			}
 
 			// Check if it exceeds size limit
-			torrentData, err := bencode.Marshal(t.MetaInfo)
+			torrentData, err := t.Marshal()
 			if err != nil {
 				return nil, fmt.Errorf("error marshaling torrent data: %w", err)
 			}
 
-			// Determine the effective max piece length ceiling
-			maxPieceLengthCeiling := uint(24) // default ceiling
-			hasTrackerCap := false
-			if len(opts.TrackerURLs) > 0 && opts.TrackerURLs[0] != "" {
-				if trackerMaxExp, ok := trackers.GetTrackerMaxPieceLength(opts.TrackerURLs[0]); ok {
-					maxPieceLengthCeiling = trackerMaxExp
-					hasTrackerCap = true
-				}
-			}
-			if opts.MaxPieceLength != nil {
-				if hasTrackerCap {
-					// tracker cap is a hard ceiling; user can lower but not exceed it
-					maxPieceLengthCeiling = min(*opts.MaxPieceLength, maxPieceLengthCeiling)
-				} else {
-					// no tracker cap; user can raise above default 24
-					maxPieceLengthCeiling = min(*opts.MaxPieceLength, 27)
-				}
-			}
+			ceiling := maxPieceLengthCeiling(opts)
 
 			// If it exceeds limit, try increasing piece length until it fits or we hit max
-			for uint64(len(torrentData)) > maxSize && pieceLength < maxPieceLengthCeiling {
+			for uint64(len(torrentData)) > maxSize && pieceLength < ceiling {
 				if opts.Verbose || opts.InfoOnly {
 					display := NewDisplay(NewFormatter(opts.Verbose || opts.InfoOnly))
 					display.SetQuiet(opts.Quiet || opts.InfoOnly)
@@ -576,7 +1114,7 @@ func CreateTorrent(opts CreateOptions) (*Torrent, error) {
 					return nil, err
 				}
 
-				torrentData, err = bencode.Marshal(t.MetaInfo)
+				torrentData, err = t.Marshal()
 				if err != nil {
 					return nil, fmt.Errorf("error marshaling torrent data: %w", err)
 				}
@@ -587,12 +1125,39 @@ func CreateTorrent(opts CreateOptions) (*Torrent, error) {
 					float64(maxSize)/(1<<10))
 			}
 
-			return t, nil
+			return expandComment(t)
 		}
 	}
 
 	// No size limit, just create with original piece length
-	return createWithPieceLength(pieceLength)
+	t, err := createWithPieceLength(pieceLength)
+	if err != nil {
+		return nil, err
+	}
+	return expandComment(t)
+}
+
+// maxPieceLengthCeiling returns the largest piece length exponent the tracker
+// size limit loop may raise the piece length to.
+func maxPieceLengthCeiling(opts CreateOptions) uint {
+	ceiling := uint(24) // default ceiling
+	hasTrackerCap := false
+	if len(opts.TrackerURLs) > 0 && opts.TrackerURLs[0] != "" {
+		if trackerMaxExp, ok := trackers.GetTrackerMaxPieceLength(opts.TrackerURLs[0]); ok {
+			ceiling = trackerMaxExp
+			hasTrackerCap = true
+		}
+	}
+	if opts.MaxPieceLength != nil {
+		if hasTrackerCap {
+			// tracker cap is a hard ceiling; user can lower but not exceed it
+			ceiling = min(*opts.MaxPieceLength, ceiling)
+		} else {
+			// no tracker cap; user can raise above default 24
+			ceiling = min(*opts.MaxPieceLength, 27)
+		}
+	}
+	return ceiling
 }
 
 // Create creates a new torrent file with the given options.
@@ -604,38 +1169,85 @@ func Create(opts CreateOptions) (*TorrentInfo, error) {
 	if _, err := os.Stat(opts.Path); err != nil {
 		return nil, fmt.Errorf("invalid path %q: %w", opts.Path, err)
 	}
+	return create(opts, nil)
+}
 
+// CreateFromFS is Create for content read from fsys, see CreateTorrentFromFS.
+// The torrent file is still written to the OS filesystem.
+func CreateFromFS(fsys fs.FS, root string, opts CreateOptions) (*TorrentInfo, error) {
+	if err := validateFSRoot(root, opts); err != nil {
+		return nil, err
+	}
+	if _, err := fs.Stat(fsys, root); err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", root, err)
+	}
+	opts.Path = root
+	return create(opts, fsys)
+}
+
+func create(opts CreateOptions, fsys fs.FS) (*TorrentInfo, error) {
 	baseName := filepath.Base(filepath.Clean(opts.Path))
 	if opts.Name == "" {
 		opts.Name = baseName
 	}
+	if opts.SanitizeName {
+		opts.Name = sanitize.Name(opts.Name, opts.ASCIIName)
+	}
+
+	var trackerURL string
+	if len(opts.TrackerURLs) > 0 {
+		trackerURL = opts.TrackerURLs[0]
+	}
+
+	// create the output dir before hashing so an unusable one fails fast;
+	// only the part from a template such as {{.InfoHash}} on has to wait
+	createdAt := now()
+	if dir := templatePrefix(preset.ExpandOutputDirAt(opts.OutputDir, trackerURL, createdAt)); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("error creating output directory %q: %w", dir, err)
+		}
+	}
+
+	// create torrent
+	t, err := createTorrentFrom(opts, fsys, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// output and output dir may use templates such as {{.InfoHash}}, which is
+	// only known now; createTorrentFrom checked them before hashing
+	data := templateData(t, opts)
+	if opts.OutputPath, err = expandTemplate("output", opts.OutputPath, data); err != nil {
+		return nil, err
+	}
+	outputDir, err := expandTemplate("output dir", opts.OutputDir, data)
+	if err != nil {
+		return nil, err
+	}
 
 	// set name if not provided
 	fileName := opts.Name
 	if len(opts.TrackerURLs) == 1 && !opts.SkipPrefix {
-		fileName = preset.GetDomainPrefix(opts.TrackerURLs[0]) + "_" + fileName
+		fileName = preset.GetDomainPrefix(trackerURL) + "_" + fileName
 	}
 
-	if opts.OutputDir != "" {
-		opts.OutputPath = filepath.Join(opts.OutputDir, fileName+".torrent")
+	// output dir may contain template variables such as {year} or {tracker}
+	outputDir = preset.ExpandOutputDirAt(outputDir, trackerURL, createdAt)
+
+	if outputDir != "" {
+		opts.OutputPath = filepath.Join(outputDir, fileName+".torrent")
 	} else if opts.OutputPath == "" {
 		opts.OutputPath = fileName + ".torrent"
 	} else if !strings.HasSuffix(opts.OutputPath, ".torrent") {
 		opts.OutputPath = opts.OutputPath + ".torrent"
 	}
 
-	if opts.OutputDir != "" {
-		if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
-			return nil, fmt.Errorf("error creating output directory %q: %w", opts.OutputDir, err)
+	if outputDir != "" {
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return nil, fmt.Errorf("error creating output directory %q: %w", outputDir, err)
 		}
 	}
 
-	// create torrent
-	t, err := CreateTorrent(opts)
-	if err != nil {
-		return nil, err
-	}
-
 	// create output file
 	f, err := os.Create(opts.OutputPath)
 	if err != nil {
@@ -653,10 +1265,16 @@ func Create(opts CreateOptions) (*TorrentInfo, error) {
 
 	// create torrent info for return
 	torrentInfo := &TorrentInfo{
-		Path:     opts.OutputPath,
-		Size:     info.Length,
-		InfoHash: t.MetaInfo.HashInfoBytes().String(),
-		Files:    len(info.Files),
+		MetaInfo:       t.MetaInfo,
+		Path:           opts.OutputPath,
+		Size:           info.TotalLength(),
+		InfoHash:       t.MetaInfo.HashInfoBytes().String(),
+		InfoHashSHA256: t.InfoHashSHA256(),
+		Files:          len(info.Files),
+		PieceLength:    info.PieceLength,
+		PieceCount:     info.NumPieces(),
+		Warnings:       t.Warnings,
+		SeasonPack:     t.SeasonPack,
 		Announce: func() string {
 			if len(opts.TrackerURLs) > 0 {
 				return opts.TrackerURLs[0]
@@ -675,9 +1293,11 @@ func Create(opts CreateOptions) (*TorrentInfo, error) {
 
 		display := NewDisplay(NewFormatter(opts.Verbose || opts.InfoOnly))
 		display.ShowTorrentInfo(t, info)
-		//if len(info.Files) > 0 {
-		//display.ShowFileTree(info)
-		//}
+	}
+
+	if (opts.ShowTree || opts.Verbose) && info.IsDir() && !opts.Quiet {
+		display := NewDisplay(NewFormatter(opts.Verbose))
+		display.ShowFileTree(info)
 	}
 
 	return torrentInfo, nil
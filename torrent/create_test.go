@@ -3,12 +3,17 @@ package torrent
 import (
 	"bytes"
 	"crypto/sha1"
+	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/anacrolix/torrent/metainfo"
 
@@ -179,7 +184,7 @@ func Test_calculatePieceLengthFromTarget(t *testing.T) {
 			totalSize:      100 << 30,
 			targetCount:    50,
 			maxPieceLength: uintPtr(27),
-			wantExp:        27, // 100GB/50 ≈ 2GB = 2^31, floor(log2) = 30, clamped to 27
+			wantExp:        27, // 100GB/50 = 2GiB = 2^31, clamped to 27
 		},
 		{
 			name:           "maxPieceLength cannot exceed tracker cap",
@@ -201,6 +206,25 @@ func Test_calculatePieceLengthFromTarget(t *testing.T) {
 			targetCount: 500,
 			wantExp:     23, // ~4GB/500 = ~8MiB = 2^23
 		},
+		{
+			name:        "rounds up when fewer pieces land closer",
+			totalSize:   12 << 30,
+			targetCount: 1000,
+			wantExp:     24, // 16 MiB → 768 pieces is closer than 8 MiB → 1536
+		},
+		{
+			name:        "3GB with target 1500",
+			totalSize:   3 << 30,
+			targetCount: 1500,
+			wantExp:     21, // 2 MiB → 1536 pieces
+		},
+		{
+			name:        "tracker cap clamps the closest length",
+			totalSize:   12 << 30,
+			targetCount: 1000,
+			trackerURLs: []string{"https://empornium.sx/announce?passkey=123"},
+			wantExp:     23, // 24 would be closer, emp max is 23
+		},
 	}
 
 	for _, tt := range tests {
@@ -400,6 +424,45 @@ func TestCreateTorrent_Symlink(t *testing.T) {
 	t.Logf("Symlink test successful: Torrent created from %q, correctly referencing content from %q", linkDir, realFilePath)
 }
 
+func TestCreate_FailFast(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping symlink test on Windows")
+	}
+
+	dir := filepath.Join(t.TempDir(), "content")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("failed to create content directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "file.bin"), []byte("content"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "missing.bin"), filepath.Join(dir, "broken.bin")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+	outputPath := filepath.Join(t.TempDir(), "content.torrent")
+
+	_, err := Create(CreateOptions{Path: dir, OutputPath: outputPath, FailFast: true, Quiet: true})
+	if err == nil || !strings.Contains(err.Error(), "could not stat symlink target") {
+		t.Fatalf("expected the broken symlink to fail, got %v", err)
+	}
+	if _, statErr := os.Stat(outputPath); !os.IsNotExist(statErr) {
+		t.Errorf("expected no torrent to be written, stat returned %v", statErr)
+	}
+
+	// without FailFast the broken symlink is skipped
+	if _, err := Create(CreateOptions{Path: dir, OutputPath: outputPath, Quiet: true, Logger: slog.New(slog.DiscardHandler)}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	mi, err := LoadFromFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to load torrent: %v", err)
+	}
+	info := mi.GetInfo()
+	if len(info.Files) != 1 || info.Files[0].Path[0] != "file.bin" {
+		t.Errorf("expected only file.bin in the torrent, got %v", info.Files)
+	}
+}
+
 func TestCreateTorrent_IgnoresSynologyMetadataDir(t *testing.T) {
 	// Setup temporary directory with a regular file and Synology metadata directory
 	rootDir := t.TempDir()
@@ -511,6 +574,178 @@ func TestCreateTorrent_SingleFilePatterns(t *testing.T) {
 	}
 }
 
+func TestCreateTorrent_CaseSensitivePatterns(t *testing.T) {
+	rootDir := t.TempDir()
+	for _, name := range []string{"README", filepath.Join("docs", "readme"), "movie.mkv"} {
+		path := filepath.Join(rootDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+	}
+
+	for _, tt := range []struct {
+		caseSensitive bool
+		want          []string
+	}{
+		{caseSensitive: false, want: []string{"movie.mkv"}},
+		{caseSensitive: true, want: []string{"docs/readme", "movie.mkv"}},
+	} {
+		tor, err := CreateTorrent(CreateOptions{
+			Path:                  rootDir,
+			ExcludePatterns:       []string{"README"},
+			CaseSensitivePatterns: tt.caseSensitive,
+			NoCreator:             true,
+			NoDate:                true,
+		})
+		if err != nil {
+			t.Fatalf("CreateTorrent() error = %v", err)
+		}
+		var got []string
+		for _, f := range tor.GetInfo().Files {
+			got = append(got, strings.Join(f.Path, "/"))
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("caseSensitive=%v: files = %v, want %v", tt.caseSensitive, got, tt.want)
+		}
+	}
+}
+
+func TestCreateTorrent_SkipHidden(t *testing.T) {
+	rootDir := t.TempDir()
+	files := map[string]string{
+		"movie.mkv":                              "movie",
+		"movie.nfo":                              "nfo",
+		".env":                                   "hidden",
+		filepath.Join(".hidden_dir", "file.txt"): "hidden",
+		filepath.Join("extras", ".thumbs"):       "hidden",
+		filepath.Join("extras", "featurette.mkv"): "extra",
+		ignoreFileName: "*.nfo\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(rootDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+	}
+
+	tor, err := CreateTorrent(CreateOptions{
+		Path:       rootDir,
+		SkipHidden: true,
+		NoCreator:  true,
+		NoDate:     true,
+	})
+	if err != nil {
+		t.Fatalf("CreateTorrent() error = %v", err)
+	}
+	var got []string
+	for _, f := range tor.GetInfo().Files {
+		got = append(got, strings.Join(f.Path, "/"))
+	}
+	slices.Sort(got)
+	// the .mkbrrignore still applies, leaving out movie.nfo
+	if want := []string{"extras/featurette.mkv", "movie.mkv"}; !slices.Equal(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
+
+func TestCreateTorrent_MaxDepth(t *testing.T) {
+	rootDir := t.TempDir()
+	mapFS := fstest.MapFS{}
+	for _, name := range []string{"top.mkv", "extras/featurette.mkv", "extras/deleted/scene.mkv"} {
+		path := filepath.Join(rootDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+		mapFS["content/"+name] = &fstest.MapFile{Data: []byte(name)}
+	}
+
+	depth := func(n int) *int { return &n }
+	for _, tt := range []struct {
+		maxDepth *int
+		want     []string
+	}{
+		{maxDepth: nil, want: []string{"extras/deleted/scene.mkv", "extras/featurette.mkv", "top.mkv"}},
+		{maxDepth: depth(0), want: []string{"top.mkv"}},
+		{maxDepth: depth(1), want: []string{"extras/featurette.mkv", "top.mkv"}},
+		{maxDepth: depth(2), want: []string{"extras/deleted/scene.mkv", "extras/featurette.mkv", "top.mkv"}},
+	} {
+		opts := CreateOptions{Path: rootDir, MaxDepth: tt.maxDepth, NoCreator: true, NoDate: true, Quiet: true}
+		tor, err := CreateTorrent(opts)
+		if err != nil {
+			t.Fatalf("CreateTorrent() error = %v", err)
+		}
+		fsOpts := opts
+		fsOpts.Path = ""
+		fsTor, err := CreateTorrentFromFS(mapFS, "content", fsOpts)
+		if err != nil {
+			t.Fatalf("CreateTorrentFromFS() error = %v", err)
+		}
+		for _, got := range [][]string{torrentFilePaths(tor), torrentFilePaths(fsTor)} {
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("maxDepth=%v: files = %v, want %v", tt.maxDepth, got, tt.want)
+			}
+		}
+	}
+
+	if _, err := CreateTorrent(CreateOptions{Path: rootDir, MaxDepth: depth(-1)}); err == nil {
+		t.Error("expected an error for a negative max depth")
+	}
+}
+
+// torrentFilePaths returns the sorted paths of the files in tor.
+func torrentFilePaths(tor *Torrent) []string {
+	var paths []string
+	for _, f := range tor.GetInfo().Files {
+		paths = append(paths, strings.Join(f.Path, "/"))
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func TestCreateTorrent_SmallPieceLengthWarning(t *testing.T) {
+	// 300 MiB chooses 256 KiB pieces automatically; placeholder pieces keep
+	// the sparse file from being read
+	path := filepath.Join(t.TempDir(), "large.bin")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	if err := os.Truncate(path, 300<<20); err != nil {
+		t.Fatalf("failed to size test file: %v", err)
+	}
+
+	exp := func(n uint) *uint { return &n }
+	for _, tt := range []struct {
+		pieceLengthExp *uint
+		wantWarning    bool
+	}{
+		{pieceLengthExp: nil, wantWarning: false},
+		{pieceLengthExp: exp(17), wantWarning: false},
+		{pieceLengthExp: exp(16), wantWarning: true},
+	} {
+		tor, err := CreateTorrent(CreateOptions{Path: path, PieceLengthExp: tt.pieceLengthExp, SkipHashing: true, Quiet: true})
+		if err != nil {
+			t.Fatalf("CreateTorrent() error = %v", err)
+		}
+		warned := slices.ContainsFunc(tor.Warnings, func(w string) bool {
+			return strings.Contains(w, "piece length 64 KiB is well below the 256 KiB recommended") &&
+				strings.Contains(w, "4800 pieces")
+		})
+		if warned != tt.wantWarning {
+			t.Errorf("pieceLengthExp=%v: warned = %v, want %v (warnings %q)", tt.pieceLengthExp, warned, tt.wantWarning, tor.Warnings)
+		}
+	}
+}
+
 func TestCreateTorrent_OutputDirPriority(t *testing.T) {
 	// Setup temporary directories for test
 	tmpDir, err := os.MkdirTemp("", "mkbrr-create-test")
@@ -779,6 +1014,85 @@ func TestCreate_UsesCustomNameForOutputPath(t *testing.T) {
 	}
 }
 
+func TestCreate_ExpandsOutputDirTemplate(t *testing.T) {
+	workspace := t.TempDir()
+	inputPath := filepath.Join(workspace, "video.mkv")
+	if err := os.WriteFile(inputPath, []byte("template output dir content"), 0644); err != nil {
+		t.Fatalf("failed to write input file: %v", err)
+	}
+
+	pinned := time.Date(2024, time.March, 9, 23, 59, 59, 0, time.UTC)
+	origNow := now
+	now = func() time.Time { return pinned }
+	defer func() { now = origNow }()
+
+	opts := CreateOptions{
+		Path:        inputPath,
+		TrackerURLs: []string{"https://tracker.example.com/announce"},
+		OutputDir:   filepath.Join(workspace, "out", "{tracker}", "{year}", "{month}", "{day}"),
+		Comment:     "{{.Date}}",
+		Quiet:       true,
+	}
+
+	info, err := Create(opts)
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+
+	wantDir := filepath.Join(workspace, "out", "example", "2024", "03", "09")
+	if filepath.Dir(info.Path) != wantDir {
+		t.Fatalf("expected torrent in %q, got %q", wantDir, info.Path)
+	}
+	if _, err := os.Stat(info.Path); err != nil {
+		t.Fatalf("expected torrent file to exist at %q, got error: %v", info.Path, err)
+	}
+
+	// the creation date and {{.Date}} come from the same clock
+	if info.MetaInfo.CreationDate != pinned.Unix() {
+		t.Errorf("creation date = %d, want %d", info.MetaInfo.CreationDate, pinned.Unix())
+	}
+	if want := pinned.Local().Format("2006-01-02"); info.MetaInfo.Comment != want {
+		t.Errorf("comment = %q, want %q", info.MetaInfo.Comment, want)
+	}
+}
+
+func TestCreate_SanitizeName(t *testing.T) {
+	workspace := t.TempDir()
+	inputPath := filepath.Join(workspace, "input.mkv")
+	if err := os.WriteFile(inputPath, []byte("sanitize name content"), 0644); err != nil {
+		t.Fatalf("failed to write input file: %v", err)
+	}
+
+	info, err := Create(CreateOptions{
+		Path:         inputPath,
+		Name:         "Amélie: Director's Cut?. ",
+		OutputDir:    filepath.Join(workspace, "out"),
+		SanitizeName: true,
+		ASCIIName:    true,
+		Quiet:        true,
+	})
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+
+	const want = "Amelie_ Director's Cut_"
+	if got := filepath.Base(info.Path); got != want+".torrent" {
+		t.Errorf("expected output file %q, got %q", want+".torrent", got)
+	}
+
+	mi, err := metainfo.LoadFromFile(info.Path)
+	if err != nil {
+		t.Fatalf("failed to load torrent: %v", err)
+	}
+	parsed, err := mi.UnmarshalInfo()
+	if err != nil {
+		t.Fatalf("failed to unmarshal info: %v", err)
+	}
+	if parsed.Name != want {
+		t.Errorf("expected info name %q, got %q", want, parsed.Name)
+	}
+}
+
 func TestCreate_NameArgument(t *testing.T) {
 
 	tracker := "https://unknown.customtracker.com/announce"
@@ -1035,3 +1349,310 @@ func TestCreate_NameArgument(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateTorrent_SkipHashing(t *testing.T) {
+	workspace := t.TempDir()
+	files := map[string]int{"a.mkv": 3 << 16, "sub/b.nfo": 1024}
+	for rel, size := range files {
+		path := filepath.Join(workspace, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, bytes.Repeat([]byte("x"), size), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", rel, err)
+		}
+	}
+
+	pieceLength := uint(16)
+	tor, err := CreateTorrent(CreateOptions{
+		Path:           workspace,
+		Name:           "template",
+		TrackerURLs:    []string{"https://tracker.example.com/announce"},
+		Source:         "EXAMPLE",
+		PieceLengthExp: &pieceLength,
+		SkipHashing:    true,
+		NoDate:         true,
+		Quiet:          true,
+	})
+	if err != nil {
+		t.Fatalf("CreateTorrent returned error: %v", err)
+	}
+
+	info, err := tor.UnmarshalInfo()
+	if err != nil {
+		t.Fatalf("failed to unmarshal info: %v", err)
+	}
+
+	if info.Name != "template" || info.Source != "EXAMPLE" || tor.Announce != "https://tracker.example.com/announce" {
+		t.Errorf("metadata not preserved: name=%q source=%q announce=%q", info.Name, info.Source, tor.Announce)
+	}
+	if len(info.Files) != 2 || info.TotalLength() != 3<<16+1024 {
+		t.Errorf("expected 2 files totalling %d bytes, got %d files totalling %d", 3<<16+1024, len(info.Files), info.TotalLength())
+	}
+	if got := len(info.Pieces) / 20; got != 4 {
+		t.Errorf("expected 4 placeholder pieces, got %d", got)
+	}
+	if !HasPlaceholderPieces(&info) {
+		t.Error("expected piece hashes to be placeholders")
+	}
+
+	var buf bytes.Buffer
+	display := NewDisplay(NewFormatter(false))
+	display.output = &buf
+	display.ShowTorrentInfo(tor, &info)
+	if !strings.Contains(stripAnsiCodes(buf.String()), "cannot be seeded") {
+		t.Errorf("expected ShowTorrentInfo to flag placeholder pieces, got:\n%s", buf.String())
+	}
+}
+
+func TestHasPlaceholderPieces(t *testing.T) {
+	if HasPlaceholderPieces(&metainfo.Info{}) {
+		t.Error("expected no pieces to not count as placeholders")
+	}
+	if !HasPlaceholderPieces(&metainfo.Info{Pieces: make([]byte, 40)}) {
+		t.Error("expected zeroed pieces to be placeholders")
+	}
+	hashed := sha1.Sum([]byte("data"))
+	if HasPlaceholderPieces(&metainfo.Info{Pieces: append(make([]byte, 20), hashed[:]...)}) {
+		t.Error("expected real hashes to not be placeholders")
+	}
+}
+
+func TestCreate_FixedDateIsReproducible(t *testing.T) {
+	contentDir := filepath.Join(t.TempDir(), "content")
+	if err := os.MkdirAll(filepath.Join(contentDir, "sub"), 0755); err != nil {
+		t.Fatalf("failed to create content dir: %v", err)
+	}
+	for name, data := range map[string]string{"a.txt": "first file", "sub/b.txt": "second file"} {
+		if err := os.WriteFile(filepath.Join(contentDir, name), []byte(data), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	date := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	outputDir := t.TempDir()
+	create := func(output string) []byte {
+		t.Helper()
+		_, err := Create(CreateOptions{
+			Path:         contentDir,
+			OutputPath:   filepath.Join(outputDir, output),
+			TrackerURLs:  []string{"https://tracker.example.com/announce"},
+			IsPrivate:    true,
+			NoCreator:    true,
+			CreationDate: date,
+			Quiet:        true,
+		})
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(outputDir, output))
+		if err != nil {
+			t.Fatalf("failed to read torrent: %v", err)
+		}
+		return data
+	}
+
+	first := create("first.torrent")
+	time.Sleep(1100 * time.Millisecond) // a date taken from the clock would differ now
+	second := create("second.torrent")
+
+	if !bytes.Equal(first, second) {
+		t.Error("expected two runs with the same fixed date to produce identical torrents")
+	}
+
+	mi, err := metainfo.Load(bytes.NewReader(first))
+	if err != nil {
+		t.Fatalf("failed to parse torrent: %v", err)
+	}
+	if mi.CreationDate != date.Unix() {
+		t.Errorf("CreationDate = %d, want %d", mi.CreationDate, date.Unix())
+	}
+}
+
+func TestParseCreationDate(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{input: "1704207845", want: 1704207845},
+		{input: "0", want: 0},
+		{input: "2024-01-02T15:04:05Z", want: 1704207845},
+		{input: "2024-01-02T17:04:05+02:00", want: 1704207845},
+		{input: "-1", wantErr: true},
+		{input: "1969-12-31T23:59:59Z", wantErr: true},
+		{input: "2024-01-02", wantErr: true},
+		{input: "yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseCreationDate(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCreationDate(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got.Unix() != tt.want {
+			t.Errorf("ParseCreationDate(%q) = %d, want %d", tt.input, got.Unix(), tt.want)
+		}
+	}
+}
+
+func TestCreate_SymlinkCycles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping symlink test on Windows")
+	}
+
+	// content/
+	//   a.bin
+	//   sub/b.bin
+	//   sub/link_to_a.bin -> ../a.bin   (sibling file, included once as a.bin)
+	//   sub/up -> ..                    (ancestor directory)
+	//   loop1 -> loop2, loop2 -> loop1  (mutually referencing links)
+	dir := filepath.Join(t.TempDir(), "content")
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatalf("failed to create directories: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.bin"), bytes.Repeat([]byte("a"), 1000), 0644); err != nil {
+		t.Fatalf("failed to write a.bin: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "b.bin"), bytes.Repeat([]byte("b"), 300), 0644); err != nil {
+		t.Fatalf("failed to write b.bin: %v", err)
+	}
+	for link, target := range map[string]string{
+		filepath.Join("sub", "link_to_a.bin"): filepath.Join("..", "a.bin"),
+		filepath.Join("sub", "up"):            "..",
+		"loop1":                               "loop2",
+		"loop2":                               "loop1",
+	} {
+		if err := os.Symlink(target, filepath.Join(dir, link)); err != nil {
+			t.Fatalf("failed to create symlink %s: %v", link, err)
+		}
+	}
+
+	wantPaths := []string{"a.bin", "sub/b.bin"}
+	for _, follow := range []bool{false, true} {
+		t.Run(fmt.Sprintf("follow=%v", follow), func(t *testing.T) {
+			mi, err := CreateTorrent(CreateOptions{
+				Path:              dir,
+				FollowDirSymlinks: follow,
+				Quiet:             true,
+				Logger:            slog.New(slog.DiscardHandler),
+			})
+			if err != nil {
+				t.Fatalf("CreateTorrent failed: %v", err)
+			}
+			info := mi.GetInfo()
+
+			var paths []string
+			for _, f := range info.Files {
+				paths = append(paths, strings.Join(f.Path, "/"))
+			}
+			if !slices.Equal(paths, wantPaths) {
+				t.Errorf("files = %q, want %q", paths, wantPaths)
+			}
+			if info.TotalLength() != 1300 {
+				t.Errorf("total size = %d, want 1300", info.TotalLength())
+			}
+		})
+	}
+}
+
+func TestCreate_FollowDirSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping symlink test on Windows")
+	}
+
+	// the content links to a directory outside it, which links back to the content
+	root := t.TempDir()
+	dir := filepath.Join(root, "content")
+	extras := filepath.Join(root, "extras")
+	for _, d := range []string{dir, extras} {
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", d, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.bin"), bytes.Repeat([]byte("m"), 500), 0644); err != nil {
+		t.Fatalf("failed to write main.bin: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(extras, "sample.bin"), bytes.Repeat([]byte("s"), 200), 0644); err != nil {
+		t.Fatalf("failed to write sample.bin: %v", err)
+	}
+	if err := os.Symlink(extras, filepath.Join(dir, "extras")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+	if err := os.Symlink(dir, filepath.Join(extras, "back")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	tests := []struct {
+		follow    bool
+		wantPaths []string
+	}{
+		{follow: false, wantPaths: []string{"main.bin"}},
+		{follow: true, wantPaths: []string{"extras/sample.bin", "main.bin"}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("follow=%v", tt.follow), func(t *testing.T) {
+			mi, err := CreateTorrent(CreateOptions{Path: dir, FollowDirSymlinks: tt.follow, Quiet: true, Logger: slog.New(slog.DiscardHandler)})
+			if err != nil {
+				t.Fatalf("CreateTorrent failed: %v", err)
+			}
+
+			var paths []string
+			for _, f := range mi.GetInfo().Files {
+				paths = append(paths, strings.Join(f.Path, "/"))
+			}
+			if !slices.Equal(paths, tt.wantPaths) {
+				t.Errorf("files = %q, want %q", paths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestCreate_SummaryCounts(t *testing.T) {
+	contentDir := filepath.Join(t.TempDir(), "content")
+	if err := os.MkdirAll(filepath.Join(contentDir, "sub"), 0755); err != nil {
+		t.Fatalf("failed to create content dir: %v", err)
+	}
+	for name, size := range map[string]int{"a.bin": 200000, "sub/b.bin": 100000} {
+		if err := os.WriteFile(filepath.Join(contentDir, name), make([]byte, size), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	pieceLenExp := uint(16)
+	info, err := Create(CreateOptions{
+		Path:           contentDir,
+		OutputPath:     filepath.Join(t.TempDir(), "content.torrent"),
+		PieceLengthExp: &pieceLenExp,
+		Quiet:          true,
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	want := CreateSummary{
+		Path:        info.Path,
+		InfoHash:    info.InfoHash,
+		Size:        300000,
+		Files:       2,
+		PieceLength: 1 << 16,
+		PieceCount:  5,
+	}
+	if got := NewCreateSummary(info); got != want {
+		t.Errorf("NewCreateSummary() = %+v, want %+v", got, want)
+	}
+
+	single, err := Create(CreateOptions{
+		Path:           filepath.Join(contentDir, "a.bin"),
+		OutputPath:     filepath.Join(t.TempDir(), "a.torrent"),
+		PieceLengthExp: &pieceLenExp,
+		Quiet:          true,
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if got := NewCreateSummary(single); got.Files != 1 || got.Size != 200000 || got.PieceCount != 4 {
+		t.Errorf("expected 1 file of 200000 bytes in 4 pieces, got %+v", got)
+	}
+}
@@ -0,0 +1,140 @@
+package torrent
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/anacrolix/torrent/metainfo"
+
+	"github.com/autobrr/mkbrr/internal/trackers"
+)
+
+// ErrIncompleteContent is returned by CrossSeed when the content does not fully
+// match the source torrent and Force is not set.
+var ErrIncompleteContent = errors.New("content does not fully match the source torrent")
+
+// CrossSeedOptions holds options for creating a torrent for another tracker
+// from an existing torrent and its verified content
+type CrossSeedOptions struct {
+	TorrentPath        string   // existing torrent whose piece layout is reused
+	ContentPath        string   // content on disk the torrent is verified against
+	TrackerURLs        []string // announce URLs of the target tracker
+	Source             string   // new source tag, the original is kept when empty
+	Comment            string   // new comment, the original is kept when empty
+	OutputDir          string
+	Version            string
+	Workers            int
+	Entropy            bool // randomize the info hash with an entropy field
+	SkipPrefix         bool
+	NoDate             bool
+	Force              bool // write the torrent even when verification is below 100%
+	NoValidateTrackers bool // write tracker URLs even when trackers.ValidateURLs rejects them
+	Verbose            bool
+	Quiet              bool
+	NoProgress         bool // print verification progress as lines instead of a progress bar
+}
+
+// CrossSeedResult holds the outcome of CrossSeed
+type CrossSeedResult struct {
+	Verification *VerificationResult
+	OutputPath   string
+	InfoHash     string
+}
+
+// CrossSeed verifies the content against an existing torrent and writes a copy
+// announcing to new trackers. Name, piece length, pieces and the file list are
+// taken verbatim from the source info dictionary so the verified data can be
+// seeded immediately; only the announce URLs, source, comment and entropy change.
+func CrossSeed(opts CrossSeedOptions) (*CrossSeedResult, error) {
+	if len(opts.TrackerURLs) == 0 {
+		return nil, fmt.Errorf("at least one tracker URL is required")
+	}
+	// catch typos before spending time on verification; warnings are shown by ModifyTorrent
+	if !opts.NoValidateTrackers {
+		if _, err := trackers.ValidateURLs(opts.TrackerURLs); err != nil {
+			return nil, err
+		}
+	}
+
+	source, err := metainfo.LoadFromFile(opts.TorrentPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not load torrent: %w", err)
+	}
+	sourceInfo, err := source.UnmarshalInfo()
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal info: %w", err)
+	}
+
+	verification, err := VerifyData(VerifyOptions{
+		TorrentPath: opts.TorrentPath,
+		ContentPath: opts.ContentPath,
+		Verbose:     opts.Verbose,
+		Quiet:       opts.Quiet,
+		Workers:     opts.Workers,
+		NoProgress:  opts.NoProgress,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("verification failed: %w", err)
+	}
+
+	result := &CrossSeedResult{Verification: verification}
+	complete := verification.BadPieces == 0 && verification.MissingPieces == 0 && len(verification.MissingFiles) == 0
+	if !complete && !opts.Force {
+		return result, fmt.Errorf("%w: %.2f%% complete", ErrIncompleteContent, verification.Completion)
+	}
+
+	modifyOpts := ModifyOptions{
+		TrackerURLs: opts.TrackerURLs,
+		Source:      opts.Source,
+		Comment:     opts.Comment,
+		OutputDir:   opts.OutputDir,
+		Version:     opts.Version,
+		SkipPrefix:  opts.SkipPrefix,
+		NoDate:      opts.NoDate,
+		Verbose:     opts.Verbose,
+		Quiet:       opts.Quiet,
+		Entropy:     &opts.Entropy,
+
+		NoValidateTrackers: opts.NoValidateTrackers,
+	}
+	modified, err := ModifyTorrent(opts.TorrentPath, modifyOpts)
+	if err != nil {
+		return result, err
+	}
+	result.OutputPath = modified.OutputPath
+
+	// the new torrent must describe exactly the same data as the verified one
+	mi, err := metainfo.LoadFromFile(modified.OutputPath)
+	if err != nil {
+		return result, fmt.Errorf("could not load written torrent: %w", err)
+	}
+	info, err := mi.UnmarshalInfo()
+	if err != nil {
+		return result, fmt.Errorf("could not unmarshal written info: %w", err)
+	}
+	if !samePieceLayout(&sourceInfo, &info) {
+		return result, fmt.Errorf("written torrent %s does not preserve the piece layout of %s", modified.OutputPath, opts.TorrentPath)
+	}
+
+	result.InfoHash = mi.HashInfoBytes().String()
+	return result, nil
+}
+
+// samePieceLayout reports whether a and b describe the same name, files,
+// piece length and piece hashes.
+func samePieceLayout(a, b *metainfo.Info) bool {
+	if a.Name != b.Name || a.PieceLength != b.PieceLength || a.Length != b.Length {
+		return false
+	}
+	if !bytes.Equal(a.Pieces, b.Pieces) || len(a.Files) != len(b.Files) {
+		return false
+	}
+	for i := range a.Files {
+		if a.Files[i].Length != b.Files[i].Length || !slices.Equal(a.Files[i].Path, b.Files[i].Path) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,151 @@
+package torrent
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCrossSeed(t *testing.T) {
+	tmpDir := t.TempDir()
+	contentDir := filepath.Join(tmpDir, "Release")
+	if err := os.MkdirAll(filepath.Join(contentDir, "Extras"), 0755); err != nil {
+		t.Fatalf("failed to create content dir: %v", err)
+	}
+	files := map[string][]byte{
+		"release.mkv":        bytes.Repeat([]byte("m"), 3<<16+100),
+		"Extras/sample.mkv":  bytes.Repeat([]byte("s"), 1<<16),
+		"Extras/release.nfo": []byte("nfo"),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(contentDir, name), data, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	pieceLenExp := uint(16)
+	sourcePath := filepath.Join(tmpDir, "source.torrent")
+	if _, err := Create(CreateOptions{
+		Path:           contentDir,
+		OutputPath:     sourcePath,
+		TrackerURLs:    []string{"https://tracker-a.example/announce"},
+		Source:         "A",
+		PieceLengthExp: &pieceLenExp,
+		IsPrivate:      true,
+		Quiet:          true,
+	}); err != nil {
+		t.Fatalf("failed to create source torrent: %v", err)
+	}
+
+	outputDir := filepath.Join(tmpDir, "out")
+	opts := CrossSeedOptions{
+		TorrentPath: sourcePath,
+		ContentPath: contentDir,
+		TrackerURLs: []string{"https://tracker-b.example/announce"},
+		Source:      "B",
+		OutputDir:   outputDir,
+		Entropy:     true,
+		Quiet:       true,
+	}
+
+	result, err := CrossSeed(opts)
+	if err != nil {
+		t.Fatalf("CrossSeed failed: %v", err)
+	}
+	if result.Verification.Completion != 100 {
+		t.Errorf("expected 100%% completion, got %.2f", result.Verification.Completion)
+	}
+	if want := filepath.Join(outputDir, "tracker-b_Release.torrent"); result.OutputPath != want {
+		t.Errorf("OutputPath = %q, want %q", result.OutputPath, want)
+	}
+
+	source, err := LoadFromFile(sourcePath)
+	if err != nil {
+		t.Fatalf("failed to load source torrent: %v", err)
+	}
+	sourceInfo, _ := source.UnmarshalInfo()
+	written, err := LoadFromFile(result.OutputPath)
+	if err != nil {
+		t.Fatalf("failed to load written torrent: %v", err)
+	}
+	info, err := written.UnmarshalInfo()
+	if err != nil {
+		t.Fatalf("failed to unmarshal written info: %v", err)
+	}
+
+	if !samePieceLayout(&sourceInfo, &info) {
+		t.Error("written torrent does not preserve the piece layout")
+	}
+	if info.Source != "B" {
+		t.Errorf("Source = %q, want %q", info.Source, "B")
+	}
+	if info.Private == nil || !*info.Private {
+		t.Error("expected the private flag to be preserved")
+	}
+	if written.Announce != "https://tracker-b.example/announce" {
+		t.Errorf("Announce = %q", written.Announce)
+	}
+	if result.InfoHash == source.HashInfoBytes().String() {
+		t.Error("expected a new info hash")
+	}
+
+	// the written torrent verifies against the same data
+	verification, err := VerifyData(VerifyOptions{TorrentPath: result.OutputPath, ContentPath: contentDir, Quiet: true})
+	if err != nil {
+		t.Fatalf("VerifyData failed: %v", err)
+	}
+	if verification.Completion != 100 {
+		t.Errorf("expected written torrent to verify completely, got %.2f%%", verification.Completion)
+	}
+}
+
+func TestCrossSeed_IncompleteContent(t *testing.T) {
+	tmpDir := t.TempDir()
+	contentPath := filepath.Join(tmpDir, "content.bin")
+	if err := os.WriteFile(contentPath, bytes.Repeat([]byte("x"), 4<<16), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+
+	pieceLenExp := uint(16)
+	sourcePath := filepath.Join(tmpDir, "source.torrent")
+	if _, err := Create(CreateOptions{
+		Path:           contentPath,
+		OutputPath:     sourcePath,
+		PieceLengthExp: &pieceLenExp,
+		Quiet:          true,
+	}); err != nil {
+		t.Fatalf("failed to create source torrent: %v", err)
+	}
+	corruptFile(t, contentPath)
+
+	outputDir := filepath.Join(tmpDir, "out")
+	opts := CrossSeedOptions{
+		TorrentPath: sourcePath,
+		ContentPath: contentPath,
+		TrackerURLs: []string{"https://tracker-b.example/announce"},
+		OutputDir:   outputDir,
+		Quiet:       true,
+	}
+
+	result, err := CrossSeed(opts)
+	if !errors.Is(err, ErrIncompleteContent) {
+		t.Fatalf("expected ErrIncompleteContent, got %v", err)
+	}
+	if result == nil || result.Verification.BadPieces != 1 {
+		t.Fatalf("expected the verification result with 1 bad piece, got %+v", result)
+	}
+	if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+		t.Error("expected no torrent to be written")
+	}
+
+	opts.Force = true
+	result, err = CrossSeed(opts)
+	if err != nil {
+		t.Fatalf("CrossSeed with Force failed: %v", err)
+	}
+	if _, err := os.Stat(result.OutputPath); err != nil {
+		t.Errorf("expected torrent to be written with Force: %v", err)
+	}
+}
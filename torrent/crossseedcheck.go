@@ -0,0 +1,148 @@
+package torrent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// CrossSeedCheckOptions holds options for checking whether content can be
+// cross-seeded with an existing torrent
+type CrossSeedCheckOptions struct {
+	TorrentPath      string // torrent whose pieces the content must match
+	OtherTorrentPath string // torrent the content belongs to, optional
+	ContentPath      string // content on disk checked against TorrentPath
+	Workers          int
+	Verbose          bool
+	Quiet            bool
+	NoProgress       bool // print verification progress as lines instead of a progress bar
+}
+
+// CrossSeedCheckResult holds the outcome of CheckCrossSeed
+type CrossSeedCheckResult struct {
+	ContentPath  string
+	Compatible   bool
+	Reason       string              // why the content is incompatible, empty when compatible
+	Verification *VerificationResult // nil when the content was ruled out without verifying
+}
+
+// CheckCrossSeed reports whether the content at opts.ContentPath can be seeded
+// with the torrent at opts.TorrentPath, which is the case when every piece of
+// the torrent matches. When opts.OtherTorrentPath is set, the piece length and
+// total size of both torrents are compared first, so content with a different
+// layout is ruled out without hashing.
+func CheckCrossSeed(opts CrossSeedCheckOptions) (*CrossSeedCheckResult, error) {
+	info, err := loadInfo(opts.TorrentPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CrossSeedCheckResult{ContentPath: opts.ContentPath}
+	if opts.OtherTorrentPath != "" {
+		other, err := loadInfo(opts.OtherTorrentPath)
+		if err != nil {
+			return nil, err
+		}
+		if info.PieceLength != other.PieceLength {
+			result.Reason = fmt.Sprintf("piece length differs: %d vs %d", info.PieceLength, other.PieceLength)
+			return result, nil
+		}
+		if info.TotalLength() != other.TotalLength() {
+			result.Reason = fmt.Sprintf("total size differs: %d vs %d bytes", info.TotalLength(), other.TotalLength())
+			return result, nil
+		}
+	}
+
+	size, err := contentSize(opts.ContentPath)
+	if err != nil {
+		return nil, err
+	}
+	if size != info.TotalLength() {
+		result.Reason = fmt.Sprintf("content size differs: %d vs %d bytes", size, info.TotalLength())
+		return result, nil
+	}
+
+	verification, err := VerifyData(VerifyOptions{
+		TorrentPath: opts.TorrentPath,
+		ContentPath: opts.ContentPath,
+		Verbose:     opts.Verbose,
+		Quiet:       opts.Quiet,
+		Workers:     opts.Workers,
+		NoProgress:  opts.NoProgress,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("verification failed: %w", err)
+	}
+	result.Verification = verification
+
+	switch {
+	case len(verification.MissingFiles) > 0:
+		result.Reason = fmt.Sprintf("%d files missing or of a different size", len(verification.MissingFiles))
+	case verification.BadPieces > 0 || verification.MissingPieces > 0:
+		result.Reason = fmt.Sprintf("%d of %d pieces do not match", verification.BadPieces+verification.MissingPieces, verification.TotalPieces)
+	default:
+		result.Compatible = true
+	}
+	return result, nil
+}
+
+// FindCrossSeedCandidates returns the entries of dir, files or directories,
+// whose total size equals that of the torrent at torrentPath.
+func FindCrossSeedCandidates(torrentPath, dir string) ([]string, error) {
+	info, err := loadInfo(torrentPath)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read directory %q: %w", dir, err)
+	}
+
+	var candidates []string
+	for _, entry := range entries {
+		entryPath := filepath.Join(dir, entry.Name())
+		size, err := contentSize(entryPath)
+		if err != nil {
+			continue
+		}
+		if size == info.TotalLength() {
+			candidates = append(candidates, entryPath)
+		}
+	}
+	return candidates, nil
+}
+
+// loadInfo loads the torrent at path and returns its info dictionary.
+func loadInfo(path string) (*metainfo.Info, error) {
+	mi, err := metainfo.LoadFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not load torrent %q: %w", path, err)
+	}
+	info, err := mi.UnmarshalInfo()
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal info dictionary from %q: %w", path, err)
+	}
+	return &info, nil
+}
+
+// contentSize returns the size of the file at path, or the combined size of
+// the regular files below it when it is a directory.
+func contentSize(path string) (int64, error) {
+	var size int64
+	err := walkLong(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("could not read content %q: %w", path, err)
+	}
+	return size, nil
+}
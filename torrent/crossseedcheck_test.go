@@ -0,0 +1,119 @@
+package torrent
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeCrossSeedCheckFixture creates a release in dir and a torrent for it
+// with 64 KiB pieces, returning the content directory and torrent path.
+func writeCrossSeedCheckFixture(t *testing.T, dir string) (string, string) {
+	t.Helper()
+
+	return writeReleaseFixture(t, dir, map[string][]byte{
+		"release.mkv": bytes.Repeat([]byte("m"), 3<<16+100),
+		"release.nfo": []byte("nfo"),
+	}, CreateOptions{
+		TrackerURLs: []string{"https://tracker-a.example/announce"},
+		IsPrivate:   true,
+	})
+}
+
+func TestCheckCrossSeed(t *testing.T) {
+	contentDir, torrentA := writeCrossSeedCheckFixture(t, t.TempDir())
+
+	modified, err := ModifyTorrent(torrentA, ModifyOptions{
+		TrackerURLs: []string{"https://tracker-b.example/announce"},
+		OutputDir:   t.TempDir(),
+		SkipPrefix:  true,
+		Quiet:       true,
+	})
+	if err != nil {
+		t.Fatalf("ModifyTorrent failed: %v", err)
+	}
+
+	result, err := CheckCrossSeed(CrossSeedCheckOptions{
+		TorrentPath:      torrentA,
+		OtherTorrentPath: modified.OutputPath,
+		ContentPath:      contentDir,
+		Quiet:            true,
+	})
+	if err != nil {
+		t.Fatalf("CheckCrossSeed failed: %v", err)
+	}
+	if !result.Compatible || result.Reason != "" {
+		t.Fatalf("expected compatible, got reason %q", result.Reason)
+	}
+	if result.Verification == nil || result.Verification.GoodPieces != result.Verification.TotalPieces {
+		t.Errorf("expected every piece to be verified, got %+v", result.Verification)
+	}
+
+	// a single changed byte makes the content incompatible
+	data, err := os.ReadFile(filepath.Join(contentDir, "release.mkv"))
+	if err != nil {
+		t.Fatalf("failed to read content: %v", err)
+	}
+	data[len(data)/2] = 'x'
+	if err := os.WriteFile(filepath.Join(contentDir, "release.mkv"), data, 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+	result, err = CheckCrossSeed(CrossSeedCheckOptions{
+		TorrentPath:      torrentA,
+		OtherTorrentPath: modified.OutputPath,
+		ContentPath:      contentDir,
+		Quiet:            true,
+	})
+	if err != nil {
+		t.Fatalf("CheckCrossSeed failed: %v", err)
+	}
+	if result.Compatible || !strings.Contains(result.Reason, "pieces do not match") {
+		t.Errorf("expected incompatible pieces, got compatible=%v reason %q", result.Compatible, result.Reason)
+	}
+}
+
+func TestCheckCrossSeed_DifferentLayout(t *testing.T) {
+	contentDir, torrentA := writeCrossSeedCheckFixture(t, t.TempDir())
+
+	pieceLenExp := uint(17)
+	torrentB := filepath.Join(t.TempDir(), "b.torrent")
+	if _, err := Create(CreateOptions{Path: contentDir, OutputPath: torrentB, PieceLengthExp: &pieceLenExp, Quiet: true}); err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+
+	result, err := CheckCrossSeed(CrossSeedCheckOptions{
+		TorrentPath:      torrentA,
+		OtherTorrentPath: torrentB,
+		ContentPath:      contentDir,
+		Quiet:            true,
+	})
+	if err != nil {
+		t.Fatalf("CheckCrossSeed failed: %v", err)
+	}
+	if result.Compatible || !strings.Contains(result.Reason, "piece length differs") {
+		t.Errorf("expected a piece length mismatch, got compatible=%v reason %q", result.Compatible, result.Reason)
+	}
+	if result.Verification != nil {
+		t.Error("expected the content not to be verified")
+	}
+}
+
+func TestFindCrossSeedCandidates(t *testing.T) {
+	downloads := t.TempDir()
+	contentDir, torrentA := writeCrossSeedCheckFixture(t, downloads)
+
+	if err := os.WriteFile(filepath.Join(downloads, "unrelated.mkv"), []byte("too small"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	candidates, err := FindCrossSeedCandidates(torrentA, downloads)
+	if err != nil {
+		t.Fatalf("FindCrossSeedCandidates failed: %v", err)
+	}
+	if want := []string{contentDir}; !reflect.DeepEqual(candidates, want) {
+		t.Errorf("candidates = %v, want %v", candidates, want)
+	}
+}
@@ -1,9 +1,11 @@
 package torrent
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sort"
@@ -14,6 +16,7 @@ import (
 	humanize "github.com/dustin/go-humanize"
 	"github.com/fatih/color"
 	progressbar "github.com/schollz/progressbar/v3"
+	"golang.org/x/term"
 )
 
 type Display struct {
@@ -22,8 +25,26 @@ type Display struct {
 	bar       *progressbar.ProgressBar
 	isBatch   bool
 	quiet     bool
+
+	// pieces and bytes of the current progress bar, for the time remaining
+	total     int
+	totalSize int64
+
+	// plain progress prints a line every plainProgressStep instead of drawing
+	// a bar, for output that is not a terminal
+	plainProgress bool
+	plain         bool
+	lastReport    time.Time
+	lastPercent   int
 }
 
+// plainProgressStep and plainProgressInterval space the lines of plain
+// progress: a line is printed every 10% or 30 seconds, whichever comes first
+const (
+	plainProgressStep     = 10
+	plainProgressInterval = 30 * time.Second
+)
+
 func NewDisplay(formatter *Formatter) *Display {
 	return &Display{
 		formatter: formatter,
@@ -42,14 +63,49 @@ func (d *Display) SetQuiet(quiet bool) {
 	}
 }
 
-func (d *Display) ShowProgress(total int) {
+// SetOutput redirects display output to w, e.g. to os.Stderr when stdout
+// carries data. Quiet mode still discards output.
+func (d *Display) SetOutput(w io.Writer) {
+	if !d.quiet {
+		d.output = w
+	}
+}
+
+// SetPlainProgress prints progress as periodic lines instead of a progress
+// bar, even when the output is a terminal.
+func (d *Display) SetPlainProgress(plain bool) {
+	d.plainProgress = plain
+}
+
+// isTerminal reports whether w is a terminal, so redrawing a progress bar
+// with carriage returns does not end up in log files. Tests replace it.
+var isTerminal = func(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// ShowProgress starts a progress bar for total pieces holding totalSize bytes,
+// or plain progress lines when the output is not a terminal.
+func (d *Display) ShowProgress(total int, totalSize int64) {
 	// Progress bar needs explicit quiet check because it writes directly to the terminal,
 	// bypassing our d.output writer
 	if d.quiet {
 		return
 	}
+	d.total = total
+	d.totalSize = totalSize
 	fmt.Fprintln(d.output)
+
+	d.plain = d.plainProgress || !isTerminal(d.output)
+	if d.plain {
+		d.lastReport = time.Now()
+		d.lastPercent = 0
+		fmt.Fprintf(d.output, "Hashing %d pieces (%s)\n", total, d.formatter.FormatBytes(totalSize))
+		return
+	}
+
 	d.bar = progressbar.NewOptions(total,
+		progressbar.OptionSetWriter(d.output),
 		progressbar.OptionEnableColorCodes(true),
 		progressbar.OptionSetDescription("[cyan][bold]Hashing pieces...[reset]"),
 		progressbar.OptionSetTheme(progressbar.Theme{
@@ -62,25 +118,78 @@ func (d *Display) ShowProgress(total int) {
 	)
 }
 
-func (d *Display) UpdateProgress(completed int, hashrate float64) {
+// UpdateProgress moves the progress bar to completed pieces and describes the
+// hash rate and the file being hashed, if currentFile is set.
+func (d *Display) UpdateProgress(completed int, hashrate float64, currentFile string, currentFileSize int64) {
 	// Progress bar needs explicit quiet check because it writes directly to the terminal,
 	// bypassing our d.output writer
 	if d.isBatch || d.quiet {
 		return
 	}
+	if d.plain {
+		d.reportPlainProgress(completed, hashrate)
+		return
+	}
 	if d.bar != nil {
 		if err := d.bar.Set(completed); err != nil {
 			log.Printf("failed to update progress bar: %v", err)
 		}
 
-		if hashrate > 0 {
-			hrStr := d.formatter.FormatBytes(int64(hashrate))
-			description := fmt.Sprintf("[cyan][bold]Hashing pieces...[reset] [%s/s]", hrStr)
-			d.bar.Describe(description)
+		if hashrate > 0 || currentFile != "" {
+			d.bar.Describe(d.progressDescription(completed, hashrate, currentFile, currentFileSize))
 		}
 	}
 }
 
+// progressDescription names the file being hashed and describes the hash rate
+// and, once the total size is known, the bytes left and the estimated time
+// remaining at that rate.
+func (d *Display) progressDescription(completed int, hashrate float64, currentFile string, currentFileSize int64) string {
+	description := "[cyan][bold]Hashing pieces...[reset]"
+	if currentFile != "" {
+		description = fmt.Sprintf("[cyan][bold]Hashing:[reset] %s (%s)", filepath.Base(currentFile), d.formatter.FormatBytes(currentFileSize))
+	}
+	if hashrate <= 0 {
+		return description
+	}
+
+	description = fmt.Sprintf("%s [%s/s]", description, d.formatter.FormatBytes(int64(hashrate)))
+	if d.total <= 0 || d.totalSize <= 0 || completed >= d.total {
+		return description
+	}
+
+	// pieces have the same length except the last, so the share of pieces left
+	// is close enough to the share of bytes left
+	remaining := d.totalSize - int64(float64(d.totalSize)*float64(completed)/float64(d.total))
+	eta := time.Duration(float64(remaining) / hashrate * float64(time.Second))
+	return fmt.Sprintf("%s [%s left, ETA %s]", description, d.formatter.FormatBytes(remaining), d.formatter.FormatDuration(eta))
+}
+
+// reportPlainProgress prints a progress line once another plainProgressStep
+// percent of the pieces is done or plainProgressInterval has passed.
+func (d *Display) reportPlainProgress(completed int, hashrate float64) {
+	if d.total <= 0 || completed >= d.total {
+		// the last line is printed by FinishProgress
+		return
+	}
+	percent := completed * 100 / d.total
+	if percent < d.lastPercent+plainProgressStep && time.Since(d.lastReport) < plainProgressInterval {
+		return
+	}
+	d.lastPercent = percent - percent%plainProgressStep
+	d.lastReport = time.Now()
+	d.printPlainProgress(completed, hashrate)
+}
+
+// printPlainProgress prints a single line of plain progress.
+func (d *Display) printPlainProgress(completed int, hashrate float64) {
+	line := fmt.Sprintf("hashed %d/%d pieces (%d%%)", completed, d.total, completed*100/d.total)
+	if hashrate > 0 {
+		line += fmt.Sprintf(", %s/s", d.formatter.FormatBytes(int64(hashrate)))
+	}
+	fmt.Fprintln(d.output, line)
+}
+
 // ShowFiles displays the list of files being processed and the number of workers used.
 func (d *Display) ShowFiles(files []fileEntry, numWorkers int) {
 	if d.quiet {
@@ -116,47 +225,129 @@ func (d *Display) ShowFiles(files []fileEntry, numWorkers int) {
 		}
 	}
 
-	// Build a tree structure
-	type fileNode struct {
-		name     string
-		size     int64
-		isDir    bool
-		children map[string]*fileNode
+	root := newFileNode(filepath.Base(commonBase))
+	for _, file := range files {
+		relPath, _ := filepath.Rel(commonBase, file.path)
+		root.add(strings.Split(relPath, string(filepath.Separator)), file.length)
+	}
+
+	d.printFileTree(root)
+}
+
+// ShowSkippedFiles lists the files and directories that were ignored while
+// collecting the torrent contents, along with the reason for each.
+func (d *Display) ShowSkippedFiles(skipped []skippedFile) {
+	if d.quiet || len(skipped) == 0 {
+		return
+	}
+
+	fmt.Fprintf(d.output, "\n%s\n", magenta(fmt.Sprintf("Skipped files (%d):", len(skipped))))
+	for _, file := range skipped {
+		fmt.Fprintf(d.output, "  %s %s\n", file.path, yellow("("+file.reason+")"))
+	}
+}
+
+// ShowConvertChanges lists what ConvertTorrent normalized in a torrent.
+func (d *Display) ShowConvertChanges(changes []string) {
+	if d.quiet {
+		return
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintf(d.output, "\n%s\n", success("Metadata is already normalized"))
+		return
+	}
+	fmt.Fprintf(d.output, "\n%s\n", magenta(fmt.Sprintf("Changes (%d):", len(changes))))
+	for _, change := range changes {
+		fmt.Fprintf(d.output, "  %s\n", change)
+	}
+}
+
+// ShowStripped lists the fields and keys removed from a modified torrent and
+// warns when the info hash changed, as clients treat it as a new torrent.
+func (d *Display) ShowStripped(stripped []string, newInfoHash string) {
+	if d.quiet || len(stripped) == 0 {
+		return
 	}
 
-	root := &fileNode{
-		name:     filepath.Base(commonBase),
+	fmt.Fprintf(d.output, "%s %s\n", label("Stripped:"), strings.Join(stripped, ", "))
+	d.ShowInfoHashChanged(newInfoHash)
+}
+
+// ShowLintResult lists the issues LintTorrent found in a torrent and what it
+// fixed.
+func (d *Display) ShowLintResult(result *LintResult) {
+	if len(result.Issues) == 0 {
+		fmt.Fprintf(d.output, "%s %s\n", result.Path, success("ok"))
+		return
+	}
+	fmt.Fprintf(d.output, "%s\n", result.Path)
+	for _, issue := range result.Issues {
+		severity := yellow(issue.Severity)
+		if issue.Severity == LintSeverityError {
+			severity = errorColor(issue.Severity)
+		}
+		message := issue.Message
+		if issue.Fixable && len(result.Fixed) == 0 {
+			message += " (fixable with --fix)"
+		}
+		fmt.Fprintf(d.output, "  %s %s %s\n", severity, label(issue.Rule+":"), message)
+	}
+	if len(result.Fixed) > 0 {
+		fmt.Fprintf(d.output, "  %s %s, wrote %s\n", success("fixed"), strings.Join(result.Fixed, ", "), result.OutputPath)
+		d.ShowInfoHashChanged(result.NewInfoHash)
+	}
+}
+
+// ShowInfoHashChanged warns that a modification gave the torrent a new info
+// hash, if newInfoHash is set.
+func (d *Display) ShowInfoHashChanged(newInfoHash string) {
+	if newInfoHash != "" {
+		d.ShowWarning(fmt.Sprintf("info hash changed to %s, the torrent must be added to clients again", newInfoHash))
+	}
+}
+
+// fileNode is a file or directory in a rendered file tree. Directory sizes are
+// the sum of everything below them.
+type fileNode struct {
+	name     string
+	size     int64
+	isDir    bool
+	children map[string]*fileNode
+}
+
+func newFileNode(name string) *fileNode {
+	return &fileNode{
+		name:     name,
 		isDir:    true,
 		children: make(map[string]*fileNode),
 	}
+}
 
-	// Add files to the tree
-	for _, file := range files {
-		relPath, _ := filepath.Rel(commonBase, file.path)
-		parts := strings.Split(relPath, string(filepath.Separator))
-
-		current := root
-		for _, part := range parts[:len(parts)-1] {
-			if _, exists := current.children[part]; !exists {
-				current.children[part] = &fileNode{
-					name:     part,
-					isDir:    true,
-					children: make(map[string]*fileNode),
-				}
-			}
-			current = current.children[part]
+// add inserts a file at the given path below n, creating intermediate
+// directories and adding its size to every directory on the way.
+func (n *fileNode) add(parts []string, size int64) {
+	current := n
+	current.size += size
+	for _, part := range parts[:len(parts)-1] {
+		child, exists := current.children[part]
+		if !exists {
+			child = newFileNode(part)
+			current.children[part] = child
 		}
+		child.size += size
+		current = child
+	}
 
-		// Add the file
-		fileName := parts[len(parts)-1]
-		current.children[fileName] = &fileNode{
-			name:  fileName,
-			size:  file.length,
-			isDir: false,
-		}
+	fileName := parts[len(parts)-1]
+	current.children[fileName] = &fileNode{
+		name: fileName,
+		size: size,
 	}
+}
 
-	// Display the tree
+// printFileTree renders the tree rooted at root with sorted children.
+func (d *Display) printFileTree(root *fileNode) {
 	var displayTree func(node *fileNode, prefix string, isLast bool)
 	displayTree = func(node *fileNode, prefix string, isLast bool) {
 		connector := "├─"
@@ -164,17 +355,11 @@ func (d *Display) ShowFiles(files []fileEntry, numWorkers int) {
 			connector = "└─"
 		}
 
-		if prefix == "" {
-			// Root node
-			fmt.Fprintf(d.output, "%s %s\n", connector, success(node.name))
-		} else {
-			if node.isDir {
-				fmt.Fprintf(d.output, "%s%s %s\n", prefix, connector, success(node.name))
-			} else {
-				fmt.Fprintf(d.output, "%s%s %s (%s)\n", prefix, connector, success(node.name),
-					label(d.formatter.FormatBytes(node.size)))
-			}
+		size := d.formatter.FormatBytes(node.size)
+		if exact := d.formatter.exactBytes(node.size); exact != "" && d.formatter.verbose {
+			size += ", " + exact
 		}
+		fmt.Fprintf(d.output, "%s%s %s (%s)\n", prefix, connector, success(node.name), label(size))
 
 		// Get sorted children
 		childNames := make([]string, 0, len(node.children))
@@ -185,18 +370,15 @@ func (d *Display) ShowFiles(files []fileEntry, numWorkers int) {
 
 		// Display children
 		for i, childName := range childNames {
-			child := node.children[childName]
-			childPrefix := prefix
-			if prefix == "" {
-				childPrefix = "  "
-			} else {
+			childPrefix := "  "
+			if prefix != "" {
 				if isLast {
 					childPrefix = prefix + "  "
 				} else {
 					childPrefix = prefix + "│ "
 				}
 			}
-			displayTree(child, childPrefix, i == len(childNames)-1)
+			displayTree(node.children[childName], childPrefix, i == len(childNames)-1)
 		}
 	}
 
@@ -209,6 +391,13 @@ func (d *Display) FinishProgress() {
 	if d.quiet {
 		return
 	}
+	if d.plain {
+		d.plain = false
+		if d.total > 0 {
+			d.printPlainProgress(d.total, 0)
+		}
+		return
+	}
 	if d.bar != nil {
 		if err := d.bar.Finish(); err != nil {
 			log.Printf("failed to finish progress bar: %v", err)
@@ -244,17 +433,51 @@ func (d *Display) ShowError(msg string) {
 	fmt.Fprintln(d.output, errorColor(msg))
 }
 
+// ShowWarning prints msg unless the default logger drops warnings, so
+// --log-level error hides these warnings along with the diagnostic ones.
 func (d *Display) ShowWarning(msg string) {
+	if !defaultLogger.Enabled(context.Background(), slog.LevelWarn) {
+		return
+	}
 	fmt.Fprintf(d.output, "%s %s\n", yellow("Warning:"), msg)
 }
 
+// ShowEstimate prints what creating the torrent would produce, without hashing
+func (d *Display) ShowEstimate(e *Estimate) {
+	fmt.Fprintf(d.output, "\n%s\n", magenta("Estimate:"))
+	fmt.Fprintf(d.output, "  %-13s %s\n", label("Name:"), e.Name)
+	fmt.Fprintf(d.output, "  %-13s %d\n", label("Files:"), e.Files)
+	fmt.Fprintf(d.output, "  %-13s %s\n", label("Size:"), d.formatter.FormatBytes(e.TotalSize))
+	fmt.Fprintf(d.output, "  %-13s %s (2^%d)\n", label("Piece length:"), d.formatter.FormatBytes(e.PieceLength), e.PieceLengthExp)
+	fmt.Fprintf(d.output, "  %-13s %d\n", label("Pieces:"), e.Pieces)
+	fmt.Fprintf(d.output, "  %-13s ~%s\n", label("Torrent size:"), d.formatter.FormatBytes(e.TorrentSize))
+}
+
+// ShowValidation prints the result of ValidateTorrent
+func (d *Display) ShowValidation(errs []ValidationError) {
+	fmt.Fprintf(d.output, "\n%s\n", magenta("Validation:"))
+	if len(errs) == 0 {
+		fmt.Fprintf(d.output, "  %s\n", success("all checks passed"))
+		return
+	}
+	for _, err := range errs {
+		fmt.Fprintf(d.output, "  %-13s %s\n", label(err.Check+":"), errorColor(err.Message))
+	}
+}
+
 func (d *Display) ShowTorrentInfo(t *Torrent, info *metainfo.Info) {
 	fmt.Fprintf(d.output, "\n%s\n", magenta("Torrent info:"))
 	fmt.Fprintf(d.output, "  %-13s %s\n", label("Name:"), info.Name)
 	fmt.Fprintf(d.output, "  %-13s %s\n", label("Hash:"), t.HashInfoBytes())
-	fmt.Fprintf(d.output, "  %-13s %s\n", label("Size:"), d.formatter.FormatBytes(info.TotalLength()))
+	if d.formatter.verbose {
+		fmt.Fprintf(d.output, "  %-13s %s\n", label("InfoHash (v2 addr):"), t.InfoHashSHA256())
+	}
+	fmt.Fprintf(d.output, "  %-13s %s\n", label("Size:"), d.formatter.formatSize(info.TotalLength()))
 	fmt.Fprintf(d.output, "  %-13s %s\n", label("Piece length:"), d.formatter.FormatBytes(info.PieceLength))
 	fmt.Fprintf(d.output, "  %-13s %d\n", label("Pieces:"), len(info.Pieces)/20)
+	if HasPlaceholderPieces(info) {
+		fmt.Fprintf(d.output, "  %-13s %s\n", label("Hashes:"), errorColor("placeholder only (not hashed, cannot be seeded)"))
+	}
 
 	magnet, err := t.MagnetV2()
 	if err == nil {
@@ -279,6 +502,13 @@ func (d *Display) ShowTorrentInfo(t *Torrent, info *metainfo.Info) {
 		}
 	}
 
+	if len(t.HTTPSeeds) > 0 {
+		fmt.Fprintf(d.output, "  %-13s\n", label("HTTP seeds:"))
+		for _, seed := range t.HTTPSeeds {
+			fmt.Fprintf(d.output, "    %s\n", highlight(seed))
+		}
+	}
+
 	if info.Private != nil && *info.Private {
 		fmt.Fprintf(d.output, "  %-13s %s\n", label("Private:"), "yes")
 	}
@@ -308,21 +538,69 @@ func (d *Display) ShowTorrentInfo(t *Torrent, info *metainfo.Info) {
 
 }
 
-// ShowFileTree displays the file structure of a multi-file torrent
-// The decision to show the tree is now handled in cmd/inspect.go
+// ShowPieceStats displays how the files line up with the pieces, to help
+// judge whether the piece length suits the content.
+func (d *Display) ShowPieceStats(info *metainfo.Info) {
+	stats := ComputePieceStats(info)
+	if stats.Files == 0 || stats.PieceLength <= 0 {
+		return
+	}
+
+	fmt.Fprintf(d.output, "%s\n", magenta("Piece stats:"))
+	fmt.Fprintf(d.output, "  %-20s %s (%.2fx piece length)\n", label("Average file size:"),
+		d.formatter.FormatBytes(stats.AverageFileSize), float64(stats.AverageFileSize)/float64(stats.PieceLength))
+	fmt.Fprintf(d.output, "  %-20s %d of %d\n", label("Below one piece:"), stats.FilesSmallerThanPiece, stats.Files)
+	if stats.LongestRun > 0 {
+		fmt.Fprintf(d.output, "  %-20s %d pieces (%s)\n", label("Longest file run:"), stats.LongestRun, stats.LongestRunFile)
+	} else {
+		fmt.Fprintf(d.output, "  %-20s %s\n", label("Longest file run:"), "no piece holds a single file only")
+	}
+	fmt.Fprintln(d.output)
+}
+
+// ShowFileAttrs lists the files carrying BEP 47 attributes other than
+// padding, if any.
+func (d *Display) ShowFileAttrs(info *metainfo.Info) {
+	files := info.UpvertedFiles()
+	if len(info.Files) == 0 && len(files) == 1 {
+		// upverting a single-file info drops its attr
+		files[0].Attr = info.Attr
+	}
+
+	var lines []string
+	for _, file := range files {
+		attr := strings.ReplaceAll(file.Attr, attrPadding, "")
+		if attr == "" {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("  %-4s %s", attr, file.DisplayPath(info)))
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	fmt.Fprintf(d.output, "%s\n", magenta("File attributes:"))
+	for _, line := range lines {
+		fmt.Fprintln(d.output, line)
+	}
+	fmt.Fprintln(d.output)
+}
+
+// ShowFileTree displays the nested file structure of a multi-file torrent,
+// with size subtotals for each directory.
 func (d *Display) ShowFileTree(info *metainfo.Info) {
 	fmt.Fprintf(d.output, "%s\n", magenta("File tree:"))
-	fmt.Fprintf(d.output, "%s %s\n", "└─", success(info.Name))
-	for i, file := range info.Files {
-		prefix := "  ├─"
-		if i == len(info.Files)-1 {
-			prefix = "  └─"
+
+	root := newFileNode(info.Name)
+	for _, file := range info.UpvertedFiles() {
+		path := file.BestPath()
+		if len(path) == 0 {
+			path = []string{info.Name}
 		}
-		fmt.Fprintf(d.output, "%s %s (%s)\n",
-			prefix,
-			success(filepath.Join(file.Path...)),
-			label(d.formatter.FormatBytes(file.Length)))
+		root.add(path, file.Length)
 	}
+
+	d.printFileTree(root)
 	fmt.Fprintln(d.output)
 }
 
@@ -341,15 +619,19 @@ func (d *Display) ShowBatchResults(results []BatchResult, duration time.Duration
 
 	successful := 0
 	failed := 0
+	skipped := 0
 	totalSize := int64(0)
 
 	for _, result := range results {
-		if result.Success {
+		switch {
+		case result.Success:
 			successful++
 			if result.Info != nil {
 				totalSize += result.Info.Size
 			}
-		} else {
+		case result.Skipped:
+			skipped++
+		default:
 			failed++
 		}
 	}
@@ -357,9 +639,40 @@ func (d *Display) ShowBatchResults(results []BatchResult, duration time.Duration
 	fmt.Fprintf(d.output, "  %-15s %d\n", label("Total jobs:"), len(results))
 	fmt.Fprintf(d.output, "  %-15s %s\n", label("Successful:"), success(successful))
 	fmt.Fprintf(d.output, "  %-15s %s\n", label("Failed:"), errorColor(failed))
-	fmt.Fprintf(d.output, "  %-15s %s\n", label("Total size:"), d.formatter.FormatBytes(totalSize))
+	if skipped > 0 {
+		fmt.Fprintf(d.output, "  %-15s %s\n", label("Skipped:"), yellow(skipped))
+	}
+	fmt.Fprintf(d.output, "  %-15s %s\n", label("Total size:"), d.formatter.formatSize(totalSize))
 	fmt.Fprintf(d.output, "  %-15s %s\n", label("Processing time:"), d.formatter.FormatDuration(duration))
 
+	// failures are always listed; verbose mode shows them with the detailed results below
+	if failed > 0 && !d.formatter.verbose {
+		fmt.Fprintf(d.output, "\n%s\n", magenta("Failed jobs:"))
+		for _, result := range results {
+			if !result.Success && !result.Skipped {
+				fmt.Fprintf(d.output, "  %s: %s\n", result.Job.Path, errorColor(result.Error))
+			}
+		}
+	}
+
+	// warnings such as empty or stub files are listed per job as well
+	if !d.formatter.verbose {
+		headerShown := false
+		for _, result := range results {
+			if !result.Success || len(result.Info.Warnings) == 0 {
+				continue
+			}
+			if !headerShown {
+				fmt.Fprintf(d.output, "\n%s\n", magenta("Jobs with warnings:"))
+				headerShown = true
+			}
+			fmt.Fprintf(d.output, "  %s:\n", result.Job.Path)
+			for _, warning := range result.Info.Warnings {
+				fmt.Fprintf(d.output, "    %s\n", yellow(warning))
+			}
+		}
+	}
+
 	if d.formatter.verbose {
 		fmt.Fprintf(d.output, "\n%s\n", magenta("Detailed results:"))
 		for i, result := range results {
@@ -367,12 +680,21 @@ func (d *Display) ShowBatchResults(results []BatchResult, duration time.Duration
 			if result.Success {
 				fmt.Fprintf(d.output, "  %-11s %s\n", label("Status:"), success("Success"))
 				fmt.Fprintf(d.output, "  %-11s %s\n", label("Output:"), result.Info.Path)
-				fmt.Fprintf(d.output, "  %-11s %s\n", label("Size:"), d.formatter.FormatBytes(result.Info.Size))
+				fmt.Fprintf(d.output, "  %-11s %s\n", label("Size:"), d.formatter.formatSize(result.Info.Size))
 				fmt.Fprintf(d.output, "  %-11s %s\n", label("Info hash:"), result.Info.InfoHash)
 				fmt.Fprintf(d.output, "  %-11s %s\n", label("Trackers:"), strings.Join(result.Trackers, ", "))
 				if result.Info.Files > 0 {
 					fmt.Fprintf(d.output, "  %-11s %d\n", label("Files:"), result.Info.Files)
 				}
+				if result.Attempts > 1 {
+					fmt.Fprintf(d.output, "  %-11s %d\n", label("Attempts:"), result.Attempts)
+				}
+				for _, warning := range result.Info.Warnings {
+					fmt.Fprintf(d.output, "  %-11s %s\n", label("Warning:"), yellow(warning))
+				}
+			} else if result.Skipped {
+				fmt.Fprintf(d.output, "  %-11s %s\n", label("Status:"), yellow("Skipped"))
+				fmt.Fprintf(d.output, "  %-11s %s\n", label("Input:"), result.Job.Path)
 			} else {
 				fmt.Fprintf(d.output, "  %-11s %s\n", label("Status:"), errorColor("Failed"))
 				fmt.Fprintf(d.output, "  %-11s %v\n", label("Error:"), result.Error)
@@ -382,18 +704,208 @@ func (d *Display) ShowBatchResults(results []BatchResult, duration time.Duration
 	}
 }
 
+// ShowBenchResults displays the throughput of each worker count per
+// benchmarked directory, marking the fastest.
+func (d *Display) ShowBenchResults(results []*BenchResult) {
+	for _, result := range results {
+		fmt.Fprintf(d.output, "\n%s %s (%s)\n", magenta("Hashing benchmark:"), result.Dir, d.formatter.FormatBytes(result.Size))
+		fmt.Fprintf(d.output, "  %-12s %12s %10s\n", "Workers", "Throughput", "Time")
+		for i, run := range result.Runs {
+			workers := fmt.Sprintf("%d", run.UsedWorkers)
+			if run.Workers <= 0 {
+				workers = fmt.Sprintf("auto (%d)", run.UsedWorkers)
+			}
+			line := fmt.Sprintf("  %-12s %6.1f MiB/s %10s", workers, run.BytesPerSecond/(1<<20), d.formatter.FormatDuration(run.Duration))
+			if i == result.Best {
+				line += " " + success("fastest")
+			}
+			fmt.Fprintln(d.output, line)
+		}
+	}
+
+	if len(results) > 1 {
+		fmt.Fprintf(d.output, "\n%s\n", magenta("Fastest per path:"))
+		for _, result := range results {
+			best := result.Runs[result.Best]
+			fmt.Fprintf(d.output, "  %s: %.1f MiB/s at --workers %d\n", result.Dir, best.BytesPerSecond/(1<<20), best.UsedWorkers)
+		}
+	}
+	fmt.Fprintln(d.output)
+}
+
+// ShowCrossSeedCheck displays whether content can be cross-seeded with a
+// torrent and, if not, why.
+func (d *Display) ShowCrossSeedCheck(result *CrossSeedCheckResult) {
+	fmt.Fprintf(d.output, "\n%s\n", magenta("Cross-seed check:"))
+	fmt.Fprintf(d.output, "  %-10s %s\n", label("Content:"), result.ContentPath)
+	if result.Compatible {
+		fmt.Fprintf(d.output, "  %-10s %s\n", label("Verdict:"), success("compatible"))
+		return
+	}
+	fmt.Fprintf(d.output, "  %-10s %s\n", label("Verdict:"), errorColor("incompatible"))
+	fmt.Fprintf(d.output, "  %-10s %s\n", label("Reason:"), result.Reason)
+}
+
+// ShowPiecesExtracted reports how many piece hashes were written and where.
+func (d *Display) ShowPiecesExtracted(count int, format, dest string) {
+	fmt.Fprintf(d.output, "\n%s\n", magenta("Extracted pieces:"))
+	fmt.Fprintf(d.output, "  %-15s %s\n", label("Pieces:"), success(count))
+	fmt.Fprintf(d.output, "  %-15s %s\n", label("Format:"), format)
+	fmt.Fprintf(d.output, "  %-15s %s\n", label("Output:"), dest)
+}
+
+// ShowModifyResults displays how many torrents were modified, skipped and failed.
+func (d *Display) ShowModifyResults(modified, skipped, failed int, duration time.Duration) {
+	fmt.Fprintf(d.output, "\n%s\n", magenta("Modify results:"))
+	fmt.Fprintf(d.output, "  %-15s %d\n", label("Total torrents:"), modified+skipped+failed)
+	fmt.Fprintf(d.output, "  %-15s %s\n", label("Modified:"), success(modified))
+	fmt.Fprintf(d.output, "  %-15s %s\n", label("Skipped:"), yellow(skipped))
+	fmt.Fprintf(d.output, "  %-15s %s\n", label("Failed:"), errorColor(failed))
+	fmt.Fprintf(d.output, "  %-15s %s\n", label("Processing time:"), d.formatter.FormatDuration(duration))
+}
+
+// ShowVerifyBatchResults displays the summary of a batch verification, including
+// the piece totals across all jobs.
+func (d *Display) ShowVerifyBatchResults(results []VerifyBatchResult, duration time.Duration) {
+	fmt.Fprintf(d.output, "\n%s\n", magenta("Batch verification results:"))
+
+	passed, failed, skipped := 0, 0, 0
+	var goodPieces, badPieces, missingPieces int
+	for _, result := range results {
+		switch {
+		case result.Skipped:
+			skipped++
+		case result.Failed():
+			failed++
+		default:
+			passed++
+		}
+		goodPieces += result.GoodPieces
+		badPieces += result.BadPieces
+		missingPieces += result.MissingPieces
+	}
+
+	fmt.Fprintf(d.output, "  %-16s %d\n", label("Total jobs:"), len(results))
+	fmt.Fprintf(d.output, "  %-16s %s\n", label("Passed:"), success(passed))
+	fmt.Fprintf(d.output, "  %-16s %s\n", label("Failed:"), errorColor(failed))
+	if skipped > 0 {
+		fmt.Fprintf(d.output, "  %-16s %s\n", label("Skipped:"), yellow(skipped))
+	}
+	fmt.Fprintf(d.output, "  %-16s %d\n", label("Good pieces:"), goodPieces)
+	fmt.Fprintf(d.output, "  %-16s %s\n", label("Bad pieces:"), errorColor(badPieces))
+	fmt.Fprintf(d.output, "  %-16s %s\n", label("Missing pieces:"), errorColor(missingPieces))
+	fmt.Fprintf(d.output, "  %-16s %s\n", label("Check time:"), d.formatter.FormatDuration(duration))
+
+	if failed > 0 {
+		fmt.Fprintf(d.output, "\n%s\n", magenta("Failed jobs:"))
+		for _, result := range results {
+			if !result.Failed() {
+				continue
+			}
+			if result.Error != nil {
+				fmt.Fprintf(d.output, "  %s: %s\n", result.TorrentPath, errorColor(result.Error))
+				continue
+			}
+			fmt.Fprintf(d.output, "  %s: %s\n", result.TorrentPath,
+				errorColor(fmt.Sprintf("%.2f%% complete, %d bad pieces, %d missing files",
+					result.Completion, result.BadPieces, len(result.MissingFiles))))
+		}
+	}
+
+	if d.formatter.verbose {
+		fmt.Fprintf(d.output, "\n%s\n", magenta("Detailed results:"))
+		for i, result := range results {
+			fmt.Fprintf(d.output, "\n%s %d:\n", label("Job"), i+1)
+			fmt.Fprintf(d.output, "  %-11s %s\n", label("Torrent:"), result.TorrentPath)
+			fmt.Fprintf(d.output, "  %-11s %s\n", label("Content:"), result.ContentPath)
+			switch {
+			case result.Skipped:
+				fmt.Fprintf(d.output, "  %-11s %s\n", label("Status:"), yellow("Skipped"))
+			case result.Error != nil:
+				fmt.Fprintf(d.output, "  %-11s %s\n", label("Status:"), errorColor("Failed"))
+				fmt.Fprintf(d.output, "  %-11s %v\n", label("Error:"), result.Error)
+			default:
+				status := success("OK")
+				if result.Failed() {
+					status = errorColor("Failed")
+				}
+				fmt.Fprintf(d.output, "  %-11s %s\n", label("Status:"), status)
+				fmt.Fprintf(d.output, "  %-11s %.2f%% (%d/%d pieces)\n", label("Completion:"),
+					result.Completion, result.GoodPieces, result.TotalPieces)
+			}
+		}
+	}
+}
+
+// Units selects how Formatter writes sizes.
+type Units int
+
+const (
+	UnitsBinary  Units = iota // powers of 1024: KiB, MiB, GiB
+	UnitsDecimal              // powers of 1000: kB, MB, GB
+)
+
+// defaultUnits are the units of formatters created by NewFormatter
+var defaultUnits = UnitsBinary
+
+// SetDefaultUnits sets the units of the formatters created by NewFormatter
+// from now on, including those created inside this package.
+func SetDefaultUnits(units Units) {
+	defaultUnits = units
+}
+
 type Formatter struct {
 	verbose bool
+	units   Units
 }
 
 func NewFormatter(verbose bool) *Formatter {
-	return &Formatter{verbose: verbose}
+	return &Formatter{verbose: verbose, units: defaultUnits}
+}
+
+// SetUnits sets the units f writes sizes in.
+func (f *Formatter) SetUnits(units Units) {
+	f.units = units
 }
 
 func (f *Formatter) FormatBytes(bytes int64) string {
+	if f.units == UnitsDecimal {
+		return humanize.Bytes(uint64(bytes))
+	}
 	return humanize.IBytes(uint64(bytes))
 }
 
+// FormatBytesExact formats bytes like FormatBytes followed by the exact byte
+// count, such as "1.0 GiB (1,073,741,824 bytes)". Sizes below one kilobyte are
+// exact already and formatted like FormatBytes.
+func (f *Formatter) FormatBytesExact(bytes int64) string {
+	if exact := f.exactBytes(bytes); exact != "" {
+		return fmt.Sprintf("%s (%s)", f.FormatBytes(bytes), exact)
+	}
+	return f.FormatBytes(bytes)
+}
+
+// formatSize formats the size of a file or torrent, exactly in verbose mode.
+func (f *Formatter) formatSize(bytes int64) string {
+	if f.verbose {
+		return f.FormatBytesExact(bytes)
+	}
+	return f.FormatBytes(bytes)
+}
+
+// exactBytes returns the byte count FormatBytes rounds away, such as
+// "1,073,741,824 bytes", or "" when FormatBytes writes it in bytes.
+func (f *Formatter) exactBytes(bytes int64) string {
+	base := int64(1024)
+	if f.units == UnitsDecimal {
+		base = 1000
+	}
+	if bytes < base {
+		return ""
+	}
+	return humanize.Comma(bytes) + " bytes"
+}
+
 func (f *Formatter) FormatDuration(dur time.Duration) string {
 	switch {
 	case dur < time.Second:
@@ -474,5 +986,41 @@ func (d *Display) ShowVerificationResult(result *VerificationResult, duration ti
 		}
 	}
 
+	if len(result.ExtraFiles) > 0 {
+		var extraSize int64
+		for _, file := range result.ExtraFiles {
+			extraSize += file.Size
+		}
+		fmt.Fprintf(d.output, "  %-15s %s (%s)\n", label("Extra files:"), yellow(len(result.ExtraFiles)), d.formatter.FormatBytes(extraSize))
+		maxFilesToShow := 10
+		if d.formatter.verbose {
+			maxFilesToShow = len(result.ExtraFiles)
+		}
+		for i, file := range result.ExtraFiles {
+			if i >= maxFilesToShow {
+				fmt.Fprintf(d.output, "    └─ ...and %d more\n", len(result.ExtraFiles)-maxFilesToShow)
+				break
+			}
+			prefix := "├─"
+			if i == len(result.ExtraFiles)-1 || i == maxFilesToShow-1 {
+				prefix = "└─"
+			}
+			fmt.Fprintf(d.output, "    %s %s (%s)\n", prefix, file.Path, d.formatter.FormatBytes(file.Size))
+		}
+	}
+
+	if len(result.RestoredAttrs) > 0 {
+		fmt.Fprintf(d.output, "  %-15s %d\n", label("Restored attrs:"), len(result.RestoredAttrs))
+		if d.formatter.verbose {
+			for i, file := range result.RestoredAttrs {
+				prefix := "├─"
+				if i == len(result.RestoredAttrs)-1 {
+					prefix = "└─"
+				}
+				fmt.Fprintf(d.output, "    %s %s\n", prefix, file)
+			}
+		}
+	}
+
 	fmt.Fprintf(d.output, "  %-15s %s\n", label("Check time:"), d.formatter.FormatDuration(duration))
 }
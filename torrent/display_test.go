@@ -2,6 +2,11 @@ package torrent
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -120,6 +125,238 @@ func TestShowFiles_QuietMode(t *testing.T) {
 	assert.Empty(t, output, "No output should be produced in quiet mode")
 }
 
+func TestShowSkippedFiles(t *testing.T) {
+	var buf bytes.Buffer
+	display := NewDisplay(NewFormatter(true))
+	display.output = &buf
+
+	display.ShowSkippedFiles([]skippedFile{
+		{path: filepath.Join("/test", "release.nfo"), reason: `exclude pattern "*.nfo"`},
+		{path: filepath.Join("/test", ".DS_Store"), reason: ignoreReasonBuiltin},
+	})
+
+	output := stripAnsiCodes(buf.String())
+	assert.Contains(t, output, "Skipped files (2):")
+	assert.Contains(t, output, filepath.Join("/test", "release.nfo")+` (exclude pattern "*.nfo")`)
+	assert.Contains(t, output, filepath.Join("/test", ".DS_Store")+" (built-in ignore)")
+
+	buf.Reset()
+	display.SetQuiet(true)
+	display.output = &buf
+	display.ShowSkippedFiles([]skippedFile{{path: "/test/file", reason: ignoreReasonInclude}})
+	assert.Empty(t, buf.String(), "No output should be produced in quiet mode")
+}
+
+func TestShowWarning_LogLevel(t *testing.T) {
+	t.Cleanup(func() { SetDefaultLogger(nil) })
+
+	var buf bytes.Buffer
+	display := NewDisplay(NewFormatter(false))
+	display.output = &buf
+
+	SetDefaultLogger(slog.New(NewLogHandler(io.Discard, slog.LevelWarn, false)))
+	display.ShowWarning("piece length differs")
+	assert.Contains(t, stripAnsiCodes(buf.String()), "Warning: piece length differs")
+
+	buf.Reset()
+	SetDefaultLogger(slog.New(NewLogHandler(io.Discard, slog.LevelError, false)))
+	display.ShowWarning("piece length differs")
+	assert.Empty(t, buf.String(), "--log-level error should hide warnings")
+}
+
+func TestProgressDescription(t *testing.T) {
+	// set by ShowProgress, which also draws the bar on the terminal
+	display := NewDisplay(NewFormatter(false))
+	display.total = 100
+	display.totalSize = 100 << 20
+
+	// 75 MiB left at 1 MiB/s
+	got := display.progressDescription(25, 1<<20, "", 0)
+	assert.Contains(t, got, "Hashing pieces...")
+	assert.Contains(t, got, "[1.0 MiB/s]")
+	assert.Contains(t, got, "[75 MiB left, ETA 1m 15s]")
+
+	assert.NotContains(t, display.progressDescription(100, 1<<20, "", 0), "ETA", "no ETA once every piece is done")
+
+	unknown := NewDisplay(NewFormatter(false))
+	assert.NotContains(t, unknown.progressDescription(25, 1<<20, "", 0), "ETA", "no ETA without a total size")
+
+	got = display.progressDescription(25, 1<<20, filepath.Join("Album", "01 - Track.flac"), 30<<20)
+	assert.Contains(t, got, "Hashing:[reset] 01 - Track.flac (30 MiB)")
+	assert.Contains(t, got, "[1.0 MiB/s]")
+
+	got = display.progressDescription(0, 0, "a.bin", 1<<10)
+	assert.Contains(t, got, "a.bin (1.0 KiB)")
+	assert.NotContains(t, got, "/s]", "no rate before anything is hashed")
+}
+
+func TestProgress_NotTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	display := NewDisplay(NewFormatter(false))
+	display.output = &buf
+
+	display.ShowProgress(200, 200<<20)
+	for completed := 1; completed <= 200; completed++ {
+		display.UpdateProgress(completed, 100<<20, "a.bin", 1<<20)
+	}
+	display.FinishProgress()
+
+	output := buf.String()
+	assert.NotContains(t, output, "\r", "no carriage returns when the output is not a terminal")
+	assert.Contains(t, output, "Hashing 200 pieces (200 MiB)")
+	assert.Contains(t, output, "hashed 20/200 pieces (10%), 100 MiB/s")
+	assert.Contains(t, output, "hashed 180/200 pieces (90%), 100 MiB/s")
+	assert.Contains(t, output, "hashed 200/200 pieces (100%)")
+	assert.Equal(t, 10, strings.Count(output, "hashed "), "a line every 10% and one when done")
+}
+
+func TestProgress_PlainForced(t *testing.T) {
+	origIsTerminal := isTerminal
+	isTerminal = func(io.Writer) bool { return true }
+	t.Cleanup(func() { isTerminal = origIsTerminal })
+
+	// a terminal gets the progress bar
+	var buf bytes.Buffer
+	display := NewDisplay(NewFormatter(false))
+	display.output = &buf
+	display.ShowProgress(10, 10<<20)
+	display.UpdateProgress(5, 1<<20, "", 0)
+	display.FinishProgress()
+	assert.Contains(t, buf.String(), "\r", "progress bar on a terminal")
+
+	// --no-progress prints lines even on a terminal
+	buf.Reset()
+	display = NewDisplay(NewFormatter(false))
+	display.output = &buf
+	display.SetPlainProgress(true)
+	display.ShowProgress(10, 10<<20)
+	display.UpdateProgress(5, 1<<20, "", 0)
+	display.FinishProgress()
+	assert.NotContains(t, buf.String(), "\r")
+	assert.Contains(t, buf.String(), "hashed 5/10 pieces (50%), 1.0 MiB/s")
+	assert.Contains(t, buf.String(), "hashed 10/10 pieces (100%)")
+}
+
+func TestShowFileTree_NestedPaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     *metainfo.Info
+		expected []string // Lines that should appear in output
+	}{
+		{
+			name: "Files with Screens subdirectory",
+			info: &metainfo.Info{
+				Name: "ShowName.S01E10",
+				Files: []metainfo.FileInfo{
+					{Path: []string{"Screens", "Screen0001.png"}, Length: 1024 * 1024},
+					{Path: []string{"Screens", "Screen0002.png"}, Length: 1024 * 1024},
+					{Path: []string{"ShowName.S01E10.mkv"}, Length: 500 * 1024 * 1024},
+					{Path: []string{"ShowName.S01E10.nfo"}, Length: 5 * 1024},
+				},
+			},
+			expected: []string{
+				"File tree:",
+				"└─ ShowName.S01E10 (502 MiB)",
+				"  ├─ Screens (2.0 MiB)",
+				"  │ ├─ Screen0001.png (1.0 MiB)",
+				"  │ └─ Screen0002.png (1.0 MiB)",
+				"  ├─ ShowName.S01E10.mkv (500 MiB)",
+				"  └─ ShowName.S01E10.nfo (5.0 KiB)",
+			},
+		},
+		{
+			name: "Deeply nested directories",
+			info: &metainfo.Info{
+				Name: "Show",
+				Files: []metainfo.FileInfo{
+					{Path: []string{"Season 02", "Show.S02E01.mkv"}, Length: 300 * 1024 * 1024},
+					{Path: []string{"Season 01", "Extras", "Featurette.mkv"}, Length: 100 * 1024 * 1024},
+					{Path: []string{"Season 01", "Show.S01E01.mkv"}, Length: 400 * 1024 * 1024},
+				},
+			},
+			expected: []string{
+				"└─ Show (800 MiB)",
+				"  ├─ Season 01 (500 MiB)",
+				"  │ ├─ Extras (100 MiB)",
+				"  │ │ └─ Featurette.mkv (100 MiB)",
+				"  │ └─ Show.S01E01.mkv (400 MiB)",
+				"  └─ Season 02 (300 MiB)",
+				"    └─ Show.S02E01.mkv (300 MiB)",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			display := NewDisplay(NewFormatter(false))
+			display.output = &buf
+
+			display.ShowFileTree(tc.info)
+
+			cleanOutput := stripAnsiCodes(buf.String())
+			for _, expectedLine := range tc.expected {
+				assert.Contains(t, cleanOutput, expectedLine+"\n",
+					"Output should contain line: %s", expectedLine)
+			}
+		})
+	}
+}
+
+func TestShowFileTree_VerboseExactSizes(t *testing.T) {
+	var buf bytes.Buffer
+	display := NewDisplay(NewFormatter(true))
+	display.output = &buf
+
+	display.ShowFileTree(&metainfo.Info{
+		Name: "Release",
+		Files: []metainfo.FileInfo{
+			{Path: []string{"release.mkv"}, Length: 1<<30 + 1},
+			{Path: []string{"release.nfo"}, Length: 512},
+		},
+	})
+
+	cleanOutput := stripAnsiCodes(buf.String())
+	assert.Contains(t, cleanOutput, "└─ Release (1.0 GiB, 1,073,742,337 bytes)\n")
+	assert.Contains(t, cleanOutput, "├─ release.mkv (1.0 GiB, 1,073,741,825 bytes)\n")
+	assert.Contains(t, cleanOutput, "└─ release.nfo (512 B)\n")
+}
+
+func TestFormatter_Units(t *testing.T) {
+	tests := []struct {
+		bytes        int64
+		binary       string
+		binaryExact  string
+		decimal      string
+		decimalExact string
+	}{
+		{999, "999 B", "999 B", "999 B", "999 B"},
+		{1000, "1000 B", "1000 B", "1.0 kB", "1.0 kB (1,000 bytes)"},
+		{1023, "1023 B", "1023 B", "1.0 kB", "1.0 kB (1,023 bytes)"},
+		{1024, "1.0 KiB", "1.0 KiB (1,024 bytes)", "1.0 kB", "1.0 kB (1,024 bytes)"},
+		{1<<30 - 1, "1024 MiB", "1024 MiB (1,073,741,823 bytes)", "1.1 GB", "1.1 GB (1,073,741,823 bytes)"},
+		{1 << 30, "1.0 GiB", "1.0 GiB (1,073,741,824 bytes)", "1.1 GB", "1.1 GB (1,073,741,824 bytes)"},
+		{1<<30 + 1, "1.0 GiB", "1.0 GiB (1,073,741,825 bytes)", "1.1 GB", "1.1 GB (1,073,741,825 bytes)"},
+	}
+
+	binary := NewFormatter(false)
+	decimal := NewFormatter(false)
+	decimal.SetUnits(UnitsDecimal)
+	for _, tc := range tests {
+		assert.Equal(t, tc.binary, binary.FormatBytes(tc.bytes), "binary FormatBytes(%d)", tc.bytes)
+		assert.Equal(t, tc.binaryExact, binary.FormatBytesExact(tc.bytes), "binary FormatBytesExact(%d)", tc.bytes)
+		assert.Equal(t, tc.decimal, decimal.FormatBytes(tc.bytes), "decimal FormatBytes(%d)", tc.bytes)
+		assert.Equal(t, tc.decimalExact, decimal.FormatBytesExact(tc.bytes), "decimal FormatBytesExact(%d)", tc.bytes)
+	}
+}
+
+func TestSetDefaultUnits(t *testing.T) {
+	SetDefaultUnits(UnitsDecimal)
+	t.Cleanup(func() { SetDefaultUnits(UnitsBinary) })
+
+	assert.Equal(t, "1.0 MB", NewFormatter(false).FormatBytes(1000*1000))
+}
+
 // Helper function to create a properly initialized torrent with InfoBytes
 func createTestTorrent(metaInfo *metainfo.MetaInfo, info *metainfo.Info) (*Torrent, error) {
 	// Marshal the info to get InfoBytes
@@ -395,3 +632,57 @@ func stripAnsiCodes(s string) string {
 	}
 	return s
 }
+
+func TestShowTorrentInfo_SHA256InfoHash(t *testing.T) {
+	tmpDir := t.TempDir()
+	contentPath := filepath.Join(tmpDir, "content.bin")
+	if err := os.WriteFile(contentPath, bytes.Repeat([]byte("x"), 1<<16), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+
+	result, err := Create(CreateOptions{
+		Path:        contentPath,
+		OutputPath:  filepath.Join(tmpDir, "content.torrent"),
+		TrackerURLs: []string{"https://tracker.example.com/announce"},
+		Quiet:       true,
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	tor, err := LoadFromFile(result.Path)
+	if err != nil {
+		t.Fatalf("failed to load torrent: %v", err)
+	}
+	sum := sha256.Sum256(tor.InfoBytes)
+	want := hex.EncodeToString(sum[:])
+	assert.Equal(t, want, result.InfoHashSHA256)
+
+	info, err := tor.UnmarshalInfo()
+	if err != nil {
+		t.Fatalf("failed to unmarshal info: %v", err)
+	}
+
+	for _, verbose := range []bool{false, true} {
+		var buf bytes.Buffer
+		display := NewDisplay(NewFormatter(verbose))
+		display.output = &buf
+		display.ShowTorrentInfo(tor, &info)
+
+		output := stripAnsiCodes(buf.String())
+		if verbose {
+			assert.Contains(t, output, "InfoHash (v2 addr): "+want)
+		} else {
+			assert.NotContains(t, output, want, "the SHA-256 hash is only shown in verbose mode")
+		}
+		assert.NotContains(t, output, "urn:btmh:", "the displayed magnet link stays v1 only")
+	}
+
+	magnet, err := tor.MagnetWithSHA256()
+	if err != nil {
+		t.Fatalf("MagnetWithSHA256 failed: %v", err)
+	}
+	link := magnet.String()
+	assert.Contains(t, link, "xt=urn:btih:"+result.InfoHash)
+	assert.Contains(t, link, "xt=urn:btmh:1220"+want)
+}
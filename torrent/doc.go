@@ -0,0 +1,42 @@
+// Package torrent creates, inspects, modifies and verifies .torrent files. It
+// is the only implementation behind the mkbrr commands and can be imported on
+// its own.
+//
+// Creating torrents:
+//   - Create writes a .torrent file for the content at CreateOptions.Path and
+//     CreateTorrent returns the Torrent without writing it. TorrentBuilder sets
+//     the same options through method chaining.
+//   - CreateFromFS and CreateTorrentFromFS read the content from an io/fs.FS.
+//   - EstimateTorrent reports the piece length, piece count and torrent size
+//     CreateTorrent would use, without hashing.
+//   - CreateSet and CreateTorrentSet create the torrents of the same content
+//     for several trackers, hashing it once for each piece length.
+//   - ProcessBatch creates the torrents of a batch YAML file.
+//
+// Changing existing torrents:
+//   - ModifyTorrent and ProcessTorrents change trackers, source, comment and
+//     other metadata, and can change the info hash.
+//   - CrossSeed writes a copy for another tracker after verifying the content,
+//     and CheckCrossSeed only reports whether content can be cross-seeded.
+//   - Repiece re-hashes the content of a torrent at a new piece length.
+//   - ConvertTorrent normalizes the metadata outside the info dictionary.
+//
+// Checking torrents and content:
+//   - VerifyData checks content against a torrent and ProcessVerifyBatch does
+//     so for a batch file. WriteVerifyReport writes a result as JSON or CSV.
+//   - WritePiecesExport archives the piece hashes and file layout of a
+//     torrent, and VerifyData can check content against that export instead.
+//   - ValidateTorrent runs sanity checks against a possibly corrupt or
+//     tampered torrent, and LintTorrent checks the raw bencode of a torrent
+//     for structural problems and can fix some of them.
+//   - FormatInspect prints selected InspectFields of a torrent through a
+//     template from ParseInspectFormat.
+//   - AnalyzeSeasonPack looks for missing episodes in a season pack, which
+//     creation also returns as Torrent.SeasonPack, and ComputePieceStats
+//     describes how files line up with pieces.
+//   - Bench measures the hashing throughput of a directory per worker count.
+//
+// Progress is drawn by Display unless a ProgressCallback is set, and
+// diagnostic messages go to the Logger of the options, or the logger set with
+// SetDefaultLogger.
+package torrent
@@ -0,0 +1,65 @@
+package torrent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anacrolix/torrent/bencode"
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// infoDictOverhead covers the keys of the info dictionary other than the file
+// list: name, piece length, pieces, private and their bencoding
+const infoDictOverhead = 80
+
+// estimateTorrentSize approximates the size of the .torrent file in bytes
+// before hashing: 20 bytes per piece plus the metadata overhead.
+func estimateTorrentSize(totalSize int64, pieceExp uint, overhead int64) int64 {
+	pieceLen := int64(1) << pieceExp
+	numPieces := (totalSize + pieceLen - 1) / pieceLen
+	return numPieces*pieceHashSize + overhead
+}
+
+// estimateMetadataSize approximates the bencoded size of everything in the
+// torrent except the piece hashes. mi holds the root fields set so far, and
+// relPaths the slash-separated file paths, or nil for a single-file torrent.
+func estimateMetadataSize(mi *metainfo.MetaInfo, opts CreateOptions, name string, relPaths []string) int64 {
+	var size int64
+	if root, err := bencode.Marshal(mi); err == nil {
+		size += int64(len(root))
+	}
+
+	size += infoDictOverhead + int64(len(name)+len(opts.Source))
+	for _, seed := range opts.WebSeeds {
+		size += int64(len(seed)) + 4
+	}
+	for _, seed := range opts.HTTPSeeds {
+		size += int64(len(seed)) + 4
+	}
+	for key, value := range opts.ExtraRootFields {
+		size += int64(len(key)+len(value)) + 8
+	}
+
+	// each entry is d6:lengthi<n>e4:pathl<components>ee
+	for _, p := range relPaths {
+		slashes := strings.Count(p, "/")
+		size += int64(len(p)-slashes) + int64(slashes+1)*3 + 24
+	}
+	return size
+}
+
+// minPieceExpForSize returns the smallest piece length exponent between
+// minExp and maxExp whose estimated torrent size fits in maxSize.
+func minPieceExpForSize(totalSize, overhead int64, maxSize uint64, minExp, maxExp uint) (uint, bool) {
+	for exp := minExp; exp <= maxExp; exp++ {
+		if uint64(estimateTorrentSize(totalSize, exp, overhead)) <= maxSize {
+			return exp, true
+		}
+	}
+	return 0, false
+}
+
+// formatKiB formats a byte count as KiB with one decimal, like the size limit messages.
+func formatKiB(size int64) string {
+	return fmt.Sprintf("%.1f KiB", float64(size)/(1<<10))
+}
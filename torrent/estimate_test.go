@@ -0,0 +1,194 @@
+package torrent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+func TestEstimateTorrentSize_MatchesCreated(t *testing.T) {
+	tmpDir := t.TempDir()
+	contentDir := filepath.Join(tmpDir, "Some.Release.Name")
+	var relPaths []string
+	for i := range 40 {
+		rel := fmt.Sprintf("Season 01/Some.Release.Name.E%02d.mkv", i+1)
+		path := filepath.Join(contentDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, make([]byte, 100<<10), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", rel, err)
+		}
+		relPaths = append(relPaths, rel)
+	}
+
+	pieceExp := uint(16)
+	opts := CreateOptions{
+		Path:           contentDir,
+		TrackerURLs:    []string{"https://tracker.example/announce/0123456789abcdef", "udp://backup.example:1337/announce"},
+		WebSeeds:       []string{"https://seed.example/files/"},
+		Comment:        "estimate test",
+		Source:         "EXAMPLE",
+		PieceLengthExp: &pieceExp,
+		Version:        "test",
+		IsPrivate:      true,
+		Quiet:          true,
+	}
+	tor, err := CreateTorrent(opts)
+	if err != nil {
+		t.Fatalf("CreateTorrent failed: %v", err)
+	}
+	data, err := tor.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	root := &metainfo.MetaInfo{
+		Announce:     tor.Announce,
+		AnnounceList: tor.AnnounceList,
+		Comment:      tor.Comment,
+		CreatedBy:    tor.CreatedBy,
+		CreationDate: tor.CreationDate,
+	}
+	overhead := estimateMetadataSize(root, opts, "Some.Release.Name", relPaths)
+	estimate := estimateTorrentSize(40*(100<<10), pieceExp, overhead)
+
+	// the estimate only has to be close enough to catch sizes clearly over a limit
+	if diff := float64(estimate-int64(len(data))) / float64(len(data)); diff < -0.05 || diff > 0.05 {
+		t.Errorf("estimated %d bytes, created torrent has %d bytes", estimate, len(data))
+	}
+}
+
+func TestCreateTorrent_ForcedPieceLengthOverTrackerLimit(t *testing.T) {
+	contentPath := filepath.Join(t.TempDir(), "large.mkv")
+	f, err := os.Create(contentPath)
+	if err != nil {
+		t.Fatalf("failed to create content: %v", err)
+	}
+	// sparse, so no disk space is used; hashing it would take a while
+	if err := f.Truncate(1 << 30); err != nil {
+		t.Fatalf("failed to size content: %v", err)
+	}
+	f.Close()
+
+	// 1 GiB in 64 KiB pieces needs 16384 hashes, 320 KiB, over ANT's 250 KiB limit
+	pieceExp := uint(16)
+	_, err = CreateTorrent(CreateOptions{
+		Path:           contentPath,
+		TrackerURLs:    []string{"https://anthelion.me/announce/0123456789abcdef"},
+		PieceLengthExp: &pieceExp,
+		Quiet:          true,
+	})
+	if err == nil {
+		t.Fatal("expected the forced piece length to be rejected before hashing")
+	}
+	for _, want := range []string{"over the tracker limit of 250.0 KiB", "at least 17 (128 KiB)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q, got %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "0123456789abcdef") {
+		t.Errorf("error must not include the passkey: %v", err)
+	}
+}
+
+func TestMinPieceExpForSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		totalSize int64
+		maxSize   uint64
+		maxExp    uint
+		wantExp   uint
+		wantOK    bool
+	}{
+		{name: "one step up", totalSize: 1 << 30, maxSize: 250 << 10, maxExp: 27, wantExp: 17, wantOK: true},
+		{name: "needs larger pieces", totalSize: 100 << 30, maxSize: 250 << 10, maxExp: 27, wantExp: 24, wantOK: true},
+		{name: "capped by max exponent", totalSize: 100 << 30, maxSize: 250 << 10, maxExp: 22, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exp, ok := minPieceExpForSize(tt.totalSize, 1000, tt.maxSize, 16, tt.maxExp)
+			if ok != tt.wantOK || (ok && exp != tt.wantExp) {
+				t.Errorf("minPieceExpForSize() = %d, %v, want %d, %v", exp, ok, tt.wantExp, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestEstimateTorrent_MatchesCreated(t *testing.T) {
+	contentDir := filepath.Join(t.TempDir(), "Release")
+	for name, size := range map[string]int{
+		"a.mkv":        300 << 10,
+		"sub/b.mkv":    150 << 10,
+		"sub/c.srt":    2 << 10,
+		"notes.nfo":    100,
+		"Sample/s.mkv": 50 << 10,
+	} {
+		path := filepath.Join(contentDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		opts CreateOptions
+	}{
+		{name: "automatic", opts: CreateOptions{}},
+		{name: "filtered", opts: CreateOptions{ExcludePatterns: []string{"*.nfo"}, ExcludeDirs: []string{"Sample"}}},
+		{name: "tracker", opts: CreateOptions{TrackerURLs: []string{"https://empornium.sx/announce?passkey=123"}}},
+		{name: "piece length", opts: CreateOptions{PieceLengthExp: uintPtr(17)}},
+		{name: "target pieces", opts: CreateOptions{TargetPieceCount: uintPtr(4)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.Path = contentDir
+			opts.Quiet = true
+
+			estimate, err := EstimateTorrent(opts)
+			if err != nil {
+				t.Fatalf("EstimateTorrent failed: %v", err)
+			}
+			tor, err := CreateTorrent(opts)
+			if err != nil {
+				t.Fatalf("CreateTorrent failed: %v", err)
+			}
+			info := tor.GetInfo()
+
+			files := len(info.Files)
+			if files == 0 {
+				files = 1
+			}
+			if estimate.Files != files {
+				t.Errorf("Files = %d, want %d", estimate.Files, files)
+			}
+			if estimate.TotalSize != info.TotalLength() {
+				t.Errorf("TotalSize = %d, want %d", estimate.TotalSize, info.TotalLength())
+			}
+			if estimate.PieceLength != info.PieceLength || int64(1)<<estimate.PieceLengthExp != info.PieceLength {
+				t.Errorf("PieceLength = %d (2^%d), want %d", estimate.PieceLength, estimate.PieceLengthExp, info.PieceLength)
+			}
+			if estimate.Pieces != int64(info.NumPieces()) {
+				t.Errorf("Pieces = %d, want %d", estimate.Pieces, info.NumPieces())
+			}
+
+			data, err := tor.Marshal()
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if diff := float64(estimate.TorrentSize-int64(len(data))) / float64(len(data)); diff < -0.05 || diff > 0.05 {
+				t.Errorf("TorrentSize = %d, created torrent has %d bytes", estimate.TorrentSize, len(data))
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package torrent
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// BEP 47 file attributes recorded by CreateOptions.PreserveAttrs
+const (
+	attrExecutable = "x"
+	attrHidden     = "h"
+	attrPadding    = "p"
+)
+
+// fileAttr returns the BEP 47 attr string of a file with the given mode,
+// "x" when any execute bit is set and "h" when hidden, or "" for neither.
+func fileAttr(mode fs.FileMode, hidden bool) string {
+	var attr string
+	if mode&0111 != 0 {
+		attr += attrExecutable
+	}
+	if hidden {
+		attr += attrHidden
+	}
+	return attr
+}
+
+// fileAttrs returns the attr strings of files. The mode is read from the file
+// itself, following symlinks, while hidden is decided by originalPaths, the
+// path the file has in the torrent. Files of fsys are hidden by name only.
+func fileAttrs(files []fileEntry, originalPaths map[string]string, fsys fs.FS) ([]string, error) {
+	attrs := make([]string, len(files))
+	for i, f := range files {
+		originalPath := originalPaths[f.path]
+		if originalPath == "" {
+			originalPath = f.path
+		}
+
+		var info fs.FileInfo
+		var err error
+		if fsys != nil {
+			info, err = fs.Stat(fsys, f.path)
+		} else {
+			info, err = os.Stat(longPath(f.path))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		hidden := strings.HasPrefix(filepath.Base(originalPath), ".")
+		if fsys == nil {
+			hidden = hidden || isHiddenFile(longPath(originalPath))
+		}
+		attrs[i] = fileAttr(info.Mode(), hidden)
+	}
+	return attrs, nil
+}
+
+// hasAttr reports whether the BEP 47 attr string contains flag.
+func hasAttr(attr, flag string) bool {
+	return strings.Contains(attr, flag)
+}
+
+// restoreExecutableAttrs sets the execute bits of the verified files the
+// torrent marks executable, for each read bit that is set. Files with a bad
+// or unchecked piece are left alone, as is everything on Windows, which has
+// no execute bits. It returns the torrent paths of the files it changed.
+func (v *pieceVerifier) restoreExecutableAttrs(baseContentPath string) ([]string, error) {
+	if runtime.GOOS == "windows" {
+		return nil, nil
+	}
+
+	attrs := make(map[string]string)
+	if v.torrentInfo.IsDir() {
+		for _, f := range v.torrentInfo.Files {
+			attrs[torrentFilePath(f.Path)] = f.Attr
+		}
+	}
+
+	bad := v.badPieceSet()
+
+	var restored []string
+	for _, f := range v.files {
+		name := v.torrentInfo.Name
+		attr := v.torrentInfo.Attr
+		if v.torrentInfo.IsDir() {
+			rel, err := filepath.Rel(baseContentPath, f.path)
+			if err != nil {
+				return restored, fmt.Errorf("failed to get relative path for %q: %w", f.path, err)
+			}
+			name = filepath.ToSlash(rel)
+			attr = attrs[name]
+		}
+		if !hasAttr(attr, attrExecutable) {
+			continue
+		}
+		if status, _ := v.fileStatus(f.offset, f.length, bad); status != FileStatusOK {
+			continue
+		}
+
+		stat, err := os.Stat(longPath(f.path))
+		if err != nil {
+			return restored, fmt.Errorf("could not stat %q: %w", f.path, err)
+		}
+		mode := stat.Mode().Perm()
+		if mode&0111 != 0 {
+			continue
+		}
+		if err := os.Chmod(longPath(f.path), mode|(mode&0444)>>2); err != nil {
+			return restored, fmt.Errorf("could not restore attributes of %q: %w", f.path, err)
+		}
+		restored = append(restored, name)
+	}
+	return restored, nil
+}
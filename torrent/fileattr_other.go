@@ -0,0 +1,9 @@
+//go:build !windows
+
+package torrent
+
+// isHiddenFile reports false, as files are only hidden by a leading dot
+// outside Windows.
+func isHiddenFile(path string) bool {
+	return false
+}
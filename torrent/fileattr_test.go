@@ -0,0 +1,176 @@
+package torrent
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/anacrolix/torrent/bencode"
+)
+
+// writeAttrFixture creates a release with an executable, a plain file and a
+// dotfile, returning the content directory.
+func writeAttrFixture(t *testing.T) string {
+	t.Helper()
+
+	contentDir := filepath.Join(t.TempDir(), "Release")
+	if err := os.MkdirAll(contentDir, 0755); err != nil {
+		t.Fatalf("failed to create content dir: %v", err)
+	}
+	files := map[string]os.FileMode{
+		"install.sh":  0755,
+		"release.nfo": 0644,
+		".hidden":     0644,
+	}
+	for name, mode := range files {
+		path := filepath.Join(contentDir, name)
+		if err := os.WriteFile(path, []byte(name), mode); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		// the umask may have dropped bits
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatalf("failed to chmod %s: %v", name, err)
+		}
+	}
+	return contentDir
+}
+
+// rawFileAttrs returns the attr of each file in the torrent at path, keyed by
+// its first path component.
+func rawFileAttrs(t *testing.T, path string) map[string]string {
+	t.Helper()
+
+	_, info := rawDicts(t, path)
+	var files []struct {
+		Path []string `bencode:"path"`
+		Attr string   `bencode:"attr,omitempty"`
+	}
+	if err := bencode.Unmarshal(info["files"], &files); err != nil {
+		t.Fatalf("failed to decode files: %v", err)
+	}
+	attrs := make(map[string]string, len(files))
+	for _, f := range files {
+		attrs[f.Path[0]] = f.Attr
+	}
+	return attrs
+}
+
+func TestCreate_PreserveAttrs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("execute bits are not available on windows")
+	}
+	contentDir := writeAttrFixture(t)
+
+	torrentPath := filepath.Join(t.TempDir(), "attrs.torrent")
+	if _, err := Create(CreateOptions{Path: contentDir, OutputPath: torrentPath, PreserveAttrs: true, Quiet: true}); err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+	want := map[string]string{"install.sh": "x", "release.nfo": "", ".hidden": "h"}
+	got := rawFileAttrs(t, torrentPath)
+	for name, attr := range want {
+		if got[name] != attr {
+			t.Errorf("attr of %s = %q, want %q", name, got[name], attr)
+		}
+	}
+
+	plainPath := filepath.Join(t.TempDir(), "plain.torrent")
+	if _, err := Create(CreateOptions{Path: contentDir, OutputPath: plainPath, Quiet: true}); err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+	for name, attr := range rawFileAttrs(t, plainPath) {
+		if attr != "" {
+			t.Errorf("attr of %s = %q without PreserveAttrs, want none", name, attr)
+		}
+	}
+}
+
+func TestCreate_PreserveAttrsSingleFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("execute bits are not available on windows")
+	}
+	contentDir := writeAttrFixture(t)
+
+	torrentPath := filepath.Join(t.TempDir(), "single.torrent")
+	if _, err := Create(CreateOptions{Path: filepath.Join(contentDir, "install.sh"), OutputPath: torrentPath, PreserveAttrs: true, Quiet: true}); err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+	_, info := rawDicts(t, torrentPath)
+	if string(info["attr"]) != "1:x" {
+		t.Errorf("attr = %s, want 1:x", info["attr"])
+	}
+}
+
+func TestVerifyData_RestoreAttrs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("execute bits are not available on windows")
+	}
+	contentDir := writeAttrFixture(t)
+
+	torrentPath := filepath.Join(t.TempDir(), "attrs.torrent")
+	if _, err := Create(CreateOptions{Path: contentDir, OutputPath: torrentPath, PreserveAttrs: true, Quiet: true}); err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+	script := filepath.Join(contentDir, "install.sh")
+	if err := os.Chmod(script, 0644); err != nil {
+		t.Fatalf("failed to chmod: %v", err)
+	}
+
+	result, err := VerifyData(VerifyOptions{TorrentPath: torrentPath, ContentPath: contentDir, Quiet: true})
+	if err != nil {
+		t.Fatalf("VerifyData failed: %v", err)
+	}
+	if len(result.RestoredAttrs) != 0 {
+		t.Fatalf("RestoredAttrs = %v without RestoreAttrs", result.RestoredAttrs)
+	}
+
+	result, err = VerifyData(VerifyOptions{TorrentPath: torrentPath, ContentPath: contentDir, Quiet: true, RestoreAttrs: true})
+	if err != nil {
+		t.Fatalf("VerifyData failed: %v", err)
+	}
+	if len(result.RestoredAttrs) != 1 || result.RestoredAttrs[0] != "install.sh" {
+		t.Fatalf("RestoredAttrs = %v, want [install.sh]", result.RestoredAttrs)
+	}
+	info, err := os.Stat(script)
+	if err != nil {
+		t.Fatalf("failed to stat: %v", err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("mode = %v, want 0755", info.Mode().Perm())
+	}
+	info, err = os.Stat(filepath.Join(contentDir, "release.nfo"))
+	if err != nil {
+		t.Fatalf("failed to stat: %v", err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("mode of release.nfo = %v, want 0644", info.Mode().Perm())
+	}
+}
+
+func TestVerifyData_RestoreAttrsSkipsBadFiles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("execute bits are not available on windows")
+	}
+	contentDir := writeAttrFixture(t)
+
+	torrentPath := filepath.Join(t.TempDir(), "attrs.torrent")
+	if _, err := Create(CreateOptions{Path: contentDir, OutputPath: torrentPath, PreserveAttrs: true, Quiet: true}); err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+	script := filepath.Join(contentDir, "install.sh")
+	// same size, different content
+	if err := os.WriteFile(script, []byte("uninstall!"), 0644); err != nil {
+		t.Fatalf("failed to rewrite: %v", err)
+	}
+	if err := os.Chmod(script, 0644); err != nil {
+		t.Fatalf("failed to chmod: %v", err)
+	}
+
+	result, err := VerifyData(VerifyOptions{TorrentPath: torrentPath, ContentPath: contentDir, Quiet: true, RestoreAttrs: true})
+	if err != nil {
+		t.Fatalf("VerifyData failed: %v", err)
+	}
+	if len(result.RestoredAttrs) != 0 {
+		t.Errorf("RestoredAttrs = %v, want none for a bad file", result.RestoredAttrs)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+package torrent
+
+import "golang.org/x/sys/windows"
+
+// isHiddenFile reports whether the hidden attribute is set on the file at path.
+func isHiddenFile(path string) bool {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return false
+	}
+	attrs, err := windows.GetFileAttributes(pathPtr)
+	if err != nil {
+		return false
+	}
+	return attrs&windows.FILE_ATTRIBUTE_HIDDEN != 0
+}
@@ -0,0 +1,126 @@
+package torrent
+
+import (
+	"fmt"
+	"io/fs"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+)
+
+// fat32MaxFileSize is the largest file a FAT32 filesystem can store (4 GiB - 1 byte)
+const fat32MaxFileSize = 1<<32 - 1
+
+// where holes cannot be found directly, a file counts as sparse when at least
+// sparseMinHole bytes and sparseMinHoleRatio of its logical size are not
+// allocated on disk
+const (
+	sparseMinHole      = 1 << 20
+	sparseMinHoleRatio = 0.1
+)
+
+// defaultMinVideoSize is the size below which a video file is reported as a
+// likely stub when CreateOptions.MinVideoSize is zero
+const defaultMinVideoSize = 1 << 20
+
+// isSparse reports whether a file of size bytes with allocated bytes on disk has
+// holes large enough to matter.
+func isSparse(size, allocated int64) bool {
+	hole := size - allocated
+	return hole >= sparseMinHole && float64(hole) >= float64(size)*sparseMinHoleRatio
+}
+
+// sparseFile reports whether the file at path, of size bytes, has holes and
+// how many bytes of it are allocated on disk. A hole before the end found with
+// SEEK_HOLE decides; the allocated size is only the fallback where the
+// platform or filesystem cannot seek to holes.
+func sparseFile(path string, size int64) (bool, int64) {
+	allocated, ok := allocatedSize(path)
+	if hole, found := firstHole(path); found {
+		return hole < size, allocated
+	}
+	return ok && isSparse(size, allocated), allocated
+}
+
+// ParseMinVideoSize parses a human-readable size such as "1MiB" or "500KB"
+// for CreateOptions.MinVideoSize. "0" disables the check and is returned as -1.
+func ParseMinVideoSize(s string) (int64, error) {
+	size, err := humanize.ParseBytes(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid minimum video size %q: %w", s, err)
+	}
+	if size == 0 {
+		return -1, nil
+	}
+	if size > math.MaxInt64 {
+		return 0, fmt.Errorf("invalid minimum video size %q: too large", s)
+	}
+	return int64(size), nil
+}
+
+// checkContent reports files that point to a bad copy: empty files, and video
+// files smaller than minVideoSize (defaultMinVideoSize when zero, no size check
+// when negative). Small files of other types such as nfo or srt are expected.
+func checkContent(files []fileEntry, minVideoSize int64) []string {
+	if minVideoSize == 0 {
+		minVideoSize = defaultMinVideoSize
+	}
+
+	var problems []string
+	for _, f := range files {
+		switch {
+		case f.length == 0:
+			problems = append(problems, fmt.Sprintf("%s is empty (0 bytes), the copy may be incomplete", f.path))
+		case minVideoSize > 0 && f.length < minVideoSize && videoExtensions[strings.ToLower(filepath.Ext(f.path))]:
+			problems = append(problems, fmt.Sprintf("%s is only %s, smaller than expected for a video file (%s), it may be a stub",
+				f.path, humanize.IBytes(uint64(f.length)), humanize.IBytes(uint64(minVideoSize))))
+		}
+	}
+	return problems
+}
+
+// checkFiles runs the pre-hash checks on the files of a torrent: files too large
+// for FAT32 when opts.FAT32Check is set, sparse files whose holes would be
+// hashed as zeros, and empty or stub content files. Findings are shown as
+// warnings and returned, or returned as an error when opts.StrictFileChecks
+// or, for content findings, opts.StrictContent is set. Sparse files are only
+// detected for files on the OS filesystem, when fsys is nil.
+func checkFiles(files []fileEntry, opts CreateOptions, fsys fs.FS, display *Display) ([]string, error) {
+	var problems []string
+
+	if opts.FAT32Check {
+		for _, f := range files {
+			if f.length > fat32MaxFileSize {
+				problems = append(problems, fmt.Sprintf("%s is %s, larger than FAT32 supports (4 GiB), downloads to FAT32 drives will fail",
+					f.path, humanize.IBytes(uint64(f.length))))
+			}
+		}
+	}
+
+	// placeholder pieces never read the files, so holes do not matter
+	if !opts.SkipHashing && fsys == nil {
+		for _, f := range files {
+			if sparse, allocated := sparseFile(f.path, f.length); sparse {
+				problems = append(problems, fmt.Sprintf("%s is sparse (%s of %s allocated), hashed content may include holes read as zeros",
+					f.path, humanize.IBytes(uint64(allocated)), humanize.IBytes(uint64(f.length))))
+			}
+		}
+	}
+
+	if opts.StrictFileChecks && len(problems) > 0 {
+		return nil, fmt.Errorf("file checks failed: %s", strings.Join(problems, "; "))
+	}
+
+	content := checkContent(files, opts.MinVideoSize)
+	if opts.StrictContent && len(content) > 0 {
+		return nil, fmt.Errorf("content checks failed: %s", strings.Join(content, "; "))
+	}
+
+	problems = append(problems, content...)
+	for _, problem := range problems {
+		display.ShowWarning(problem)
+	}
+	return problems, nil
+}
@@ -0,0 +1,14 @@
+//go:build unix && !(linux || darwin || freebsd)
+
+package torrent
+
+import (
+	"errors"
+	"os"
+)
+
+// seekHole is not available on this platform, so only the allocated size
+// tells sparse files apart.
+func seekHole(f *os.File) (int64, error) {
+	return 0, errors.ErrUnsupported
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package torrent
+
+// allocatedSize is not available on this platform, so sparse files are not detected.
+func allocatedSize(path string) (int64, bool) {
+	return 0, false
+}
+
+// firstHole is not available on this platform, so sparse files are not detected.
+func firstHole(path string) (int64, bool) {
+	return 0, false
+}
@@ -0,0 +1,14 @@
+//go:build linux || darwin || freebsd
+
+package torrent
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// seekHole returns the offset of the first hole in f, or its size when it has none.
+func seekHole(f *os.File) (int64, error) {
+	return unix.Seek(int(f.Fd()), 0, unix.SEEK_HOLE)
+}
@@ -0,0 +1,279 @@
+package torrent
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// createSparseFile creates a file of the given size without writing any data,
+// skipping the test when the filesystem does not report it as sparse.
+func createSparseFile(t *testing.T, path string, size int64) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	if err := f.Truncate(size); err != nil {
+		f.Close()
+		t.Fatalf("failed to truncate file: %v", err)
+	}
+	f.Close()
+
+	if sparse, _ := sparseFile(path, size); !sparse {
+		t.Skip("sparse files are not supported on this platform or filesystem")
+	}
+}
+
+func TestSparseFile_SeekHole(t *testing.T) {
+	dir := t.TempDir()
+
+	// a hole of 1 MiB after 16 MiB of data is too small a share for the
+	// allocated size to tell, but SEEK_HOLE finds it
+	holePath := filepath.Join(dir, "hole.bin")
+	if err := os.WriteFile(holePath, bytes.Repeat([]byte("x"), 16<<20), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.Truncate(holePath, 17<<20); err != nil {
+		t.Fatalf("failed to truncate file: %v", err)
+	}
+	if hole, ok := firstHole(holePath); !ok || hole >= 17<<20 {
+		t.Skip("SEEK_HOLE is not supported on this platform or filesystem")
+	}
+	if sparse, _ := sparseFile(holePath, 17<<20); !sparse {
+		t.Errorf("sparseFile(%s) = false, want true", holePath)
+	}
+
+	densePath := filepath.Join(dir, "dense.bin")
+	if err := os.WriteFile(densePath, bytes.Repeat([]byte("x"), 4<<20), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if sparse, _ := sparseFile(densePath, 4<<20); sparse {
+		t.Errorf("sparseFile(%s) = true, want false", densePath)
+	}
+}
+
+func TestIsSparse(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int64
+		allocated int64
+		want      bool
+	}{
+		{name: "fully allocated", size: 100 << 20, allocated: 100 << 20, want: false},
+		{name: "allocated in larger blocks", size: 10 << 20, allocated: 10<<20 + 4096, want: false},
+		{name: "no blocks allocated", size: 100 << 20, allocated: 0, want: true},
+		{name: "half allocated", size: 100 << 20, allocated: 50 << 20, want: true},
+		{name: "small hole in a large file", size: 100 << 30, allocated: 100<<30 - 2<<20, want: false},
+		{name: "small file", size: 64 << 10, allocated: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSparse(tt.size, tt.allocated); got != tt.want {
+				t.Errorf("isSparse(%d, %d) = %v, want %v", tt.size, tt.allocated, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckFiles_FAT32(t *testing.T) {
+	// paths do not exist, so only the logical sizes are checked
+	files := []fileEntry{
+		{path: "small.mkv", length: 1 << 30},
+		{path: "exact.mkv", length: fat32MaxFileSize},
+		{path: "large.mkv", length: 1 << 32},
+	}
+
+	var buf bytes.Buffer
+	display := NewDisplay(NewFormatter(false))
+	display.output = &buf
+
+	if _, err := checkFiles(files, CreateOptions{}, nil, display); err != nil {
+		t.Fatalf("checkFiles without FAT32Check failed: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no warnings without FAT32Check, got:\n%s", buf.String())
+	}
+
+	if _, err := checkFiles(files, CreateOptions{FAT32Check: true}, nil, display); err != nil {
+		t.Fatalf("checkFiles failed: %v", err)
+	}
+	output := stripAnsiCodes(buf.String())
+	if !strings.Contains(output, "Warning: large.mkv is 4.0 GiB, larger than FAT32 supports") {
+		t.Errorf("expected a FAT32 warning for large.mkv, got:\n%s", output)
+	}
+	if strings.Contains(output, "small.mkv") || strings.Contains(output, "exact.mkv") {
+		t.Errorf("expected only large.mkv to be reported, got:\n%s", output)
+	}
+
+	_, err := checkFiles(files, CreateOptions{FAT32Check: true, StrictFileChecks: true}, nil, display)
+	if err == nil || !strings.Contains(err.Error(), "large.mkv") {
+		t.Errorf("expected a strict error naming large.mkv, got %v", err)
+	}
+}
+
+func TestCheckFiles_Sparse(t *testing.T) {
+	dir := t.TempDir()
+	sparsePath := filepath.Join(dir, "sparse.bin")
+	createSparseFile(t, sparsePath, 8<<20)
+
+	densePath := filepath.Join(dir, "dense.bin")
+	if err := os.WriteFile(densePath, bytes.Repeat([]byte{1}, 8<<20), 0644); err != nil {
+		t.Fatalf("failed to write dense file: %v", err)
+	}
+
+	files := []fileEntry{
+		{path: densePath, length: 8 << 20},
+		{path: sparsePath, length: 8 << 20},
+	}
+
+	var buf bytes.Buffer
+	display := NewDisplay(NewFormatter(false))
+	display.output = &buf
+
+	if _, err := checkFiles(files, CreateOptions{}, nil, display); err != nil {
+		t.Fatalf("checkFiles failed: %v", err)
+	}
+	output := stripAnsiCodes(buf.String())
+	if !strings.Contains(output, sparsePath+" is sparse") {
+		t.Errorf("expected a sparse warning for %s, got:\n%s", sparsePath, output)
+	}
+	if strings.Contains(output, densePath) {
+		t.Errorf("did not expect a warning for the dense file, got:\n%s", output)
+	}
+
+	buf.Reset()
+	if _, err := checkFiles(files, CreateOptions{SkipHashing: true}, nil, display); err != nil {
+		t.Fatalf("checkFiles with SkipHashing failed: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no sparse warning when hashing is skipped, got:\n%s", buf.String())
+	}
+}
+
+func TestCreateTorrent_StrictFileChecks(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "content")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("failed to create content dir: %v", err)
+	}
+	createSparseFile(t, filepath.Join(dir, "sparse.bin"), 4<<20)
+
+	opts := CreateOptions{Path: dir, Quiet: true}
+	if _, err := CreateTorrent(opts); err != nil {
+		t.Fatalf("expected sparse files to only warn, got %v", err)
+	}
+
+	opts.StrictFileChecks = true
+	if _, err := CreateTorrent(opts); err == nil || !strings.Contains(err.Error(), "is sparse") {
+		t.Errorf("expected a sparse file error with StrictFileChecks, got %v", err)
+	}
+}
+
+// writeStubRelease creates a release directory with an empty and a stub video
+// file next to small files that are legitimately small.
+func writeStubRelease(t *testing.T) string {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "Release")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("failed to create content dir: %v", err)
+	}
+	files := map[string]int{
+		"episode.mkv": 0,
+		"sample.mp4":  100 << 10,
+		"movie.mkv":   2 << 20,
+		"release.nfo": 100,
+		"movie.srt":   200,
+	}
+	for name, size := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestCheckContent(t *testing.T) {
+	files := []fileEntry{
+		{path: "empty.nfo", length: 0},
+		{path: "stub.MKV", length: 512 << 10},
+		{path: "movie.mkv", length: 2 << 20},
+		{path: "release.nfo", length: 100},
+		{path: "movie.srt", length: 200},
+	}
+
+	problems := checkContent(files, 0)
+	want := []string{
+		"empty.nfo is empty (0 bytes), the copy may be incomplete",
+		"stub.MKV is only 512 KiB, smaller than expected for a video file (1.0 MiB), it may be a stub",
+	}
+	if strings.Join(problems, "\n") != strings.Join(want, "\n") {
+		t.Errorf("checkContent() =\n%s\nwant\n%s", strings.Join(problems, "\n"), strings.Join(want, "\n"))
+	}
+
+	if problems := checkContent(files, 256<<10); len(problems) != 1 {
+		t.Errorf("expected only the empty file below a 256 KiB threshold, got %q", problems)
+	}
+	if problems := checkContent(files, -1); len(problems) != 1 {
+		t.Errorf("expected only the empty file with the size check disabled, got %q", problems)
+	}
+}
+
+func TestCreateTorrent_ContentChecks(t *testing.T) {
+	dir := writeStubRelease(t)
+
+	var buf bytes.Buffer
+	display := NewDisplay(NewFormatter(false))
+	display.output = &buf
+	files := []fileEntry{{path: filepath.Join(dir, "episode.mkv"), length: 0}}
+	if _, err := checkFiles(files, CreateOptions{}, nil, display); err != nil {
+		t.Fatalf("checkFiles failed: %v", err)
+	}
+	if want := "Warning: " + filepath.Join(dir, "episode.mkv") + " is empty (0 bytes)"; !strings.Contains(stripAnsiCodes(buf.String()), want) {
+		t.Errorf("expected %q in output, got:\n%s", want, buf.String())
+	}
+
+	opts := CreateOptions{Path: dir, Quiet: true}
+	mi, err := CreateTorrent(opts)
+	if err != nil {
+		t.Fatalf("expected content problems to only warn, got %v", err)
+	}
+	warnings := strings.Join(mi.Warnings, "\n")
+	if len(mi.Warnings) != 2 || !strings.Contains(warnings, "episode.mkv is empty") || !strings.Contains(warnings, "sample.mp4 is only 100 KiB") {
+		t.Errorf("expected warnings for episode.mkv and sample.mp4, got:\n%s", warnings)
+	}
+
+	opts.StrictContent = true
+	_, err = CreateTorrent(opts)
+	if err == nil || !strings.Contains(err.Error(), "content checks failed") || !strings.Contains(err.Error(), "episode.mkv is empty") {
+		t.Errorf("expected a content check error with StrictContent, got %v", err)
+	}
+}
+
+func TestParseMinVideoSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{input: "1MiB", want: 1 << 20},
+		{input: "500KB", want: 500000},
+		{input: "0", want: -1},
+		{input: "lots", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMinVideoSize(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMinVideoSize(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMinVideoSize(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
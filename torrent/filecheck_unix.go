@@ -0,0 +1,39 @@
+//go:build unix
+
+package torrent
+
+import (
+	"os"
+	"syscall"
+)
+
+// allocatedSize returns the number of bytes allocated on disk for the file at path.
+func allocatedSize(path string) (int64, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, false
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	// st_blocks is counted in 512-byte units regardless of the filesystem block size
+	return int64(stat.Blocks) * 512, true
+}
+
+// firstHole returns the offset of the first hole in the file at path, which is
+// its size when it has none. ok is false when the platform or filesystem
+// cannot tell.
+func firstHole(path string) (int64, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+
+	offset, err := seekHole(f)
+	if err != nil {
+		return 0, false
+	}
+	return offset, true
+}
@@ -0,0 +1,27 @@
+//go:build !unix
+
+package torrent
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// fileID identifies a file or directory by its absolute path with symlinks
+// resolved, as there are no inodes to compare on this platform.
+type fileID struct {
+	path string
+}
+
+// fileIdentity returns the identity of the file at path.
+func fileIdentity(path string, info os.FileInfo) (fileID, bool) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fileID{}, false
+	}
+	abs, err := filepath.Abs(resolved)
+	if err != nil {
+		return fileID{}, false
+	}
+	return fileID{path: abs}, true
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package torrent
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileID identifies a file or directory by device and inode, however it was
+// reached, so content linked more than once is only included once.
+type fileID struct {
+	dev, ino uint64
+}
+
+// fileIdentity returns the identity of the file described by info, which
+// must come from os.Stat or os.Lstat of path.
+func fileIdentity(path string, info os.FileInfo) (fileID, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, false
+	}
+	return fileID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, true
+}
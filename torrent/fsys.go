@@ -0,0 +1,214 @@
+package torrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+)
+
+// walkFS collects the files below root in fsys, applying the same built-in
+// ignores, exclude and include patterns and .mkbrrignore files as the walk of
+// the OS filesystem. File paths are fsys paths. Symlinks are not resolved:
+// entries are read through fsys.Open and directories behind links are skipped.
+func walkFS(ctx context.Context, fsys fs.FS, root string, rootIsDir bool, opts CreateOptions, skipFile func(path, reason string)) ([]fileEntry, int64, error) {
+	matchBasePath := root
+	if !rootIsDir {
+		matchBasePath = path.Dir(root)
+	}
+
+	var files []fileEntry
+	var totalSize int64
+	var ignoreRules []*IgnoreRuleSet
+
+	err := fs.WalkDir(fsys, root, func(currentPath string, entry fs.DirEntry, walkErr error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if walkErr != nil {
+			return walkErr
+		}
+
+		relPath := relFSPath(matchBasePath, currentPath)
+
+		for len(ignoreRules) > 0 {
+			if _, ok := ignoreRules[len(ignoreRules)-1].relativePath(currentPath); ok {
+				break
+			}
+			ignoreRules = ignoreRules[:len(ignoreRules)-1]
+		}
+
+		if entry.IsDir() {
+			if shouldIgnoreDir(currentPath) {
+				skipFile(currentPath+"/", ignoreReasonBuiltin)
+				return fs.SkipDir
+			}
+
+			if relPath != "" {
+				if beyondMaxDepth(relPath, opts.MaxDepth) {
+					skipFile(currentPath+"/", ignoreReasonMaxDepth)
+					return fs.SkipDir
+				}
+				if opts.SkipHidden && isHiddenName(entry.Name()) {
+					skipFile(currentPath+"/", ignoreReasonHidden)
+					return fs.SkipDir
+				}
+				if isExcludedDir(currentPath, opts.ExcludeDirs) {
+					skipFile(currentPath+"/", ignoreReasonExcludeDir)
+					return fs.SkipDir
+				}
+
+				reason, err := ignoreEntryReason(relPath, true, opts.ExcludePatterns, opts.IncludePatterns, opts.CaseSensitivePatterns)
+				if err != nil {
+					return fmt.Errorf("error processing directory patterns for %q: %w", currentPath, err)
+				}
+				if reason == "" {
+					reason, err = ignoreRuleSetsReason(currentPath, true, ignoreRules)
+					if err != nil {
+						return err
+					}
+				}
+				if reason != "" {
+					skipFile(currentPath+"/", reason)
+					return fs.SkipDir
+				}
+			}
+
+			ruleSet, err := loadIgnoreRuleSetFS(fsys, currentPath)
+			if err != nil {
+				return err
+			}
+			if ruleSet != nil {
+				ignoreRules = append(ignoreRules, ruleSet)
+			}
+			return nil
+		}
+
+		if rootIsDir && entry.Name() == ignoreFileName {
+			return nil
+		}
+		if rootIsDir && opts.SkipHidden && isHiddenName(entry.Name()) {
+			skipFile(currentPath, ignoreReasonHidden)
+			return nil
+		}
+
+		// stat through fsys so a link reports the size of its target
+		info, err := fs.Stat(fsys, currentPath)
+		if err != nil {
+			return fmt.Errorf("error checking %q: %w", currentPath, err)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		reason, err := ignoreEntryReason(relPath, false, opts.ExcludePatterns, opts.IncludePatterns, opts.CaseSensitivePatterns)
+		if err != nil {
+			return fmt.Errorf("error processing file patterns for %q: %w", currentPath, err)
+		}
+		if reason == "" {
+			reason, err = ignoreRuleSetsReason(currentPath, false, ignoreRules)
+			if err != nil {
+				return err
+			}
+		}
+		if reason != "" {
+			skipFile(currentPath, reason)
+			return nil
+		}
+
+		files = append(files, fileEntry{
+			path:   currentPath,
+			length: info.Size(),
+			offset: totalSize,
+		})
+		totalSize += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return files, totalSize, nil
+}
+
+// relFSPath returns p relative to the fsys directory base, which is "." for
+// the root of the fsys.
+func relFSPath(base, p string) string {
+	if base == "." {
+		if p == "." {
+			return ""
+		}
+		return p
+	}
+	if p == base {
+		return ""
+	}
+	return p[len(base)+1:]
+}
+
+// fsOpener returns a function opening files of fsys for the hashers. Files
+// that cannot seek are wrapped in an fsSeeker.
+func fsOpener(fsys fs.FS) func(name string) (io.ReadSeekCloser, error) {
+	return func(name string) (io.ReadSeekCloser, error) {
+		f, err := fsys.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		if file, ok := f.(io.ReadSeekCloser); ok {
+			return file, nil
+		}
+		return &fsSeeker{fsys: fsys, name: name, file: f}, nil
+	}
+}
+
+// fsSeeker lets an fs.File without Seek, such as a file in a zip archive, be
+// read from any offset: seeking forward discards data and seeking backward
+// reopens the file.
+type fsSeeker struct {
+	fsys fs.FS
+	file fs.File
+	name string
+	pos  int64
+}
+
+func (s *fsSeeker) Read(p []byte) (int, error) {
+	n, err := s.file.Read(p)
+	s.pos += int64(n)
+	return n, err
+}
+
+func (s *fsSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.pos
+	default:
+		return s.pos, fmt.Errorf("seek relative to the end is not supported for %s", s.name)
+	}
+	if offset < 0 {
+		return s.pos, errors.New("negative seek offset")
+	}
+
+	if offset < s.pos {
+		f, err := s.fsys.Open(s.name)
+		if err != nil {
+			return s.pos, err
+		}
+		_ = s.file.Close()
+		s.file = f
+		s.pos = 0
+	}
+	if offset > s.pos {
+		n, err := io.CopyN(io.Discard, s.file, offset-s.pos)
+		s.pos += n
+		if err != nil {
+			return s.pos, err
+		}
+	}
+	return s.pos, nil
+}
+
+func (s *fsSeeker) Close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,212 @@
+package torrent
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// fsTestFiles holds content spanning several 64 KiB pieces, so pieces cross
+// file boundaries and workers seek into files.
+var fsTestFiles = map[string][]byte{
+	"Release/a.bin":        bytes.Repeat([]byte{1}, 100<<10),
+	"Release/sub/b.bin":    bytes.Repeat([]byte{2}, 70<<10),
+	"Release/sub/c.bin":    bytes.Repeat([]byte{3}, 3<<10),
+	"Release/sample/s.bin": bytes.Repeat([]byte{4}, 10<<10),
+	"Release/notes.nfo":    []byte("notes"),
+	"Release/.mkbrrignore": []byte("*.nfo\n"),
+}
+
+// writeFSTestFiles writes fsTestFiles to a temporary directory and returns the
+// path of the release directory.
+func writeFSTestFiles(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, data := range fsTestFiles {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return filepath.Join(dir, "Release")
+}
+
+// zipFS returns fsTestFiles as a zip archive, whose files cannot seek.
+func zipFS(t *testing.T) fs.FS {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, data := range fsTestFiles {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("failed to add %s to zip: %v", name, err)
+		}
+		if _, err := f.Write(data); err != nil {
+			t.Fatalf("failed to write %s to zip: %v", name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close zip: %v", err)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("failed to read zip: %v", err)
+	}
+	return r
+}
+
+func TestCreateTorrentFromFS(t *testing.T) {
+	pieceLenExp := uint(16)
+	opts := CreateOptions{
+		PieceLengthExp: &pieceLenExp,
+		ExcludeDirs:    []string{"sample"},
+		NoCreator:      true,
+		NoDate:         true,
+		Workers:        2,
+		Quiet:          true,
+	}
+
+	diskOpts := opts
+	diskOpts.Path = writeFSTestFiles(t)
+	want, err := CreateTorrent(diskOpts)
+	if err != nil {
+		t.Fatalf("CreateTorrent failed: %v", err)
+	}
+
+	mapFS := fstest.MapFS{}
+	for name, data := range fsTestFiles {
+		mapFS[name] = &fstest.MapFile{Data: data}
+	}
+
+	filesystems := map[string]fs.FS{
+		"map": mapFS,
+		"zip": zipFS(t),
+	}
+	for name, fsys := range filesystems {
+		t.Run(name, func(t *testing.T) {
+			got, err := CreateTorrentFromFS(fsys, "Release", opts)
+			if err != nil {
+				t.Fatalf("CreateTorrentFromFS failed: %v", err)
+			}
+
+			info := got.GetInfo()
+			var paths []string
+			for _, f := range info.Files {
+				paths = append(paths, strings.Join(f.Path, "/"))
+			}
+			if wantPaths := []string{"a.bin", "sub/b.bin", "sub/c.bin"}; !slices.Equal(paths, wantPaths) {
+				t.Errorf("files = %v, want %v", paths, wantPaths)
+			}
+			if info.Name != "Release" {
+				t.Errorf("name = %q, want %q", info.Name, "Release")
+			}
+			if got.HashInfoBytes() != want.HashInfoBytes() {
+				t.Errorf("info hash = %s, want %s as created from disk", got.HashInfoBytes(), want.HashInfoBytes())
+			}
+		})
+	}
+}
+
+func TestCreateFromFS_SingleFile(t *testing.T) {
+	fsys := fstest.MapFS{"dir/movie.mkv": &fstest.MapFile{Data: bytes.Repeat([]byte{7}, 1<<17)}}
+	outputPath := filepath.Join(t.TempDir(), "movie.torrent")
+
+	result, err := CreateFromFS(fsys, "dir/movie.mkv", CreateOptions{OutputPath: outputPath, NoDate: true, Quiet: true})
+	if err != nil {
+		t.Fatalf("CreateFromFS failed: %v", err)
+	}
+	if result.Path != outputPath {
+		t.Errorf("Path = %q, want %q", result.Path, outputPath)
+	}
+
+	mi, err := LoadFromFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to load torrent: %v", err)
+	}
+	info, err := mi.UnmarshalInfo()
+	if err != nil {
+		t.Fatalf("failed to read info: %v", err)
+	}
+	if info.Name != "movie.mkv" || info.Length != 1<<17 || len(info.Files) != 0 {
+		t.Errorf("got name %q, length %d and %d files, want a single file movie.mkv of %d bytes", info.Name, info.Length, len(info.Files), 1<<17)
+	}
+}
+
+func TestCreateTorrentFromFS_Errors(t *testing.T) {
+	fsys := fstest.MapFS{"a.bin": &fstest.MapFile{Data: []byte("data")}}
+
+	tests := []struct {
+		name    string
+		root    string
+		opts    CreateOptions
+		wantErr string
+	}{
+		{name: "invalid path", root: "/a.bin", wantErr: "invalid fs path"},
+		{name: "root without name", root: ".", wantErr: "torrent name is required"},
+		{name: "missing", root: "b.bin", wantErr: "error checking path"},
+		{name: "reuse", root: "a.bin", opts: CreateOptions{ReuseFrom: "old.torrent"}, wantErr: "reusing piece hashes is not supported"},
+		{name: "inode order", root: "a.bin", opts: CreateOptions{FileOrder: FileOrderInode}, wantErr: "not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Quiet = true
+			_, err := CreateTorrentFromFS(fsys, tt.root, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// the root of the filesystem works with a name
+	if _, err := CreateTorrentFromFS(fsys, ".", CreateOptions{Name: "root", Quiet: true}); err != nil {
+		t.Errorf("CreateTorrentFromFS of the root failed: %v", err)
+	}
+}
+
+func TestFSSeeker(t *testing.T) {
+	mapFS := fstest.MapFS{"f": &fstest.MapFile{Data: []byte("0123456789")}}
+	s := &fsSeeker{fsys: mapFS, name: "f"}
+	f, err := mapFS.Open("f")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	s.file = f
+	defer s.Close()
+
+	buf := make([]byte, 3)
+	for _, tt := range []struct {
+		offset int64
+		want   string
+	}{
+		{offset: 4, want: "456"},
+		{offset: 1, want: "123"},
+		{offset: 7, want: "789"},
+	} {
+		if pos, err := s.Seek(tt.offset, io.SeekStart); err != nil || pos != tt.offset {
+			t.Fatalf("Seek(%d) = %d, %v", tt.offset, pos, err)
+		}
+		if _, err := io.ReadFull(s, buf); err != nil {
+			t.Fatalf("read at %d failed: %v", tt.offset, err)
+		}
+		if string(buf) != tt.want {
+			t.Errorf("read at %d = %q, want %q", tt.offset, buf, tt.want)
+		}
+	}
+
+	if _, err := s.Seek(0, io.SeekEnd); err == nil {
+		t.Error("expected seeking from the end to fail")
+	}
+}
@@ -0,0 +1,79 @@
+package torrent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// fuzzyMaxDistance is the largest edit distance between a directory entry and
+// the torrent name that VerifyOptions.FuzzyPathMatch accepts
+const fuzzyMaxDistance = 2
+
+// resolveFuzzyContentPath returns the entry of the parent directory of
+// contentPath whose name is closest to the torrent name, for content that was
+// renamed slightly after download, e.g. with a -FIXED suffix. Only directories
+// are considered for multi-file torrents and only files for single-file ones.
+func resolveFuzzyContentPath(contentPath string, info *metainfo.Info) (string, int, error) {
+	dir := filepath.Dir(contentPath)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", 0, fmt.Errorf("could not read directory %q: %w", dir, err)
+	}
+
+	var candidates []string
+	for _, entry := range entries {
+		// stat instead of entry.IsDir so symlinked content is followed
+		entryInfo, err := os.Stat(filepath.Join(dir, entry.Name()))
+		if err != nil || entryInfo.IsDir() != info.IsDir() {
+			continue
+		}
+		candidates = append(candidates, entry.Name())
+	}
+
+	match, dist := fuzzyMatch(candidates, info.Name, fuzzyMaxDistance)
+	if match == "" {
+		return "", 0, fmt.Errorf("content path %q does not exist and nothing in %q is within edit distance %d of torrent name %q",
+			contentPath, dir, fuzzyMaxDistance, info.Name)
+	}
+	return filepath.Join(dir, match), dist, nil
+}
+
+// fuzzyMatch returns the candidate with the smallest Levenshtein distance to
+// target and that distance, the first one on a tie. It returns "" and -1 when
+// no candidate is within maxDist.
+func fuzzyMatch(candidates []string, target string, maxDist int) (string, int) {
+	best, bestDist := "", -1
+	for _, candidate := range candidates {
+		dist := levenshtein(candidate, target)
+		if dist <= maxDist && (bestDist < 0 || dist < bestDist) {
+			best, bestDist = candidate, dist
+		}
+	}
+	return best, bestDist
+}
+
+// levenshtein returns the number of single rune insertions, deletions and
+// substitutions needed to turn a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,29 @@
+package torrent
+
+import "testing"
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []string
+		target     string
+		want       string
+		wantDist   int
+	}{
+		{"exact", []string{"Other", "My.Show.S01"}, "My.Show.S01", "My.Show.S01", 0},
+		{"closest wins", []string{"TestDir-v22", "TestDir-v2"}, "TestDir-v1", "TestDir-v2", 1},
+		{"first on a tie", []string{"TestDir-a", "TestDir-b"}, "TestDir-c", "TestDir-a", 1},
+		{"too far", []string{"My.Show.S01.720p-Group-FIXED"}, "My.Show.S01.720p-Group", "", -1},
+		{"runes not bytes", []string{"Café"}, "Cafe", "Café", 1},
+		{"no candidates", nil, "TestDir", "", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, dist := fuzzyMatch(tt.candidates, tt.target, 2)
+			if got != tt.want || dist != tt.wantDist {
+				t.Errorf("fuzzyMatch() = %q, %d, want %q, %d", got, dist, tt.want, tt.wantDist)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package torrent
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// hashCacheFile is the name of the cache file in CreateOptions.HashCacheDir
+const hashCacheFile = "pieces.cache"
+
+// hashCacheMagic starts a cache file; it changes whenever the key or record
+// layout changes, so an old cache is ignored rather than misread.
+var hashCacheMagic = []byte("mkbrrhc1")
+
+// hashCacheRecordSize is the size of one record, a key followed by the piece hash
+const hashCacheRecordSize = 2 * sha1.Size
+
+// hashCache is an on-disk store of piece hashes keyed by the path, mtime,
+// size and offset of every file a piece covers and the piece length, so a
+// piece is found again only if none of its files changed. New hashes are
+// appended; entries of changed files are never looked up again but stay in
+// the file until the cache directory is removed.
+type hashCache struct {
+	path    string
+	mu      sync.Mutex
+	entries map[[sha1.Size]byte][sha1.Size]byte
+	valid   bool // the cache file exists and starts with hashCacheMagic
+}
+
+// cachePlan holds the pieces of a hasher that were not found in the cache,
+// with their keys, so their hashes can be stored once they are computed.
+type cachePlan struct {
+	hits    int
+	pending map[int][sha1.Size]byte
+}
+
+// loadHashCache opens the cache in dir, creating the directory if needed. A
+// missing, foreign or truncated cache file is treated as empty or read up to
+// the last complete record.
+func loadHashCache(dir string) (*hashCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("could not create hash cache directory %q: %w", dir, err)
+	}
+	c := &hashCache{
+		path:    filepath.Join(dir, hashCacheFile),
+		entries: make(map[[sha1.Size]byte][sha1.Size]byte),
+	}
+
+	f, err := os.Open(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open hash cache: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	magic := make([]byte, len(hashCacheMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || !bytes.Equal(magic, hashCacheMagic) {
+		return c, nil
+	}
+	c.valid = true
+	var record [hashCacheRecordSize]byte
+	for {
+		if _, err := io.ReadFull(r, record[:]); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return c, nil
+			}
+			return nil, fmt.Errorf("could not read hash cache: %w", err)
+		}
+		c.entries[[sha1.Size]byte(record[:sha1.Size])] = [sha1.Size]byte(record[sha1.Size:])
+	}
+}
+
+// plan copies the hashes of cached pieces into h and marks them reused, so
+// hashPieces skips them. Pieces already reused, e.g. from an existing torrent,
+// are left alone. Files read through a custom opener are not cached.
+func (c *hashCache) plan(h *pieceHasher) (*cachePlan, error) {
+	p := &cachePlan{pending: make(map[int][sha1.Size]byte)}
+	if h.open != nil || h.numPieces == 0 {
+		return p, nil
+	}
+
+	// the identity of each file: absolute path, mtime and size
+	ids := make([][]byte, len(h.files))
+	for i, file := range h.files {
+		if file.pad {
+			ids[i] = []byte("pad")
+			continue
+		}
+		abs, err := filepath.Abs(file.path)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve %s: %w", file.path, err)
+		}
+		info, err := os.Stat(longPath(file.path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat file %s: %w", file.path, err)
+		}
+		ids[i] = binary.AppendVarint([]byte(abs+"\x00"), info.ModTime().UnixNano())
+		ids[i] = binary.AppendVarint(ids[i], info.Size())
+	}
+
+	if h.reused == nil {
+		h.reused = make([]bool, h.numPieces)
+	}
+	for pieceIndex := 0; pieceIndex < h.numPieces; pieceIndex++ {
+		if h.reused[pieceIndex] {
+			continue
+		}
+		key := h.cacheKey(pieceIndex, ids)
+		c.mu.Lock()
+		hash, ok := c.entries[key]
+		c.mu.Unlock()
+		if ok {
+			copy(h.pieces[pieceIndex], hash[:])
+			h.reused[pieceIndex] = true
+			p.hits++
+			continue
+		}
+		p.pending[pieceIndex] = key
+	}
+	return p, nil
+}
+
+// cacheKey returns the key of a piece: the SHA-1 of the piece length and, for
+// each file the piece covers, its identity and the range read from it.
+func (h *pieceHasher) cacheKey(pieceIndex int, ids [][]byte) [sha1.Size]byte {
+	start := int64(pieceIndex) * h.pieceLen
+	end := start + h.pieceLengthFor(pieceIndex)
+
+	key := sha1.New()
+	key.Write(binary.AppendVarint(nil, h.pieceLen))
+	for fileIndex := h.startFileForPiece(pieceIndex); fileIndex < len(h.files) && h.files[fileIndex].offset < end; fileIndex++ {
+		file := h.files[fileIndex]
+		readStart := max(start, file.offset) - file.offset
+		readEnd := min(end, file.offset+file.length) - file.offset
+		if readEnd <= readStart {
+			continue
+		}
+		key.Write(ids[fileIndex])
+		key.Write(binary.AppendVarint(binary.AppendVarint(nil, readStart), readEnd-readStart))
+	}
+	return [sha1.Size]byte(key.Sum(nil))
+}
+
+// store appends the hashes of the pending pieces of p, computed by h, to the
+// cache file. A cache file that could not be read is replaced.
+func (c *hashCache) store(h *pieceHasher, p *cachePlan) error {
+	if len(p.pending) == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := make([]byte, 0, len(hashCacheMagic)+len(p.pending)*hashCacheRecordSize)
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if !c.valid {
+		data = append(data, hashCacheMagic...)
+		flags |= os.O_TRUNC
+	}
+	for pieceIndex, key := range p.pending {
+		hash := [sha1.Size]byte(h.pieces[pieceIndex])
+		c.entries[key] = hash
+		data = append(data, key[:]...)
+		data = append(data, hash[:]...)
+	}
+
+	f, err := os.OpenFile(c.path, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("could not open hash cache: %w", err)
+	}
+	if c.valid {
+		// drop a record cut short by an earlier write, so the new ones stay aligned
+		if info, err := f.Stat(); err == nil {
+			if partial := (info.Size() - int64(len(hashCacheMagic))) % hashCacheRecordSize; partial != 0 {
+				_ = f.Truncate(info.Size() - partial)
+			}
+		}
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("could not write hash cache: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("could not write hash cache: %w", err)
+	}
+	c.valid = true
+	return nil
+}
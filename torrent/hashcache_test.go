@@ -0,0 +1,120 @@
+package torrent
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// hashWithCache plans the hasher against the cache in cacheDir, hashes the
+// remaining pieces and stores them, returning the number of cache hits.
+func hashWithCache(t *testing.T, h *pieceHasher, cacheDir string) int {
+	t.Helper()
+
+	cache, err := loadHashCache(cacheDir)
+	if err != nil {
+		t.Fatalf("loadHashCache failed: %v", err)
+	}
+	plan, err := cache.plan(h)
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+	if err := h.hashPieces(1); err != nil {
+		t.Fatalf("hashPieces failed: %v", err)
+	}
+	if err := cache.store(h, plan); err != nil {
+		t.Fatalf("store failed: %v", err)
+	}
+	return plan.hits
+}
+
+func TestHashCache(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	// a.bin fills pieces 0-1 and the start of 2, which it shares with b.bin
+	writeReuseTestFiles(t, dir, []reuseTestFile{
+		{name: "a.bin", size: 2*reuseTestPieceLen + 100, seed: 1},
+		{name: "b.bin", size: 3 * reuseTestPieceLen, seed: 2},
+	})
+
+	first, _ := newReuseTestHasher(t, dir)
+	if hits := hashWithCache(t, first, cacheDir); hits != 0 {
+		t.Fatalf("first run: got %d cache hits, want 0", hits)
+	}
+
+	second, _ := newReuseTestHasher(t, dir)
+	if hits := hashWithCache(t, second, cacheDir); hits != second.numPieces {
+		t.Fatalf("second run: got %d cache hits, want %d", hits, second.numPieces)
+	}
+	for i := range first.pieces {
+		if !bytes.Equal(first.pieces[i], second.pieces[i]) {
+			t.Errorf("piece %d from the cache differs from the hashed one", i)
+		}
+	}
+
+	// rewriting a.bin with a new mtime invalidates its pieces, including the
+	// one it shares with b.bin
+	writeReuseTestFiles(t, dir, []reuseTestFile{{name: "a.bin", size: 2*reuseTestPieceLen + 100, seed: 9}})
+	if err := os.Chtimes(filepath.Join(dir, "a.bin"), time.Now(), time.Now()); err != nil {
+		t.Fatalf("failed to set mtime: %v", err)
+	}
+	third, _ := newReuseTestHasher(t, dir)
+	if hits := hashWithCache(t, third, cacheDir); hits != third.numPieces-3 {
+		t.Errorf("after change: got %d cache hits, want %d", hits, third.numPieces-3)
+	}
+
+	fresh, _ := newReuseTestHasher(t, dir)
+	if err := fresh.hashPieces(1); err != nil {
+		t.Fatalf("hashPieces failed: %v", err)
+	}
+	for i := range fresh.pieces {
+		if !bytes.Equal(fresh.pieces[i], third.pieces[i]) {
+			t.Errorf("piece %d does not match a run without the cache", i)
+		}
+	}
+}
+
+func TestHashCache_InvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := t.TempDir()
+	writeReuseTestFiles(t, dir, []reuseTestFile{{name: "a.bin", size: reuseTestPieceLen + 1, seed: 1}})
+	if err := os.WriteFile(filepath.Join(cacheDir, hashCacheFile), []byte("not a cache"), 0644); err != nil {
+		t.Fatalf("failed to write cache: %v", err)
+	}
+
+	h, _ := newReuseTestHasher(t, dir)
+	if hits := hashWithCache(t, h, cacheDir); hits != 0 {
+		t.Fatalf("got %d cache hits from an invalid cache, want 0", hits)
+	}
+	h, _ = newReuseTestHasher(t, dir)
+	if hits := hashWithCache(t, h, cacheDir); hits != h.numPieces {
+		t.Errorf("got %d cache hits after replacing the invalid cache, want %d", hits, h.numPieces)
+	}
+}
+
+func TestCreate_HashCache(t *testing.T) {
+	dir := t.TempDir()
+	writeReuseTestFiles(t, dir, []reuseTestFile{
+		{name: "a.bin", size: 2*reuseTestPieceLen + 100, seed: 1},
+		{name: "b.bin", size: reuseTestPieceLen, seed: 2},
+	})
+
+	exp := uint(16)
+	opts := CreateOptions{Path: dir, PieceLengthExp: &exp, HashCacheDir: t.TempDir(), NoDate: true, NoCreator: true, Quiet: true}
+	var hashes []string
+	for range 2 {
+		tor, err := CreateTorrent(opts)
+		if err != nil {
+			t.Fatalf("CreateTorrent failed: %v", err)
+		}
+		hashes = append(hashes, tor.HashInfoBytes().HexString())
+	}
+	if hashes[0] != hashes[1] {
+		t.Errorf("info hash with a warm cache %s differs from %s", hashes[1], hashes[0])
+	}
+	if _, err := os.Stat(filepath.Join(opts.HashCacheDir, hashCacheFile)); err != nil {
+		t.Errorf("expected a cache file: %v", err)
+	}
+}
@@ -1,7 +1,9 @@
 package torrent
 
 import (
+	"context"
 	"crypto/sha1"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -10,6 +12,10 @@ import (
 	"time"
 )
 
+// ErrContentChanged is returned when a file no longer has the length it had
+// when the content was scanned, so the pieces would not match its data.
+var ErrContentChanged = errors.New("content changed since it was scanned")
+
 type pieceHasher struct {
 	display          Displayer
 	bufferPool       *sync.Pool
@@ -25,7 +31,22 @@ type pieceHasher struct {
 
 	startTime               time.Time
 	bytesProcessed          int64
+	bytesRead               []int64         // bytes hashed per file during hashPieces, to catch files that changed length
+	fileReopens             int64           // files reopened after being evicted from a worker's reader cache
+	readRetries             int             // extra attempts for failed open/seek/read calls, 0 disables retrying
+	reused                  []bool          // pieces whose hash was copied from an existing torrent and are skipped
+	throttle                *readThrottle   // limits the read rate across workers, nil for no limit
+	ctx                     context.Context // stops workers between pieces once done, nil to never stop
 	failOnSeasonPackWarning bool
+	skipSeasonCheck         bool            // skip AnalyzeSeasonPack unless failOnSeasonPackWarning needs it
+	seasonInfo              *SeasonPackInfo // result of the season pack check, nil when it was skipped
+
+	// open opens files for reading, os.Open when nil
+	open func(name string) (io.ReadSeekCloser, error)
+
+	// fileEvents receives the index of each file a worker starts reading
+	// during hashPieces, nil when there is only one file or no run drains it
+	fileEvents chan int
 }
 
 // optimizeForWorkload determines optimal read buffer size and number of worker goroutines
@@ -111,11 +132,15 @@ func (h *pieceHasher) hashPieces(numWorkers int) error {
 
 	if numWorkers == 0 {
 		// no workers needed, possibly no pieces to hash
-		h.display.ShowProgress(0)
+		h.display.ShowProgress(0, 0)
 		h.display.FinishProgress()
 		return nil
 	}
 
+	if err := h.checkFileSizes(); err != nil {
+		return err
+	}
+
 	// initialize buffer pool
 	h.bufferPool = &sync.Pool{
 		New: func() interface{} {
@@ -126,22 +151,30 @@ func (h *pieceHasher) hashPieces(numWorkers int) error {
 
 	h.startTime = time.Now()
 	h.bytesProcessed = 0
+	h.bytesRead = make([]int64, len(h.files))
 
-	h.display.ShowFiles(h.files, numWorkers)
+	contentFiles := contentFileEntries(h.files)
+	h.display.ShowFiles(contentFiles, numWorkers)
 
-	seasonInfo := AnalyzeSeasonPack(h.files)
+	if !h.skipSeasonCheck || h.failOnSeasonPackWarning {
+		h.seasonInfo = AnalyzeSeasonPack(contentFiles)
 
-	h.display.ShowSeasonPackWarnings(seasonInfo)
+		h.display.ShowSeasonPackWarnings(h.seasonInfo)
 
-	if seasonInfo.IsSuspicious && h.failOnSeasonPackWarning {
-		return fmt.Errorf("season pack is suspicious, and --fail-on-season-warning is enabled")
+		if h.seasonInfo.IsSuspicious && h.failOnSeasonPackWarning {
+			return fmt.Errorf("season pack is suspicious, and --fail-on-season-warning is enabled")
+		}
 	}
 
 	var completedPieces uint64
 	piecesPerWorker := (h.numPieces + numWorkers - 1) / numWorkers
 	errorsCh := make(chan error, numWorkers)
 
-	h.display.ShowProgress(h.numPieces)
+	h.display.ShowProgress(h.numPieces, h.totalSize)
+
+	if len(h.files) > 1 {
+		h.fileEvents = make(chan int, numWorkers)
+	}
 
 	// spawn worker goroutines to process piece ranges in parallel
 	var wg sync.WaitGroup
@@ -161,7 +194,8 @@ func (h *pieceHasher) hashPieces(numWorkers int) error {
 		}(start, end)
 	}
 
-	// monitor and update progress bar in separate goroutine
+	// monitor and update progress bar in separate goroutine, which also
+	// reports every file a worker moves to
 	stopProgress := make(chan struct{})
 	progressDone := make(chan struct{})
 	go func() {
@@ -169,24 +203,41 @@ func (h *pieceHasher) hashPieces(numWorkers int) error {
 		ticker := time.NewTicker(200 * time.Millisecond)
 		defer ticker.Stop()
 
+		currentFile := -1
+		update := func() {
+			completed := atomic.LoadUint64(&completedPieces)
+			bytesProcessed := atomic.LoadInt64(&h.bytesProcessed)
+			elapsed := time.Since(h.startTime).Seconds()
+
+			var hashrate float64
+			if elapsed > 0 {
+				hashrate = float64(bytesProcessed) / elapsed
+			}
+
+			var name string
+			var size int64
+			if currentFile >= 0 {
+				name, size = h.files[currentFile].path, h.files[currentFile].length
+			}
+			h.display.UpdateProgress(int(completed), hashrate, name, size)
+		}
+
 		for {
 			select {
 			case <-stopProgress:
-				return
-			case <-ticker.C:
-				completed := atomic.LoadUint64(&completedPieces)
-				bytesProcessed := atomic.LoadInt64(&h.bytesProcessed)
-				elapsed := time.Since(h.startTime).Seconds()
-
-				var hashrate float64
-				if elapsed > 0 {
-					hashrate = float64(bytesProcessed) / elapsed
-				}
-
-				h.display.UpdateProgress(int(completed), hashrate)
-				if completed >= uint64(h.numPieces) {
-					return
+				// report files the last workers moved to after the previous update
+				for {
+					select {
+					case currentFile = <-h.fileEvents:
+						update()
+					default:
+						return
+					}
 				}
+			case currentFile = <-h.fileEvents:
+				update()
+			case <-ticker.C:
+				update()
 			}
 		}
 	}()
@@ -194,6 +245,7 @@ func (h *pieceHasher) hashPieces(numWorkers int) error {
 	wg.Wait()
 	close(stopProgress)
 	<-progressDone
+	h.fileEvents = nil
 	close(errorsCh)
 
 	for err := range errorsCh {
@@ -202,10 +254,65 @@ func (h *pieceHasher) hashPieces(numWorkers int) error {
 		}
 	}
 
+	expected := h.expectedFileBytes()
+	for i, file := range h.files {
+		if h.bytesRead[i] != expected[i] {
+			return fmt.Errorf("%w: hashed %d bytes of %s, expected %d", ErrContentChanged, h.bytesRead[i], file.path, expected[i])
+		}
+	}
+
 	h.display.FinishProgress()
 	return nil
 }
 
+// checkFileSizes returns an error naming the first file whose size differs
+// from the length recorded when the content was scanned. Files read through
+// a custom opener are not checked.
+func (h *pieceHasher) checkFileSizes() error {
+	if h.open != nil {
+		return nil
+	}
+	for _, file := range h.files {
+		if file.pad {
+			continue
+		}
+		info, err := os.Stat(longPath(file.path))
+		if err != nil {
+			return fmt.Errorf("failed to stat file %s: %w", file.path, err)
+		}
+		if info.Size() != file.length {
+			return fmt.Errorf("%w: %s is %d bytes, expected %d", ErrContentChanged, file.path, info.Size(), file.length)
+		}
+	}
+	return nil
+}
+
+// expectedFileBytes returns the number of bytes hashPieces reads from each
+// file, its length less the parts in reused pieces.
+func (h *pieceHasher) expectedFileBytes() []int64 {
+	expected := make([]int64, len(h.files))
+	for i, file := range h.files {
+		expected[i] = file.length
+	}
+	for pieceIndex, reused := range h.reused {
+		if !reused {
+			continue
+		}
+		start := int64(pieceIndex) * h.pieceLen
+		end := start + h.pieceLengthFor(pieceIndex)
+		for fileIndex := h.startFileForPiece(pieceIndex); fileIndex < len(h.files); fileIndex++ {
+			file := h.files[fileIndex]
+			if file.offset >= end {
+				break
+			}
+			if overlap := min(end, file.offset+file.length) - max(start, file.offset); overlap > 0 {
+				expected[fileIndex] -= overlap
+			}
+		}
+	}
+	return expected
+}
+
 // hashPieceRange processes and hashes a specific range of pieces assigned to a worker.
 // It handles:
 // - reading from multiple files that may span piece boundaries
@@ -223,16 +330,26 @@ func (h *pieceHasher) hashPieceRange(startPiece, endPiece int, completedPieces *
 	defer h.bufferPool.Put(buf)
 
 	hasher := sha1.New()
-	readers := make([]*fileReader, len(h.files))
+	readers := newReaderCache(maxOpenFilesPerWorker)
+	readers.open = h.open
 	defer func() {
-		for _, reader := range readers {
-			if reader != nil {
-				_ = reader.file.Close()
-			}
-		}
+		readers.closeAll()
+		atomic.AddInt64(&h.fileReopens, readers.reopens)
 	}()
 
+	lastFile := -1
 	for pieceIndex := startPiece; pieceIndex < endPiece; pieceIndex++ {
+		if h.ctx != nil {
+			if err := h.ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		if h.reused != nil && h.reused[pieceIndex] {
+			atomic.AddUint64(completedPieces, 1)
+			continue
+		}
+
 		pieceOffset := int64(pieceIndex) * h.pieceLen
 		pieceReadOffset := pieceOffset
 		pieceLength := h.pieceLengthFor(pieceIndex)
@@ -253,22 +370,26 @@ func (h *pieceHasher) hashPieceRange(startPiece, endPiece int, completedPieces *
 				continue
 			}
 
-			reader := readers[fileIndex]
-			if reader == nil {
-				f, err := os.Open(file.path)
-				if err != nil {
-					return fmt.Errorf("failed to open file %s: %w", file.path, err)
-				}
-				reader = &fileReader{
-					file:     f,
-					position: 0,
-					length:   file.length,
+			var reader *fileReader
+			if err := retryIO(h.ctx, h.readRetries, func() error {
+				var err error
+				reader, err = readers.get(fileIndex, file)
+				return err
+			}); err != nil {
+				return fmt.Errorf("failed to open file %s: %w", file.path, err)
+			}
+			if fileIndex != lastFile {
+				lastFile = fileIndex
+				if h.fileEvents != nil && !file.pad {
+					h.fileEvents <- fileIndex
 				}
-				readers[fileIndex] = reader
 			}
 
 			if reader.position != readStart {
-				if _, err := reader.file.Seek(readStart, io.SeekStart); err != nil {
+				if err := retryIO(h.ctx, h.readRetries, func() error {
+					_, err := reader.file.Seek(readStart, io.SeekStart)
+					return err
+				}); err != nil {
 					return fmt.Errorf("failed to seek in file %s: %w", file.path, err)
 				}
 				reader.position = readStart
@@ -281,12 +402,30 @@ func (h *pieceHasher) hashPieceRange(startPiece, endPiece int, completedPieces *
 					n = len(buf)
 				}
 
-				read, err := io.ReadFull(reader.file, buf[:n])
-				if err != nil && err != io.ErrUnexpectedEOF {
+				var read int
+				if err := retryIO(h.ctx, h.readRetries, func() error {
+					var err error
+					read, err = io.ReadFull(reader.file, buf[:n])
+					if err == io.EOF || err == io.ErrUnexpectedEOF {
+						// the file ended early, which retrying cannot fix
+						return nil
+					}
+					if err != nil {
+						// rewind a partial read so a retry starts at the same offset
+						if _, seekErr := reader.file.Seek(reader.position, io.SeekStart); seekErr != nil {
+							return seekErr
+						}
+						return err
+					}
+					return nil
+				}); err != nil {
 					return fmt.Errorf("failed to read file %s: %w", file.path, err)
 				}
-				if read == 0 {
-					return fmt.Errorf("short read while hashing file %s", file.path)
+				if read < n {
+					return fmt.Errorf("%w: %s ended after %d bytes, expected %d", ErrContentChanged, file.path, reader.position+int64(read), file.length)
+				}
+				if err := h.throttle.wait(h.ctx, read); err != nil {
+					return err
 				}
 
 				hasher.Write(buf[:read])
@@ -295,6 +434,9 @@ func (h *pieceHasher) hashPieceRange(startPiece, endPiece int, completedPieces *
 				pieceReadOffset += int64(read)
 				reader.position += int64(read)
 				bytesHashed += int64(read)
+				if h.bytesRead != nil {
+					atomic.AddInt64(&h.bytesRead[fileIndex], int64(read))
+				}
 			}
 		}
 
@@ -3,14 +3,17 @@ package torrent
 import (
 	"bytes"
 	"crypto/sha1"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"runtime"
 	"slices"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/autobrr/mkbrr/internal/trackers"
 )
@@ -18,12 +21,12 @@ import (
 // mockDisplay implements Displayer interface for testing
 type mockDisplay struct{}
 
-func (m *mockDisplay) ShowProgress(total int)                      {}
-func (m *mockDisplay) UpdateProgress(count int, hashrate float64)  {}
-func (m *mockDisplay) ShowFiles(files []fileEntry, numWorkers int) {}
-func (m *mockDisplay) ShowSeasonPackWarnings(info *SeasonPackInfo) {}
-func (m *mockDisplay) FinishProgress()                             {}
-func (m *mockDisplay) IsBatch() bool                               { return true }
+func (m *mockDisplay) ShowProgress(total int, totalSize int64)                       {}
+func (m *mockDisplay) UpdateProgress(count int, hashrate float64, _ string, _ int64) {}
+func (m *mockDisplay) ShowFiles(files []fileEntry, numWorkers int)                   {}
+func (m *mockDisplay) ShowSeasonPackWarnings(info *SeasonPackInfo)                   {}
+func (m *mockDisplay) FinishProgress()                                               {}
+func (m *mockDisplay) IsBatch() bool                                                 { return true }
 
 // TestPieceHasher_Concurrent tests the hasher with various real-world scenarios.
 // Test cases are designed to cover common torrent types and sizes:
@@ -170,6 +173,40 @@ func createTestFilesFast(t *testing.T, numFiles int, fileSize, pieceLen int64) (
 	return files, expectedHashes
 }
 
+// writeReleaseFixture writes files, keyed by slash-separated path, into a
+// Release directory under dir and creates a torrent of it from opts, with
+// 64 KiB pieces unless opts sets a piece length. The torrent is written to its
+// own temp dir. It returns the content directory and torrent path.
+func writeReleaseFixture(t *testing.T, dir string, files map[string][]byte, opts CreateOptions) (string, string) {
+	t.Helper()
+
+	contentDir := filepath.Join(dir, "Release")
+	if err := os.MkdirAll(contentDir, 0755); err != nil {
+		t.Fatalf("failed to create content dir: %v", err)
+	}
+	for name, data := range files {
+		path := filepath.Join(contentDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	if opts.PieceLengthExp == nil {
+		pieceLenExp := uint(16)
+		opts.PieceLengthExp = &pieceLenExp
+	}
+	opts.Path = contentDir
+	opts.OutputPath = filepath.Join(t.TempDir(), "release.torrent")
+	opts.Quiet = true
+	if _, err := Create(opts); err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+	return contentDir, opts.OutputPath
+}
+
 // createTestFilesWithPattern creates test files filled with a deterministic pattern.
 func createTestFilesWithPattern(tb testing.TB, tempDir string, fileSizes []int64, pieceLen int64) ([]fileEntry, [][]byte) {
 	tb.Helper()
@@ -420,6 +457,55 @@ func TestPieceHasher_RaceConditions(t *testing.T) {
 	wg.Wait()
 }
 
+// progressRecorder is a mockDisplay recording the files reported by UpdateProgress
+type progressRecorder struct {
+	mockDisplay
+	files    []string
+	hashrate float64
+}
+
+func (r *progressRecorder) UpdateProgress(count int, hashrate float64, currentFile string, _ int64) {
+	if currentFile != "" && (len(r.files) == 0 || r.files[len(r.files)-1] != currentFile) {
+		r.files = append(r.files, currentFile)
+	}
+	if hashrate > 0 {
+		r.hashrate = hashrate
+	}
+}
+
+func (r *progressRecorder) IsBatch() bool { return false }
+
+func TestPieceHasher_ReportsCurrentFile(t *testing.T) {
+	const pieceLen = 1 << 16
+	fileSizes := []int64{16 << 20, 3 << 16, 16 << 20}
+	files, expectedHashes := createTestFilesWithPattern(t, t.TempDir(), fileSizes, pieceLen)
+
+	var totalSize int64
+	for _, size := range fileSizes {
+		totalSize += size
+	}
+	numPieces := int((totalSize + pieceLen - 1) / pieceLen)
+
+	recorder := &progressRecorder{}
+	hasher := NewPieceHasher(files, pieceLen, numPieces, recorder, false)
+
+	start := time.Now()
+	if err := hasher.hashPieces(1); err != nil {
+		t.Fatalf("hashPieces failed: %v", err)
+	}
+	throughput := float64(totalSize) / time.Since(start).Seconds()
+	verifyHashes(t, hasher.pieces, expectedHashes)
+
+	want := []string{files[0].path, files[1].path, files[2].path}
+	if !slices.Equal(recorder.files, want) {
+		t.Errorf("reported files %v, want %v", recorder.files, want)
+	}
+
+	if recorder.hashrate < throughput/2 || recorder.hashrate > throughput*2 {
+		t.Errorf("last reported hash rate %.0f B/s is not within 2x of the measured %.0f B/s", recorder.hashrate, throughput)
+	}
+}
+
 func TestPieceHasher_NoFiles(t *testing.T) {
 	hasher := NewPieceHasher([]fileEntry{}, 1<<16, 0, &mockDisplay{}, false)
 
@@ -526,6 +612,73 @@ func TestPieceHasher_CorruptedData(t *testing.T) {
 	}
 }
 
+func TestPieceHasher_FileTruncatedBeforeHashing(t *testing.T) {
+	files, _ := createTestFilesFast(t, 2, 1<<16, 1<<16)
+
+	hasher := NewPieceHasher(files, 1<<16, 2, &mockDisplay{}, false)
+	if err := os.Truncate(files[1].path, 1<<15); err != nil {
+		t.Fatalf("failed to truncate file: %v", err)
+	}
+
+	err := hasher.hashPieces(1)
+	if !errors.Is(err, ErrContentChanged) {
+		t.Fatalf("expected ErrContentChanged, got %v", err)
+	}
+	if !strings.Contains(err.Error(), files[1].path) || !strings.Contains(err.Error(), "32768") || !strings.Contains(err.Error(), "65536") {
+		t.Errorf("error should name the file and both sizes: %v", err)
+	}
+}
+
+func TestPieceHasher_FileGrownBeforeHashing(t *testing.T) {
+	files, _ := createTestFilesFast(t, 1, 1<<16, 1<<16)
+
+	hasher := NewPieceHasher(files, 1<<16, 1, &mockDisplay{}, false)
+	f, err := os.OpenFile(files[0].path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	if _, err := f.Write([]byte("more")); err != nil {
+		t.Fatalf("failed to append: %v", err)
+	}
+	f.Close()
+
+	if err := hasher.hashPieces(1); !errors.Is(err, ErrContentChanged) {
+		t.Fatalf("expected ErrContentChanged, got %v", err)
+	}
+}
+
+// shortReader serves only the first part of a file, as if it shrank after
+// hashing started.
+type shortReader struct {
+	*io.SectionReader
+	io.Closer
+}
+
+func TestPieceHasher_ShortReadDuringHashing(t *testing.T) {
+	files, _ := createTestFilesFast(t, 2, 1<<16, 1<<16)
+
+	hasher := NewPieceHasher(files, 1<<16, 2, &mockDisplay{}, false)
+	hasher.open = func(name string) (io.ReadSeekCloser, error) {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		size := int64(1 << 16)
+		if name == files[1].path {
+			size = 1000
+		}
+		return shortReader{io.NewSectionReader(f, 0, size), f}, nil
+	}
+
+	err := hasher.hashPieces(1)
+	if !errors.Is(err, ErrContentChanged) {
+		t.Fatalf("expected ErrContentChanged, got %v", err)
+	}
+	if !strings.Contains(err.Error(), files[1].path) || !strings.Contains(err.Error(), "ended after 1000 bytes") {
+		t.Errorf("error should name the file and the bytes read: %v", err)
+	}
+}
+
 // TestPieceHasher_BoundaryConditions tests scenarios where file boundaries
 // align exactly with piece boundaries.
 func TestPieceHasher_BoundaryConditions(t *testing.T) {
@@ -618,12 +771,12 @@ func TestTorrentFileSize(t *testing.T) {
 			wantError:  false,
 		},
 		{
-			name:       "large torrent for ant should adjust piece length",
+			name:       "large torrent for ant with forced small pieces should fail before hashing",
 			trackerURL: "https://anthelion.me/announce",
 			fileSize:   1 << 30, // 1 GB (down from 10 GB)
 			numFiles:   20,      // 20 files (down from 100)
-			pieceLen:   16,      // start with 64 KiB pieces
-			wantError:  false,   // should succeed by adjusting piece length
+			pieceLen:   16,      // forced 64 KiB pieces
+			wantError:  true,    // the estimate already exceeds 250 KiB
 		},
 		{
 			name:       "small torrent for ggn should be under 1 MB",
@@ -634,12 +787,12 @@ func TestTorrentFileSize(t *testing.T) {
 			wantError:  false,
 		},
 		{
-			name:       "large torrent for ggn should adjust piece length",
+			name:       "large torrent for ggn with forced small pieces should fail before hashing",
 			trackerURL: "https://gazellegames.net/announce",
 			fileSize:   5 << 30, // 5 GB (down from 50 GB)
 			numFiles:   50,      // 50 files (down from 500)
-			pieceLen:   16,      // start with 64 KiB pieces
-			wantError:  false,   // should succeed by adjusting piece length
+			pieceLen:   16,      // forced 64 KiB pieces
+			wantError:  true,    // the estimate already exceeds 1 MB
 		},
 		{
 			name:       "large torrent for ptp should be fine",
@@ -0,0 +1,65 @@
+package torrent
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/anacrolix/torrent/bencode"
+)
+
+// httpSeedsKey is the root key of BEP 17 http seeds. metainfo.MetaInfo only
+// models the BEP 19 url-list, so this key is read and written separately.
+const httpSeedsKey = "httpseeds"
+
+// ParseHTTPSeeds returns the BEP 17 http seeds of the bencoded torrent in data,
+// or nil when it has none or cannot be decoded.
+func ParseHTTPSeeds(data []byte) []string {
+	var root struct {
+		HTTPSeeds []string `bencode:"httpseeds,omitempty"`
+	}
+	if err := bencode.Unmarshal(data, &root); err != nil {
+		return nil
+	}
+	return root.HTTPSeeds
+}
+
+// Marshal returns the bencoded torrent, including the httpseeds key when
+// HTTPSeeds is set and the keys of ExtraFields.
+func (t *Torrent) Marshal() ([]byte, error) {
+	data, err := bencode.Marshal(t.MetaInfo)
+	if err != nil || len(t.HTTPSeeds) == 0 && len(t.ExtraFields) == 0 {
+		return data, err
+	}
+
+	// the info dictionary stays as raw bytes so the info hash is unchanged
+	var root map[string]bencode.Bytes
+	if err := bencode.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("could not decode torrent: %w", err)
+	}
+	for key, value := range t.ExtraFields {
+		encoded, err := bencode.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("could not encode metadata field %q: %w", key, err)
+		}
+		root[key] = encoded
+	}
+	if len(t.HTTPSeeds) > 0 {
+		seeds, err := bencode.Marshal(t.HTTPSeeds)
+		if err != nil {
+			return nil, fmt.Errorf("could not encode http seeds: %w", err)
+		}
+		root[httpSeedsKey] = seeds
+	}
+	return bencode.Marshal(root)
+}
+
+// Write writes the bencoded torrent to w, including the httpseeds key when
+// HTTPSeeds is set and the keys of ExtraFields.
+func (t *Torrent) Write(w io.Writer) error {
+	data, err := t.Marshal()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
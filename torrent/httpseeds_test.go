@@ -0,0 +1,100 @@
+package torrent
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCreate_HTTPSeeds(t *testing.T) {
+	tmpDir := t.TempDir()
+	contentPath := filepath.Join(tmpDir, "content.bin")
+	if err := os.WriteFile(contentPath, bytes.Repeat([]byte("x"), 1<<16), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+
+	webSeeds := []string{"https://seed.example/files/"}
+	httpSeeds := []string{"http://seed.example/seed.php", "http://mirror.example/seed.php"}
+	result, err := Create(CreateOptions{
+		Path:       contentPath,
+		OutputPath: filepath.Join(tmpDir, "content.torrent"),
+		WebSeeds:   webSeeds,
+		HTTPSeeds:  httpSeeds,
+		Quiet:      true,
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	loaded, err := LoadFromFile(result.Path)
+	if err != nil {
+		t.Fatalf("failed to load torrent: %v", err)
+	}
+	if !slices.Equal(loaded.HTTPSeeds, httpSeeds) {
+		t.Errorf("HTTPSeeds = %v, want %v", loaded.HTTPSeeds, httpSeeds)
+	}
+	if !slices.Equal([]string(loaded.UrlList), webSeeds) {
+		t.Errorf("UrlList = %v, want %v", loaded.UrlList, webSeeds)
+	}
+	if got := loaded.HashInfoBytes().String(); got != result.InfoHash {
+		t.Errorf("info hash changed when writing http seeds: got %s, want %s", got, result.InfoHash)
+	}
+
+	var buf bytes.Buffer
+	display := NewDisplay(NewFormatter(false))
+	display.output = &buf
+	info, _ := loaded.UnmarshalInfo()
+	display.ShowTorrentInfo(loaded, &info)
+
+	output := stripAnsiCodes(buf.String())
+	webIdx := strings.Index(output, "Web seeds:")
+	httpIdx := strings.Index(output, "HTTP seeds:")
+	if webIdx < 0 || httpIdx < 0 {
+		t.Fatalf("expected both seed lists in output:\n%s", output)
+	}
+	if section := output[httpIdx:]; !strings.Contains(section, httpSeeds[1]) || strings.Contains(section, webSeeds[0]) {
+		t.Errorf("expected the http seeds to be listed separately from the web seeds:\n%s", output)
+	}
+}
+
+func TestModifyTorrent_KeepsHTTPSeeds(t *testing.T) {
+	tmpDir := t.TempDir()
+	contentPath := filepath.Join(tmpDir, "content.bin")
+	if err := os.WriteFile(contentPath, make([]byte, 1<<16), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+	torrentPath := filepath.Join(tmpDir, "content.torrent")
+	httpSeeds := []string{"http://seed.example/seed.php"}
+	if _, err := Create(CreateOptions{Path: contentPath, OutputPath: torrentPath, HTTPSeeds: httpSeeds, Quiet: true}); err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+
+	result, err := ModifyTorrent(torrentPath, ModifyOptions{
+		OutputDir: filepath.Join(tmpDir, "out"),
+		Comment:   "modified",
+		Quiet:     true,
+	})
+	if err != nil {
+		t.Fatalf("ModifyTorrent failed: %v", err)
+	}
+
+	modified, err := LoadFromFile(result.OutputPath)
+	if err != nil {
+		t.Fatalf("failed to load modified torrent: %v", err)
+	}
+	if !slices.Equal(modified.HTTPSeeds, httpSeeds) {
+		t.Errorf("HTTPSeeds = %v, want %v", modified.HTTPSeeds, httpSeeds)
+	}
+	if modified.Comment != "modified" {
+		t.Errorf("Comment = %q, want %q", modified.Comment, "modified")
+	}
+}
+
+func TestParseHTTPSeeds_Invalid(t *testing.T) {
+	if seeds := ParseHTTPSeeds([]byte("not bencode")); seeds != nil {
+		t.Errorf("expected nil for invalid data, got %v", seeds)
+	}
+}
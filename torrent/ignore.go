@@ -1,6 +1,13 @@
 package torrent
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
 	"path/filepath"
 	"slices"
 	"strings"
@@ -17,9 +24,117 @@ var ignoredPatterns = []string{
 	"zone.identifier", // https://superuser.com/questions/1692240/auto-generated-zone-identity-files-can-should-i-delete
 }
 
+// file name prefixes to ignore in source directory (case insensitive) - These are always ignored.
+var ignoredPrefixes = []string{
+	"._", // AppleDouble resource forks written by macOS to non-HFS+ volumes
+}
+
 // directories to ignore in source directory (case insensitive) - These are always ignored.
 var ignoredDirNames = []string{
 	"@eadir",
+	"__macosx", // resource forks of archives created on macOS
+}
+
+// ignoreFileName is the name of per-directory ignore files. Patterns in such a file
+// apply to the directory containing it and all of its descendants.
+const ignoreFileName = ".mkbrrignore"
+
+// IgnoreRuleSet holds the patterns loaded from a single .mkbrrignore file.
+// Patterns are matched against paths relative to Dir.
+type IgnoreRuleSet struct {
+	Dir      string   // directory containing the .mkbrrignore file
+	Patterns []string // normalized doublestar patterns
+}
+
+// loadIgnoreRuleSet reads the .mkbrrignore file in dir, if any. It returns nil
+// when the directory has no ignore file or the file contains no patterns.
+// Blank lines and lines starting with "#" are skipped.
+func loadIgnoreRuleSet(dir string) (*IgnoreRuleSet, error) {
+	f, err := os.Open(longPath(filepath.Join(dir, ignoreFileName)))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not open %s: %w", ignoreFileName, err)
+	}
+	defer f.Close()
+	return parseIgnoreRuleSet(dir, f)
+}
+
+// loadIgnoreRuleSetFS is loadIgnoreRuleSet for a directory of fsys.
+func loadIgnoreRuleSetFS(fsys fs.FS, dir string) (*IgnoreRuleSet, error) {
+	f, err := fsys.Open(path.Join(dir, ignoreFileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not open %s: %w", ignoreFileName, err)
+	}
+	defer f.Close()
+	return parseIgnoreRuleSet(dir, f)
+}
+
+func parseIgnoreRuleSet(dir string, r io.Reader) (*IgnoreRuleSet, error) {
+	ruleSet := &IgnoreRuleSet{Dir: filepath.Clean(dir)}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, pattern := range splitPatterns(line) {
+			if normalized := normalizePattern(pattern); normalized != "" {
+				ruleSet.Patterns = append(ruleSet.Patterns, normalized)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read %s in %q: %w", ignoreFileName, dir, err)
+	}
+
+	if len(ruleSet.Patterns) == 0 {
+		return nil, nil
+	}
+	return ruleSet, nil
+}
+
+// relativePath returns path relative to the rule set's directory, or false if
+// the directory is not an ancestor of path.
+func (rs *IgnoreRuleSet) relativePath(path string) (string, bool) {
+	rel, err := filepath.Rel(rs.Dir, filepath.Clean(path))
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// matchIgnoreRuleSets reports whether path is ignored by any rule set whose
+// directory is an ancestor of path.
+func matchIgnoreRuleSets(path string, isDir bool, ruleSets []*IgnoreRuleSet) (bool, error) {
+	reason, err := ignoreRuleSetsReason(path, isDir, ruleSets)
+	return reason != "", err
+}
+
+// ignoreRuleSetsReason is like matchIgnoreRuleSets but returns a description of
+// the matching pattern and its ignore file, or "" if path is not ignored.
+func ignoreRuleSetsReason(path string, isDir bool, ruleSets []*IgnoreRuleSet) (string, error) {
+	for _, ruleSet := range ruleSets {
+		relPath, ok := ruleSet.relativePath(path)
+		if !ok {
+			continue
+		}
+		ignoreFile := filepath.Join(ruleSet.Dir, ignoreFileName)
+		for _, pattern := range ruleSet.Patterns {
+			match, err := matchPattern(pattern, relPath, isDir, false)
+			if err != nil {
+				return "", fmt.Errorf("invalid pattern %q in %s: %w", pattern, ignoreFile, err)
+			}
+			if match {
+				return fmt.Sprintf("pattern %q in %s", pattern, ignoreFile), nil
+			}
+		}
+	}
+	return "", nil
 }
 
 // normalizePattern converts a pattern to doublestar format for consistent matching.
@@ -83,18 +198,22 @@ func splitPatterns(patternGroup string) []string {
 }
 
 // matchPattern matches a pattern against a path using doublestar.
-// It handles case-insensitivity and proper directory matching.
-func matchPattern(pattern, relPath string, isDir bool) (bool, error) {
+// It ignores case unless caseSensitive is set, and handles directory matching.
+func matchPattern(pattern, relPath string, isDir, caseSensitive bool) (bool, error) {
 	if pattern == "" || relPath == "" {
 		return false, nil
 	}
 
 	pattern = normalizePattern(pattern)
-	lowerPattern := strings.ToLower(pattern)
-	lowerPath := strings.ToLower(filepath.ToSlash(relPath))
+	normPattern := pattern
+	normPath := filepath.ToSlash(relPath)
+	if !caseSensitive {
+		normPattern = strings.ToLower(normPattern)
+		normPath = strings.ToLower(normPath)
+	}
 
 	// Try matching the path directly
-	match, err := doublestar.Match(lowerPattern, lowerPath)
+	match, err := doublestar.Match(normPattern, normPath)
 	if err != nil {
 		return false, err
 	}
@@ -105,7 +224,7 @@ func matchPattern(pattern, relPath string, isDir bool) (bool, error) {
 	// For directories, also try matching with trailing slash
 	// This allows patterns like "**/extras/**" to match directory "extras"
 	if isDir {
-		match, err = doublestar.Match(lowerPattern, lowerPath+"/")
+		match, err = doublestar.Match(normPattern, normPath+"/")
 		if err != nil {
 			return false, err
 		}
@@ -115,13 +234,13 @@ func matchPattern(pattern, relPath string, isDir bool) (bool, error) {
 
 		// For patterns like "**/dirname/**", check if the directory is in the path
 		// This allows early directory skipping for recursive exclude patterns
-		if strings.HasPrefix(lowerPattern, "**/") && strings.HasSuffix(lowerPattern, "/**") {
+		if strings.HasPrefix(normPattern, "**/") && strings.HasSuffix(normPattern, "/**") {
 			// Extract the middle part: "**/extras/**" -> "extras"
-			middle := strings.TrimPrefix(lowerPattern, "**/")
+			middle := strings.TrimPrefix(normPattern, "**/")
 			middle = strings.TrimSuffix(middle, "/**")
 			// Only match if middle is a simple literal (no wildcards)
 			if !strings.ContainsAny(middle, "*?[{") {
-				pathParts := strings.Split(lowerPath, "/")
+				pathParts := strings.Split(normPath, "/")
 				if slices.Contains(pathParts, middle) {
 					return true, nil
 				}
@@ -132,6 +251,29 @@ func matchPattern(pattern, relPath string, isDir bool) (bool, error) {
 	return false, nil
 }
 
+// skippedFile is a file or directory left out of a torrent, with the reason it was ignored.
+type skippedFile struct {
+	path   string
+	reason string
+}
+
+// Reasons reported for entries skipped by ignoreEntryReason.
+const (
+	ignoreReasonBuiltin    = "built-in ignore"
+	ignoreReasonExclude    = "exclude pattern"
+	ignoreReasonExcludeDir = "excluded directory"
+	ignoreReasonInclude    = "not matching include"
+	ignoreReasonHidden     = "hidden"
+	ignoreReasonMaxDepth   = "beyond max depth"
+)
+
+// Reasons reported for symlinks and duplicates skipped during the walk.
+const (
+	skipReasonDirSymlink  = "directory symlink, not followed"
+	skipReasonSymlinkLoop = "directory already included, symlink loop"
+	skipReasonDuplicate   = "same file as one already included"
+)
+
 // shouldIgnoreEntry checks if a file or directory should be ignored based on
 // predefined patterns, user-defined include patterns, and user-defined exclude patterns.
 // It uses doublestar for full glob support including ** recursive matching.
@@ -141,6 +283,7 @@ func matchPattern(pattern, relPath string, isDir bool) (bool, error) {
 //   - isDir: true if the entry is a directory
 //   - excludePatterns: patterns to exclude (glob syntax)
 //   - includePatterns: patterns to include (glob syntax, acts as whitelist)
+//   - caseSensitive: match the patterns without ignoring case
 //
 // Logic:
 //  1. Check hardcoded ignored directory names (always ignored).
@@ -150,9 +293,17 @@ func matchPattern(pattern, relPath string, isDir bool) (bool, error) {
 //     - For files: must match at least one include pattern, otherwise ignored.
 //  4. Check exclude patterns: if matched, ignore the entry.
 //  5. If none of the above, keep the entry.
-func shouldIgnoreEntry(relPath string, isDir bool, excludePatterns []string, includePatterns []string) (bool, error) {
+func shouldIgnoreEntry(relPath string, isDir bool, excludePatterns []string, includePatterns []string, caseSensitive bool) (bool, error) {
+	reason, err := ignoreEntryReason(relPath, isDir, excludePatterns, includePatterns, caseSensitive)
+	return reason != "", err
+}
+
+// ignoreEntryReason applies the same rules as shouldIgnoreEntry and returns why
+// the entry is ignored, or "" if it is kept. Exclude reasons name the matching pattern.
+// The built-in ignores always ignore case.
+func ignoreEntryReason(relPath string, isDir bool, excludePatterns []string, includePatterns []string, caseSensitive bool) (string, error) {
 	if relPath == "" || relPath == "." {
-		return false, nil
+		return "", nil
 	}
 
 	// Normalize path to forward slashes
@@ -163,7 +314,7 @@ func shouldIgnoreEntry(relPath string, isDir bool, excludePatterns []string, inc
 	segments := strings.Split(lowerRelPath, "/")
 	for _, segment := range segments {
 		if slices.Contains(ignoredDirNames, segment) {
-			return true, nil
+			return ignoreReasonBuiltin, nil
 		}
 	}
 
@@ -171,16 +322,19 @@ func shouldIgnoreEntry(relPath string, isDir bool, excludePatterns []string, inc
 	if !isDir {
 		for _, pattern := range ignoredPatterns {
 			if strings.HasSuffix(lowerRelPath, pattern) {
-				return true, nil
+				return ignoreReasonBuiltin, nil
 			}
 		}
+		if hasIgnoredPrefix(segments[len(segments)-1]) {
+			return ignoreReasonBuiltin, nil
+		}
 	}
 
 	// 3. Check include patterns if provided
 	if len(includePatterns) > 0 {
 		// For directories: always traverse to find matching files inside
 		if isDir {
-			return false, nil
+			return "", nil
 		}
 
 		// For files: must match at least one include pattern
@@ -190,9 +344,9 @@ func shouldIgnoreEntry(relPath string, isDir bool, excludePatterns []string, inc
 				if pattern == "" {
 					continue
 				}
-				match, err := matchPattern(pattern, relPath, false)
+				match, err := matchPattern(pattern, relPath, false, caseSensitive)
 				if err != nil {
-					return false, err
+					return "", err
 				}
 				if match {
 					matchesInclude = true
@@ -205,10 +359,10 @@ func shouldIgnoreEntry(relPath string, isDir bool, excludePatterns []string, inc
 		}
 
 		if !matchesInclude {
-			return true, nil // Ignore file because no include pattern matched
+			return ignoreReasonInclude, nil // Ignore file because no include pattern matched
 		}
 
-		return false, nil // Keep file because include patterns are a whitelist
+		return "", nil // Keep file because include patterns are a whitelist
 	}
 
 	// 4. Check exclude patterns
@@ -218,31 +372,51 @@ func shouldIgnoreEntry(relPath string, isDir bool, excludePatterns []string, inc
 				if pattern == "" {
 					continue
 				}
-				match, err := matchPattern(pattern, relPath, isDir)
+				match, err := matchPattern(pattern, relPath, isDir, caseSensitive)
 				if err != nil {
-					return false, err
+					return "", err
 				}
 				if match {
-					return true, nil // Ignore because it matches exclude pattern
+					return fmt.Sprintf("%s %q", ignoreReasonExclude, pattern), nil // Ignore because it matches exclude pattern
 				}
 			}
 		}
 	}
 
 	// 5. Keep the entry (don't ignore)
-	return false, nil
+	return "", nil
 }
 
 // shouldIgnoreFile checks if a file should be ignored based on predefined patterns,
 // user-defined include patterns, and user-defined exclude patterns (glob matching).
 // This is a wrapper around shouldIgnoreEntry for backward compatibility.
+// Rule sets loaded from .mkbrrignore files are applied only when their directory
+// is an ancestor of path.
 //
 // Deprecated: Use shouldIgnoreEntry directly for new code.
-func shouldIgnoreFile(path string, excludePatterns []string, includePatterns []string) (bool, error) {
+func shouldIgnoreFile(path string, excludePatterns []string, includePatterns []string, ruleSets []*IgnoreRuleSet) (bool, error) {
 	// For backward compatibility, extract just the filename and match against it
 	// This maintains the old behavior when called with absolute paths
 	filename := filepath.Base(path)
-	return shouldIgnoreEntry(filename, false, excludePatterns, includePatterns)
+	ignore, err := shouldIgnoreEntry(filename, false, excludePatterns, includePatterns, false)
+	if err != nil || ignore {
+		return ignore, err
+	}
+	return matchIgnoreRuleSets(path, false, ruleSets)
+}
+
+// isExcludedDir reports whether the base name of dir matches one of
+// excludeDirs, ignoring case. Entries may hold comma-separated names.
+func isExcludedDir(dir string, excludeDirs []string) bool {
+	name := filepath.Base(dir)
+	for _, group := range excludeDirs {
+		for _, excluded := range splitPatterns(group) {
+			if strings.EqualFold(name, excluded) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // shouldIgnoreDir checks if any directory segment in the path should be ignored.
@@ -261,3 +435,45 @@ func shouldIgnoreDir(path string) bool {
 
 	return false
 }
+
+// hasIgnoredPrefix reports whether name, a lowercase file name, starts with
+// one of ignoredPrefixes and has more after it.
+func hasIgnoredPrefix(name string) bool {
+	for _, prefix := range ignoredPrefixes {
+		if len(name) > len(prefix) && strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMacOSResourceFork reports whether path is macOS metadata that never
+// belongs in a torrent: an AppleDouble file named "._<name>", or anything in a
+// __MACOSX directory. Both / and \ separate path elements, whatever the OS.
+func IsMacOSResourceFork(path string) bool {
+	segments := strings.FieldsFunc(strings.ToLower(path), func(r rune) bool {
+		return r == '/' || r == '\\'
+	})
+	if len(segments) == 0 {
+		return false
+	}
+	if slices.Contains(segments, "__macosx") {
+		return true
+	}
+	return hasIgnoredPrefix(segments[len(segments)-1])
+}
+
+// isHiddenName reports whether name, a single path element, starts with a dot.
+func isHiddenName(name string) bool {
+	return strings.HasPrefix(name, ".") && name != "." && name != ".."
+}
+
+// beyondMaxDepth reports whether the directory at relPath, relative to the
+// content path, lies deeper than maxDepth levels, counting the directory
+// itself. It never does when maxDepth is nil.
+func beyondMaxDepth(relPath string, maxDepth *int) bool {
+	if maxDepth == nil || relPath == "" {
+		return false
+	}
+	return strings.Count(filepath.ToSlash(relPath), "/")+1 > *maxDepth
+}
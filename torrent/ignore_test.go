@@ -1,6 +1,10 @@
 package torrent
 
 import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -271,7 +275,7 @@ func TestMatchPattern(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := matchPattern(tt.pattern, tt.relPath, tt.isDir)
+			got, err := matchPattern(tt.pattern, tt.relPath, tt.isDir, false)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("matchPattern() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -538,7 +542,7 @@ func TestShouldIgnoreEntry(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := shouldIgnoreEntry(tt.relPath, tt.isDir, tt.excludePatterns, tt.includePatterns)
+			got, err := shouldIgnoreEntry(tt.relPath, tt.isDir, tt.excludePatterns, tt.includePatterns, false)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("shouldIgnoreEntry() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -584,7 +588,7 @@ func TestShouldIgnoreFile(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := shouldIgnoreFile(tt.path, tt.excludePatterns, tt.includePatterns)
+			got, err := shouldIgnoreFile(tt.path, tt.excludePatterns, tt.includePatterns, nil)
 			if err != nil {
 				t.Errorf("shouldIgnoreFile() error = %v", err)
 				return
@@ -635,3 +639,290 @@ func TestShouldIgnoreDir(t *testing.T) {
 		})
 	}
 }
+
+func TestShouldIgnoreEntry_CaseSensitive(t *testing.T) {
+	tests := []struct {
+		name            string
+		relPath         string
+		isDir           bool
+		excludePatterns []string
+		includePatterns []string
+		caseSensitive   bool
+		want            bool
+	}{
+		{name: "insensitive exclude matches other case", relPath: "readme", excludePatterns: []string{"README"}, want: true},
+		{name: "sensitive exclude matches same case", relPath: "README", excludePatterns: []string{"README"}, caseSensitive: true, want: true},
+		{name: "sensitive exclude keeps other case", relPath: "readme", excludePatterns: []string{"README"}, caseSensitive: true, want: false},
+		{name: "sensitive exclude in subdirectory", relPath: "docs/README", excludePatterns: []string{"README"}, caseSensitive: true, want: true},
+		{name: "sensitive recursive directory pattern", relPath: "Extras", isDir: true, excludePatterns: []string{"**/extras/**"}, caseSensitive: true, want: false},
+		{name: "insensitive recursive directory pattern", relPath: "Extras", isDir: true, excludePatterns: []string{"**/extras/**"}, want: true},
+		{name: "sensitive include skips other case", relPath: "video.MKV", includePatterns: []string{"*.mkv"}, caseSensitive: true, want: true},
+		{name: "insensitive include keeps other case", relPath: "video.MKV", includePatterns: []string{"*.mkv"}, want: false},
+		{name: "built-in ignores still ignore case", relPath: "THUMBS.DB", caseSensitive: true, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := shouldIgnoreEntry(tt.relPath, tt.isDir, tt.excludePatterns, tt.includePatterns, tt.caseSensitive)
+			if err != nil {
+				t.Fatalf("shouldIgnoreEntry() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("shouldIgnoreEntry(%q, caseSensitive=%v) = %v, want %v", tt.relPath, tt.caseSensitive, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestIgnoreEntryReason tests that skipped entries report why they were ignored.
+func TestIgnoreEntryReason(t *testing.T) {
+	tests := []struct {
+		name            string
+		relPath         string
+		isDir           bool
+		excludePatterns []string
+		includePatterns []string
+		wantReason      string
+	}{
+		{
+			name:       "built-in file pattern",
+			relPath:    "Screens/Thumbs.db",
+			wantReason: ignoreReasonBuiltin,
+		},
+		{
+			name:       "built-in directory",
+			relPath:    "@eaDir",
+			isDir:      true,
+			wantReason: ignoreReasonBuiltin,
+		},
+		{
+			name:            "exclude pattern names the pattern",
+			relPath:         "release.nfo",
+			excludePatterns: []string{"*.jpg,*.nfo"},
+			wantReason:      `exclude pattern "*.nfo"`,
+		},
+		{
+			name:            "not matching include",
+			relPath:         "release.nfo",
+			includePatterns: []string{"*.mkv"},
+			wantReason:      ignoreReasonInclude,
+		},
+		{
+			name:            "kept file",
+			relPath:         "release.mkv",
+			excludePatterns: []string{"*.nfo"},
+			wantReason:      "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ignoreEntryReason(tt.relPath, tt.isDir, tt.excludePatterns, tt.includePatterns, false)
+			if err != nil {
+				t.Fatalf("ignoreEntryReason() error = %v", err)
+			}
+			if got != tt.wantReason {
+				t.Errorf("ignoreEntryReason(%q) = %q, want %q", tt.relPath, got, tt.wantReason)
+			}
+		})
+	}
+
+	root := filepath.Join("/data", "release")
+	ruleSets := []*IgnoreRuleSet{{Dir: root, Patterns: []string{normalizePattern("*.nfo")}}}
+	got, err := ignoreRuleSetsReason(filepath.Join(root, "release.nfo"), false, ruleSets)
+	if err != nil {
+		t.Fatalf("ignoreRuleSetsReason() error = %v", err)
+	}
+	if want := filepath.Join(root, ignoreFileName); !strings.Contains(got, want) {
+		t.Errorf("ignoreRuleSetsReason() = %q, want it to mention %q", got, want)
+	}
+}
+
+// TestShouldIgnoreFileRuleSets tests that .mkbrrignore rule sets only apply to
+// files below the directory they were loaded from.
+func TestShouldIgnoreFileRuleSets(t *testing.T) {
+	root := filepath.Join("/data", "release")
+	ruleSets := []*IgnoreRuleSet{
+		{Dir: root, Patterns: []string{normalizePattern("*.nfo")}},
+		{Dir: filepath.Join(root, "Screens"), Patterns: []string{normalizePattern("*.jpg")}},
+	}
+
+	tests := []struct {
+		path       string
+		wantIgnore bool
+	}{
+		{path: filepath.Join(root, "release.nfo"), wantIgnore: true},
+		{path: filepath.Join(root, "Screens", "info.nfo"), wantIgnore: true},
+		{path: filepath.Join(root, "Screens", "shot.jpg"), wantIgnore: true},
+		{path: filepath.Join(root, "cover.jpg"), wantIgnore: false},
+		{path: filepath.Join(root, "Extras", "still.jpg"), wantIgnore: false},
+		{path: filepath.Join(root, "Screens2", "shot.jpg"), wantIgnore: false},
+		{path: filepath.Join("/data", "other.nfo"), wantIgnore: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := shouldIgnoreFile(tt.path, nil, nil, ruleSets)
+			if err != nil {
+				t.Fatalf("shouldIgnoreFile() error = %v", err)
+			}
+			if got != tt.wantIgnore {
+				t.Errorf("shouldIgnoreFile(%q) = %v, want %v", tt.path, got, tt.wantIgnore)
+			}
+		})
+	}
+}
+
+// TestCreateTorrent_MkbrrignoreCascading tests that nested .mkbrrignore files
+// apply to their own directory and descendants, but not to sibling directories.
+func TestCreateTorrent_MkbrrignoreCascading(t *testing.T) {
+	rootDir := t.TempDir()
+
+	files := map[string]string{
+		".mkbrrignore":         "# release metadata\n*.nfo\n",
+		"movie.mkv":            "video data",
+		"release.nfo":          "ignored by root rules",
+		"cover.jpg":            "kept, root has no jpg rule",
+		"Screens/.mkbrrignore": "*.jpg\nraw/\n",
+		"Screens/shot1.jpg":    "ignored by Screens rules",
+		"Screens/shot1.png":    "kept",
+		"Screens/info.nfo":     "ignored by cascaded root rules",
+		"Screens/raw/a.png":    "ignored directory",
+		"Extras/still.jpg":     "kept, sibling of Screens",
+		"Extras/bonus.nfo":     "ignored by cascaded root rules",
+	}
+	for rel, content := range files {
+		path := filepath.Join(rootDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", rel, err)
+		}
+	}
+
+	tor, err := CreateTorrent(CreateOptions{
+		Path:      rootDir,
+		NoCreator: true,
+		NoDate:    true,
+	})
+	if err != nil {
+		t.Fatalf("CreateTorrent failed: %v", err)
+	}
+
+	var got []string
+	for _, f := range tor.GetInfo().Files {
+		got = append(got, strings.Join(f.Path, "/"))
+	}
+	slices.Sort(got)
+
+	want := []string{"Extras/still.jpg", "Screens/shot1.png", "cover.jpg", "movie.mkv"}
+	if !slices.Equal(got, want) {
+		t.Errorf("torrent files = %v, want %v", got, want)
+	}
+}
+
+// TestCreateTorrent_ExcludeDirs tests that excluded directory names prune the
+// whole subtree at any depth, case-insensitively, without affecting files.
+func TestCreateTorrent_ExcludeDirs(t *testing.T) {
+	rootDir := t.TempDir()
+
+	files := map[string]string{
+		"movie.mkv":               "video data",
+		"sample":                  "kept, a file named like an excluded dir",
+		"Sample/movie.sample.mkv": "ignored directory",
+		"Extras/proof/cover.jpg":  "ignored nested directory",
+		"Extras/still.jpg":        "kept",
+		"SampleShots/shot.png":    "kept, only the exact name is excluded",
+		".git/config":             "ignored directory",
+	}
+	for rel, content := range files {
+		path := filepath.Join(rootDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", rel, err)
+		}
+	}
+
+	tor, err := NewTorrentBuilder(rootDir).
+		WithExcludeDir("sample,PROOF").
+		WithExcludeDir(".git").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	var got []string
+	for _, f := range tor.GetInfo().Files {
+		got = append(got, strings.Join(f.Path, "/"))
+	}
+	slices.Sort(got)
+
+	want := []string{"Extras/still.jpg", "SampleShots/shot.png", "movie.mkv", "sample"}
+	if !slices.Equal(got, want) {
+		t.Errorf("torrent files = %v, want %v", got, want)
+	}
+}
+
+func TestIsMacOSResourceFork(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"._movie.mkv", true},
+		{"Release/._movie.mkv", true},
+		{"Release/__MACOSX/movie.mkv", true},
+		{"C:\\Release\\__macosx\\._movie.mkv", true},
+		{"__MACOSX", true},
+		{"Release/movie.mkv", false},
+		{"Release/._", false},
+		{"Release/.hidden", false},
+		{"._dir/movie.mkv", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsMacOSResourceFork(tt.path); got != tt.want {
+			t.Errorf("IsMacOSResourceFork(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCreateTorrent_IgnoresMacOSResourceForks(t *testing.T) {
+	rootDir := t.TempDir()
+
+	files := map[string]string{
+		"movie.mkv":                "video data",
+		"._movie.mkv":              "resource fork",
+		"Extras/._cover.jpg":       "resource fork",
+		"Extras/cover.jpg":         "kept",
+		"__MACOSX/._movie.mkv":     "archive metadata",
+		"__MACOSX/Extras/info.txt": "archive metadata",
+	}
+	for rel, content := range files {
+		path := filepath.Join(rootDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", rel, err)
+		}
+	}
+
+	tor, err := CreateTorrent(CreateOptions{Path: rootDir, NoCreator: true, NoDate: true})
+	if err != nil {
+		t.Fatalf("CreateTorrent failed: %v", err)
+	}
+
+	var got []string
+	for _, f := range tor.GetInfo().Files {
+		got = append(got, strings.Join(f.Path, "/"))
+	}
+	slices.Sort(got)
+
+	want := []string{"Extras/cover.jpg", "movie.mkv"}
+	if !slices.Equal(got, want) {
+		t.Errorf("torrent files = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+package torrent
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// InspectFields are the torrent fields available to a format template of
+// FormatInspect, e.g. "{{.Name}} {{.InfoHash}}"
+type InspectFields struct {
+	Name         string
+	InfoHash     string // hex v1 info hash
+	Size         int64  // total size of the files in bytes
+	PieceLength  int64
+	PieceCount   int
+	Announce     string
+	AnnounceList []string // every tracker of every tier, in order
+	Private      bool
+	Source       string
+	Comment      string
+	CreationDate int64 // unix seconds, 0 when not set
+	CreatedBy    string
+	Files        []InspectFile // the single file of a single-file torrent as well
+	WebSeeds     []string
+}
+
+// InspectFile is a file of InspectFields
+type InspectFile struct {
+	Path string // path in the torrent, "/" separated
+	Size int64
+}
+
+// NewInspectFields returns the fields of the torrent for a format template.
+func NewInspectFields(mi *metainfo.MetaInfo, info *metainfo.Info) InspectFields {
+	fields := InspectFields{
+		Name:         info.Name,
+		InfoHash:     mi.HashInfoBytes().HexString(),
+		Size:         info.TotalLength(),
+		PieceLength:  info.PieceLength,
+		PieceCount:   info.NumPieces(),
+		Announce:     mi.Announce,
+		Private:      info.Private != nil && *info.Private,
+		Source:       info.Source,
+		Comment:      mi.Comment,
+		CreationDate: mi.CreationDate,
+		CreatedBy:    mi.CreatedBy,
+		WebSeeds:     mi.UrlList,
+	}
+	for _, tier := range mi.AnnounceList {
+		fields.AnnounceList = append(fields.AnnounceList, tier...)
+	}
+	if info.IsDir() {
+		for _, f := range info.Files {
+			fields.Files = append(fields.Files, InspectFile{Path: strings.Join(f.Path, "/"), Size: f.Length})
+		}
+	} else {
+		fields.Files = []InspectFile{{Path: info.Name, Size: info.Length}}
+	}
+	return fields
+}
+
+// ParseInspectFormat parses a text/template over InspectFields. The template
+// is run once against placeholder fields, so a field that does not exist
+// fails here rather than after the first torrent is loaded.
+func ParseInspectFormat(text string) (*template.Template, error) {
+	tmpl, err := template.New("format").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format template: %w", err)
+	}
+	placeholder := InspectFields{
+		AnnounceList: []string{""},
+		Files:        []InspectFile{{}},
+		WebSeeds:     []string{""},
+	}
+	if err := tmpl.Execute(io.Discard, placeholder); err != nil {
+		return nil, fmt.Errorf("invalid format template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// FormatInspect writes the fields of the torrent to w through tmpl, from
+// ParseInspectFormat, followed by a newline.
+func FormatInspect(w io.Writer, tmpl *template.Template, mi *metainfo.MetaInfo, info *metainfo.Info) error {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, NewInspectFields(mi, info)); err != nil {
+		return fmt.Errorf("failed to format %q: %w", info.Name, err)
+	}
+	b.WriteString("\n")
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
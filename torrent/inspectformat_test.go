@@ -0,0 +1,81 @@
+package torrent
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// loadInspectFixture creates a private two-file torrent and returns it loaded.
+func loadInspectFixture(t *testing.T) (*metainfo.MetaInfo, *metainfo.Info) {
+	t.Helper()
+
+	_, torrentPath := writeReleaseFixture(t, t.TempDir(), map[string][]byte{
+		"a.bin": bytes.Repeat([]byte("a"), 1000),
+		"b.bin": bytes.Repeat([]byte("b"), 70000),
+	}, CreateOptions{
+		TrackerURLs: []string{"https://tracker.example/announce"},
+		Source:      "EXAMPLE",
+		IsPrivate:   true,
+		NoDate:      true,
+	})
+
+	mi, err := metainfo.LoadFromFile(torrentPath)
+	if err != nil {
+		t.Fatalf("failed to load torrent: %v", err)
+	}
+	info, err := mi.UnmarshalInfo()
+	if err != nil {
+		t.Fatalf("failed to unmarshal info: %v", err)
+	}
+	return mi, &info
+}
+
+func TestFormatInspect_InfoHash(t *testing.T) {
+	mi, info := loadInspectFixture(t)
+
+	tmpl, err := ParseInspectFormat("{{.InfoHash}}")
+	if err != nil {
+		t.Fatalf("ParseInspectFormat failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := FormatInspect(&buf, tmpl, mi, info); err != nil {
+		t.Fatalf("FormatInspect failed: %v", err)
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{40}\n$`).MatchString(buf.String()) {
+		t.Errorf("output = %q, want a 40 character hex info hash and a newline", buf.String())
+	}
+	if want := mi.HashInfoBytes().HexString() + "\n"; buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestFormatInspect_Fields(t *testing.T) {
+	mi, info := loadInspectFixture(t)
+
+	tmpl, err := ParseInspectFormat(`{{.Name}} {{.Size}} {{.PieceLength}} {{.PieceCount}} {{.Private}} {{.Source}} {{.Announce}} {{.CreationDate}}{{range .Files}} {{.Path}}={{.Size}}{{end}}`)
+	if err != nil {
+		t.Fatalf("ParseInspectFormat failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := FormatInspect(&buf, tmpl, mi, info); err != nil {
+		t.Fatalf("FormatInspect failed: %v", err)
+	}
+	want := "Release 71000 65536 2 true EXAMPLE https://tracker.example/announce 0 a.bin=1000 b.bin=70000\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestParseInspectFormat_Invalid(t *testing.T) {
+	for _, text := range []string{"{{.Name", "{{.Passkey}}", "{{range .Files}}{{.Length}}{{end}}"} {
+		if _, err := ParseInspectFormat(text); err == nil {
+			t.Errorf("ParseInspectFormat(%q) succeeded, want an error", text)
+		}
+	}
+	if _, err := ParseInspectFormat("{{(index .Files 0).Path}} {{index .AnnounceList 0}}"); err != nil {
+		t.Errorf("indexing the lists should be valid: %v", err)
+	}
+}
@@ -0,0 +1,561 @@
+package torrent
+
+import (
+	"fmt"
+	"math/bits"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/anacrolix/torrent/bencode"
+	"github.com/anacrolix/torrent/metainfo"
+
+	"github.com/autobrr/mkbrr/internal/trackers"
+)
+
+// Severities of LintIssue
+const (
+	LintSeverityError   = "error"   // clients may reject or misread the torrent
+	LintSeverityWarning = "warning" // the torrent works but is likely not what was intended
+)
+
+// LintIssue is a problem LintTorrent found in a torrent
+type LintIssue struct {
+	Rule     string // name of the rule that found it, such as "pieces"
+	Severity string // one of the LintSeverity* constants
+	Message  string
+	Fixable  bool // fixed by LintOptions.Fix
+}
+
+// LintOptions holds options for LintTorrent
+type LintOptions struct {
+	// Fix rewrites the torrent with the fixable issues fixed. Fixing a rule
+	// for the info dictionary, such as path-separators, changes the info hash.
+	Fix bool
+	// OutputPath is where the fixed torrent is written, the torrent itself
+	// when empty
+	OutputPath string
+}
+
+// LintResult is the outcome of LintTorrent
+type LintResult struct {
+	Path        string
+	Issues      []LintIssue // found before fixing
+	Fixed       []string    // rules whose issues were fixed
+	OutputPath  string      // where the fixed torrent was written, empty when nothing was fixed
+	NewInfoHash string      // hex info hash after a fix changed the info dictionary
+}
+
+// HasErrors reports whether any issue is an error rather than a warning.
+func (r *LintResult) HasErrors() bool {
+	for _, issue := range r.Issues {
+		if issue.Severity == LintSeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// lintTorrent is a torrent decoded at the bencode level, so fields with the
+// wrong type or invalid values are seen as they are instead of being
+// rejected or converted like metainfo does
+type lintTorrent struct {
+	root        map[string]bencode.Bytes
+	info        map[string]bencode.Bytes   // nil when missing or not a dictionary
+	files       []map[string]bencode.Bytes // nil for single-file torrents or a malformed files list
+	infoChanged bool                       // a fix changed info or files
+}
+
+// lintRule is one check of LintTorrent. fix, when set, rewrites the torrent so
+// the check passes.
+type lintRule struct {
+	name  string
+	check func(t *lintTorrent) []LintIssue
+	fix   func(t *lintTorrent) error
+}
+
+// lintRules are the checks LintTorrent runs, in order
+var lintRules = []lintRule{
+	{name: "info", check: lintInfo},
+	{name: "length-files", check: lintLengthFiles},
+	{name: "negative-length", check: lintNegativeLength},
+	{name: "piece-length", check: lintPieceLength},
+	{name: "pieces", check: lintPieces},
+	{name: "name", check: lintName},
+	{name: "utf8", check: lintUTF8},
+	{name: "path-components", check: lintPathComponents},
+	{name: "path-separators", check: lintPathSeparators, fix: fixPathSeparators},
+	{name: "duplicate-paths", check: lintDuplicatePaths},
+	{name: "private-value", check: lintPrivateValue},
+	{name: "private-flag", check: lintPrivateFlag},
+	{name: "announce-list", check: lintAnnounceList, fix: fixAnnounceList},
+}
+
+// LintRules returns the names of the rules LintTorrent runs, in order.
+func LintRules() []string {
+	names := make([]string, len(lintRules))
+	for i, rule := range lintRules {
+		names[i] = rule.name
+	}
+	return names
+}
+
+// LintTorrent loads the torrent at path at the bencode level and runs a set
+// of structural checks against it, see LintRules. Only a file that is not a
+// bencoded dictionary is an error; problems with its contents are returned
+// as issues. With opts.Fix the fixable issues are fixed and the torrent is
+// written again.
+func LintTorrent(path string, opts LintOptions) (*LintResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read torrent %q: %w", path, err)
+	}
+	t, err := parseLintTorrent(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode torrent %q: %w", path, err)
+	}
+
+	result := &LintResult{Path: path}
+	var toFix []lintRule
+	for _, rule := range lintRules {
+		issues := rule.check(t)
+		for i := range issues {
+			issues[i].Rule = rule.name
+			issues[i].Fixable = rule.fix != nil
+		}
+		result.Issues = append(result.Issues, issues...)
+		if len(issues) > 0 && rule.fix != nil {
+			toFix = append(toFix, rule)
+		}
+	}
+
+	if !opts.Fix || len(toFix) == 0 {
+		return result, nil
+	}
+	for _, rule := range toFix {
+		if err := rule.fix(t); err != nil {
+			return nil, fmt.Errorf("could not fix %s: %w", rule.name, err)
+		}
+		result.Fixed = append(result.Fixed, rule.name)
+	}
+
+	if t.infoChanged {
+		if t.files != nil {
+			if err := t.setInfo("files", t.files); err != nil {
+				return nil, err
+			}
+		}
+		info, err := bencode.Marshal(t.info)
+		if err != nil {
+			return nil, fmt.Errorf("could not encode info dictionary: %w", err)
+		}
+		t.root["info"] = info
+		result.NewInfoHash = metainfo.HashBytes(info).HexString()
+	}
+	fixed, err := bencode.Marshal(t.root)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode torrent: %w", err)
+	}
+
+	outPath := opts.OutputPath
+	if outPath == "" {
+		outPath = path
+	}
+	if err := os.WriteFile(outPath, fixed, 0644); err != nil {
+		return nil, fmt.Errorf("could not write fixed torrent: %w", err)
+	}
+	result.OutputPath = outPath
+	return result, nil
+}
+
+// parseLintTorrent decodes data, which must be a bencoded dictionary.
+func parseLintTorrent(data []byte) (*lintTorrent, error) {
+	var root map[string]bencode.Bytes
+	if err := bencode.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	t := &lintTorrent{root: root}
+	if raw, ok := root["info"]; ok {
+		var info map[string]bencode.Bytes
+		if bencode.Unmarshal(raw, &info) == nil {
+			t.info = info
+		}
+	}
+	if raw, ok := t.info["files"]; ok {
+		var files []map[string]bencode.Bytes
+		if bencode.Unmarshal(raw, &files) == nil {
+			t.files = files
+		}
+	}
+	return t, nil
+}
+
+// setInfo replaces key of the info dictionary with the encoded value.
+func (t *lintTorrent) setInfo(key string, value any) error {
+	data, err := bencode.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("could not encode %s: %w", key, err)
+	}
+	t.info[key] = data
+	t.infoChanged = true
+	return nil
+}
+
+// lintInt decodes the integer at key of dict, reporting whether it is present
+// and an integer.
+func lintInt(dict map[string]bencode.Bytes, key string) (int64, bool) {
+	var n int64
+	raw, ok := dict[key]
+	return n, ok && bencode.Unmarshal(raw, &n) == nil
+}
+
+// lintString is lintInt for byte strings.
+func lintString(dict map[string]bencode.Bytes, key string) (string, bool) {
+	var s string
+	raw, ok := dict[key]
+	return s, ok && bencode.Unmarshal(raw, &s) == nil
+}
+
+// lintPath returns the path components of a files entry.
+func lintPath(file map[string]bencode.Bytes) []string {
+	var path []string
+	if raw, ok := file["path"]; ok {
+		_ = bencode.Unmarshal(raw, &path)
+	}
+	return path
+}
+
+// totalLength returns the v1 content size, reporting false when a length is
+// missing or not an integer.
+func (t *lintTorrent) totalLength() (int64, bool) {
+	if _, ok := t.info["files"]; !ok {
+		return lintInt(t.info, "length")
+	}
+	if t.files == nil {
+		return 0, false
+	}
+	var total int64
+	for _, file := range t.files {
+		length, ok := lintInt(file, "length")
+		if !ok {
+			return 0, false
+		}
+		total += length
+	}
+	return total, true
+}
+
+// v2Only reports whether the torrent is a v2 torrent without v1 fields.
+func (t *lintTorrent) v2Only() bool {
+	version, _ := lintInt(t.info, "meta version")
+	_, hasPieces := t.info["pieces"]
+	return version == 2 && !hasPieces
+}
+
+func lintError(format string, args ...any) LintIssue {
+	return LintIssue{Severity: LintSeverityError, Message: fmt.Sprintf(format, args...)}
+}
+
+func lintWarning(format string, args ...any) LintIssue {
+	return LintIssue{Severity: LintSeverityWarning, Message: fmt.Sprintf(format, args...)}
+}
+
+// lintInfo checks that the info dictionary is present.
+func lintInfo(t *lintTorrent) []LintIssue {
+	if _, ok := t.root["info"]; !ok {
+		return []LintIssue{lintError("the info dictionary is missing")}
+	}
+	if t.info == nil {
+		return []LintIssue{lintError("info is not a dictionary")}
+	}
+	return nil
+}
+
+// lintLengthFiles checks that a torrent is either a single-file torrent with
+// length or a multi-file torrent with files, not both or neither.
+func lintLengthFiles(t *lintTorrent) []LintIssue {
+	if t.info == nil || t.v2Only() {
+		return nil
+	}
+	_, hasLength := t.info["length"]
+	_, hasFiles := t.info["files"]
+	switch {
+	case hasLength && hasFiles:
+		return []LintIssue{lintError("both length and files are present, so clients disagree on whether it is a single-file or multi-file torrent")}
+	case !hasLength && !hasFiles:
+		return []LintIssue{lintError("neither length nor files is present")}
+	case hasFiles && t.files == nil:
+		return []LintIssue{lintError("files is not a list of dictionaries")}
+	}
+	return nil
+}
+
+// lintNegativeLength checks that lengths are integers and not negative.
+func lintNegativeLength(t *lintTorrent) []LintIssue {
+	if t.info == nil {
+		return nil
+	}
+	var issues []LintIssue
+	if _, ok := t.info["length"]; ok {
+		if length, ok := lintInt(t.info, "length"); !ok {
+			issues = append(issues, lintError("length is not an integer"))
+		} else if length < 0 {
+			issues = append(issues, lintError("length %d is negative", length))
+		}
+	}
+	for i, file := range t.files {
+		if length, ok := lintInt(file, "length"); !ok {
+			issues = append(issues, lintError("file #%d has no integer length", i+1))
+		} else if length < 0 {
+			issues = append(issues, lintError("file #%d has negative length %d", i+1, length))
+		}
+	}
+	return issues
+}
+
+// lintPieceLength checks that the piece length is positive and, as clients
+// expect, a power of two.
+func lintPieceLength(t *lintTorrent) []LintIssue {
+	if t.info == nil {
+		return nil
+	}
+	pieceLength, ok := lintInt(t.info, "piece length")
+	switch {
+	case !ok:
+		return []LintIssue{lintError("piece length is missing or not an integer")}
+	case pieceLength <= 0:
+		return []LintIssue{lintError("piece length %d is not positive", pieceLength)}
+	case bits.OnesCount64(uint64(pieceLength)) != 1:
+		return []LintIssue{lintWarning("piece length %d is not a power of two", pieceLength)}
+	}
+	return nil
+}
+
+// lintPieces checks that pieces holds one 20-byte hash per piece of the
+// content.
+func lintPieces(t *lintTorrent) []LintIssue {
+	if t.info == nil || t.v2Only() {
+		return nil
+	}
+	pieces, ok := lintString(t.info, "pieces")
+	if !ok {
+		return []LintIssue{lintError("pieces is missing or not a byte string")}
+	}
+	if len(pieces)%pieceHashSize != 0 {
+		return []LintIssue{lintError("pieces length %d is not a multiple of %d", len(pieces), pieceHashSize)}
+	}
+	pieceLength, _ := lintInt(t.info, "piece length")
+	total, ok := t.totalLength()
+	if pieceLength <= 0 || !ok || total < 0 {
+		return nil
+	}
+	if got, want := int64(len(pieces)/pieceHashSize), (total+pieceLength-1)/pieceLength; got != want {
+		return []LintIssue{lintError("%d pieces, but %d bytes in %d byte pieces need %d", got, total, pieceLength, want)}
+	}
+	return nil
+}
+
+// lintName checks that the name is a single path component.
+func lintName(t *lintTorrent) []LintIssue {
+	if t.info == nil {
+		return nil
+	}
+	name, ok := lintString(t.info, "name")
+	switch {
+	case !ok || name == "":
+		return []LintIssue{lintError("name is missing or empty")}
+	case strings.ContainsAny(name, `/\`) || name == "." || name == "..":
+		return []LintIssue{lintError("name %q is a path", name)}
+	}
+	return nil
+}
+
+// lintUTF8 checks that the name and file paths are valid UTF-8, which BEP 3
+// requires and clients otherwise decode differently.
+func lintUTF8(t *lintTorrent) []LintIssue {
+	if t.info == nil {
+		return nil
+	}
+	var issues []LintIssue
+	if name, ok := lintString(t.info, "name"); ok && !utf8.ValidString(name) {
+		issues = append(issues, lintWarning("name %q is not valid UTF-8", name))
+	}
+	for i, file := range t.files {
+		for _, component := range lintPath(file) {
+			if !utf8.ValidString(component) {
+				issues = append(issues, lintWarning("file #%d path component %q is not valid UTF-8", i+1, component))
+			}
+		}
+	}
+	return issues
+}
+
+// lintPathComponents checks that file paths are relative and stay inside the
+// torrent directory.
+func lintPathComponents(t *lintTorrent) []LintIssue {
+	var issues []LintIssue
+	for i, file := range t.files {
+		path := lintPath(file)
+		if len(path) == 0 {
+			issues = append(issues, lintError("file #%d has no path", i+1))
+			continue
+		}
+		for _, component := range path {
+			switch component {
+			case "":
+				issues = append(issues, lintError("file #%d path %q has an empty component", i+1, strings.Join(path, "/")))
+			case ".", "..":
+				issues = append(issues, lintError("file #%d path %q has a %q component", i+1, strings.Join(path, "/"), component))
+			}
+		}
+	}
+	return issues
+}
+
+// lintPathSeparators checks for path components with a separator in them,
+// which some tools write instead of one component per directory.
+func lintPathSeparators(t *lintTorrent) []LintIssue {
+	var issues []LintIssue
+	for i, file := range t.files {
+		for _, component := range lintPath(file) {
+			if strings.ContainsAny(component, `/\`) {
+				issues = append(issues, lintWarning("file #%d path component %q contains a path separator", i+1, component))
+			}
+		}
+	}
+	return issues
+}
+
+// fixPathSeparators splits path components at their separators.
+func fixPathSeparators(t *lintTorrent) error {
+	for _, file := range t.files {
+		var path []string
+		for _, component := range lintPath(file) {
+			for _, part := range strings.FieldsFunc(component, func(r rune) bool { return r == '/' || r == '\\' }) {
+				path = append(path, part)
+			}
+		}
+		data, err := bencode.Marshal(path)
+		if err != nil {
+			return fmt.Errorf("could not encode path: %w", err)
+		}
+		file["path"] = data
+	}
+	t.infoChanged = true
+	return nil
+}
+
+// lintDuplicatePaths checks that no two files have the same path.
+func lintDuplicatePaths(t *lintTorrent) []LintIssue {
+	var issues []LintIssue
+	seen := make(map[string]int)
+	for i, file := range t.files {
+		path := strings.Join(lintPath(file), "/")
+		if first, ok := seen[path]; ok {
+			issues = append(issues, lintError("file #%d has the same path as file #%d: %q", i+1, first, path))
+			continue
+		}
+		seen[path] = i + 1
+	}
+	return issues
+}
+
+// lintPrivateValue checks that private, when present, is 0 or 1.
+func lintPrivateValue(t *lintTorrent) []LintIssue {
+	if _, ok := t.info["private"]; !ok {
+		return nil
+	}
+	if private, ok := lintInt(t.info, "private"); !ok || (private != 0 && private != 1) {
+		return []LintIssue{lintError("private is %s, want 0 or 1", t.info["private"])}
+	}
+	return nil
+}
+
+// lintPrivateFlag checks that torrents announcing to a private tracker, one
+// with a passkey or a known tracker, have the private flag set.
+func lintPrivateFlag(t *lintTorrent) []LintIssue {
+	if t.info == nil {
+		return nil
+	}
+	if private, _ := lintInt(t.info, "private"); private == 1 {
+		return nil
+	}
+	for _, trackerURL := range t.trackers() {
+		if hasPasskey, _ := trackers.DetectPasskey(trackerURL); hasPasskey || trackers.IsKnownTracker(trackerURL) {
+			return []LintIssue{lintWarning("announces to the private tracker %s but the private flag is not set", trackers.CanonicalURL(trackerURL))}
+		}
+	}
+	return nil
+}
+
+// trackers returns the announce URL and those of the announce list.
+func (t *lintTorrent) trackers() []string {
+	var urls []string
+	if announce, ok := lintString(t.root, "announce"); ok && announce != "" {
+		urls = append(urls, announce)
+	}
+	for _, tier := range t.announceList() {
+		urls = append(urls, tier...)
+	}
+	return urls
+}
+
+// announceList decodes the announce list, nil when missing or malformed.
+func (t *lintTorrent) announceList() metainfo.AnnounceList {
+	var list metainfo.AnnounceList
+	if raw, ok := t.root["announce-list"]; ok {
+		if bencode.Unmarshal(raw, &list) != nil {
+			return nil
+		}
+	}
+	return list
+}
+
+// lintAnnounceList checks the announce list for empty and duplicate trackers
+// and empty tiers.
+func lintAnnounceList(t *lintTorrent) []LintIssue {
+	seen := make(map[string]bool)
+	var empty, duplicates, emptyTiers int
+	for _, tier := range t.announceList() {
+		if len(tier) == 0 {
+			emptyTiers++
+		}
+		for _, url := range tier {
+			switch {
+			case strings.TrimSpace(url) == "":
+				empty++
+			case seen[url]:
+				duplicates++
+			}
+			seen[url] = true
+		}
+	}
+
+	var issues []LintIssue
+	if empty > 0 {
+		issues = append(issues, lintWarning("announce list has %d empty tracker(s)", empty))
+	}
+	if duplicates > 0 {
+		issues = append(issues, lintWarning("announce list has %d duplicate tracker(s)", duplicates))
+	}
+	if emptyTiers > 0 {
+		issues = append(issues, lintWarning("announce list has %d empty tier(s)", emptyTiers))
+	}
+	return issues
+}
+
+// fixAnnounceList drops empty and duplicate trackers and empty tiers as
+// convert does, removing the announce list when nothing is left.
+func fixAnnounceList(t *lintTorrent) error {
+	list, _ := normalizeAnnounceList(t.announceList())
+	if len(list) == 0 {
+		delete(t.root, "announce-list")
+		return nil
+	}
+	data, err := bencode.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("could not encode announce list: %w", err)
+	}
+	t.root["announce-list"] = data
+	return nil
+}
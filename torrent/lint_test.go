@@ -0,0 +1,140 @@
+package torrent
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// lintFixture loads a torrent of testdata/lint.
+func lintFixture(t *testing.T, name string) *lintTorrent {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "lint", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	lt, err := parseLintTorrent(data)
+	if err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+	return lt
+}
+
+func TestLintRules(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []string // rules with issues
+	}{
+		{"valid.torrent", nil},
+		{"pieces-length.torrent", []string{"pieces"}},
+		{"piece-count.torrent", []string{"pieces"}},
+		{"length-and-files.torrent", []string{"length-files"}},
+		{"negative-length.torrent", []string{"negative-length"}},
+		{"non-utf8-name.torrent", []string{"utf8"}},
+		{"duplicate-paths.torrent", []string{"duplicate-paths"}},
+		{"path-separators.torrent", []string{"path-separators"}},
+		{"dot-dot-path.torrent", []string{"path-components"}},
+		{"duplicate-announce.torrent", []string{"announce-list"}},
+		{"missing-private.torrent", []string{"private-flag"}},
+		{"private-value.torrent", []string{"private-value"}},
+		{"piece-length.torrent", []string{"piece-length"}},
+		{"missing-info.torrent", []string{"info"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			lt := lintFixture(t, tt.fixture)
+			var got []string
+			for _, rule := range lintRules {
+				if issues := rule.check(lt); len(issues) > 0 {
+					got = append(got, rule.name)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rules with issues = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintTorrent(t *testing.T) {
+	result, err := LintTorrent(filepath.Join("testdata", "lint", "negative-length.torrent"), LintOptions{})
+	if err != nil {
+		t.Fatalf("LintTorrent failed: %v", err)
+	}
+	if !result.HasErrors() {
+		t.Errorf("expected errors, got %+v", result.Issues)
+	}
+
+	result, err = LintTorrent(filepath.Join("testdata", "lint", "piece-length.torrent"), LintOptions{})
+	if err != nil {
+		t.Fatalf("LintTorrent failed: %v", err)
+	}
+	if result.HasErrors() || len(result.Issues) != 1 || result.Issues[0].Severity != LintSeverityWarning {
+		t.Errorf("expected a single warning, got %+v", result.Issues)
+	}
+
+	notTorrent := filepath.Join(t.TempDir(), "not.torrent")
+	if err := os.WriteFile(notTorrent, []byte("not bencode"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := LintTorrent(notTorrent, LintOptions{}); err == nil {
+		t.Error("expected an error for a file that is not a torrent")
+	}
+}
+
+func TestLintTorrent_Fix(t *testing.T) {
+	tests := []struct {
+		fixture         string
+		fixed           string
+		infoHashChanged bool
+	}{
+		{"duplicate-announce.torrent", "announce-list", false},
+		{"path-separators.torrent", "path-separators", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			src := filepath.Join("testdata", "lint", tt.fixture)
+			before, err := metainfo.LoadFromFile(src)
+			if err != nil {
+				t.Fatalf("failed to load fixture: %v", err)
+			}
+
+			outPath := filepath.Join(t.TempDir(), tt.fixture)
+			result, err := LintTorrent(src, LintOptions{Fix: true, OutputPath: outPath})
+			if err != nil {
+				t.Fatalf("LintTorrent failed: %v", err)
+			}
+			if !slices.Equal(result.Fixed, []string{tt.fixed}) || result.OutputPath != outPath {
+				t.Fatalf("expected %s fixed into %s, got %v into %s", tt.fixed, outPath, result.Fixed, result.OutputPath)
+			}
+
+			after, err := metainfo.LoadFromFile(outPath)
+			if err != nil {
+				t.Fatalf("failed to load fixed torrent: %v", err)
+			}
+			if changed := before.HashInfoBytes() != after.HashInfoBytes(); changed != tt.infoHashChanged {
+				t.Errorf("info hash changed = %v, want %v", changed, tt.infoHashChanged)
+			}
+			if tt.infoHashChanged && result.NewInfoHash != after.HashInfoBytes().HexString() {
+				t.Errorf("NewInfoHash = %s, want %s", result.NewInfoHash, after.HashInfoBytes().HexString())
+			}
+			if !tt.infoHashChanged && result.NewInfoHash != "" {
+				t.Errorf("expected no new info hash, got %s", result.NewInfoHash)
+			}
+
+			relint, err := LintTorrent(outPath, LintOptions{})
+			if err != nil {
+				t.Fatalf("LintTorrent failed: %v", err)
+			}
+			if len(relint.Issues) != 0 {
+				t.Errorf("expected no issues after fixing, got %+v", relint.Issues)
+			}
+		})
+	}
+}
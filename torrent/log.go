@@ -0,0 +1,172 @@
+package torrent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/autobrr/mkbrr/internal/trackers"
+)
+
+// LogLevels lists the names accepted by ParseLogLevel, from least to most verbose.
+var LogLevels = []string{"error", "warn", "info", "debug"}
+
+// ParseLogLevel converts a level name ("error", "warn", "info" or "debug") to a slog.Level.
+func ParseLogLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "error":
+		return slog.LevelError, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	default:
+		return 0, fmt.Errorf("invalid log level %q: must be one of %s", name, strings.Join(LogLevels, ", "))
+	}
+}
+
+// NewLogHandler returns a slog.Handler writing records at or above level to w,
+// as JSON objects when json is set and otherwise one line per record in the
+// format mkbrr has always used on stderr, "Warning: msg key=value ...".
+func NewLogHandler(w io.Writer, level slog.Level, json bool) slog.Handler {
+	if json {
+		return slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})
+	}
+	return &lineHandler{mu: &sync.Mutex{}, w: w, level: level}
+}
+
+// lineHandler writes a record as "<Level>: msg key=value ..." on one line.
+type lineHandler struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	level  slog.Level
+	attrs  string // preformatted " key=value" pairs from WithAttrs
+	prefix string // group names from WithGroup, joined by "."
+}
+
+func (h *lineHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *lineHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	switch {
+	case r.Level >= slog.LevelError:
+		b.WriteString("Error: ")
+	case r.Level >= slog.LevelWarn:
+		b.WriteString("Warning: ")
+	case r.Level >= slog.LevelInfo:
+		b.WriteString("Info: ")
+	default:
+		b.WriteString("Debug: ")
+	}
+	b.WriteString(r.Message)
+	b.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		appendAttr(&b, h.prefix, a)
+		return true
+	})
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *lineHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	for _, a := range attrs {
+		appendAttr(&b, h.prefix, a)
+	}
+	h2 := *h
+	h2.attrs += b.String()
+	return &h2
+}
+
+func (h *lineHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix += name + "."
+	return &h2
+}
+
+// appendAttr writes a as " key=value" to b, quoting values with spaces or
+// other characters that would make the line ambiguous.
+func appendAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			appendAttr(b, prefix, ga)
+		}
+		return
+	}
+	value := a.Value.String()
+	if value == "" || strings.ContainsFunc(value, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || !unicode.IsPrint(r)
+	}) {
+		value = strconv.Quote(value)
+	}
+	fmt.Fprintf(b, " %s%s=%s", prefix, a.Key, value)
+}
+
+// Logger receives diagnostic messages such as skipped files. Arguments are
+// alternating keys and values as with slog; *slog.Logger implements it, so a
+// slog.Handler is injected with slog.New(handler).
+type Logger interface {
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// defaultLogger receives the diagnostic messages of calls whose options
+// carry no Logger: warnings and errors on stderr, in the same format as
+// NewLogHandler without json.
+var defaultLogger = newDefaultLogger()
+
+func newDefaultLogger() *slog.Logger {
+	return slog.New(NewLogHandler(os.Stderr, slog.LevelWarn, false))
+}
+
+// SetDefaultLogger sets the logger used from now on by calls whose options
+// carry no Logger, as the --log-level and --log-json flags do. It also
+// decides whether Display.ShowWarning prints and receives the messages of
+// remote tracker configs and rules. nil restores the default.
+func SetDefaultLogger(l *slog.Logger) {
+	if l == nil {
+		l = newDefaultLogger()
+	}
+	defaultLogger = l
+	trackers.SetLogger(l)
+}
+
+// resolveLogger returns l when set and the default logger otherwise.
+func resolveLogger(l Logger) Logger {
+	if l != nil {
+		return l
+	}
+	return defaultLogger
+}
+
+// logDebug logs a debug message when l supports that level, as *slog.Logger does.
+func logDebug(l Logger, msg string, args ...any) {
+	if d, ok := l.(interface{ Debug(string, ...any) }); ok {
+		d.Debug(msg, args...)
+	}
+}
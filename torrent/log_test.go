@@ -0,0 +1,259 @@
+package torrent
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    slog.Level
+		wantErr bool
+	}{
+		{name: "error", want: slog.LevelError},
+		{name: "warn", want: slog.LevelWarn},
+		{name: "WARNING", want: slog.LevelWarn},
+		{name: "info", want: slog.LevelInfo},
+		{name: " debug ", want: slog.LevelDebug},
+		{name: "trace", wantErr: true},
+		{name: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLogLevel(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLogLevel(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseLogLevel(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCreateTorrent_LogsWalkWarnings(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping symlink test on Windows")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("content"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	brokenLink := filepath.Join(dir, "broken.txt")
+	if err := os.Symlink(filepath.Join(dir, "missing.txt"), brokenLink); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		level    slog.Level
+		wantWarn bool
+	}{
+		{name: "warn level", level: slog.LevelWarn, wantWarn: true},
+		{name: "error level", level: slog.LevelError, wantWarn: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			pieceLenExp := uint(16)
+			_, err := CreateTorrent(CreateOptions{
+				Path:           dir,
+				PieceLengthExp: &pieceLenExp,
+				Quiet:          true,
+				Logger:         slog.New(NewLogHandler(&buf, tt.level, false)),
+			})
+			if err != nil {
+				t.Fatalf("CreateTorrent failed: %v", err)
+			}
+
+			logged := buf.String()
+			if !tt.wantWarn {
+				if logged != "" {
+					t.Errorf("expected no log output, got %q", logged)
+				}
+				return
+			}
+
+			if !strings.HasPrefix(logged, "Warning: ") {
+				t.Errorf("expected a warning, got %q", logged)
+			}
+			if !strings.Contains(logged, brokenLink) {
+				t.Errorf("expected log to mention %q, got %q", brokenLink, logged)
+			}
+		})
+	}
+}
+
+type logRecord struct {
+	level string
+	msg   string
+	args  []any
+}
+
+// capturingLogger records messages in memory instead of writing them anywhere.
+type capturingLogger struct {
+	records []logRecord
+}
+
+func (l *capturingLogger) Info(msg string, args ...any) {
+	l.records = append(l.records, logRecord{level: "info", msg: msg, args: args})
+}
+
+func (l *capturingLogger) Warn(msg string, args ...any) {
+	l.records = append(l.records, logRecord{level: "warn", msg: msg, args: args})
+}
+
+func (l *capturingLogger) Error(msg string, args ...any) {
+	l.records = append(l.records, logRecord{level: "error", msg: msg, args: args})
+}
+
+// captureStderr returns everything written to os.Stderr while fn runs.
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+
+	f, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer f.Close()
+
+	orig := os.Stderr
+	os.Stderr = f
+	defer func() { os.Stderr = orig }()
+	fn()
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatalf("failed to read captured stderr: %v", err)
+	}
+	return string(data)
+}
+
+func TestCreateTorrent_Logger(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping symlink test on Windows")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("content"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	brokenLink := filepath.Join(dir, "broken.txt")
+	if err := os.Symlink(filepath.Join(dir, "missing.txt"), brokenLink); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	logger := &capturingLogger{}
+	stderr := captureStderr(t, func() {
+		pieceLenExp := uint(16)
+		if _, err := CreateTorrent(CreateOptions{
+			Path:           dir,
+			PieceLengthExp: &pieceLenExp,
+			Quiet:          true,
+			Logger:         logger,
+		}); err != nil {
+			t.Fatalf("CreateTorrent failed: %v", err)
+		}
+	})
+
+	if stderr != "" {
+		t.Errorf("expected nothing on stderr, got %q", stderr)
+	}
+	if len(logger.records) != 1 {
+		t.Fatalf("expected 1 log record, got %+v", logger.records)
+	}
+	record := logger.records[0]
+	if record.level != "warn" || !strings.Contains(record.msg, "symlink") {
+		t.Errorf("expected a symlink warning, got %+v", record)
+	}
+	if !slices.Contains(record.args, any(brokenLink)) {
+		t.Errorf("expected the warning to name %q, got %v", brokenLink, record.args)
+	}
+}
+
+func TestModifyTorrent_LoggerReportsSkips(t *testing.T) {
+	tmpDir := t.TempDir()
+	contentPath := filepath.Join(tmpDir, "content.bin")
+	if err := os.WriteFile(contentPath, make([]byte, 1<<16), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+	torrentPath := filepath.Join(tmpDir, "content.torrent")
+	if _, err := Create(CreateOptions{Path: contentPath, OutputPath: torrentPath, Source: "A", Quiet: true}); err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+
+	logger := &capturingLogger{}
+	result, err := ModifyTorrent(torrentPath, ModifyOptions{
+		Source:              "A",
+		SourceSet:           true,
+		SkipIfSourceMatches: true,
+		OutputDir:           tmpDir,
+		Quiet:               true,
+		Logger:              logger,
+	})
+	if err != nil {
+		t.Fatalf("ModifyTorrent failed: %v", err)
+	}
+	if result.SkipReason == "" {
+		t.Fatal("expected the torrent to be skipped")
+	}
+	if len(logger.records) != 1 || logger.records[0].level != "info" || !slices.Contains(logger.records[0].args, any(result.SkipReason)) {
+		t.Errorf("expected an info record with the skip reason, got %+v", logger.records)
+	}
+}
+
+func TestNewLogHandler_LineFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(&buf, slog.LevelInfo, false))
+
+	logger.Warn("could not stat symlink target", "path", "/data/my file.mkv", "error", "no such file")
+	logger.With("job", 2).WithGroup("walk").Info("skipping file", "reason", "hidden")
+	logger.Error("walk failed", "path", "")
+	logger.Debug("not shown")
+
+	want := `Warning: could not stat symlink target path="/data/my file.mkv" error="no such file"
+Info: skipping file job=2 walk.reason=hidden
+Error: walk failed path=""
+`
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestCreateTorrent_DefaultLoggerWritesToStderr(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping symlink test on Windows")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("content"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	brokenLink := filepath.Join(dir, "broken.txt")
+	if err := os.Symlink(filepath.Join(dir, "missing.txt"), brokenLink); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	t.Cleanup(func() { SetDefaultLogger(nil) })
+	stderr := captureStderr(t, func() {
+		// the default logger writes to the stderr of the time it is made
+		SetDefaultLogger(nil)
+		pieceLenExp := uint(16)
+		if _, err := CreateTorrent(CreateOptions{Path: dir, PieceLengthExp: &pieceLenExp, Quiet: true}); err != nil {
+			t.Fatalf("CreateTorrent failed: %v", err)
+		}
+	})
+
+	if !strings.HasPrefix(stderr, "Warning: ") || !strings.Contains(stderr, brokenLink) || strings.Count(stderr, "\n") != 1 {
+		t.Errorf("expected one warning line naming %q on stderr, got %q", brokenLink, stderr)
+	}
+}
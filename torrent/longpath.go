@@ -0,0 +1,27 @@
+package torrent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// walkLong walks root like filepath.Walk, through its longPath form so deep
+// trees work on Windows, and passes fn the paths in the form root was given
+// in, so relative paths computed from them stay the same.
+func walkLong(root string, fn filepath.WalkFunc) error {
+	long := longPath(root)
+	if long == root {
+		return filepath.Walk(root, fn)
+	}
+	return filepath.Walk(long, func(path string, info os.FileInfo, err error) error {
+		rel, ok := strings.CutPrefix(path, long)
+		switch {
+		case !ok:
+			return fn(path, info, err)
+		case rel == "":
+			return fn(root, info, err)
+		}
+		return fn(filepath.Join(root, rel), info, err)
+	})
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package torrent
+
+// longPath returns path unchanged, as only Windows limits path length to
+// MAX_PATH without an extended-length prefix.
+func longPath(path string) string {
+	return path
+}
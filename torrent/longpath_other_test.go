@@ -0,0 +1,13 @@
+//go:build !windows
+
+package torrent
+
+import "testing"
+
+func TestLongPath_NoOp(t *testing.T) {
+	for _, path := range []string{"", "content", "/data/content/", "../a/b.bin"} {
+		if got := longPath(path); got != path {
+			t.Errorf("longPath(%q) = %q, want it unchanged", path, got)
+		}
+	}
+}
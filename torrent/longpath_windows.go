@@ -0,0 +1,33 @@
+//go:build windows
+
+package torrent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// longPath returns path in the extended-length form \\?\C:\... or, for UNC
+// shares, \\?\UNC\server\share\..., which Windows APIs accept beyond the
+// 260 character MAX_PATH limit. Paths already in that form, device paths and
+// paths that cannot be made absolute are returned unchanged. A trailing
+// separator is kept.
+func longPath(path string) string {
+	if path == "" || strings.HasPrefix(path, `\\?\`) || strings.HasPrefix(path, `\\.\`) {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		abs = `\\?\UNC\` + abs[2:]
+	} else {
+		abs = `\\?\` + abs
+	}
+	if os.IsPathSeparator(path[len(path)-1]) && !os.IsPathSeparator(abs[len(abs)-1]) {
+		abs += string(filepath.Separator)
+	}
+	return abs
+}
@@ -0,0 +1,83 @@
+//go:build windows
+
+package torrent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLongPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: `C:\data\content`, want: `\\?\C:\data\content`},
+		{path: `C:\data\content\`, want: `\\?\C:\data\content\`},
+		{path: `\\server\share\content`, want: `\\?\UNC\server\share\content`},
+		{path: `\\?\C:\data\content`, want: `\\?\C:\data\content`},
+		{path: `\\.\pipe\name`, want: `\\.\pipe\name`},
+		{path: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := longPath(tt.path); got != tt.want {
+			t.Errorf("longPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	if got, want := longPath("content"), `\\?\`+filepath.Join(wd, "content"); got != want {
+		t.Errorf("longPath(%q) = %q, want %q", "content", got, want)
+	}
+}
+
+func TestCreateAndVerify_LongPath(t *testing.T) {
+	// nest the content until its files are well past MAX_PATH
+	root := filepath.Join(t.TempDir(), "content")
+	dir := root
+	for len(dir) < 300 {
+		dir = filepath.Join(dir, strings.Repeat("d", 50))
+	}
+	if err := os.MkdirAll(longPath(dir), 0755); err != nil {
+		t.Fatalf("failed to create nested directories: %v", err)
+	}
+	filePath := filepath.Join(dir, "file.bin")
+	if err := os.WriteFile(longPath(filePath), make([]byte, 1<<17), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(longPath(root)) })
+
+	torrentPath := filepath.Join(t.TempDir(), "content.torrent")
+	if _, err := Create(CreateOptions{Path: root, OutputPath: torrentPath, Quiet: true}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	loaded, err := LoadFromFile(torrentPath)
+	if err != nil {
+		t.Fatalf("failed to load torrent: %v", err)
+	}
+	info := loaded.GetInfo()
+	if len(info.Files) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(info.Files))
+	}
+	rel, err := filepath.Rel(root, filePath)
+	if err != nil {
+		t.Fatalf("failed to compute relative path: %v", err)
+	}
+	if got, want := strings.Join(info.Files[0].Path, "/"), filepath.ToSlash(rel); got != want {
+		t.Errorf("torrent path = %q, want the relative path %q", got, want)
+	}
+
+	result, err := VerifyData(VerifyOptions{TorrentPath: torrentPath, ContentPath: root, Quiet: true})
+	if err != nil {
+		t.Fatalf("VerifyData failed: %v", err)
+	}
+	if result.GoodPieces != result.TotalPieces || len(result.MissingFiles) != 0 {
+		t.Errorf("verified %d of %d pieces, missing %v", result.GoodPieces, result.TotalPieces, result.MissingFiles)
+	}
+}
@@ -1,14 +1,20 @@
 package torrent
 
 import (
+	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/anacrolix/torrent/bencode"
 	"github.com/anacrolix/torrent/metainfo"
 
 	"github.com/autobrr/mkbrr/internal/preset"
+	"github.com/autobrr/mkbrr/internal/sanitize"
+	"github.com/autobrr/mkbrr/internal/trackers"
 )
 
 // ModifyOptions represents the options for modifying a torrent,
@@ -28,6 +34,7 @@ type ModifyOptions struct {
 	Version        string
 	WebSeeds       []string
 	NoDate         bool
+	CreationDate   time.Time // fixed creation date instead of the current time, overrides a preset no_date
 	NoCreator      bool
 	DryRun         bool
 	Verbose        bool
@@ -37,6 +44,43 @@ type ModifyOptions struct {
 	SourceSet      bool // true when --source flag was explicitly provided (allows empty string to clear)
 	CommentSet     bool // true when --comment flag was explicitly provided (allows empty string to clear)
 	RemovePrivate  bool // true when --no-private flag is provided (removes private field entirely)
+
+	SkipIfSourceMatches  bool // leave the torrent untouched when its source already equals the target source
+	SkipIfTrackerMatches bool // leave the torrent untouched when it already announces to one of the target trackers
+	NoValidateTrackers   bool // write tracker URLs even when trackers.ValidateURLs rejects them
+
+	// Logger receives diagnostic messages such as skipped torrents, the logger set with SetDefaultLogger if nil
+	Logger Logger
+	// RandomizeAnnounceList shuffles the tracker tiers and the trackers within each tier
+	RandomizeAnnounceList bool
+
+	// Strip removes fields named in StripFields after all other changes.
+	// "announce-list" keeps the primary announce URL and "webseeds" also
+	// removes BEP 17 http seeds. Stripping source or private changes the info hash.
+	Strip []string
+	// StripKeys removes these keys from the root dictionary
+	StripKeys []string
+	// StripInfoKeys removes these keys from the info dictionary, which changes the info hash
+	StripInfoKeys []string
+	// Creator replaces the created by field, unless NoCreator or a preset leaves it out
+	Creator string
+	// CopyInfoFieldsFrom is a torrent to copy the InfoFieldNames fields of the info
+	// dictionary from, overwriting fields already present, which changes the info hash
+	CopyInfoFieldsFrom string
+	InfoFieldNames     []string
+	// NormalizeName tidies the new name, or the current one when Name is empty,
+	// with sanitize.Tidy. Renaming changes the info hash.
+	NormalizeName bool
+	// AddTrackerURLs are appended to the trackers of the torrent, after
+	// TrackerURLs replaced them if set, each as a tier of its own and skipping
+	// the ones it already announces to. The primary announce URL is kept.
+	AddTrackerURLs []string
+	// SetPrimary makes the first of AddTrackerURLs the primary announce URL
+	SetPrimary bool
+	// RemoveTrackers drops every announce URL containing one of these
+	// substrings, before AddTrackerURLs are added. Tiers left empty are
+	// removed, and the announce URL is cleared when no tracker is left.
+	RemoveTrackers []string
 }
 
 // Result represents the result of modifying a torrent
@@ -44,17 +88,26 @@ type Result struct {
 	Error       error
 	Path        string
 	OutputPath  string
+	SkipReason  string // set when the torrent was skipped by SkipIfSourceMatches or SkipIfTrackerMatches
 	WasModified bool
+	Stripped    []string // fields and keys removed by Strip, StripKeys and StripInfoKeys
+	NewInfoHash string   // set when the modification changed the info hash
+	// RemovedTrackers is the number of announce URLs dropped by RemoveTrackers
+	RemovedTrackers int
 }
 
 // LoadFromFile loads a torrent file from disk and returns a Torrent struct.
 // The returned Torrent wraps the metainfo and provides additional functionality.
 func LoadFromFile(path string) (*Torrent, error) {
-	mi, err := metainfo.LoadFromFile(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not load torrent: %w", err)
+	}
+	mi, err := metainfo.Load(bytes.NewReader(data))
 	if err != nil {
 		return nil, fmt.Errorf("could not load torrent: %w", err)
 	}
-	return &Torrent{MetaInfo: mi}, nil
+	return &Torrent{MetaInfo: mi, HTTPSeeds: ParseHTTPSeeds(data)}, nil
 }
 
 // ModifyTorrent modifies a single torrent file according to the given options.
@@ -65,12 +118,31 @@ func ModifyTorrent(path string, opts ModifyOptions) (*Result, error) {
 		Path: path,
 	}
 
+	if err := ValidateStrip(opts); err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+	if err := ValidateCopyInfoFields(opts); err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+
 	// load torrent file
-	mi, err := metainfo.LoadFromFile(path)
+	loaded, err := LoadFromFile(path)
 	if err != nil {
-		result.Error = fmt.Errorf("could not load torrent: %w", err)
+		result.Error = err
 		return result, result.Error
 	}
+	mi := loaded.MetaInfo
+	originalInfoHash := mi.HashInfoBytes()
+
+	// refuse to rewrite a torrent that looks corrupt or tampered with
+	if originalInfo, err := mi.UnmarshalInfo(); err == nil {
+		if err := validationError(ValidateTorrent(mi, &originalInfo)); err != nil {
+			result.Error = fmt.Errorf("invalid torrent %q: %w", path, err)
+			return result, result.Error
+		}
+	}
 
 	// load preset if specified
 	var presetOpts *preset.Options
@@ -96,10 +168,50 @@ func ModifyTorrent(path string, opts ModifyOptions) (*Result, error) {
 		presetOpts.Version = opts.Version
 	}
 
+	if !opts.NoValidateTrackers {
+		trackerURLs := opts.TrackerURLs
+		if len(trackerURLs) == 0 && presetOpts != nil {
+			trackerURLs = presetOpts.Trackers
+		}
+		trackerURLs = append(slices.Clone(trackerURLs), opts.AddTrackerURLs...)
+		warnings, err := trackers.ValidateURLs(trackerURLs)
+		if err != nil {
+			result.Error = err
+			return result, result.Error
+		}
+		display := NewDisplay(NewFormatter(opts.Verbose))
+		display.SetQuiet(opts.Quiet)
+		for _, warning := range warnings {
+			display.ShowWarning(warning)
+		}
+	}
+
+	// warn about passkeys in the trackers being set, without printing the URL
+	if opts.Verbose {
+		trackerURLs := opts.TrackerURLs
+		if len(trackerURLs) == 0 && presetOpts != nil {
+			trackerURLs = presetOpts.Trackers
+		}
+		trackerURLs = append(slices.Clone(trackerURLs), opts.AddTrackerURLs...)
+		display := NewDisplay(NewFormatter(opts.Verbose))
+		display.SetQuiet(opts.Quiet)
+		for i, trackerURL := range trackerURLs {
+			if found, pattern := trackers.DetectPasskey(trackerURL); found {
+				display.ShowWarning(fmt.Sprintf("tracker URL #%d may contain a passkey (%s)", i+1, pattern))
+			}
+		}
+	}
+
+	if reason := skipReason(mi, opts, presetOpts); reason != "" {
+		resolveLogger(opts.Logger).Info("skipping torrent", "path", path, "reason", reason)
+		result.SkipReason = reason
+		return result, nil
+	}
+
 	// apply preset modifications if any
 	wasModified := false
 	if presetOpts != nil {
-		wasModified, err = presetOpts.ApplyToMetaInfo(mi)
+		wasModified, err = presetOpts.ApplyToMetaInfo(mi, now())
 		if err != nil {
 			result.Error = fmt.Errorf("could not apply preset: %w", err)
 			return result, result.Error
@@ -120,6 +232,7 @@ func ModifyTorrent(path string, opts ModifyOptions) (*Result, error) {
 		key    string
 		value  any
 		remove bool
+		copied bool
 	}
 	var infoChanges []infoChange
 
@@ -136,9 +249,55 @@ func ModifyTorrent(path string, opts ModifyOptions) (*Result, error) {
 		// Note: This overrides any trackers set by a preset
 	}
 
+	// split a comma-separated primary announce into the first tier
+	if NormalizeAnnounce(mi) {
+		wasModified = true
+	}
+
+	if result.RemovedTrackers = removeTrackers(mi, opts.RemoveTrackers); result.RemovedTrackers > 0 {
+		wasModified = true
+	}
+	if addTrackers(mi, opts.AddTrackerURLs, opts.SetPrimary) {
+		wasModified = true
+	}
+	if (len(opts.AddTrackerURLs) > 0 || len(opts.RemoveTrackers) > 0) && opts.Verbose {
+		// passkeys are left out, as for inspect --strip-passkeys
+		var urls []string
+		for _, tier := range mi.AnnounceList {
+			for _, trackerURL := range tier {
+				urls = append(urls, trackers.CanonicalURL(trackerURL))
+			}
+		}
+		display := NewDisplay(NewFormatter(opts.Verbose))
+		display.SetQuiet(opts.Quiet)
+		display.ShowMessage(fmt.Sprintf("trackers of %s: %s", filepath.Base(path), strings.Join(urls, ", ")))
+	}
+
+	if opts.RandomizeAnnounceList && len(mi.AnnounceList) > 0 {
+		if err := shuffleAnnounceList(mi); err != nil {
+			result.Error = err
+			return result, result.Error
+		}
+		wasModified = true
+	}
+
 	// update name if provided via flag
-	if opts.Name != "" && info.Name != opts.Name {
-		infoChanges = append(infoChanges, infoChange{key: "name", value: opts.Name})
+	newName := opts.Name
+	if opts.NormalizeName {
+		if newName == "" {
+			newName = info.Name
+		}
+		if newName = sanitize.Tidy(newName); newName == "" {
+			result.Error = fmt.Errorf("name %q is empty after normalizing", info.Name)
+			return result, result.Error
+		}
+	}
+	if newName != "" && info.Name != newName {
+		if strings.ContainsAny(newName, `/\`) {
+			result.Error = fmt.Errorf("name %q must not contain path separators", newName)
+			return result, result.Error
+		}
+		infoChanges = append(infoChanges, infoChange{key: "name", value: newName})
 		wasModified = true
 	}
 
@@ -205,14 +364,34 @@ func ModifyTorrent(path string, opts ModifyOptions) (*Result, error) {
 		wasModified = true
 	}
 
+	// copy fields from another torrent's info dictionary
+	copiedFields, err := loadCopiedInfoFields(opts)
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+	for _, field := range copiedFields {
+		infoChanges = append(infoChanges, infoChange{key: field.key, value: field.value, copied: true})
+		wasModified = true
+	}
+
 	// apply all info-level changes via raw map to preserve custom keys
-	if len(infoChanges) > 0 {
+	stripsInfo := len(opts.StripInfoKeys) > 0 || slices.Contains(opts.Strip, "source") || slices.Contains(opts.Strip, "private")
+	if len(infoChanges) > 0 || stripsInfo {
 		infoMap := make(map[string]any)
 		if err := bencode.Unmarshal(mi.InfoBytes, &infoMap); err != nil {
 			result.Error = fmt.Errorf("could not unmarshal info map: %w", err)
 			return result, result.Error
 		}
+		for _, key := range stripInfoKeys(infoMap, opts) {
+			infoChanges = append(infoChanges, infoChange{key: key, remove: true})
+			result.Stripped = append(result.Stripped, key)
+			wasModified = true
+		}
 		for _, c := range infoChanges {
+			if _, ok := infoMap[c.key]; ok && c.copied {
+				resolveLogger(opts.Logger).Warn("overwriting info field", "path", path, "field", c.key)
+			}
 			if c.remove {
 				delete(infoMap, c.key)
 			} else {
@@ -231,15 +410,41 @@ func ModifyTorrent(path string, opts ModifyOptions) (*Result, error) {
 	if presetOpts != nil && presetOpts.NoCreator != nil && *presetOpts.NoCreator || opts.NoCreator {
 		mi.CreatedBy = ""
 		wasModified = true
+	} else if opts.Creator != "" {
+		mi.CreatedBy = opts.Creator
+		wasModified = true
 	}
 
 	// update creation date based on preset and command line options
-	if presetOpts != nil && presetOpts.NoDate != nil && *presetOpts.NoDate || opts.NoDate {
+	switch {
+	case opts.NoDate:
 		mi.CreationDate = 0
-		wasModified = true
-	} else {
-		mi.CreationDate = time.Now().Unix()
-		wasModified = true
+	case !opts.CreationDate.IsZero():
+		mi.CreationDate = opts.CreationDate.Unix()
+	case presetOpts != nil && presetOpts.NoDate != nil && *presetOpts.NoDate:
+		mi.CreationDate = 0
+	default:
+		mi.CreationDate = now().Unix()
+	}
+	wasModified = true
+
+	// keep BEP 17 http seeds, which metainfo.MetaInfo does not model
+	modified := &Torrent{MetaInfo: mi, HTTPSeeds: loaded.HTTPSeeds}
+	result.Stripped = append(stripRootFields(modified, opts.Strip), result.Stripped...)
+	data, err := modified.Marshal()
+	if err != nil {
+		result.Error = fmt.Errorf("could not encode torrent: %w", err)
+		return result, result.Error
+	}
+	data, strippedKeys, err := stripRootKeys(data, opts.StripKeys)
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+	result.Stripped = append(result.Stripped, strippedKeys...)
+
+	if infoHash := mi.HashInfoBytes(); infoHash != originalInfoHash {
+		result.NewInfoHash = infoHash.String()
 	}
 
 	if !wasModified {
@@ -257,9 +462,14 @@ func ModifyTorrent(path string, opts ModifyOptions) (*Result, error) {
 		metaInfoName = updatedInfo.Name
 	}
 
+	// a renamed torrent is written under its new name
+	baseName := metaInfoName
+	if baseName == "" {
+		baseName = originalMetaInfoName
+	}
 	basePath := path
-	if opts.OutputPattern == "" && originalMetaInfoName != "" {
-		basePath = originalMetaInfoName + ".torrent"
+	if opts.OutputPattern == "" && baseName != "" {
+		basePath = baseName + ".torrent"
 	}
 
 	// determine output directory: command-line flag takes precedence over preset
@@ -275,6 +485,7 @@ func ModifyTorrent(path string, opts ModifyOptions) (*Result, error) {
 	} else {
 		trackerForOutput = ""
 	}
+	outputDir = preset.ExpandOutputDirAt(outputDir, trackerForOutput, now())
 	outPath := preset.GenerateOutputPath(basePath, outputDir, opts.PresetName, opts.OutputPattern, trackerForOutput, metaInfoName, opts.SkipPrefix)
 	result.OutputPath = outPath
 
@@ -294,7 +505,7 @@ func ModifyTorrent(path string, opts ModifyOptions) (*Result, error) {
 	}
 	defer f.Close()
 
-	if err := mi.Write(f); err != nil {
+	if _, err := f.Write(data); err != nil {
 		result.Error = fmt.Errorf("could not write output file: %w", err)
 		return result, result.Error
 	}
@@ -303,6 +514,42 @@ func ModifyTorrent(path string, opts ModifyOptions) (*Result, error) {
 	return result, nil
 }
 
+// skipReason reports why the torrent should be left untouched according to
+// SkipIfSourceMatches and SkipIfTrackerMatches, or "" if it should be modified.
+// Flag values take precedence over preset values as target source and trackers.
+func skipReason(mi *metainfo.MetaInfo, opts ModifyOptions, presetOpts *preset.Options) string {
+	if opts.SkipIfSourceMatches {
+		source := opts.Source
+		if source == "" && !opts.SourceSet && presetOpts != nil {
+			source = presetOpts.Source
+		}
+		if source != "" {
+			if info, err := mi.UnmarshalInfo(); err == nil && info.Source == source {
+				return fmt.Sprintf("source already %q", source)
+			}
+		}
+	}
+
+	if opts.SkipIfTrackerMatches {
+		trackerURLs := opts.TrackerURLs
+		if len(trackerURLs) == 0 && presetOpts != nil {
+			trackerURLs = presetOpts.Trackers
+		}
+		for _, tracker := range trackerURLs {
+			if mi.Announce == tracker {
+				return "tracker already set"
+			}
+			for _, tier := range mi.AnnounceList {
+				if slices.Contains(tier, tracker) {
+					return "tracker already set"
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
 // ProcessTorrents modifies multiple torrent files according to the given options.
 // It processes each torrent file and returns the results for all operations.
 // This function provides parallel processing for better performance with multiple files.
@@ -323,3 +570,39 @@ func ProcessTorrents(paths []string, opts ModifyOptions) ([]*Result, error) {
 
 	return results, nil
 }
+
+// NormalizeAnnounce splits a primary announce field holding several
+// comma-separated URLs, a non-standard form some tracker software writes,
+// into the first tier of the announce list and keeps only the first URL as
+// the announce field. Other URLs already in the first tier stay after the
+// split ones. It reports whether mi was changed.
+func NormalizeAnnounce(mi *metainfo.MetaInfo) bool {
+	if !strings.Contains(mi.Announce, ",") {
+		return false
+	}
+
+	original := mi.Announce
+	var urls []string
+	for _, trackerURL := range strings.Split(original, ",") {
+		if trackerURL = strings.TrimSpace(trackerURL); trackerURL != "" && !slices.Contains(urls, trackerURL) {
+			urls = append(urls, trackerURL)
+		}
+	}
+	if len(urls) == 0 {
+		mi.Announce = ""
+		return true
+	}
+
+	mi.Announce = urls[0]
+	if len(mi.AnnounceList) == 0 {
+		mi.AnnounceList = metainfo.AnnounceList{urls}
+		return true
+	}
+	for _, trackerURL := range mi.AnnounceList[0] {
+		if trackerURL != original && !slices.Contains(urls, trackerURL) {
+			urls = append(urls, trackerURL)
+		}
+	}
+	mi.AnnounceList[0] = urls
+	return true
+}
@@ -4,9 +4,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/anacrolix/torrent/bencode"
+	"github.com/anacrolix/torrent/metainfo"
 )
 
 func TestModifyTorrent_OutputDirPriority(t *testing.T) {
@@ -323,6 +327,9 @@ func TestModify_NameArgument(t *testing.T) {
 		t.Fatalf("Created prefixed torrent file, %q does not exist: %v", prefixedTorrentFilepath, err)
 	}
 
+	// cases without an output dir write to the working directory
+	t.Chdir(t.TempDir())
+
 	// Test cases
 	tests := []struct {
 		name             string
@@ -369,11 +376,12 @@ func TestModify_NameArgument(t *testing.T) {
 			path: torrentFilepath,
 			opts: ModifyOptions{
 				Name:       "customname",
+				OutputDir:  tmpDir,
 				SkipPrefix: false,
 				Quiet:      true,
 			},
 			expectedName:     "customname",
-			expectedFilename: "modified_oldname.torrent",
+			expectedFilename: "modified_customname.torrent",
 		},
 		{
 			name: "With --name argument --skip-prefix present -o supplied",
@@ -427,11 +435,12 @@ func TestModify_NameArgument(t *testing.T) {
 			path: prefixedTorrentFilepath,
 			opts: ModifyOptions{
 				Name:       "customname",
+				OutputDir:  tmpDir,
 				SkipPrefix: false,
 				Quiet:      true,
 			},
 			expectedName:     "customname",
-			expectedFilename: "modified_oldname.torrent",
+			expectedFilename: "modified_customname.torrent",
 		},
 	}
 
@@ -788,3 +797,375 @@ func TestModifyTorrent_RemoveFields(t *testing.T) {
 		}
 	})
 }
+
+func TestModifyTorrent_SkipIfMatches(t *testing.T) {
+	tmpDir := t.TempDir()
+	contentPath := filepath.Join(tmpDir, "content.txt")
+	if err := os.WriteFile(contentPath, []byte("skip if matches content"), 0644); err != nil {
+		t.Fatalf("Failed to create content file: %v", err)
+	}
+
+	withSource := filepath.Join(tmpDir, "with-source.torrent")
+	if _, err := Create(CreateOptions{
+		Path:        contentPath,
+		Name:        "with-source",
+		OutputPath:  withSource,
+		Source:      "SRC",
+		TrackerURLs: []string{"https://tracker.example.com/announce"},
+		NoDate:      true,
+		Quiet:       true,
+	}); err != nil {
+		t.Fatalf("Failed to create torrent: %v", err)
+	}
+
+	withoutSource := filepath.Join(tmpDir, "without-source.torrent")
+	if _, err := Create(CreateOptions{
+		Path:       contentPath,
+		Name:       "without-source",
+		OutputPath: withoutSource,
+		NoDate:     true,
+		Quiet:      true,
+	}); err != nil {
+		t.Fatalf("Failed to create torrent: %v", err)
+	}
+
+	t.Run("source", func(t *testing.T) {
+		outDir := filepath.Join(tmpDir, "source-out")
+		results, err := ProcessTorrents([]string{withSource, withoutSource}, ModifyOptions{
+			OutputDir:           outDir,
+			Source:              "SRC",
+			SkipIfSourceMatches: true,
+			Version:             "test",
+		})
+		if err != nil {
+			t.Fatalf("ProcessTorrents failed: %v", err)
+		}
+
+		if results[0].WasModified || results[0].SkipReason == "" {
+			t.Errorf("Expected torrent with matching source to be skipped, got %+v", results[0])
+		}
+		if !results[1].WasModified {
+			t.Errorf("Expected torrent without source to be modified, got %+v", results[1])
+		}
+
+		entries, err := os.ReadDir(outDir)
+		if err != nil {
+			t.Fatalf("Failed to read output dir: %v", err)
+		}
+		if len(entries) != 1 || entries[0].Name() != filepath.Base(results[1].OutputPath) {
+			var names []string
+			for _, e := range entries {
+				names = append(names, e.Name())
+			}
+			t.Errorf("Expected only the output for the torrent without source, got %v", names)
+		}
+	})
+
+	t.Run("tracker", func(t *testing.T) {
+		outDir := filepath.Join(tmpDir, "tracker-out")
+		results, err := ProcessTorrents([]string{withSource, withoutSource}, ModifyOptions{
+			OutputDir:            outDir,
+			TrackerURLs:          []string{"https://tracker.example.com/announce"},
+			SkipIfTrackerMatches: true,
+			SkipPrefix:           true,
+			Version:              "test",
+		})
+		if err != nil {
+			t.Fatalf("ProcessTorrents failed: %v", err)
+		}
+
+		if results[0].WasModified || results[0].SkipReason == "" {
+			t.Errorf("Expected torrent with matching tracker to be skipped, got %+v", results[0])
+		}
+		if !results[1].WasModified {
+			t.Errorf("Expected torrent without tracker to be modified, got %+v", results[1])
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		result, err := ModifyTorrent(withSource, ModifyOptions{
+			OutputDir: filepath.Join(tmpDir, "disabled-out"),
+			Source:    "SRC",
+			Version:   "test",
+		})
+		if err != nil {
+			t.Fatalf("ModifyTorrent failed: %v", err)
+		}
+		if result.SkipReason != "" {
+			t.Errorf("Expected no skip without SkipIfSourceMatches, got %q", result.SkipReason)
+		}
+	})
+}
+
+func TestModifyTorrent_ValidatesTrackers(t *testing.T) {
+	tmpDir := t.TempDir()
+	contentPath := filepath.Join(tmpDir, "content.txt")
+	if err := os.WriteFile(contentPath, []byte("test content"), 0644); err != nil {
+		t.Fatalf("Failed to create content file: %v", err)
+	}
+
+	torrentPath := filepath.Join(tmpDir, "test.torrent")
+	if _, err := Create(CreateOptions{Path: contentPath, OutputPath: torrentPath, Quiet: true}); err != nil {
+		t.Fatalf("Failed to create test torrent: %v", err)
+	}
+
+	opts := ModifyOptions{
+		TrackerURLs: []string{"https://tracker.example.com/announce", "ttps://typo.example.com/announce"},
+		OutputDir:   filepath.Join(tmpDir, "out"),
+		Quiet:       true,
+	}
+
+	result, err := ModifyTorrent(torrentPath, opts)
+	if err == nil || !strings.Contains(err.Error(), `#2 "ttps://typo.example.com/announce"`) {
+		t.Fatalf("Expected an error naming the second tracker, got %v", err)
+	}
+	if result.OutputPath != "" {
+		t.Errorf("Expected no output for an invalid tracker, got %q", result.OutputPath)
+	}
+
+	opts.NoValidateTrackers = true
+	result, err = ModifyTorrent(torrentPath, opts)
+	if err != nil {
+		t.Fatalf("Expected NoValidateTrackers to accept the tracker, got %v", err)
+	}
+	if !result.WasModified {
+		t.Error("Expected the torrent to be modified")
+	}
+}
+
+func TestModifyTorrent_CreationDate(t *testing.T) {
+	tmpDir := t.TempDir()
+	contentPath := filepath.Join(tmpDir, "content.txt")
+	if err := os.WriteFile(contentPath, []byte("test content"), 0644); err != nil {
+		t.Fatalf("Failed to create content file: %v", err)
+	}
+
+	torrentPath := filepath.Join(tmpDir, "test.torrent")
+	if _, err := Create(CreateOptions{Path: contentPath, OutputPath: torrentPath, Quiet: true}); err != nil {
+		t.Fatalf("Failed to create test torrent: %v", err)
+	}
+
+	date := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	result, err := ModifyTorrent(torrentPath, ModifyOptions{
+		Comment:      "fixed date",
+		CreationDate: date,
+		OutputDir:    filepath.Join(tmpDir, "out"),
+		Quiet:        true,
+	})
+	if err != nil {
+		t.Fatalf("ModifyTorrent failed: %v", err)
+	}
+
+	mi, err := LoadFromFile(result.OutputPath)
+	if err != nil {
+		t.Fatalf("Failed to load modified torrent: %v", err)
+	}
+	if mi.CreationDate != date.Unix() {
+		t.Errorf("CreationDate = %d, want %d", mi.CreationDate, date.Unix())
+	}
+}
+
+func TestModifyTorrent_Creator(t *testing.T) {
+	tmpDir := t.TempDir()
+	contentPath := filepath.Join(tmpDir, "content.txt")
+	if err := os.WriteFile(contentPath, []byte("test content"), 0644); err != nil {
+		t.Fatalf("Failed to create content file: %v", err)
+	}
+
+	torrentPath := filepath.Join(tmpDir, "test.torrent")
+	if _, err := Create(CreateOptions{Path: contentPath, OutputPath: torrentPath, Version: "test", Quiet: true}); err != nil {
+		t.Fatalf("Failed to create test torrent: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts ModifyOptions
+		want string
+	}{
+		{name: "kept", opts: ModifyOptions{Comment: "changed"}, want: "mkbrr/test (https://github.com/autobrr/mkbrr)"},
+		{name: "replaced", opts: ModifyOptions{Creator: "Transmission/4.0.5"}, want: "Transmission/4.0.5"},
+		{name: "no creator wins", opts: ModifyOptions{Creator: "Transmission/4.0.5", NoCreator: true}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.OutputDir = t.TempDir()
+			tt.opts.Quiet = true
+			result, err := ModifyTorrent(torrentPath, tt.opts)
+			if err != nil {
+				t.Fatalf("ModifyTorrent failed: %v", err)
+			}
+
+			mi, err := LoadFromFile(result.OutputPath)
+			if err != nil {
+				t.Fatalf("Failed to load modified torrent: %v", err)
+			}
+			if mi.CreatedBy != tt.want {
+				t.Errorf("CreatedBy = %q, want %q", mi.CreatedBy, tt.want)
+			}
+		})
+	}
+}
+
+func TestModifyTorrent_Rename(t *testing.T) {
+	tmpDir := t.TempDir()
+	contentPath := filepath.Join(tmpDir, "content.txt")
+	if err := os.WriteFile(contentPath, []byte("test content"), 0644); err != nil {
+		t.Fatalf("Failed to create content file: %v", err)
+	}
+
+	torrentPath := filepath.Join(tmpDir, "test.torrent")
+	if _, err := Create(CreateOptions{Path: contentPath, OutputPath: torrentPath, Name: "  Show  S01 1080p.. ", Quiet: true}); err != nil {
+		t.Fatalf("Failed to create test torrent: %v", err)
+	}
+	original, err := LoadFromFile(torrentPath)
+	if err != nil {
+		t.Fatalf("Failed to load torrent: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		opts         ModifyOptions
+		wantName     string
+		wantFilename string
+		wantErr      string
+	}{
+		{name: "renamed", opts: ModifyOptions{Name: "Show.S01.1080p"}, wantName: "Show.S01.1080p", wantFilename: "Show.S01.1080p.torrent"},
+		{name: "normalized", opts: ModifyOptions{NormalizeName: true}, wantName: "Show S01 1080p", wantFilename: "Show S01 1080p.torrent"},
+		{name: "renamed and normalized", opts: ModifyOptions{Name: "Show  S02. ", NormalizeName: true}, wantName: "Show S02", wantFilename: "Show S02.torrent"},
+		{name: "path separator", opts: ModifyOptions{Name: "Show/S01"}, wantErr: "path separators"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.OutputDir = t.TempDir()
+			tt.opts.SkipPrefix = true
+			tt.opts.Quiet = true
+			result, err := ModifyTorrent(torrentPath, tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ModifyTorrent error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ModifyTorrent failed: %v", err)
+			}
+
+			mi, err := LoadFromFile(result.OutputPath)
+			if err != nil {
+				t.Fatalf("Failed to load modified torrent: %v", err)
+			}
+			if name := mi.GetInfo().Name; name != tt.wantName {
+				t.Errorf("Name = %q, want %q", name, tt.wantName)
+			}
+			if filename := filepath.Base(result.OutputPath); filename != tt.wantFilename {
+				t.Errorf("output filename = %q, want %q", filename, tt.wantFilename)
+			}
+			if mi.HashInfoBytes() == original.HashInfoBytes() {
+				t.Error("info hash did not change")
+			}
+			if result.NewInfoHash != mi.HashInfoBytes().String() {
+				t.Errorf("NewInfoHash = %q, want %s", result.NewInfoHash, mi.HashInfoBytes())
+			}
+		})
+	}
+}
+
+func TestNormalizeAnnounce(t *testing.T) {
+	tests := []struct {
+		name         string
+		announce     string
+		announceList metainfo.AnnounceList
+		wantChanged  bool
+		wantAnnounce string
+		wantList     metainfo.AnnounceList
+	}{
+		{
+			name:         "no announce list",
+			announce:     "url1,url2,url3",
+			wantChanged:  true,
+			wantAnnounce: "url1",
+			wantList:     metainfo.AnnounceList{{"url1", "url2", "url3"}},
+		},
+		{
+			name:         "merged into the first tier",
+			announce:     "url1, url2,,url1",
+			announceList: metainfo.AnnounceList{{"url1, url2,,url1", "url4", "url2"}, {"url5"}},
+			wantChanged:  true,
+			wantAnnounce: "url1",
+			wantList:     metainfo.AnnounceList{{"url1", "url2", "url4"}, {"url5"}},
+		},
+		{
+			name:         "single URL",
+			announce:     "url1",
+			announceList: metainfo.AnnounceList{{"url1"}},
+			wantAnnounce: "url1",
+			wantList:     metainfo.AnnounceList{{"url1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mi := &metainfo.MetaInfo{Announce: tt.announce, AnnounceList: tt.announceList}
+			if changed := NormalizeAnnounce(mi); changed != tt.wantChanged {
+				t.Errorf("NormalizeAnnounce() = %v, want %v", changed, tt.wantChanged)
+			}
+			if mi.Announce != tt.wantAnnounce {
+				t.Errorf("Announce = %q, want %q", mi.Announce, tt.wantAnnounce)
+			}
+			if !reflect.DeepEqual(mi.AnnounceList, tt.wantList) {
+				t.Errorf("AnnounceList = %v, want %v", mi.AnnounceList, tt.wantList)
+			}
+		})
+	}
+}
+
+func TestModifyTorrent_NormalizesCommaAnnounce(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "dummy.txt"), []byte("test content"), 0644); err != nil {
+		t.Fatalf("Failed to create dummy file: %v", err)
+	}
+
+	torrentPath := filepath.Join(t.TempDir(), "test.torrent")
+	created, err := Create(CreateOptions{
+		Path:        tmpDir,
+		OutputPath:  torrentPath,
+		TrackerURLs: []string{"https://tracker1.com/announce,https://tracker2.com/announce"},
+		NoDate:      true,
+		Quiet:       true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test torrent: %v", err)
+	}
+	want := metainfo.AnnounceList{{"https://tracker1.com/announce", "https://tracker2.com/announce"}}
+	mi, err := LoadFromFile(created.Path)
+	if err != nil {
+		t.Fatalf("Failed to load torrent: %v", err)
+	}
+	if mi.Announce != "https://tracker1.com/announce" || !reflect.DeepEqual(mi.AnnounceList, want) {
+		t.Errorf("created announce = %q, list %v", mi.Announce, mi.AnnounceList)
+	}
+
+	// a torrent written by other software keeps the comma-separated field
+	mi.Announce = "https://tracker1.com/announce,https://tracker2.com/announce"
+	mi.AnnounceList = nil
+	f, err := os.Create(torrentPath)
+	if err != nil {
+		t.Fatalf("Failed to open torrent: %v", err)
+	}
+	if err := mi.Write(f); err != nil {
+		t.Fatalf("Failed to write torrent: %v", err)
+	}
+	f.Close()
+
+	result, err := ModifyTorrent(torrentPath, ModifyOptions{OutputDir: t.TempDir(), Comment: "normalized", Version: "test"})
+	if err != nil {
+		t.Fatalf("ModifyTorrent failed: %v", err)
+	}
+	mi, err = LoadFromFile(result.OutputPath)
+	if err != nil {
+		t.Fatalf("Failed to load modified torrent: %v", err)
+	}
+	if mi.Announce != "https://tracker1.com/announce" || !reflect.DeepEqual(mi.AnnounceList, want) {
+		t.Errorf("modified announce = %q, list %v", mi.Announce, mi.AnnounceList)
+	}
+}
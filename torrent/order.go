@@ -0,0 +1,110 @@
+package torrent
+
+import (
+	"fmt"
+	"sort"
+)
+
+// sortFiles orders files in place according to the given file order.
+// The order determines file offsets and therefore the info hash, so every
+// mode must be deterministic for the same input. The path orders compare
+// key(file), the slash-separated path in the torrent, so they do not depend
+// on the OS path separator.
+func sortFiles(files []fileEntry, order string, key func(fileEntry) string) error {
+	switch order {
+	case "", FileOrderPath:
+		sort.Slice(files, func(i, j int) bool {
+			return key(files[i]) < key(files[j])
+		})
+	case FileOrderNatural:
+		sort.SliceStable(files, func(i, j int) bool {
+			return naturalLess(key(files[i]), key(files[j]))
+		})
+	case FileOrderNone:
+		// keep walk order
+	case FileOrderInode:
+		inodes := make(map[string]uint64, len(files))
+		for _, f := range files {
+			ino, err := fileInode(f.path)
+			if err != nil {
+				return err
+			}
+			inodes[f.path] = ino
+		}
+		sort.SliceStable(files, func(i, j int) bool {
+			return inodes[files[i].path] < inodes[files[j].path]
+		})
+	case FileOrderSizeDesc, FileOrderLargestFirst:
+		sort.SliceStable(files, func(i, j int) bool {
+			return files[i].length > files[j].length
+		})
+	case FileOrderSizeAsc:
+		sort.SliceStable(files, func(i, j int) bool {
+			return files[i].length < files[j].length
+		})
+	default:
+		return validateFileOrder(order)
+	}
+	return nil
+}
+
+// validateFileOrder returns an error unless order is empty or one of the FileOrder* constants.
+func validateFileOrder(order string) error {
+	switch order {
+	case "", FileOrderPath, FileOrderNatural, FileOrderNone, FileOrderInode, FileOrderSizeDesc, FileOrderSizeAsc, FileOrderLargestFirst:
+		return nil
+	}
+	return fmt.Errorf("invalid file order %q: must be one of %q, %q, %q, %q, %q (or %q) or %q", order,
+		FileOrderPath, FileOrderNatural, FileOrderNone, FileOrderInode, FileOrderSizeDesc, FileOrderLargestFirst, FileOrderSizeAsc)
+}
+
+// naturalLess compares two strings treating runs of digits as numbers,
+// so "track2" sorts before "track10". Strings that compare equal numerically
+// (e.g. "a01" and "a1") fall back to a plain comparison to stay deterministic.
+func naturalLess(a, b string) bool {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		ca, cb := a[i], b[j]
+		if isDigit(ca) && isDigit(cb) {
+			startA, startB := i, j
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+
+			numA := trimLeadingZeros(a[startA:i])
+			numB := trimLeadingZeros(b[startB:j])
+			if len(numA) != len(numB) {
+				return len(numA) < len(numB)
+			}
+			if numA != numB {
+				return numA < numB
+			}
+			continue
+		}
+
+		if ca != cb {
+			return ca < cb
+		}
+		i++
+		j++
+	}
+
+	if len(a)-i != len(b)-j {
+		return len(a)-i < len(b)-j
+	}
+	return a < b
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func trimLeadingZeros(s string) string {
+	for len(s) > 1 && s[0] == '0' {
+		s = s[1:]
+	}
+	return s
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package torrent
+
+import "fmt"
+
+// fileInode is not available on this platform, so the inode file order cannot be used.
+func fileInode(path string) (uint64, error) {
+	return 0, fmt.Errorf("file order %q is not supported on this platform", FileOrderInode)
+}
@@ -0,0 +1,222 @@
+package torrent
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "track2", b: "track10", want: true},
+		{a: "track10", b: "track2", want: false},
+		{a: "disc1/track9", b: "disc1/track10", want: true},
+		{a: "disc2/track1", b: "disc10/track1", want: true},
+		{a: "a", b: "b", want: true},
+		{a: "abc", b: "abcd", want: true},
+		{a: "a01", b: "a1", want: true},
+		{a: "a1", b: "a01", want: false},
+		{a: "same", b: "same", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := naturalLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("naturalLess(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSortFiles(t *testing.T) {
+	paths := func(files []fileEntry) []string {
+		out := make([]string, len(files))
+		for i, f := range files {
+			out[i] = f.path
+		}
+		return out
+	}
+	filePath := func(f fileEntry) string { return f.path }
+	newFiles := func() []fileEntry {
+		return []fileEntry{{path: "track10.flac"}, {path: "track2.flac"}, {path: "track1.flac"}}
+	}
+
+	files := newFiles()
+	if err := sortFiles(files, FileOrderPath, filePath); err != nil {
+		t.Fatalf("sortFiles returned error: %v", err)
+	}
+	if want := []string{"track1.flac", "track10.flac", "track2.flac"}; !slices.Equal(paths(files), want) {
+		t.Errorf("path order = %v, want %v", paths(files), want)
+	}
+
+	files = newFiles()
+	if err := sortFiles(files, FileOrderNatural, filePath); err != nil {
+		t.Fatalf("sortFiles returned error: %v", err)
+	}
+	if want := []string{"track1.flac", "track2.flac", "track10.flac"}; !slices.Equal(paths(files), want) {
+		t.Errorf("natural order = %v, want %v", paths(files), want)
+	}
+
+	files = newFiles()
+	if err := sortFiles(files, FileOrderNone, filePath); err != nil {
+		t.Fatalf("sortFiles returned error: %v", err)
+	}
+	if want := paths(newFiles()); !slices.Equal(paths(files), want) {
+		t.Errorf("none order = %v, want %v", paths(files), want)
+	}
+
+	sized := func() []fileEntry {
+		return []fileEntry{{path: "a", length: 10}, {path: "b", length: 30}, {path: "c", length: 10}, {path: "d", length: 20}}
+	}
+	files = sized()
+	if err := sortFiles(files, FileOrderSizeDesc, filePath); err != nil {
+		t.Fatalf("sortFiles returned error: %v", err)
+	}
+	if want := []string{"b", "d", "a", "c"}; !slices.Equal(paths(files), want) {
+		t.Errorf("size-desc order = %v, want %v", paths(files), want)
+	}
+
+	files = sized()
+	if err := sortFiles(files, FileOrderSizeAsc, filePath); err != nil {
+		t.Fatalf("sortFiles returned error: %v", err)
+	}
+	if want := []string{"a", "c", "d", "b"}; !slices.Equal(paths(files), want) {
+		t.Errorf("size-asc order = %v, want %v", paths(files), want)
+	}
+
+	if err := sortFiles(newFiles(), "random", filePath); err == nil {
+		t.Error("expected error for invalid file order")
+	}
+}
+
+func TestCreateTorrent_NaturalFileOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"track1.flac", "track2.flac", "track10.flac"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("audio data for "+name), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+	}
+
+	pieceLenExp := uint(16)
+	create := func(order string) []string {
+		mi, err := CreateTorrent(CreateOptions{
+			Path:           tmpDir,
+			PieceLengthExp: &pieceLenExp,
+			FileOrder:      order,
+			Quiet:          true,
+		})
+		if err != nil {
+			t.Fatalf("CreateTorrent(%q) returned error: %v", order, err)
+		}
+		var names []string
+		for _, f := range mi.GetInfo().Files {
+			names = append(names, filepath.Join(f.Path...))
+		}
+		return names
+	}
+
+	if got, want := create(FileOrderNatural), []string{"track1.flac", "track2.flac", "track10.flac"}; !slices.Equal(got, want) {
+		t.Errorf("natural order files = %v, want %v", got, want)
+	}
+	if got, want := create(FileOrderPath), []string{"track1.flac", "track10.flac", "track2.flac"}; !slices.Equal(got, want) {
+		t.Errorf("path order files = %v, want %v", got, want)
+	}
+}
+
+func TestCreateTorrent_PathOrderIsPlatformIndependent(t *testing.T) {
+	contentDir := filepath.Join(t.TempDir(), "Release")
+	// '.' < '/' < '0' < '\', so sorting by OS paths would put a0.bin before
+	// a/b.bin on Windows only
+	for i, name := range []string{"a0.bin", "a/b.bin", "a.bin"} {
+		path := filepath.Join(contentDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, bytes.Repeat([]byte{byte(i + 1)}, 1000*(i+1)), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	pieceLenExp := uint(16)
+	mi, err := CreateTorrent(CreateOptions{
+		Path:           contentDir,
+		PieceLengthExp: &pieceLenExp,
+		IsPrivate:      true,
+		NoDate:         true,
+		Quiet:          true,
+	})
+	if err != nil {
+		t.Fatalf("CreateTorrent returned error: %v", err)
+	}
+
+	var names []string
+	for _, f := range mi.GetInfo().Files {
+		names = append(names, strings.Join(f.Path, "/"))
+	}
+	if want := []string{"a.bin", "a/b.bin", "a0.bin"}; !slices.Equal(names, want) {
+		t.Errorf("files = %v, want %v", names, want)
+	}
+
+	// the same on every OS
+	if got, want := mi.HashInfoBytes().String(), "d6ae2de679f1d8b9261f57e0107b2760143a4ffd"; got != want {
+		t.Errorf("info hash = %s, want %s", got, want)
+	}
+}
+
+func TestCreateTorrent_LargestFirst(t *testing.T) {
+	contentDir := filepath.Join(t.TempDir(), "Release")
+	if err := os.MkdirAll(contentDir, 0755); err != nil {
+		t.Fatalf("failed to create content dir: %v", err)
+	}
+	sizes := map[string]int{"a.bin": 3000, "b.bin": 150000, "c.bin": 70000, "d.bin": 1, "e.bin": 90000}
+	for name, size := range sizes {
+		if err := os.WriteFile(filepath.Join(contentDir, name), bytes.Repeat([]byte(name[:1]), size), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	pieceLenExp := uint(16)
+	pieces := make(map[string]string)
+	for _, order := range []string{FileOrderSizeDesc, FileOrderLargestFirst} {
+		torrentPath := filepath.Join(t.TempDir(), order+".torrent")
+		if _, err := Create(CreateOptions{
+			Path:           contentDir,
+			OutputPath:     torrentPath,
+			PieceLengthExp: &pieceLenExp,
+			FileOrder:      order,
+			NoDate:         true,
+			Quiet:          true,
+		}); err != nil {
+			t.Fatalf("Create(%q) returned error: %v", order, err)
+		}
+
+		info, err := loadInfo(torrentPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, f := range info.Files {
+			names = append(names, strings.Join(f.Path, "/"))
+		}
+		if want := []string{"b.bin", "e.bin", "c.bin", "a.bin", "d.bin"}; !slices.Equal(names, want) {
+			t.Errorf("%s files = %v, want %v", order, names, want)
+		}
+
+		// the pieces must still match the content in the new order
+		result, err := VerifyData(VerifyOptions{TorrentPath: torrentPath, ContentPath: contentDir, Quiet: true})
+		if err != nil {
+			t.Fatalf("VerifyData returned error: %v", err)
+		}
+		if result.GoodPieces != result.TotalPieces {
+			t.Errorf("%s: %d of %d pieces verified", order, result.GoodPieces, result.TotalPieces)
+		}
+		pieces[order] = string(info.Pieces)
+	}
+	if pieces[FileOrderSizeDesc] != pieces[FileOrderLargestFirst] {
+		t.Error("largest-first should produce the same pieces as size-desc")
+	}
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package torrent
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// fileInode returns the inode number of the file at path.
+func fileInode(path string) (uint64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("could not stat %s for inode order: %w", path, err)
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("inode of %s is not available", path)
+	}
+	return uint64(stat.Ino), nil
+}
@@ -0,0 +1,56 @@
+//go:build unix
+
+package torrent
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"testing"
+)
+
+func TestCreateTorrent_InodeFileOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// write the files in reverse alphabetical order so inode order differs from path order
+	names := []string{"d.bin", "c.bin", "b.bin", "a.bin"}
+	inodes := make(map[string]uint64)
+	for _, name := range names {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte("content of "+name), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("failed to stat test file: %v", err)
+		}
+		inodes[name] = uint64(info.Sys().(*syscall.Stat_t).Ino)
+	}
+
+	want := slices.Clone(names)
+	slices.SortStableFunc(want, func(a, b string) int {
+		switch {
+		case inodes[a] < inodes[b]:
+			return -1
+		case inodes[a] > inodes[b]:
+			return 1
+		}
+		return 0
+	})
+	if slices.IsSorted(want) {
+		t.Skip("the filesystem allocated inodes in alphabetical order")
+	}
+
+	mi, err := CreateTorrent(CreateOptions{Path: tmpDir, FileOrder: FileOrderInode, Quiet: true})
+	if err != nil {
+		t.Fatalf("CreateTorrent returned error: %v", err)
+	}
+	var got []string
+	for _, f := range mi.GetInfo().Files {
+		got = append(got, filepath.Join(f.Path...))
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("inode order files = %v, want %v (inodes %v)", got, want, inodes)
+	}
+}
@@ -0,0 +1,122 @@
+package torrent
+
+import (
+	"errors"
+	"io"
+	"path"
+	"strconv"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// padDir is the directory of BEP 47 padding files in a torrent
+const padDir = ".pad"
+
+// isPadFile reports whether a file of a torrent is padding, which clients
+// read as zeros instead of from disk. The BEP 47 "p" attribute decides; the
+// .pad directory is only a fallback for files without any attributes, as
+// some older creators write padding without them.
+func isPadFile(f metainfo.FileInfo) bool {
+	if f.Attr != "" {
+		return hasAttr(f.Attr, attrPadding)
+	}
+	return len(f.Path) > 0 && f.Path[0] == padDir
+}
+
+// padLength returns the padding needed after offset bytes to reach the next
+// piece boundary, 0 when offset is on one.
+func padLength(offset, pieceLen int64) int64 {
+	if rem := offset % pieceLen; rem != 0 {
+		return pieceLen - rem
+	}
+	return 0
+}
+
+// padFileEntries returns files with a padding entry after each file but the
+// last that does not end on a piece boundary, with offsets recalculated.
+func padFileEntries(files []fileEntry, pieceLen int64) []fileEntry {
+	padded := make([]fileEntry, 0, 2*len(files))
+	var offset int64
+	for i, f := range files {
+		f.offset = offset
+		padded = append(padded, f)
+		offset += f.length
+		if i == len(files)-1 {
+			break
+		}
+		if n := padLength(offset, pieceLen); n > 0 {
+			padded = append(padded, fileEntry{path: path.Join(padDir, strconv.FormatInt(n, 10)), length: n, offset: offset, pad: true})
+			offset += n
+		}
+	}
+	return padded
+}
+
+// padInfoFiles is padFileEntries for the files of an info dictionary. The
+// padding files are named .pad/<length> and carry the BEP 47 "p" attribute.
+func padInfoFiles(files []metainfo.FileInfo, pieceLen int64) []metainfo.FileInfo {
+	padded := make([]metainfo.FileInfo, 0, 2*len(files))
+	var offset int64
+	for i, f := range files {
+		padded = append(padded, f)
+		offset += f.Length
+		if i == len(files)-1 {
+			break
+		}
+		if n := padLength(offset, pieceLen); n > 0 {
+			pad := metainfo.FileInfo{Path: []string{padDir, strconv.FormatInt(n, 10)}, Length: n}
+			pad.Attr = attrPadding
+			padded = append(padded, pad)
+			offset += n
+		}
+	}
+	return padded
+}
+
+// contentFileEntries returns files without the padding entries.
+func contentFileEntries(files []fileEntry) []fileEntry {
+	content := make([]fileEntry, 0, len(files))
+	for _, f := range files {
+		if !f.pad {
+			content = append(content, f)
+		}
+	}
+	return content
+}
+
+// zeroReader reads length zero bytes, standing in for a padding file.
+type zeroReader struct {
+	length   int64
+	position int64
+}
+
+func (z *zeroReader) Read(p []byte) (int, error) {
+	if z.position >= z.length {
+		return 0, io.EOF
+	}
+	n := int(min(int64(len(p)), z.length-z.position))
+	clear(p[:n])
+	z.position += int64(n)
+	return n, nil
+}
+
+func (z *zeroReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += z.position
+	case io.SeekEnd:
+		offset += z.length
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	z.position = offset
+	return offset, nil
+}
+
+func (z *zeroReader) Close() error {
+	return nil
+}
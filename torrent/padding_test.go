@@ -0,0 +1,131 @@
+package torrent
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+func TestCreateTorrent_PadToPieceBoundary(t *testing.T) {
+	const pieceLen = 1 << 16
+	contentDir := filepath.Join(t.TempDir(), "Padded")
+	if err := os.MkdirAll(contentDir, 0755); err != nil {
+		t.Fatalf("failed to create content dir: %v", err)
+	}
+	contents := [][]byte{
+		bytes.Repeat([]byte("a"), 70000),
+		[]byte("tiny file!"),
+		bytes.Repeat([]byte("c"), pieceLen+5),
+	}
+	for i, data := range contents {
+		if err := os.WriteFile(filepath.Join(contentDir, string(rune('a'+i))+".bin"), data, 0644); err != nil {
+			t.Fatalf("failed to write content: %v", err)
+		}
+	}
+
+	pieceLenExp := uint(16)
+	torrentPath := filepath.Join(t.TempDir(), "padded.torrent")
+	if _, err := Create(CreateOptions{
+		Path:               contentDir,
+		OutputPath:         torrentPath,
+		PieceLengthExp:     &pieceLenExp,
+		PadToPieceBoundary: true,
+		Quiet:              true,
+	}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	info, err := loadInfo(torrentPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every file but the last is padded up to the next piece boundary
+	var gotPaths [][]string
+	for _, f := range info.Files {
+		gotPaths = append(gotPaths, f.Path)
+		if isPadFile(f) != (f.Attr == attrPadding) {
+			t.Errorf("file %v has attr %q", f.Path, f.Attr)
+		}
+	}
+	wantPaths := [][]string{{"a.bin"}, {".pad", "61072"}, {"b.bin"}, {".pad", "65526"}, {"c.bin"}}
+	if !reflect.DeepEqual(gotPaths, wantPaths) {
+		t.Fatalf("files = %v, want %v", gotPaths, wantPaths)
+	}
+
+	var stream []byte
+	for i, data := range contents {
+		stream = append(stream, data...)
+		if i < len(contents)-1 {
+			stream = append(stream, make([]byte, padLength(int64(len(stream)), pieceLen))...)
+		}
+	}
+	var wantPieces []byte
+	for start := 0; start < len(stream); start += pieceLen {
+		sum := sha1.Sum(stream[start:min(start+pieceLen, len(stream))])
+		wantPieces = append(wantPieces, sum[:]...)
+	}
+	if !bytes.Equal(info.Pieces, wantPieces) {
+		t.Errorf("pieces do not match the zero-padded content: got %d hashes, want %d", len(info.Pieces)/20, len(wantPieces)/20)
+	}
+
+	// no padding files exist on disk, verification reads them as zeros
+	result, err := VerifyData(VerifyOptions{TorrentPath: torrentPath, ContentPath: contentDir, Quiet: true})
+	if err != nil {
+		t.Fatalf("VerifyData failed: %v", err)
+	}
+	if result.GoodPieces != result.TotalPieces || len(result.MissingFiles) != 0 {
+		t.Errorf("expected every piece to match, got %d of %d good, missing %v", result.GoodPieces, result.TotalPieces, result.MissingFiles)
+	}
+	if len(result.Files) != len(contents) {
+		t.Errorf("expected %d files in the result without padding, got %+v", len(contents), result.Files)
+	}
+}
+
+func TestPadInfoFiles(t *testing.T) {
+	files := []metainfo.FileInfo{{Path: []string{"a"}, Length: 10}, {Path: []string{"b"}, Length: 16}, {Path: []string{"c"}, Length: 3}}
+	got := padInfoFiles(files, 16)
+	var lengths []int64
+	for _, f := range got {
+		lengths = append(lengths, f.Length)
+	}
+	// b ends on a boundary, so only a is padded
+	if want := []int64{10, 6, 16, 3}; !reflect.DeepEqual(lengths, want) {
+		t.Errorf("lengths = %v, want %v", lengths, want)
+	}
+
+	entries := padFileEntries([]fileEntry{{path: "a", length: 10}, {path: "b", length: 16}, {path: "c", length: 3}}, 16)
+	if len(entries) != len(got) {
+		t.Fatalf("padFileEntries gave %d entries, padInfoFiles %d", len(entries), len(got))
+	}
+	for i, e := range entries {
+		if e.length != got[i].Length || e.pad != isPadFile(got[i]) {
+			t.Errorf("entry %d = %+v, want length %d", i, e, got[i].Length)
+		}
+	}
+}
+
+func TestIsPadFile(t *testing.T) {
+	tests := []struct {
+		name string
+		file metainfo.FileInfo
+		want bool
+	}{
+		{name: "padding attribute", file: metainfo.FileInfo{Path: []string{".pad", "6"}, ExtendedFileAttrs: metainfo.ExtendedFileAttrs{Attr: "p"}}, want: true},
+		{name: "padding attribute outside .pad", file: metainfo.FileInfo{Path: []string{"_____padding_file_0"}, ExtendedFileAttrs: metainfo.ExtendedFileAttrs{Attr: "p"}}, want: true},
+		{name: "other attributes in .pad", file: metainfo.FileInfo{Path: []string{".pad", "notes.txt"}, ExtendedFileAttrs: metainfo.ExtendedFileAttrs{Attr: "x"}}, want: false},
+		{name: "no attributes in .pad", file: metainfo.FileInfo{Path: []string{".pad", "6"}}, want: true},
+		{name: "regular file", file: metainfo.FileInfo{Path: []string{"a.mkv"}}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPadFile(tt.file); got != tt.want {
+				t.Errorf("isPadFile(%+v) = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
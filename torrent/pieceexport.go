@@ -0,0 +1,187 @@
+package torrent
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// piecesExportHeader starts every piece export, followed by the format version
+const (
+	piecesExportHeader  = "mkbrr-pieces"
+	piecesExportVersion = 1
+)
+
+// WritePiecesExport writes the piece hashes of info with the layout needed to
+// verify content against them, so the content can be checked without the
+// .torrent file. The format is line based:
+//
+//	mkbrr-pieces 1
+//	name "Show.S01"
+//	piece-length 262144
+//	total-size 734003200
+//	file 367001600 "Show.S01E01.mkv"
+//	file 367001600 "Show.S01E02.mkv"
+//	pieces 2800
+//	<one lowercase hex SHA-1 per line>
+//
+// Names and paths are Go-quoted strings with "/" between path components.
+// Single-file torrents have no file lines.
+func WritePiecesExport(w io.Writer, info *metainfo.Info) error {
+	if len(info.Pieces) == 0 {
+		return fmt.Errorf("torrent has no v1 piece hashes to export")
+	}
+	if len(info.Pieces)%pieceHashSize != 0 {
+		return fmt.Errorf("invalid pieces field: length %d is not a multiple of %d", len(info.Pieces), pieceHashSize)
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s %d\n", piecesExportHeader, piecesExportVersion)
+	fmt.Fprintf(bw, "name %s\n", strconv.Quote(info.Name))
+	fmt.Fprintf(bw, "piece-length %d\n", info.PieceLength)
+	fmt.Fprintf(bw, "total-size %d\n", info.TotalLength())
+	for _, f := range info.Files {
+		fmt.Fprintf(bw, "file %d %s\n", f.Length, strconv.Quote(strings.Join(f.Path, "/")))
+	}
+	fmt.Fprintf(bw, "pieces %d\n", len(info.Pieces)/pieceHashSize)
+
+	if _, err := WritePieceHashes(bw, info, PiecesFormatHex); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write piece export: %w", err)
+	}
+	return nil
+}
+
+// ReadPiecesExport reads a piece export written by WritePiecesExport and
+// returns an info dictionary with its name, piece length, files and piece
+// hashes, enough to verify content against.
+func ReadPiecesExport(r io.Reader) (*metainfo.Info, error) {
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	next := func() (string, bool) {
+		if !scanner.Scan() {
+			return "", false
+		}
+		lineNum++
+		return scanner.Text(), true
+	}
+	fail := func(format string, args ...any) error {
+		return fmt.Errorf("invalid piece export, line %d: %s", lineNum, fmt.Sprintf(format, args...))
+	}
+
+	line, ok := next()
+	if !ok || line != fmt.Sprintf("%s %d", piecesExportHeader, piecesExportVersion) {
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("could not read piece export: %w", err)
+		}
+		return nil, fail("expected %q header", fmt.Sprintf("%s %d", piecesExportHeader, piecesExportVersion))
+	}
+
+	info := &metainfo.Info{}
+	var totalSize int64 = -1
+	numPieces := -1
+	for numPieces < 0 {
+		line, ok := next()
+		if !ok {
+			if err := scanner.Err(); err != nil {
+				return nil, fmt.Errorf("could not read piece export: %w", err)
+			}
+			return nil, fail("missing pieces line")
+		}
+
+		key, value, _ := strings.Cut(line, " ")
+		var err error
+		switch key {
+		case "name":
+			info.Name, err = strconv.Unquote(value)
+		case "piece-length":
+			info.PieceLength, err = strconv.ParseInt(value, 10, 64)
+			if err == nil && info.PieceLength <= 0 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "total-size":
+			totalSize, err = strconv.ParseInt(value, 10, 64)
+			if err == nil && totalSize < 0 {
+				err = fmt.Errorf("must not be negative")
+			}
+		case "file":
+			size, quoted, _ := strings.Cut(value, " ")
+			var f metainfo.FileInfo
+			if f.Length, err = strconv.ParseInt(size, 10, 64); err != nil {
+				break
+			}
+			var p string
+			if p, err = strconv.Unquote(quoted); err != nil {
+				break
+			}
+			f.Path = strings.Split(p, "/")
+			info.Files = append(info.Files, f)
+		case "pieces":
+			numPieces, err = strconv.Atoi(value)
+			if err == nil && numPieces < 0 {
+				err = fmt.Errorf("must not be negative")
+			}
+		default:
+			return nil, fail("unknown field %q", key)
+		}
+		if err != nil {
+			return nil, fail("invalid %s %q: %v", key, value, err)
+		}
+	}
+
+	if info.PieceLength == 0 {
+		return nil, fmt.Errorf("invalid piece export: missing piece-length")
+	}
+	if totalSize < 0 {
+		return nil, fmt.Errorf("invalid piece export: missing total-size")
+	}
+	if len(info.Files) == 0 {
+		info.Length = totalSize
+	} else if sum := info.TotalLength(); sum != totalSize {
+		return nil, fmt.Errorf("invalid piece export: files add up to %d bytes, total-size is %d", sum, totalSize)
+	}
+	if want := int((totalSize + info.PieceLength - 1) / info.PieceLength); numPieces != want {
+		return nil, fmt.Errorf("invalid piece export: %d pieces listed, %d bytes in %d byte pieces need %d", numPieces, totalSize, info.PieceLength, want)
+	}
+
+	info.Pieces = make([]byte, 0, numPieces*pieceHashSize)
+	for range numPieces {
+		line, ok := next()
+		if !ok {
+			if err := scanner.Err(); err != nil {
+				return nil, fmt.Errorf("could not read piece export: %w", err)
+			}
+			return nil, fmt.Errorf("invalid piece export: expected %d piece hashes, got %d", numPieces, len(info.Pieces)/pieceHashSize)
+		}
+		hash, err := hex.DecodeString(strings.TrimSpace(line))
+		if err != nil || len(hash) != pieceHashSize {
+			return nil, fail("invalid piece hash %q", line)
+		}
+		info.Pieces = append(info.Pieces, hash...)
+	}
+
+	return info, nil
+}
+
+// LoadPiecesExport reads the piece export at path, see ReadPiecesExport.
+func LoadPiecesExport(path string) (*metainfo.Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open piece export: %w", err)
+	}
+	defer f.Close()
+
+	info, err := ReadPiecesExport(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return info, nil
+}
@@ -0,0 +1,171 @@
+package torrent
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writePiecesExportFixture creates a torrent for contentPath, writes its piece
+// export next to it and returns the torrent and export paths.
+func writePiecesExportFixture(t *testing.T, contentPath string) (string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	pieceLenExp := uint(16)
+	torrentPath := filepath.Join(dir, "content.torrent")
+	if _, err := Create(CreateOptions{Path: contentPath, OutputPath: torrentPath, PieceLengthExp: &pieceLenExp, Quiet: true}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	loaded, err := LoadFromFile(torrentPath)
+	if err != nil {
+		t.Fatalf("failed to load torrent: %v", err)
+	}
+	info := loaded.GetInfo()
+
+	piecesPath := filepath.Join(dir, "content.pieces")
+	f, err := os.Create(piecesPath)
+	if err != nil {
+		t.Fatalf("failed to create export: %v", err)
+	}
+	defer f.Close()
+	if err := WritePiecesExport(f, info); err != nil {
+		t.Fatalf("WritePiecesExport failed: %v", err)
+	}
+	return torrentPath, piecesPath
+}
+
+func TestPiecesExport_RoundTrip(t *testing.T) {
+	contentDir := filepath.Join(t.TempDir(), "Show.S01")
+	writeFile := func(name string, size int, seed byte) {
+		path := filepath.Join(contentDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i) ^ seed
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	writeFile("Show.S01E01.mkv", 150000, 1)
+	writeFile("Extras/sample file.mkv", 70000, 2)
+
+	torrentPath, piecesPath := writePiecesExportFixture(t, contentDir)
+
+	loaded, err := LoadFromFile(torrentPath)
+	if err != nil {
+		t.Fatalf("failed to load torrent: %v", err)
+	}
+	want := loaded.GetInfo()
+
+	got, err := LoadPiecesExport(piecesPath)
+	if err != nil {
+		t.Fatalf("LoadPiecesExport failed: %v", err)
+	}
+	if !bytes.Equal(got.Pieces, want.Pieces) {
+		t.Error("imported pieces differ from info.Pieces")
+	}
+	if got.Name != want.Name || got.PieceLength != want.PieceLength || got.TotalLength() != want.TotalLength() {
+		t.Errorf("imported name %q, piece length %d, size %d; want %q, %d, %d",
+			got.Name, got.PieceLength, got.TotalLength(), want.Name, want.PieceLength, want.TotalLength())
+	}
+	if !reflect.DeepEqual(got.Files, want.Files) {
+		t.Errorf("imported files = %+v, want %+v", got.Files, want.Files)
+	}
+
+	// the hash lines are the --extract-pieces hex output
+	var hashes bytes.Buffer
+	if _, err := WritePieceHashes(&hashes, want, PiecesFormatHex); err != nil {
+		t.Fatalf("WritePieceHashes failed: %v", err)
+	}
+	export, err := os.ReadFile(piecesPath)
+	if err != nil {
+		t.Fatalf("failed to read export: %v", err)
+	}
+	if !bytes.HasSuffix(export, hashes.Bytes()) {
+		t.Error("export does not end with the hex piece hashes")
+	}
+
+	// verifying against the export matches verifying against the torrent,
+	// for intact and for corrupted content
+	compare := func(name string) {
+		t.Helper()
+		fromTorrent, err := VerifyData(VerifyOptions{TorrentPath: torrentPath, ContentPath: contentDir, Quiet: true})
+		if err != nil {
+			t.Fatalf("%s: VerifyData with torrent failed: %v", name, err)
+		}
+		fromExport, err := VerifyData(VerifyOptions{PiecesPath: piecesPath, ContentPath: contentDir, Quiet: true})
+		if err != nil {
+			t.Fatalf("%s: VerifyData with export failed: %v", name, err)
+		}
+		if !reflect.DeepEqual(fromTorrent, fromExport) {
+			t.Errorf("%s: export result %+v, torrent result %+v", name, fromExport, fromTorrent)
+		}
+	}
+	compare("intact")
+
+	f, err := os.OpenFile(filepath.Join(contentDir, "Show.S01E01.mkv"), os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("failed to open content: %v", err)
+	}
+	if _, err := f.WriteAt([]byte("corrupt"), 70000); err != nil {
+		t.Fatalf("failed to corrupt content: %v", err)
+	}
+	f.Close()
+	compare("corrupted")
+
+	if err := os.Remove(filepath.Join(contentDir, "Extras", "sample file.mkv")); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+	compare("missing file")
+}
+
+func TestPiecesExport_SingleFile(t *testing.T) {
+	contentPath := filepath.Join(t.TempDir(), "content.bin")
+	if err := os.WriteFile(contentPath, bytes.Repeat([]byte("mkbrr"), 30000), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+	_, piecesPath := writePiecesExportFixture(t, contentPath)
+
+	result, err := VerifyData(VerifyOptions{PiecesPath: piecesPath, ContentPath: contentPath, Quiet: true})
+	if err != nil {
+		t.Fatalf("VerifyData failed: %v", err)
+	}
+	if result.GoodPieces != result.TotalPieces || result.TotalPieces != 3 {
+		t.Errorf("good pieces = %d of %d, want 3 of 3", result.GoodPieces, result.TotalPieces)
+	}
+}
+
+func TestReadPiecesExport_Invalid(t *testing.T) {
+	hash := strings.Repeat("ab", pieceHashSize)
+	tests := []struct {
+		name    string
+		export  string
+		wantErr string
+	}{
+		{name: "no header", export: "piece-length 16\n", wantErr: "header"},
+		{name: "unknown version", export: "mkbrr-pieces 2\n", wantErr: "header"},
+		{name: "unknown field", export: "mkbrr-pieces 1\ncolor blue\n", wantErr: "unknown field"},
+		{name: "missing pieces line", export: "mkbrr-pieces 1\npiece-length 16\ntotal-size 16\n", wantErr: "missing pieces line"},
+		{name: "wrong piece count", export: "mkbrr-pieces 1\npiece-length 16\ntotal-size 40\npieces 2\n" + hash + "\n" + hash + "\n", wantErr: "need 3"},
+		{name: "files do not add up", export: "mkbrr-pieces 1\npiece-length 16\ntotal-size 16\nfile 10 \"a\"\npieces 1\n" + hash + "\n", wantErr: "add up to 10"},
+		{name: "short hash", export: "mkbrr-pieces 1\npiece-length 16\ntotal-size 16\npieces 1\nabcd\n", wantErr: "invalid piece hash"},
+		{name: "truncated", export: "mkbrr-pieces 1\npiece-length 16\ntotal-size 32\npieces 2\n" + hash + "\n", wantErr: "expected 2 piece hashes, got 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadPiecesExport(strings.NewReader(tt.export))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ReadPiecesExport error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package torrent
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// Piece hash formats supported by WritePieceHashes
+const (
+	PiecesFormatHex    = "hex"    // one lowercase hex string per line (default)
+	PiecesFormatBase64 = "base64" // one standard base64 string per line
+	PiecesFormatBinary = "binary" // raw 20-byte hashes back to back, as stored in the torrent
+)
+
+// pieceHashSize is the length of a v1 SHA1 piece hash in bytes
+const pieceHashSize = 20
+
+// ValidatePiecesFormat returns an error if format is not one of the PiecesFormat* constants.
+// An empty format selects PiecesFormatHex.
+func ValidatePiecesFormat(format string) error {
+	switch format {
+	case "", PiecesFormatHex, PiecesFormatBase64, PiecesFormatBinary:
+		return nil
+	default:
+		return fmt.Errorf("invalid pieces format %q: must be one of %q, %q or %q", format, PiecesFormatHex, PiecesFormatBase64, PiecesFormatBinary)
+	}
+}
+
+// WritePieceHashes streams the piece hashes of info to w in the given format
+// and returns the number of pieces written.
+func WritePieceHashes(w io.Writer, info *metainfo.Info, format string) (int, error) {
+	if err := ValidatePiecesFormat(format); err != nil {
+		return 0, err
+	}
+
+	// binary hashes are written as-is
+	var encode func(hash []byte) string
+	switch format {
+	case "", PiecesFormatHex:
+		encode = hex.EncodeToString
+	case PiecesFormatBase64:
+		encode = base64.StdEncoding.EncodeToString
+	}
+
+	if len(info.Pieces)%pieceHashSize != 0 {
+		return 0, fmt.Errorf("invalid pieces field: length %d is not a multiple of %d", len(info.Pieces), pieceHashSize)
+	}
+
+	bw := bufio.NewWriter(w)
+	count := 0
+	for offset := 0; offset < len(info.Pieces); offset += pieceHashSize {
+		hash := info.Pieces[offset : offset+pieceHashSize]
+
+		var err error
+		if encode == nil {
+			_, err = bw.Write(hash)
+		} else {
+			_, err = fmt.Fprintln(bw, encode(hash))
+		}
+		if err != nil {
+			return count, fmt.Errorf("failed to write piece hash %d: %w", count, err)
+		}
+		count++
+	}
+
+	if err := bw.Flush(); err != nil {
+		return count, fmt.Errorf("failed to write piece hashes: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,112 @@
+package torrent
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWritePieceHashes(t *testing.T) {
+	const pieceLen = 1 << 16
+	content := make([]byte, 3*pieceLen+1234) // last piece is short
+	for i := range content {
+		content[i] = byte(i * 7)
+	}
+
+	dir := t.TempDir()
+	contentPath := filepath.Join(dir, "content.bin")
+	if err := os.WriteFile(contentPath, content, 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+
+	pieceLenExp := uint(16)
+	mi, err := CreateTorrent(CreateOptions{
+		Path:           contentPath,
+		PieceLengthExp: &pieceLenExp,
+		Quiet:          true,
+	})
+	if err != nil {
+		t.Fatalf("CreateTorrent failed: %v", err)
+	}
+	info, err := mi.UnmarshalInfo()
+	if err != nil {
+		t.Fatalf("failed to unmarshal info: %v", err)
+	}
+
+	var want [][]byte
+	for offset := 0; offset < len(content); offset += pieceLen {
+		sum := sha1.Sum(content[offset:min(offset+pieceLen, len(content))])
+		want = append(want, sum[:])
+	}
+
+	t.Run("hex to file", func(t *testing.T) {
+		outPath := filepath.Join(dir, "pieces.txt")
+		f, err := os.Create(outPath)
+		if err != nil {
+			t.Fatalf("failed to create output file: %v", err)
+		}
+		count, err := WritePieceHashes(f, &info, PiecesFormatHex)
+		f.Close()
+		if err != nil {
+			t.Fatalf("WritePieceHashes failed: %v", err)
+		}
+		if count != len(want) {
+			t.Fatalf("count = %d, want %d", count, len(want))
+		}
+
+		data, err := os.ReadFile(outPath)
+		if err != nil {
+			t.Fatalf("failed to read output file: %v", err)
+		}
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		if len(lines) != len(want) {
+			t.Fatalf("got %d lines, want %d", len(lines), len(want))
+		}
+		for i, line := range lines {
+			if line != hex.EncodeToString(want[i]) {
+				t.Errorf("piece %d = %s, want %x", i, line, want[i])
+			}
+		}
+	})
+
+	t.Run("base64", func(t *testing.T) {
+		var buf bytes.Buffer
+		if _, err := WritePieceHashes(&buf, &info, PiecesFormatBase64); err != nil {
+			t.Fatalf("WritePieceHashes failed: %v", err)
+		}
+		lines := strings.Fields(buf.String())
+		if len(lines) != len(want) {
+			t.Fatalf("got %d lines, want %d", len(lines), len(want))
+		}
+		for i, line := range lines {
+			if line != base64.StdEncoding.EncodeToString(want[i]) {
+				t.Errorf("piece %d = %s, want base64 of %x", i, line, want[i])
+			}
+		}
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		var buf bytes.Buffer
+		if _, err := WritePieceHashes(&buf, &info, PiecesFormatBinary); err != nil {
+			t.Fatalf("WritePieceHashes failed: %v", err)
+		}
+		if !bytes.Equal(buf.Bytes(), bytes.Join(want, nil)) {
+			t.Errorf("binary output does not match the piece hashes")
+		}
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		var buf bytes.Buffer
+		if _, err := WritePieceHashes(&buf, &info, "base32"); err == nil {
+			t.Fatal("expected an error for an invalid format")
+		}
+		if buf.Len() != 0 {
+			t.Errorf("expected no output for an invalid format, got %d bytes", buf.Len())
+		}
+	})
+}
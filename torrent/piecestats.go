@@ -0,0 +1,58 @@
+package torrent
+
+import (
+	"strings"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// PieceStats summarizes how the files of a torrent line up with its pieces.
+// It is derived from the metadata alone and helps judge the piece length.
+type PieceStats struct {
+	LongestRunFile        string // file holding the longest run
+	PieceLength           int64
+	AverageFileSize       int64
+	LongestRun            int64 // most consecutive pieces holding data of a single file only
+	Files                 int
+	FilesSmallerThanPiece int
+}
+
+// ComputePieceStats returns the piece statistics of info. BEP 47 padding files
+// are not counted as files.
+func ComputePieceStats(info *metainfo.Info) PieceStats {
+	stats := PieceStats{PieceLength: info.PieceLength}
+	if info.PieceLength <= 0 {
+		return stats
+	}
+
+	var offset, total int64
+	for _, file := range info.UpvertedFiles() {
+		start, end := offset, offset+file.Length
+		offset = end
+		if hasAttr(file.Attr, attrPadding) {
+			continue
+		}
+
+		stats.Files++
+		total += file.Length
+		if file.Length < info.PieceLength {
+			stats.FilesSmallerThanPiece++
+		}
+
+		// pieces that start and end inside this file
+		first := (start + info.PieceLength - 1) / info.PieceLength
+		last := end / info.PieceLength
+		if run := last - first; run > stats.LongestRun {
+			stats.LongestRun = run
+			stats.LongestRunFile = strings.Join(file.BestPath(), "/")
+			if stats.LongestRunFile == "" {
+				stats.LongestRunFile = info.Name
+			}
+		}
+	}
+
+	if stats.Files > 0 {
+		stats.AverageFileSize = total / int64(stats.Files)
+	}
+	return stats
+}
@@ -0,0 +1,92 @@
+package torrent
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+func TestComputePieceStats(t *testing.T) {
+	tests := []struct {
+		name string
+		info *metainfo.Info
+		want PieceStats
+	}{
+		{
+			name: "multi file with padding",
+			info: &metainfo.Info{
+				Name:        "release",
+				PieceLength: 16,
+				Files: []metainfo.FileInfo{
+					{Path: []string{"a.mkv"}, Length: 40},
+					{Path: []string{"b.nfo"}, Length: 5},
+					{Path: []string{".pad", "3"}, Length: 3, ExtendedFileAttrs: metainfo.ExtendedFileAttrs{Attr: "p"}},
+					{Path: []string{"sub", "c.mkv"}, Length: 64},
+				},
+			},
+			want: PieceStats{
+				LongestRunFile:        "sub/c.mkv",
+				PieceLength:           16,
+				AverageFileSize:       36,
+				LongestRun:            4,
+				Files:                 3,
+				FilesSmallerThanPiece: 1,
+			},
+		},
+		{
+			name: "single file",
+			info: &metainfo.Info{Name: "movie.mkv", PieceLength: 16, Length: 100},
+			want: PieceStats{
+				LongestRunFile:  "movie.mkv",
+				PieceLength:     16,
+				AverageFileSize: 100,
+				LongestRun:      6,
+				Files:           1,
+			},
+		},
+		{
+			name: "no piece within a single file",
+			info: &metainfo.Info{
+				Name:        "tiny",
+				PieceLength: 16,
+				Files: []metainfo.FileInfo{
+					{Path: []string{"a"}, Length: 10},
+					{Path: []string{"b"}, Length: 10},
+				},
+			},
+			want: PieceStats{PieceLength: 16, AverageFileSize: 10, Files: 2, FilesSmallerThanPiece: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ComputePieceStats(tt.info); got != tt.want {
+				t.Errorf("ComputePieceStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShowPieceStats(t *testing.T) {
+	var buf bytes.Buffer
+	display := NewDisplay(NewFormatter(true))
+	display.output = &buf
+
+	display.ShowPieceStats(&metainfo.Info{
+		Name:        "release",
+		PieceLength: 1 << 20,
+		Files: []metainfo.FileInfo{
+			{Path: []string{"video.mkv"}, Length: 8 << 20},
+			{Path: []string{"info.nfo"}, Length: 1 << 10},
+		},
+	})
+
+	output := stripAnsiCodes(buf.String())
+	for _, want := range []string{"Piece stats:", "(4.00x piece length)", "1 of 2", "8 pieces (video.mkv)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+}
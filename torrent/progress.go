@@ -2,8 +2,8 @@ package torrent
 
 // Displayer defines the interface for displaying progress during torrent creation
 type Displayer interface {
-	ShowProgress(total int)
-	UpdateProgress(completed int, hashrate float64)
+	ShowProgress(total int, totalSize int64)
+	UpdateProgress(completed int, hashrate float64, currentFile string, currentFileSize int64)
 	ShowFiles(files []fileEntry, numWorkers int)
 	ShowSeasonPackWarnings(info *SeasonPackInfo)
 	FinishProgress()
@@ -0,0 +1,90 @@
+package torrent
+
+import (
+	"container/list"
+	"io"
+	"os"
+)
+
+// maxOpenFilesPerWorker caps the number of file handles each hashing or
+// verification worker keeps open. Without a cap, torrents with tens of
+// thousands of small files can exhaust the process file descriptor limit.
+var maxOpenFilesPerWorker = 16
+
+// readerCache keeps a bounded set of open file readers for a single worker,
+// closing the least recently used file when the cap is exceeded.
+// It is not safe for concurrent use.
+type readerCache struct {
+	lru     *list.List            // front is most recently used, values are file indices
+	entries map[int]*list.Element // file index -> element in lru
+	readers map[int]*fileReader
+	opened  map[int]bool // file indices opened at least once, used to count reopens
+	limit   int
+	reopens int64
+
+	// open opens the file at a fileEntry path, os.Open when nil
+	open func(name string) (io.ReadSeekCloser, error)
+}
+
+func newReaderCache(limit int) *readerCache {
+	return &readerCache{
+		lru:     list.New(),
+		entries: make(map[int]*list.Element),
+		readers: make(map[int]*fileReader),
+		opened:  make(map[int]bool),
+		limit:   max(limit, 1),
+	}
+}
+
+// get returns an open reader for the file at index, opening it if needed.
+// A newly opened reader is positioned at the start of the file.
+func (c *readerCache) get(index int, file fileEntry) (*fileReader, error) {
+	if elem, ok := c.entries[index]; ok {
+		c.lru.MoveToFront(elem)
+		return c.readers[index], nil
+	}
+
+	var f io.ReadSeekCloser
+	var err error
+	if file.pad {
+		f = &zeroReader{length: file.length}
+	} else if c.open != nil {
+		f, err = c.open(file.path)
+	} else {
+		f, err = os.Open(longPath(file.path))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.opened[index] {
+		c.reopens++
+	}
+	c.opened[index] = true
+
+	for c.lru.Len() >= c.limit {
+		c.evict(c.lru.Back())
+	}
+
+	reader := &fileReader{file: f, position: 0, length: file.length}
+	c.entries[index] = c.lru.PushFront(index)
+	c.readers[index] = reader
+	return reader, nil
+}
+
+func (c *readerCache) evict(elem *list.Element) {
+	index := elem.Value.(int)
+	c.lru.Remove(elem)
+	delete(c.entries, index)
+	if reader := c.readers[index]; reader != nil {
+		_ = reader.file.Close()
+	}
+	delete(c.readers, index)
+}
+
+// closeAll closes every open reader.
+func (c *readerCache) closeAll() {
+	for c.lru.Len() > 0 {
+		c.evict(c.lru.Back())
+	}
+}
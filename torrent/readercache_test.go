@@ -0,0 +1,72 @@
+package torrent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReaderCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := make([]fileEntry, 3)
+	for i := range files {
+		path := filepath.Join(tmpDir, string(rune('a'+i)))
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+		files[i] = fileEntry{path: path, length: 4}
+	}
+
+	cache := newReaderCache(2)
+	defer cache.closeAll()
+
+	for _, idx := range []int{0, 1, 0, 2} {
+		if _, err := cache.get(idx, files[idx]); err != nil {
+			t.Fatalf("get(%d) returned error: %v", idx, err)
+		}
+	}
+
+	// file 1 was least recently used when file 2 was opened
+	if _, ok := cache.readers[1]; ok {
+		t.Error("expected file 1 to be evicted")
+	}
+	if len(cache.readers) != 2 {
+		t.Errorf("expected 2 open readers, got %d", len(cache.readers))
+	}
+	if cache.reopens != 0 {
+		t.Errorf("expected no reopens yet, got %d", cache.reopens)
+	}
+
+	if _, err := cache.get(1, files[1]); err != nil {
+		t.Fatalf("get(1) returned error: %v", err)
+	}
+	if cache.reopens != 1 {
+		t.Errorf("expected 1 reopen, got %d", cache.reopens)
+	}
+}
+
+func TestPieceHasher_ManySmallFilesWithLowOpenFileCap(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping many-file test in short mode")
+	}
+
+	orig := maxOpenFilesPerWorker
+	maxOpenFilesPerWorker = 4
+	defer func() { maxOpenFilesPerWorker = orig }()
+
+	const numFiles = 3000
+	sizes := make([]int64, numFiles)
+	for i := range sizes {
+		sizes[i] = int64(50 + i%97) // uneven sizes so pieces start mid-file
+	}
+
+	pieceLen := int64(1 << 14)
+	files, expectedHashes := createTestFilesWithPattern(t, t.TempDir(), sizes, pieceLen)
+
+	hasher := NewPieceHasher(files, pieceLen, len(expectedHashes), &mockDisplay{}, false)
+	if err := hasher.hashPieces(4); err != nil {
+		t.Fatalf("hashPieces failed: %v", err)
+	}
+
+	verifyHashes(t, hasher.pieces, expectedHashes)
+}
@@ -0,0 +1,193 @@
+package torrent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/anacrolix/torrent/bencode"
+	"github.com/anacrolix/torrent/metainfo"
+
+	"github.com/autobrr/mkbrr/internal/trackers"
+)
+
+// RepieceOptions holds options for re-hashing a torrent at a new piece length
+type RepieceOptions struct {
+	TorrentPath    string // existing torrent whose metadata is kept
+	ContentPath    string // content on disk the torrent describes
+	PieceLengthExp uint   // new piece length as an exponent (2^n)
+	OutputPath     string // defaults to <input>.repiece.torrent next to the input
+	Workers        int
+	SkipVerify     bool // hash without checking the content against the original first
+	Force          bool // write the torrent even when verification is below 100%
+	NoDate         bool
+	Verbose        bool
+	Quiet          bool
+	NoProgress     bool // print progress as lines instead of a progress bar
+}
+
+// RepieceResult holds the outcome of Repiece
+type RepieceResult struct {
+	Verification *VerificationResult // nil when SkipVerify is set
+	OutputPath   string
+	InfoHash     string
+	PieceLength  int64
+	NumPieces    int
+}
+
+// Repiece re-hashes the content of an existing torrent at a new piece length
+// and writes a copy with the new pieces. The content is verified against the
+// original first unless SkipVerify is set. The info dictionary is kept except
+// for its piece length and pieces, so the name, file list and order, source,
+// private flag and custom keys stay; trackers, web seeds, comment and creator
+// are kept as well. The info hash changes.
+func Repiece(opts RepieceOptions) (*RepieceResult, error) {
+	loaded, err := LoadFromFile(opts.TorrentPath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := loaded.UnmarshalInfo()
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal info: %w", err)
+	}
+	if info.HasV2() {
+		return nil, fmt.Errorf("repiecing v2 and hybrid torrents is not supported")
+	}
+
+	maxExp := uint(27) // absolute max 128 MiB
+	if trackerMaxExp, ok := trackers.GetTrackerMaxPieceLength(loaded.Announce); ok {
+		maxExp = trackerMaxExp
+	}
+	if opts.PieceLengthExp < 16 || opts.PieceLengthExp > maxExp {
+		return nil, fmt.Errorf("piece length exponent must be between 16 (64 KiB) and %d (%d MiB), got: %d",
+			maxExp, 1<<(maxExp-20), opts.PieceLengthExp)
+	}
+	pieceLen := int64(1) << opts.PieceLengthExp
+	if pieceLen == info.PieceLength {
+		return nil, fmt.Errorf("torrent already uses %s pieces", formatPieceSize(opts.PieceLengthExp))
+	}
+
+	outPath := opts.OutputPath
+	if outPath == "" {
+		outPath = strings.TrimSuffix(opts.TorrentPath, filepath.Ext(opts.TorrentPath)) + ".repiece.torrent"
+	}
+	if sameFile(outPath, opts.TorrentPath) {
+		return nil, fmt.Errorf("output %s would overwrite the original torrent", outPath)
+	}
+
+	result := &RepieceResult{PieceLength: pieceLen}
+	if !opts.SkipVerify {
+		verification, err := VerifyData(VerifyOptions{
+			TorrentPath: opts.TorrentPath,
+			ContentPath: opts.ContentPath,
+			Verbose:     opts.Verbose,
+			Quiet:       opts.Quiet,
+			Workers:     opts.Workers,
+			NoProgress:  opts.NoProgress,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("verification failed: %w", err)
+		}
+		result.Verification = verification
+		complete := verification.BadPieces == 0 && verification.MissingPieces == 0 && len(verification.MissingFiles) == 0
+		if !complete && !opts.Force {
+			return result, fmt.Errorf("%w: %.2f%% complete", ErrIncompleteContent, verification.Completion)
+		}
+	}
+
+	files, err := repieceFiles(&info, opts.ContentPath)
+	if err != nil {
+		return result, err
+	}
+	totalSize := info.TotalLength()
+	result.NumPieces = int((totalSize + pieceLen - 1) / pieceLen)
+
+	display := NewDisplay(NewFormatter(opts.Verbose))
+	display.SetQuiet(opts.Quiet)
+	display.SetPlainProgress(opts.NoProgress)
+	hasher := NewPieceHasher(files, pieceLen, result.NumPieces, display, false)
+	if err := hasher.hashPieces(opts.Workers); err != nil {
+		return result, err
+	}
+
+	// swap the pieces in the raw info dictionary, keeping every other key
+	var infoMap map[string]bencode.Bytes
+	if err := bencode.Unmarshal(loaded.InfoBytes, &infoMap); err != nil {
+		return result, fmt.Errorf("could not unmarshal info map: %w", err)
+	}
+	infoMap["piece length"] = bencode.MustMarshal(pieceLen)
+	infoMap["pieces"] = bencode.MustMarshal(hasher.pieceHashStorage)
+	if loaded.InfoBytes, err = bencode.Marshal(infoMap); err != nil {
+		return result, fmt.Errorf("could not marshal info map: %w", err)
+	}
+
+	if opts.NoDate {
+		loaded.CreationDate = 0
+	} else {
+		loaded.CreationDate = now().Unix()
+	}
+
+	if dir := filepath.Dir(outPath); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return result, fmt.Errorf("could not create output directory: %w", err)
+		}
+	}
+	f, err := os.Create(outPath)
+	if err != nil {
+		return result, fmt.Errorf("could not create output file: %w", err)
+	}
+	defer f.Close()
+	if err := loaded.Write(f); err != nil {
+		return result, fmt.Errorf("could not write output file: %w", err)
+	}
+
+	result.OutputPath = outPath
+	result.InfoHash = loaded.HashInfoBytes().String()
+	return result, nil
+}
+
+// repieceFiles returns the content files of info in torrent order, failing
+// when a file is missing or has the wrong size.
+func repieceFiles(info *metainfo.Info, contentPath string) ([]fileEntry, error) {
+	contentPath = filepath.Clean(contentPath)
+	if !info.IsDir() {
+		path := contentPath
+		if stat, err := os.Stat(longPath(path)); err == nil && stat.IsDir() {
+			path = filepath.Join(path, info.Name)
+		}
+		return repieceFileEntries([]string{path}, []int64{info.Length})
+	}
+
+	paths := make([]string, len(info.Files))
+	lengths := make([]int64, len(info.Files))
+	for i, f := range info.Files {
+		paths[i] = filepath.Join(contentPath, filepath.FromSlash(torrentFilePath(f.Path)))
+		lengths[i] = f.Length
+	}
+	return repieceFileEntries(paths, lengths)
+}
+
+func repieceFileEntries(paths []string, lengths []int64) ([]fileEntry, error) {
+	files := make([]fileEntry, len(paths))
+	var offset int64
+	for i, path := range paths {
+		stat, err := os.Stat(longPath(path))
+		if err != nil {
+			return nil, fmt.Errorf("could not stat content file: %w", err)
+		}
+		if stat.IsDir() || stat.Size() != lengths[i] {
+			return nil, fmt.Errorf("content file %q does not match the torrent: expected %d bytes", path, lengths[i])
+		}
+		files[i] = fileEntry{path: path, length: lengths[i], offset: offset}
+		offset += lengths[i]
+	}
+	return files, nil
+}
+
+// sameFile reports whether a and b name the same existing file.
+func sameFile(a, b string) bool {
+	statA, errA := os.Stat(a)
+	statB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(statA, statB)
+}
@@ -0,0 +1,159 @@
+package torrent
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeRepieceFixture creates a multi-file release and a torrent for it with
+// 64 KiB pieces, returning the content directory and torrent path.
+func writeRepieceFixture(t *testing.T) (string, string) {
+	t.Helper()
+
+	return writeReleaseFixture(t, t.TempDir(), map[string][]byte{
+		"release.mkv":        bytes.Repeat([]byte("m"), 5<<16+100),
+		"Extras/sample.mkv":  bytes.Repeat([]byte("s"), 1<<16),
+		"Extras/release.nfo": []byte("nfo"),
+	}, CreateOptions{
+		TrackerURLs: []string{"https://tracker-a.example/announce", "https://tracker-b.example/announce"},
+		WebSeeds:    []string{"https://seed.example/"},
+		Comment:     "original comment",
+		Source:      "A",
+		IsPrivate:   true,
+		Entropy:     true,
+	})
+}
+
+func TestRepiece(t *testing.T) {
+	contentDir, torrentPath := writeRepieceFixture(t)
+	original, err := LoadFromFile(torrentPath)
+	if err != nil {
+		t.Fatalf("failed to load original: %v", err)
+	}
+
+	result, err := Repiece(RepieceOptions{TorrentPath: torrentPath, ContentPath: contentDir, PieceLengthExp: 17, Quiet: true})
+	if err != nil {
+		t.Fatalf("Repiece failed: %v", err)
+	}
+	if want := strings.TrimSuffix(torrentPath, ".torrent") + ".repiece.torrent"; result.OutputPath != want {
+		t.Errorf("OutputPath = %q, want %q", result.OutputPath, want)
+	}
+	if result.Verification == nil || result.Verification.Completion != 100 {
+		t.Errorf("expected the original to verify at 100%%, got %+v", result.Verification)
+	}
+
+	repieced, err := LoadFromFile(result.OutputPath)
+	if err != nil {
+		t.Fatalf("failed to load repieced torrent: %v", err)
+	}
+	if result.InfoHash != repieced.HashInfoBytes().String() || repieced.HashInfoBytes() == original.HashInfoBytes() {
+		t.Errorf("InfoHash = %s, want the new info hash %s", result.InfoHash, repieced.HashInfoBytes())
+	}
+
+	oldInfo, newInfo := original.GetInfo(), repieced.GetInfo()
+	if newInfo.PieceLength != 1<<17 || result.NumPieces != len(newInfo.Pieces)/pieceHashSize {
+		t.Errorf("piece length %d with %d pieces, result reports %d pieces", newInfo.PieceLength, len(newInfo.Pieces)/pieceHashSize, result.NumPieces)
+	}
+	if newInfo.Name != oldInfo.Name || newInfo.Source != oldInfo.Source || !reflect.DeepEqual(newInfo.Files, oldInfo.Files) {
+		t.Errorf("name, source or files changed: %+v", newInfo)
+	}
+	if newInfo.Private == nil || !*newInfo.Private {
+		t.Error("private flag was lost")
+	}
+	_, oldRaw := rawDicts(t, torrentPath)
+	_, newRaw := rawDicts(t, result.OutputPath)
+	if string(newRaw["entropy"]) != string(oldRaw["entropy"]) {
+		t.Error("entropy field was not kept")
+	}
+	if !reflect.DeepEqual(repieced.AnnounceList, original.AnnounceList) || repieced.Comment != original.Comment ||
+		!reflect.DeepEqual(repieced.UrlList, original.UrlList) {
+		t.Error("trackers, comment or web seeds were not kept")
+	}
+
+	// the new pieces match a fresh torrent at the same piece length
+	pieceLenExp := uint(17)
+	freshPath := filepath.Join(t.TempDir(), "fresh.torrent")
+	if _, err := Create(CreateOptions{Path: contentDir, OutputPath: freshPath, PieceLengthExp: &pieceLenExp, Quiet: true}); err != nil {
+		t.Fatalf("failed to create fresh torrent: %v", err)
+	}
+	fresh, err := LoadFromFile(freshPath)
+	if err != nil {
+		t.Fatalf("failed to load fresh torrent: %v", err)
+	}
+	if !bytes.Equal(newInfo.Pieces, fresh.GetInfo().Pieces) {
+		t.Error("repieced hashes differ from a fresh torrent of the content")
+	}
+}
+
+func TestRepiece_Verification(t *testing.T) {
+	contentDir, torrentPath := writeRepieceFixture(t)
+
+	f, err := os.OpenFile(filepath.Join(contentDir, "release.mkv"), os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("failed to open content: %v", err)
+	}
+	if _, err := f.WriteAt([]byte("corrupt"), 1000); err != nil {
+		t.Fatalf("failed to corrupt content: %v", err)
+	}
+	f.Close()
+
+	outPath := filepath.Join(t.TempDir(), "out.torrent")
+	_, err = Repiece(RepieceOptions{TorrentPath: torrentPath, ContentPath: contentDir, PieceLengthExp: 17, OutputPath: outPath, Quiet: true})
+	if !errors.Is(err, ErrIncompleteContent) {
+		t.Fatalf("Repiece error = %v, want ErrIncompleteContent", err)
+	}
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Error("torrent was written for content that does not match")
+	}
+
+	result, err := Repiece(RepieceOptions{TorrentPath: torrentPath, ContentPath: contentDir, PieceLengthExp: 17, OutputPath: outPath, SkipVerify: true, Quiet: true})
+	if err != nil {
+		t.Fatalf("Repiece with SkipVerify failed: %v", err)
+	}
+	if result.Verification != nil {
+		t.Error("expected no verification with SkipVerify")
+	}
+	verification, err := VerifyData(VerifyOptions{TorrentPath: outPath, ContentPath: contentDir, Quiet: true})
+	if err != nil {
+		t.Fatalf("VerifyData failed: %v", err)
+	}
+	if verification.Completion != 100 {
+		t.Errorf("repieced torrent verifies at %.2f%%, want 100%%", verification.Completion)
+	}
+}
+
+func TestRepiece_Invalid(t *testing.T) {
+	contentDir, torrentPath := writeRepieceFixture(t)
+
+	tests := []struct {
+		name    string
+		opts    RepieceOptions
+		wantErr string
+	}{
+		{name: "same piece length", opts: RepieceOptions{PieceLengthExp: 16}, wantErr: "already uses"},
+		{name: "too small", opts: RepieceOptions{PieceLengthExp: 14}, wantErr: "between 16"},
+		{name: "overwrites original", opts: RepieceOptions{PieceLengthExp: 17, OutputPath: torrentPath}, wantErr: "overwrite the original"},
+		{name: "missing content", opts: RepieceOptions{PieceLengthExp: 17, SkipVerify: true, ContentPath: t.TempDir()}, wantErr: "could not stat"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.TorrentPath = torrentPath
+			if tt.opts.ContentPath == "" {
+				tt.opts.ContentPath = contentDir
+			}
+			if tt.opts.OutputPath == "" {
+				tt.opts.OutputPath = filepath.Join(t.TempDir(), "out.torrent")
+			}
+			tt.opts.Quiet = true
+			_, err := Repiece(tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Repiece error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
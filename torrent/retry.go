@@ -0,0 +1,97 @@
+package torrent
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"time"
+)
+
+// readRetryBaseDelay is the wait before the first retry; it doubles after
+// every failed attempt up to readRetryMaxDelay.
+var (
+	readRetryBaseDelay = 100 * time.Millisecond
+	readRetryMaxDelay  = 5 * time.Second
+)
+
+// batchRetryMaxDelay caps the backoff between retries of a batch job.
+var batchRetryMaxDelay = 5 * time.Minute
+
+// sleep waits between retries until the delay passes or ctx is done. It is
+// replaced in tests to check the backoff without waiting.
+var sleep = sleepContext
+
+// RetryableError is implemented by errors that know whether repeating the
+// failed operation may succeed.
+type RetryableError interface {
+	error
+	IsRetryable() bool
+}
+
+// IsRetryable reports whether err is transient, such as an I/O or network
+// error from a briefly unavailable mount. A RetryableError in the chain
+// decides for itself; other errors, like invalid options, are permanent.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var retryable RetryableError
+	if errors.As(err, &retryable) {
+		return retryable.IsRetryable()
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, fs.ErrPermission) {
+		return false
+	}
+
+	var pathErr *fs.PathError
+	var syscallErr *os.SyscallError
+	var netErr net.Error
+	return errors.As(err, &pathErr) || errors.As(err, &syscallErr) || errors.As(err, &netErr)
+}
+
+// retryBatchJob calls fn until it succeeds, fails with a permanent error or has
+// failed retries+1 times. The wait starts at delay and doubles after every
+// attempt, capped at batchRetryMaxDelay. It returns the number of attempts.
+// Once ctx is done the backoff stops and its error is returned.
+func retryBatchJob(ctx context.Context, retries int, delay time.Duration, fn func() error) (int, error) {
+	delay = min(delay, batchRetryMaxDelay)
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= retries || !IsRetryable(err) {
+			return attempt + 1, err
+		}
+
+		if err := sleep(ctx, delay); err != nil {
+			return attempt + 1, err
+		}
+		delay = min(delay*2, batchRetryMaxDelay)
+	}
+}
+
+// retryIO calls fn until it succeeds or it has failed retries+1 times, backing
+// off between attempts. It is meant for files briefly locked by another
+// process (e.g. sharing violations on Windows or network shares). Missing
+// files are not retried so they still fail fast. The wait ends early with the
+// error of ctx once it is done; ctx may be nil to never stop.
+func retryIO(ctx context.Context, retries int, fn func() error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	delay := readRetryBaseDelay
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= retries || errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+		delay = min(delay*2, readRetryMaxDelay)
+	}
+}
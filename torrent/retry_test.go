@@ -0,0 +1,171 @@
+package torrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRetryIO(t *testing.T) {
+	origDelay := readRetryBaseDelay
+	readRetryBaseDelay = 0
+	defer func() { readRetryBaseDelay = origDelay }()
+
+	errLocked := errors.New("file is locked")
+
+	tests := []struct {
+		name         string
+		retries      int
+		failures     int
+		err          error
+		wantErr      bool
+		wantAttempts int
+	}{
+		{name: "succeeds first time", retries: 3, failures: 0, err: errLocked, wantAttempts: 1},
+		{name: "succeeds after transient failures", retries: 3, failures: 2, err: errLocked, wantAttempts: 3},
+		{name: "gives up after retries are exhausted", retries: 2, failures: 5, err: errLocked, wantErr: true, wantAttempts: 3},
+		{name: "disabled by default", retries: 0, failures: 1, err: errLocked, wantErr: true, wantAttempts: 1},
+		{name: "missing files fail fast", retries: 3, failures: 1, err: fmt.Errorf("open: %w", os.ErrNotExist), wantErr: true, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := retryIO(nil, tt.retries, func() error {
+				attempts++
+				if attempts <= tt.failures {
+					return tt.err
+				}
+				return nil
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("retryIO() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("retryIO() made %d attempts, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryIO_CancelDuringBackoff(t *testing.T) {
+	origDelay := readRetryBaseDelay
+	readRetryBaseDelay = time.Hour
+	defer func() { readRetryBaseDelay = origDelay }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- retryIO(ctx, 3, func() error {
+			attempts++
+			return errors.New("file is locked")
+		})
+	}()
+
+	// give the first attempt time to fail and start the hour-long backoff
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("retryIO() error = %v, want context.Canceled", err)
+		}
+		if attempts != 1 {
+			t.Errorf("retryIO() made %d attempts, want 1", attempts)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("retryIO() did not return after the context was cancelled")
+	}
+}
+
+// retryableErr is a RetryableError with a fixed answer.
+type retryableErr struct {
+	retryable bool
+}
+
+func (e retryableErr) Error() string     { return fmt.Sprintf("retryable: %v", e.retryable) }
+func (e retryableErr) IsRetryable() bool { return e.retryable }
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "plain error", err: errors.New("invalid piece length"), want: false},
+		{name: "path error", err: fmt.Errorf("failed to read file: %w", &os.PathError{Op: "read", Path: "/mnt/a", Err: errors.New("stale handle")}), want: true},
+		{name: "missing file on a mount", err: fmt.Errorf("failed to open file: %w", &os.PathError{Op: "open", Path: "/mnt/a", Err: os.ErrNotExist}), want: true},
+		{name: "permission denied", err: &os.PathError{Op: "open", Path: "/mnt/a", Err: os.ErrPermission}, want: false},
+		{name: "canceled", err: fmt.Errorf("hashing stopped: %w", context.Canceled), want: false},
+		{name: "retryable error", err: fmt.Errorf("wrapped: %w", retryableErr{retryable: true}), want: true},
+		{name: "permanent retryable error", err: retryableErr{retryable: false}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryBatchJob_Backoff(t *testing.T) {
+	var delays []time.Duration
+	origSleep := sleep
+	sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	defer func() { sleep = origSleep }()
+
+	attempts, err := retryBatchJob(context.Background(), 6, 2*time.Minute, func() error { return retryableErr{retryable: true} })
+	if err == nil {
+		t.Fatal("expected the last error once retries are exhausted")
+	}
+	if attempts != 7 {
+		t.Errorf("attempts = %d, want 7", attempts)
+	}
+
+	want := []time.Duration{2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	if !slices.Equal(delays, want) {
+		t.Errorf("delays = %v, want %v", delays, want)
+	}
+}
+
+func TestRetryBatchJob_CancelDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	done := make(chan error, 1)
+	go func() {
+		_, err := retryBatchJob(ctx, 3, time.Hour, func() error {
+			attempts++
+			return retryableErr{retryable: true}
+		})
+		done <- err
+	}()
+
+	// give the first attempt time to fail and start the hour-long backoff
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("retryBatchJob() error = %v, want context.Canceled", err)
+		}
+		if attempts != 1 {
+			t.Errorf("retryBatchJob() made %d attempts, want 1", attempts)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("retryBatchJob() did not return after the context was cancelled")
+	}
+}
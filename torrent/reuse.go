@@ -0,0 +1,211 @@
+package torrent
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// reuseSource holds the layout and piece hashes of an existing torrent whose
+// hashes can be copied into a new torrent with the same piece length.
+type reuseSource struct {
+	path      string
+	files     map[string]reuseFile // torrent path ("" for single-file torrents) -> layout
+	pieces    []byte
+	pieceLen  int64
+	totalSize int64
+	createdAt time.Time // files modified after this are treated as changed
+}
+
+// reuseFile is the position of a file in the source torrent's byte stream.
+type reuseFile struct {
+	offset int64
+	length int64
+}
+
+// loadReuseSource reads the torrent at path. Its creation date, or the file's
+// modification time when the torrent has none, is the cutoff for unchanged files.
+func loadReuseSource(path string) (*reuseSource, error) {
+	mi, err := metainfo.LoadFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not load torrent to reuse %q: %w", path, err)
+	}
+
+	info, err := mi.UnmarshalInfo()
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal info dictionary from %q: %w", path, err)
+	}
+
+	createdAt := time.Unix(mi.CreationDate, 0)
+	if mi.CreationDate == 0 {
+		stat, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not stat torrent to reuse %q: %w", path, err)
+		}
+		createdAt = stat.ModTime()
+	}
+
+	return newReuseSource(path, &info, createdAt), nil
+}
+
+// newReuseSource returns the layout and piece hashes of info, with files
+// modified after createdAt treated as changed. path names the source in
+// messages.
+func newReuseSource(path string, info *metainfo.Info, createdAt time.Time) *reuseSource {
+	source := &reuseSource{
+		path:      path,
+		files:     make(map[string]reuseFile),
+		pieces:    info.Pieces,
+		pieceLen:  info.PieceLength,
+		createdAt: createdAt,
+	}
+	// single-file torrents have no path and are keyed by ""
+	for _, f := range info.UpvertedFiles() {
+		source.files[strings.Join(f.BestPath(), "/")] = reuseFile{offset: source.totalSize, length: f.Length}
+		source.totalSize += f.Length
+	}
+	return source
+}
+
+// planReuse copies the source hash into h for every piece whose data is known to
+// be unchanged, and marks those pieces so they are not hashed again. paths holds
+// the torrent path of each of h's files ("" for a single-file torrent).
+//
+// A piece is reused only if every non-empty file overlapping it has the same path
+// and size as in the source and was not modified after the source was created,
+// all of those files are shifted by the same amount in the source layout, and the
+// shifted range is exactly one piece of the source. It returns the number of
+// reused pieces.
+func (r *reuseSource) planReuse(h *pieceHasher, paths []string) int {
+	if r.pieceLen != h.pieceLen || h.numPieces == 0 {
+		return 0
+	}
+
+	// shift of each unchanged file from its new offset to its source offset
+	deltas := make([]int64, len(h.files))
+	unchanged := make([]bool, len(h.files))
+	for i, file := range h.files {
+		old, ok := r.files[paths[i]]
+		if !ok || old.length != file.length {
+			continue
+		}
+		stat, err := os.Stat(file.path)
+		if err != nil || stat.ModTime().After(r.createdAt) {
+			continue
+		}
+		deltas[i] = old.offset - file.offset
+		unchanged[i] = true
+	}
+
+	h.reused = make([]bool, h.numPieces)
+	reused := 0
+	for pieceIndex := 0; pieceIndex < h.numPieces; pieceIndex++ {
+		start := int64(pieceIndex) * h.pieceLen
+		end := start + h.pieceLengthFor(pieceIndex)
+
+		delta, ok := int64(0), true
+		seen := false
+		for fileIndex := h.startFileForPiece(pieceIndex); fileIndex < len(h.files) && h.files[fileIndex].offset < end; fileIndex++ {
+			if h.files[fileIndex].length == 0 {
+				continue
+			}
+			if !unchanged[fileIndex] || (seen && deltas[fileIndex] != delta) {
+				ok = false
+				break
+			}
+			delta, seen = deltas[fileIndex], true
+		}
+		if !ok || !seen {
+			continue
+		}
+
+		oldStart, oldEnd := start+delta, end+delta
+		if oldStart%r.pieceLen != 0 {
+			continue
+		}
+		// a short piece must also be the last piece of the source
+		if end-start != r.pieceLen && oldEnd != r.totalSize {
+			continue
+		}
+
+		oldIndex := oldStart / r.pieceLen
+		if (oldIndex+1)*sha1.Size > int64(len(r.pieces)) {
+			continue
+		}
+		copy(h.pieces[pieceIndex], r.pieces[oldIndex*sha1.Size:(oldIndex+1)*sha1.Size])
+		h.reused[pieceIndex] = true
+		reused++
+	}
+
+	return reused
+}
+
+// verifyReusedPieces rehashes up to n randomly chosen reused pieces and returns
+// the indices of those whose copied hash does not match the data on disk, which
+// happens when a file was modified without its mtime changing. Checked pieces
+// keep their freshly computed hash.
+func (h *pieceHasher) verifyReusedPieces(n int) ([]int, error) {
+	var candidates []int
+	for i, reused := range h.reused {
+		if reused {
+			candidates = append(candidates, i)
+		}
+	}
+	rand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+
+	var mismatched []int
+	var completed uint64
+	for _, pieceIndex := range candidates[:min(n, len(candidates))] {
+		copied := bytes.Clone(h.pieces[pieceIndex])
+		h.reused[pieceIndex] = false
+		if err := h.hashPieceRange(pieceIndex, pieceIndex+1, &completed); err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(copied, h.pieces[pieceIndex]) {
+			mismatched = append(mismatched, pieceIndex)
+		}
+	}
+
+	return mismatched, nil
+}
+
+// checkReusedPieces reports how many pieces were reused and runs the optional
+// spot-check. If any checked piece does not match, every reused piece is hashed
+// again since the file timestamps cannot be trusted.
+func checkReusedPieces(h *pieceHasher, source *reuseSource, reusedPieces int, opts CreateOptions) error {
+	display := NewDisplay(NewFormatter(opts.Verbose))
+	display.SetQuiet(opts.Quiet)
+
+	if source.pieceLen != h.pieceLen {
+		display.ShowWarning(fmt.Sprintf("piece length differs from %s, no piece hashes were reused", source.path))
+		return nil
+	}
+
+	display.ShowMessage(fmt.Sprintf("reused %d of %d piece hashes from %s", reusedPieces, h.numPieces, source.path))
+
+	if opts.VerifyReused <= 0 || reusedPieces == 0 {
+		return nil
+	}
+
+	mismatched, err := h.verifyReusedPieces(opts.VerifyReused)
+	if err != nil {
+		return err
+	}
+	if len(mismatched) == 0 {
+		return nil
+	}
+
+	display.ShowWarning(fmt.Sprintf("%d spot-checked reused pieces did not match the data on disk, rehashing all reused pieces", len(mismatched)))
+
+	// hash only the pieces that were reused and have not been checked yet
+	for i, reused := range h.reused {
+		h.reused[i] = !reused
+	}
+	return h.hashPieces(opts.Workers)
+}
@@ -0,0 +1,230 @@
+package torrent
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+const reuseTestPieceLen = int64(1 << 16)
+
+type reuseTestFile struct {
+	name string
+	size int64
+	seed byte
+}
+
+// writeReuseTestFiles writes files into dir with content derived from seed and
+// backdates their mtime so they count as unchanged relative to a torrent created now.
+func writeReuseTestFiles(t *testing.T, dir string, files []reuseTestFile) {
+	t.Helper()
+
+	past := time.Now().Add(-time.Hour)
+	for _, f := range files {
+		data := make([]byte, f.size)
+		for i := range data {
+			data[i] = f.seed + byte(i%251)
+		}
+		path := filepath.Join(dir, f.name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", f.name, err)
+		}
+		if err := os.Chtimes(path, past, past); err != nil {
+			t.Fatalf("failed to set mtime on %s: %v", f.name, err)
+		}
+	}
+}
+
+// newReuseTestHasher builds a hasher over the sorted files in dir, as CreateTorrent would.
+func newReuseTestHasher(t *testing.T, dir string) (*pieceHasher, []string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read dir: %v", err)
+	}
+
+	var files []fileEntry
+	var paths []string
+	var offset int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			t.Fatalf("failed to stat %s: %v", entry.Name(), err)
+		}
+		files = append(files, fileEntry{path: filepath.Join(dir, entry.Name()), length: info.Size(), offset: offset})
+		paths = append(paths, entry.Name())
+		offset += info.Size()
+	}
+
+	numPieces := int((offset + reuseTestPieceLen - 1) / reuseTestPieceLen)
+	return NewPieceHasher(files, reuseTestPieceLen, numPieces, &mockDisplay{}, false), paths
+}
+
+func createReuseTestTorrent(t *testing.T, dir, output, reuseFrom string, verifyReused int) string {
+	t.Helper()
+
+	exp := uint(16)
+	opts := CreateOptions{
+		Path:         dir,
+		OutputPath:   output,
+		ReuseFrom:    reuseFrom,
+		VerifyReused: verifyReused,
+		NoCreator:    true,
+		Quiet:        true,
+	}
+	if reuseFrom == "" {
+		opts.PieceLengthExp = &exp
+	}
+
+	info, err := Create(opts)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	return info.InfoHash
+}
+
+func TestPlanReuse(t *testing.T) {
+	p := reuseTestPieceLen
+	base := []reuseTestFile{
+		{name: "a.bin", size: 3*p + p/2, seed: 1},
+		{name: "b.bin", size: 2 * p, seed: 2},
+		{name: "c.bin", size: p + p/4, seed: 3},
+	}
+
+	tests := []struct {
+		name       string
+		change     func(t *testing.T, dir string)
+		wantReused []int
+	}{
+		{
+			name:       "unchanged",
+			change:     func(t *testing.T, dir string) {},
+			wantReused: []int{0, 1, 2, 3, 4, 5, 6},
+		},
+		{
+			// a [0,3.5) x [3.5,4.5) b [4.5,6.5) c [6.5,7.75): b and c shift by exactly one piece
+			name: "piece-aligned insertion in the middle",
+			change: func(t *testing.T, dir string) {
+				writeNewFile(t, filepath.Join(dir, "ab.bin"), p)
+			},
+			wantReused: []int{0, 1, 2, 5, 6, 7},
+		},
+		{
+			name: "unaligned insertion in the middle",
+			change: func(t *testing.T, dir string) {
+				writeNewFile(t, filepath.Join(dir, "ab.nfo"), 1000)
+			},
+			wantReused: []int{0, 1, 2},
+		},
+		{
+			// a [0,3.5) c [3.5,4.75): the last piece holds only the tail of c, shifted by two pieces
+			name: "removal in the middle",
+			change: func(t *testing.T, dir string) {
+				if err := os.Remove(filepath.Join(dir, "b.bin")); err != nil {
+					t.Fatalf("failed to remove file: %v", err)
+				}
+			},
+			wantReused: []int{0, 1, 2, 4},
+		},
+		{
+			name: "modified file",
+			change: func(t *testing.T, dir string) {
+				writeNewFile(t, filepath.Join(dir, "b.bin"), 2*p)
+			},
+			wantReused: []int{0, 1, 2, 6},
+		},
+		{
+			name: "appended file",
+			change: func(t *testing.T, dir string) {
+				writeNewFile(t, filepath.Join(dir, "d.nfo"), 100)
+			},
+			wantReused: []int{0, 1, 2, 3, 4, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspace := t.TempDir()
+			dir := filepath.Join(workspace, "content")
+			if err := os.Mkdir(dir, 0755); err != nil {
+				t.Fatalf("failed to create content dir: %v", err)
+			}
+			writeReuseTestFiles(t, dir, base)
+
+			oldTorrent := filepath.Join(workspace, "old.torrent")
+			createReuseTestTorrent(t, dir, oldTorrent, "", 0)
+
+			tt.change(t, dir)
+
+			source, err := loadReuseSource(oldTorrent)
+			if err != nil {
+				t.Fatalf("loadReuseSource failed: %v", err)
+			}
+			hasher, paths := newReuseTestHasher(t, dir)
+			count := source.planReuse(hasher, paths)
+
+			var got []int
+			for i, reused := range hasher.reused {
+				if reused {
+					got = append(got, i)
+				}
+			}
+			if !slices.Equal(got, tt.wantReused) {
+				t.Errorf("reused pieces = %v, want %v", got, tt.wantReused)
+			}
+			if count != len(tt.wantReused) {
+				t.Errorf("planReuse returned %d, want %d", count, len(tt.wantReused))
+			}
+
+			// the incremental torrent must be identical to one hashed from scratch
+			fresh := createReuseTestTorrent(t, dir, filepath.Join(workspace, "fresh.torrent"), "", 0)
+			incremental := createReuseTestTorrent(t, dir, filepath.Join(workspace, "new.torrent"), oldTorrent, 0)
+			if fresh != incremental {
+				t.Errorf("incremental info hash %s differs from fresh %s", incremental, fresh)
+			}
+		})
+	}
+}
+
+func TestCreate_VerifyReusedCatchesStaleMtime(t *testing.T) {
+	p := reuseTestPieceLen
+	workspace := t.TempDir()
+	dir := filepath.Join(workspace, "content")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("failed to create content dir: %v", err)
+	}
+	writeReuseTestFiles(t, dir, []reuseTestFile{
+		{name: "a.bin", size: 2 * p, seed: 1},
+		{name: "b.bin", size: 2 * p, seed: 2},
+	})
+
+	oldTorrent := filepath.Join(workspace, "old.torrent")
+	createReuseTestTorrent(t, dir, oldTorrent, "", 0)
+
+	// change the content of b.bin but keep its size and old mtime
+	writeReuseTestFiles(t, dir, []reuseTestFile{{name: "b.bin", size: 2 * p, seed: 9}})
+	fresh := createReuseTestTorrent(t, dir, filepath.Join(workspace, "fresh.torrent"), "", 0)
+
+	// without a spot-check the stale hashes are trusted
+	unchecked := createReuseTestTorrent(t, dir, filepath.Join(workspace, "unchecked.torrent"), oldTorrent, 0)
+	if unchecked == fresh {
+		t.Fatal("expected stale reused hashes without --verify-reused")
+	}
+
+	// checking every reused piece detects the mismatch and rehashes
+	checked := createReuseTestTorrent(t, dir, filepath.Join(workspace, "checked.torrent"), oldTorrent, 4)
+	if checked != fresh {
+		t.Errorf("verified info hash %s differs from fresh %s", checked, fresh)
+	}
+}
+
+func writeNewFile(t *testing.T, path string, size int64) {
+	t.Helper()
+	if err := os.WriteFile(path, bytes.Repeat([]byte{0xAB}, int(size)), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
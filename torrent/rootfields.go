@@ -0,0 +1,59 @@
+package torrent
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// reservedRootKeys are the root keys mkbrr or the torrent format define,
+// which CreateOptions.ExtraRootFields must not replace.
+var reservedRootKeys = []string{
+	"announce",
+	"announce-list",
+	"comment",
+	"created by",
+	"creation date",
+	"encoding",
+	"httpseeds",
+	"info",
+	"nodes",
+	"piece layers",
+	"url-list",
+}
+
+// ParseRootFields parses key=value pairs, as given to create --meta, into
+// root fields for CreateOptions.ExtraRootFields. A later pair replaces an
+// earlier one with the same key.
+func ParseRootFields(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	fields := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid metadata field %q: expected key=value", pair)
+		}
+		fields[strings.TrimSpace(key)] = value
+	}
+	if err := validateRootFields(fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// validateRootFields rejects empty keys and keys of standard root fields,
+// compared ignoring case.
+func validateRootFields(fields map[string]string) error {
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		if key == "" {
+			return fmt.Errorf("metadata field key must not be empty")
+		}
+		if slices.Contains(reservedRootKeys, strings.ToLower(key)) {
+			return fmt.Errorf("metadata field %q is a standard torrent field and cannot be set", key)
+		}
+	}
+	return nil
+}
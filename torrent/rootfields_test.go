@@ -0,0 +1,92 @@
+package torrent
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/anacrolix/torrent/bencode"
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+func TestParseRootFields(t *testing.T) {
+	fields, err := ParseRootFields([]string{"publisher=Group", "publisher-url=https://example.com/?a=b", "publisher=Other"})
+	if err != nil {
+		t.Fatalf("ParseRootFields failed: %v", err)
+	}
+	want := map[string]string{"publisher": "Other", "publisher-url": "https://example.com/?a=b"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("fields = %v, want %v", fields, want)
+	}
+
+	for _, pair := range []string{"publisher", "=value", "announce=https://example.com", "Created By=me", "info=x"} {
+		if _, err := ParseRootFields([]string{pair}); err == nil {
+			t.Errorf("ParseRootFields(%q): expected an error", pair)
+		}
+	}
+}
+
+func TestCreate_ExtraRootFields(t *testing.T) {
+	dir := t.TempDir()
+	contentPath := filepath.Join(dir, "content.bin")
+	if err := os.WriteFile(contentPath, []byte("content"), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+
+	create := func(name string, fields map[string]string) string {
+		t.Helper()
+		out := filepath.Join(dir, name)
+		if _, err := Create(CreateOptions{
+			Path:            contentPath,
+			OutputPath:      out,
+			TrackerURLs:     []string{"https://tracker.example/announce"},
+			IsPrivate:       true,
+			NoDate:          true,
+			Creator:         "Group toolkit",
+			ExtraRootFields: fields,
+			Quiet:           true,
+		}); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		return out
+	}
+	plain := create("plain.torrent", nil)
+	withFields := create("fields.torrent", map[string]string{"publisher": "Group", "publisher-url": "https://group.example"})
+
+	data, err := os.ReadFile(withFields)
+	if err != nil {
+		t.Fatalf("failed to read torrent: %v", err)
+	}
+	var root map[string]any
+	if err := bencode.Unmarshal(data, &root); err != nil {
+		t.Fatalf("failed to decode torrent: %v", err)
+	}
+	if root["publisher"] != "Group" || root["publisher-url"] != "https://group.example" {
+		t.Errorf("root fields = %v, %v", root["publisher"], root["publisher-url"])
+	}
+	if root["created by"] != "Group toolkit" {
+		t.Errorf("created by = %v, want %q", root["created by"], "Group toolkit")
+	}
+
+	plainMI, err := metainfo.LoadFromFile(plain)
+	if err != nil {
+		t.Fatalf("failed to load torrent: %v", err)
+	}
+	fieldsMI, err := metainfo.LoadFromFile(withFields)
+	if err != nil {
+		t.Fatalf("failed to load torrent: %v", err)
+	}
+	if plainMI.HashInfoBytes() != fieldsMI.HashInfoBytes() {
+		t.Error("extra root fields changed the info hash")
+	}
+
+	if _, err := Create(CreateOptions{
+		Path:            contentPath,
+		OutputPath:      filepath.Join(dir, "bad.torrent"),
+		ExtraRootFields: map[string]string{"comment": "x"},
+		Quiet:           true,
+	}); err == nil {
+		t.Error("expected an error for a standard root key")
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"strings"
 )
 
+// SeasonPackInfo is the result of AnalyzeSeasonPack. IsSuspicious is set for
+// a season pack with gaps in its episode numbers, listed in MissingEpisodes.
 type SeasonPackInfo struct {
 	Episodes        []int
 	MissingEpisodes []int
@@ -1,6 +1,7 @@
 package torrent
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -132,3 +133,71 @@ func TestAnalyzeSeasonPack_SingleEpisode(t *testing.T) {
 		})
 	}
 }
+
+// createSeasonFixture writes a small file for each name into a new
+// directory called dir and returns its path.
+func createSeasonFixture(t *testing.T, dir string, names ...string) string {
+	t.Helper()
+
+	contentDir := filepath.Join(t.TempDir(), dir)
+	if err := os.MkdirAll(contentDir, 0755); err != nil {
+		t.Fatalf("failed to create content dir: %v", err)
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(contentDir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return contentDir
+}
+
+func TestCreateTorrent_SeasonPack(t *testing.T) {
+	t.Run("complete", func(t *testing.T) {
+		dir := createSeasonFixture(t, "Show.S01.1080p", "Show.S01E01.mkv", "Show.S01E02.mkv", "Show.S01E03.mkv")
+		tor, err := CreateTorrent(CreateOptions{Path: dir, Quiet: true})
+		if err != nil {
+			t.Fatalf("CreateTorrent failed: %v", err)
+		}
+		if assert.NotNil(t, tor.SeasonPack) {
+			assert.True(t, tor.SeasonPack.IsSeasonPack)
+			assert.False(t, tor.SeasonPack.IsSuspicious)
+			assert.Equal(t, 1, tor.SeasonPack.Season)
+			assert.Equal(t, []int{1, 2, 3}, tor.SeasonPack.Episodes)
+			assert.Empty(t, tor.SeasonPack.MissingEpisodes)
+		}
+	})
+
+	t.Run("incomplete", func(t *testing.T) {
+		dir := createSeasonFixture(t, "Show.S02.1080p", "Show.S02E01.mkv", "Show.S02E02.mkv", "Show.S02E05.mkv")
+		info, err := Create(CreateOptions{Path: dir, OutputPath: filepath.Join(t.TempDir(), "show.torrent"), Quiet: true})
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		if assert.NotNil(t, info.SeasonPack) {
+			assert.True(t, info.SeasonPack.IsSuspicious)
+			assert.Equal(t, 2, info.SeasonPack.Season)
+			assert.Equal(t, []int{3, 4}, info.SeasonPack.MissingEpisodes)
+		}
+
+		_, err = CreateTorrent(CreateOptions{Path: dir, Quiet: true, FailOnSeasonPackWarning: true, SkipSeasonPackCheck: true})
+		assert.Error(t, err, "FailOnSeasonPackWarning should still run the check")
+	})
+
+	t.Run("not a season", func(t *testing.T) {
+		dir := createSeasonFixture(t, "Regular.Movie.2024.1080p", "Regular.Movie.2024.1080p.mkv", "Regular.Movie.2024.1080p.nfo")
+		tor, err := CreateTorrent(CreateOptions{Path: dir, Quiet: true})
+		if err != nil {
+			t.Fatalf("CreateTorrent failed: %v", err)
+		}
+		assert.True(t, tor.SeasonPack == nil || !tor.SeasonPack.IsSeasonPack, "should not be a season pack")
+	})
+
+	t.Run("skipped", func(t *testing.T) {
+		dir := createSeasonFixture(t, "Show.S01.1080p", "Show.S01E01.mkv", "Show.S01E02.mkv")
+		tor, err := CreateTorrent(CreateOptions{Path: dir, Quiet: true, SkipSeasonPackCheck: true})
+		if err != nil {
+			t.Fatalf("CreateTorrent failed: %v", err)
+		}
+		assert.Nil(t, tor.SeasonPack)
+	})
+}
@@ -0,0 +1,10 @@
+//go:build !purego
+
+package torrent
+
+// SHA1Implementation describes the SHA-1 code piece hashes use, e.g. for the
+// verbose output of create. crypto/sha1 picks the SHA extensions or AVX2 on
+// x86-64 and the SHA-1 instructions on ARM64 itself when the CPU has them.
+func SHA1Implementation() string {
+	return "crypto/sha1 (hardware-accelerated where available)"
+}
@@ -0,0 +1,9 @@
+//go:build purego
+
+package torrent
+
+// SHA1Implementation describes the SHA-1 code piece hashes use. Built with
+// -tags purego, crypto/sha1 leaves its assembly out.
+func SHA1Implementation() string {
+	return "crypto/sha1 (pure Go, built with -tags purego)"
+}
@@ -0,0 +1,118 @@
+package torrent
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/anacrolix/torrent/bencode"
+)
+
+// StripFields are the field names accepted by ModifyOptions.Strip
+var StripFields = []string{"webseeds", "comment", "creator", "date", "announce-list", "source", "private"}
+
+// requiredInfoKeys cannot be stripped, as the torrent is unusable without them
+var requiredInfoKeys = []string{"name", "piece length", "pieces", "length", "files"}
+
+// ValidateStrip checks the fields and keys to strip of opts, which ModifyTorrent
+// also does before changing anything.
+func ValidateStrip(opts ModifyOptions) error {
+	for _, field := range opts.Strip {
+		if !slices.Contains(StripFields, field) {
+			return fmt.Errorf("unknown field to strip %q (valid: %s)", field, strings.Join(StripFields, ", "))
+		}
+	}
+	for _, key := range opts.StripKeys {
+		if key == "info" {
+			return fmt.Errorf("cannot strip the info dictionary")
+		}
+	}
+	for _, key := range opts.StripInfoKeys {
+		if slices.Contains(requiredInfoKeys, key) {
+			return fmt.Errorf("cannot strip required info key %q", key)
+		}
+	}
+	return nil
+}
+
+// stripRootFields clears the fields of opts.Strip stored outside the info
+// dictionary and returns the names of those that were set.
+func stripRootFields(t *Torrent, fields []string) []string {
+	mi := t.MetaInfo
+	var stripped []string
+	for _, field := range fields {
+		var had bool
+		switch field {
+		case "webseeds":
+			had = len(mi.UrlList) > 0 || len(t.HTTPSeeds) > 0
+			mi.UrlList, t.HTTPSeeds = nil, nil
+		case "comment":
+			had = mi.Comment != ""
+			mi.Comment = ""
+		case "creator":
+			had = mi.CreatedBy != ""
+			mi.CreatedBy = ""
+		case "date":
+			had = mi.CreationDate != 0
+			mi.CreationDate = 0
+		case "announce-list":
+			// the primary announce URL is kept
+			had = len(mi.AnnounceList) > 0
+			mi.AnnounceList = nil
+		}
+		if had && !slices.Contains(stripped, field) {
+			stripped = append(stripped, field)
+		}
+	}
+	return stripped
+}
+
+// stripInfoKeys returns the info keys to remove for the source and private
+// fields of opts.Strip and for opts.StripInfoKeys, limited to keys present in info.
+func stripInfoKeys(info map[string]any, opts ModifyOptions) []string {
+	var keys []string
+	for _, field := range opts.Strip {
+		if field == "source" || field == "private" {
+			keys = append(keys, field)
+		}
+	}
+	keys = append(keys, opts.StripInfoKeys...)
+
+	var present []string
+	for _, key := range keys {
+		if _, ok := info[key]; ok && !slices.Contains(present, key) {
+			present = append(present, key)
+		}
+	}
+	return present
+}
+
+// stripRootKeys removes keys from the root dictionary of the bencoded torrent
+// in data, leaving the info dictionary byte for byte. It returns the new data
+// and the keys that were present.
+func stripRootKeys(data []byte, keys []string) ([]byte, []string, error) {
+	if len(keys) == 0 {
+		return data, nil, nil
+	}
+
+	var root map[string]bencode.Bytes
+	if err := bencode.Unmarshal(data, &root); err != nil {
+		return nil, nil, fmt.Errorf("could not decode torrent: %w", err)
+	}
+	var stripped []string
+	for _, key := range keys {
+		if _, ok := root[key]; ok {
+			delete(root, key)
+			stripped = append(stripped, key)
+		}
+	}
+	if len(stripped) == 0 {
+		return data, nil, nil
+	}
+
+	data, err := bencode.Marshal(root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not encode torrent: %w", err)
+	}
+	return data, stripped, nil
+}
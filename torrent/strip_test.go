@@ -0,0 +1,199 @@
+package torrent
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/anacrolix/torrent/bencode"
+)
+
+// writeStripTestTorrent creates a private torrent with every strippable field
+// set, plus an encoding root key and a custom info key.
+func writeStripTestTorrent(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	contentPath := filepath.Join(dir, "content.bin")
+	if err := os.WriteFile(contentPath, []byte("content to strip"), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+	torrentPath := filepath.Join(dir, "full.torrent")
+	if _, err := Create(CreateOptions{
+		Path:        contentPath,
+		OutputPath:  torrentPath,
+		TrackerURLs: []string{"https://a.example/announce", "https://b.example/announce"},
+		WebSeeds:    []string{"https://seed.example/"},
+		HTTPSeeds:   []string{"https://httpseed.example/seed.php"},
+		Comment:     "comment",
+		Source:      "SRC",
+		IsPrivate:   true,
+		Quiet:       true,
+	}); err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+
+	loaded, err := LoadFromFile(torrentPath)
+	if err != nil {
+		t.Fatalf("failed to load torrent: %v", err)
+	}
+	infoMap := make(map[string]any)
+	if err := bencode.Unmarshal(loaded.InfoBytes, &infoMap); err != nil {
+		t.Fatalf("failed to decode info: %v", err)
+	}
+	infoMap["x-cross-seed"] = "abc"
+	if loaded.InfoBytes, err = bencode.Marshal(infoMap); err != nil {
+		t.Fatalf("failed to encode info: %v", err)
+	}
+	loaded.Encoding = "UTF-8"
+
+	f, err := os.Create(torrentPath)
+	if err != nil {
+		t.Fatalf("failed to create torrent file: %v", err)
+	}
+	defer f.Close()
+	if err := loaded.Write(f); err != nil {
+		t.Fatalf("failed to write torrent: %v", err)
+	}
+	return torrentPath
+}
+
+// rawDicts returns the root and info dictionaries of the torrent at path.
+func rawDicts(t *testing.T, path string) (map[string]bencode.Bytes, map[string]bencode.Bytes) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read torrent: %v", err)
+	}
+	var root, info map[string]bencode.Bytes
+	if err := bencode.Unmarshal(data, &root); err != nil {
+		t.Fatalf("failed to decode torrent: %v", err)
+	}
+	if err := bencode.Unmarshal(root["info"], &info); err != nil {
+		t.Fatalf("failed to decode info: %v", err)
+	}
+	return root, info
+}
+
+func TestModifyTorrent_Strip(t *testing.T) {
+	torrentPath := writeStripTestTorrent(t)
+	root, info := rawDicts(t, torrentPath)
+	for _, key := range []string{"url-list", "httpseeds", "comment", "created by", "creation date", "announce-list", "encoding"} {
+		if _, ok := root[key]; !ok {
+			t.Fatalf("test torrent is missing root key %q", key)
+		}
+	}
+	for _, key := range []string{"source", "private", "x-cross-seed"} {
+		if _, ok := info[key]; !ok {
+			t.Fatalf("test torrent is missing info key %q", key)
+		}
+	}
+
+	result, err := ModifyTorrent(torrentPath, ModifyOptions{
+		Name:          "renamed.bin",
+		Comment:       "set and stripped",
+		Strip:         StripFields,
+		StripKeys:     []string{"encoding", "missing"},
+		StripInfoKeys: []string{"x-cross-seed"},
+		OutputDir:     t.TempDir(),
+		Quiet:         true,
+	})
+	if err != nil {
+		t.Fatalf("ModifyTorrent failed: %v", err)
+	}
+
+	root, info = rawDicts(t, result.OutputPath)
+	for _, key := range []string{"url-list", "httpseeds", "comment", "created by", "creation date", "announce-list", "encoding"} {
+		if _, ok := root[key]; ok {
+			t.Errorf("root key %q was not stripped", key)
+		}
+	}
+	for _, key := range []string{"source", "private", "x-cross-seed"} {
+		if _, ok := info[key]; ok {
+			t.Errorf("info key %q was not stripped", key)
+		}
+	}
+	if string(root["announce"]) != "26:https://a.example/announce" {
+		t.Errorf("announce = %s, want the primary tracker to be kept", root["announce"])
+	}
+	if string(info["name"]) != "11:renamed.bin" {
+		t.Errorf("name = %s, want the rename to be applied", info["name"])
+	}
+
+	want := []string{"webseeds", "comment", "creator", "date", "announce-list", "source", "private", "x-cross-seed", "encoding"}
+	if !slices.Equal(result.Stripped, want) {
+		t.Errorf("Stripped = %q, want %q", result.Stripped, want)
+	}
+
+	modified, err := LoadFromFile(result.OutputPath)
+	if err != nil {
+		t.Fatalf("failed to load modified torrent: %v", err)
+	}
+	if result.NewInfoHash != modified.HashInfoBytes().String() {
+		t.Errorf("NewInfoHash = %q, want %q", result.NewInfoHash, modified.HashInfoBytes())
+	}
+}
+
+func TestModifyTorrent_StripKeepsInfoHash(t *testing.T) {
+	torrentPath := writeStripTestTorrent(t)
+	original, err := LoadFromFile(torrentPath)
+	if err != nil {
+		t.Fatalf("failed to load torrent: %v", err)
+	}
+
+	result, err := ModifyTorrent(torrentPath, ModifyOptions{
+		Strip:     []string{"comment", "webseeds"},
+		OutputDir: t.TempDir(),
+		Quiet:     true,
+	})
+	if err != nil {
+		t.Fatalf("ModifyTorrent failed: %v", err)
+	}
+	if result.NewInfoHash != "" {
+		t.Errorf("NewInfoHash = %q, want the info hash to be kept", result.NewInfoHash)
+	}
+
+	root, info := rawDicts(t, result.OutputPath)
+	for _, key := range []string{"comment", "url-list", "httpseeds"} {
+		if _, ok := root[key]; ok {
+			t.Errorf("root key %q was not stripped", key)
+		}
+	}
+	if _, ok := info["x-cross-seed"]; !ok {
+		t.Error("custom info key was lost")
+	}
+	modified, err := LoadFromFile(result.OutputPath)
+	if err != nil {
+		t.Fatalf("failed to load modified torrent: %v", err)
+	}
+	if modified.HashInfoBytes() != original.HashInfoBytes() {
+		t.Errorf("info hash changed from %s to %s", original.HashInfoBytes(), modified.HashInfoBytes())
+	}
+}
+
+func TestModifyTorrent_StripValidation(t *testing.T) {
+	torrentPath := writeStripTestTorrent(t)
+
+	tests := []struct {
+		name    string
+		opts    ModifyOptions
+		wantErr string
+	}{
+		{name: "unknown field", opts: ModifyOptions{Strip: []string{"nodes"}}, wantErr: "unknown field to strip"},
+		{name: "info dictionary", opts: ModifyOptions{StripKeys: []string{"info"}}, wantErr: "cannot strip the info dictionary"},
+		{name: "required info key", opts: ModifyOptions{StripInfoKeys: []string{"pieces"}}, wantErr: "cannot strip required info key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.DryRun = true
+			tt.opts.Quiet = true
+			_, err := ModifyTorrent(torrentPath, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package torrent
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+	"unicode/utf16"
+)
+
+// ioReparseTagMountPoint is the reparse tag of a directory junction
+const ioReparseTagMountPoint = 0xA0000003
+
+// parseJunctionTarget reads the target of a directory junction from the
+// REPARSE_DATA_BUFFER returned by FSCTL_GET_REPARSE_POINT. It reports false
+// for other kinds of reparse points, such as symlinks and cloud files.
+func parseJunctionTarget(buf []byte) (bool, string, error) {
+	// ReparseTag, ReparseDataLength, Reserved and the four name offsets and lengths
+	const headerLen = 16
+	if len(buf) < 8 {
+		return false, "", errors.New("reparse data buffer too short")
+	}
+	if binary.LittleEndian.Uint32(buf) != ioReparseTagMountPoint {
+		return false, "", nil
+	}
+	if len(buf) < headerLen {
+		return false, "", errors.New("mount point reparse buffer too short")
+	}
+
+	name := func(offset, length uint16) (string, error) {
+		start, end := headerLen+int(offset), headerLen+int(offset)+int(length)
+		if end > len(buf) || length%2 != 0 {
+			return "", errors.New("mount point name outside of the reparse buffer")
+		}
+		units := make([]uint16, length/2)
+		for i := range units {
+			units[i] = binary.LittleEndian.Uint16(buf[start+2*i:])
+		}
+		return string(utf16.Decode(units)), nil
+	}
+
+	// the print name is the path as the user gave it; the substitute name is
+	// the NT path, which is usable once its \??\ prefix is removed
+	target, err := name(binary.LittleEndian.Uint16(buf[12:]), binary.LittleEndian.Uint16(buf[14:]))
+	if err != nil {
+		return false, "", err
+	}
+	if target == "" {
+		target, err = name(binary.LittleEndian.Uint16(buf[8:]), binary.LittleEndian.Uint16(buf[10:]))
+		if err != nil {
+			return false, "", err
+		}
+		target = strings.TrimPrefix(target, `\??\`)
+	}
+	return true, target, nil
+}
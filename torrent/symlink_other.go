@@ -0,0 +1,8 @@
+//go:build !windows
+
+package torrent
+
+// isJunction reports false, as directory junctions only exist on Windows.
+func isJunction(path string) (bool, string, error) {
+	return false, "", nil
+}
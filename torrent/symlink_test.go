@@ -0,0 +1,75 @@
+package torrent
+
+import (
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+)
+
+// mountPointBuffer builds the REPARSE_DATA_BUFFER of a junction with the
+// given substitute and print names.
+func mountPointBuffer(tag uint32, substitute, print string) []byte {
+	sub := utf16.Encode([]rune(substitute))
+	prn := utf16.Encode([]rune(print))
+
+	buf := make([]byte, 16+2*(len(sub)+len(prn)))
+	binary.LittleEndian.PutUint32(buf[0:], tag)
+	binary.LittleEndian.PutUint16(buf[4:], uint16(len(buf)-8))
+	binary.LittleEndian.PutUint16(buf[8:], 0)
+	binary.LittleEndian.PutUint16(buf[10:], uint16(2*len(sub)))
+	binary.LittleEndian.PutUint16(buf[12:], uint16(2*len(sub)))
+	binary.LittleEndian.PutUint16(buf[14:], uint16(2*len(prn)))
+	for i, u := range append(sub, prn...) {
+		binary.LittleEndian.PutUint16(buf[16+2*i:], u)
+	}
+	return buf
+}
+
+func TestParseJunctionTarget(t *testing.T) {
+	tests := []struct {
+		name         string
+		buf          []byte
+		wantJunction bool
+		wantTarget   string
+		wantErr      bool
+	}{
+		{
+			name:         "print name",
+			buf:          mountPointBuffer(ioReparseTagMountPoint, `\??\C:\Media\Shows`, `C:\Media\Shows`),
+			wantJunction: true,
+			wantTarget:   `C:\Media\Shows`,
+		},
+		{
+			name:         "substitute name only",
+			buf:          mountPointBuffer(ioReparseTagMountPoint, `\??\D:\Downloads`, ""),
+			wantJunction: true,
+			wantTarget:   `D:\Downloads`,
+		},
+		{
+			name: "symlink",
+			buf:  mountPointBuffer(0xA000000C, `\??\C:\target`, `C:\target`),
+		},
+		{
+			name:    "truncated",
+			buf:     mountPointBuffer(ioReparseTagMountPoint, `\??\C:\target`, `C:\target`)[:20],
+			wantErr: true,
+		},
+		{
+			name:    "too short",
+			buf:     []byte{3, 0, 0},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			junction, target, err := parseJunctionTarget(tt.buf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseJunctionTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if junction != tt.wantJunction || target != tt.wantTarget {
+				t.Errorf("parseJunctionTarget() = %v, %q, want %v, %q", junction, target, tt.wantJunction, tt.wantTarget)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+//go:build windows
+
+package torrent
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// isJunction reports whether path is a directory junction and returns its
+// target. os.Lstat does not report junctions as symlinks, so without this
+// check they end up in the torrent as empty files.
+func isJunction(path string) (bool, string, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return false, "", err
+	}
+
+	// skip the reparse point query for plain files and directories
+	attrs, err := windows.GetFileAttributes(pathPtr)
+	if err != nil {
+		return false, "", err
+	}
+	if attrs&windows.FILE_ATTRIBUTE_REPARSE_POINT == 0 {
+		return false, "", nil
+	}
+
+	handle, err := windows.CreateFile(pathPtr, 0, windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE,
+		nil, windows.OPEN_EXISTING, windows.FILE_FLAG_OPEN_REPARSE_POINT|windows.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		return false, "", err
+	}
+	defer windows.CloseHandle(handle)
+
+	buf := make([]byte, windows.MAXIMUM_REPARSE_DATA_BUFFER_SIZE)
+	var returned uint32
+	err = windows.DeviceIoControl(handle, windows.FSCTL_GET_REPARSE_POINT, nil, 0, &buf[0], uint32(len(buf)), &returned, nil)
+	if errors.Is(err, windows.ERROR_NOT_A_REPARSE_POINT) {
+		return false, "", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	return parseJunctionTarget(buf[:returned])
+}
@@ -0,0 +1,55 @@
+//go:build windows
+
+package torrent
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateTorrent_Junction(t *testing.T) {
+	tmpDir := t.TempDir()
+	targetDir := filepath.Join(tmpDir, "target")
+	contentDir := filepath.Join(tmpDir, "content")
+	for _, dir := range []string{targetDir, contentDir} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "linked.bin"), make([]byte, 4096), 0644); err != nil {
+		t.Fatalf("failed to write linked file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(contentDir, "movie.bin"), make([]byte, 8192), 0644); err != nil {
+		t.Fatalf("failed to write content file: %v", err)
+	}
+
+	junctionPath := filepath.Join(contentDir, "extras")
+	if out, err := exec.Command("cmd", "/c", "mklink", "/J", junctionPath, targetDir).CombinedOutput(); err != nil {
+		t.Skipf("could not create junction: %v: %s", err, out)
+	}
+
+	junction, target, err := isJunction(junctionPath)
+	if err != nil {
+		t.Fatalf("isJunction failed: %v", err)
+	}
+	if !junction || !strings.EqualFold(filepath.Clean(target), targetDir) {
+		t.Fatalf("isJunction() = %v, %q, want true, %q", junction, target, targetDir)
+	}
+	if junction, _, err := isJunction(contentDir); err != nil || junction {
+		t.Errorf("isJunction() of a plain directory = %v, %v, want false", junction, err)
+	}
+
+	// a junction is skipped like a symlink to a directory instead of ending
+	// up in the torrent as an empty file
+	mi, err := CreateTorrent(CreateOptions{Path: contentDir, Quiet: true})
+	if err != nil {
+		t.Fatalf("CreateTorrent failed: %v", err)
+	}
+	info := mi.GetInfo()
+	if len(info.Files) != 1 || info.Files[0].Path[0] != "movie.bin" || info.TotalLength() != 8192 {
+		t.Errorf("expected only movie.bin of 8192 bytes, got %d files of %d bytes", len(info.Files), info.TotalLength())
+	}
+}
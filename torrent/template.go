@@ -0,0 +1,101 @@
+package torrent
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/autobrr/mkbrr/internal/preset"
+)
+
+// templateVars lists the variables the comment and output templates can use,
+// such as "{{.Name}} uploaded {{.Date}}". InfoHash is only known once the
+// content is hashed, so templates are expanded after hashing; the comment is
+// outside the info dictionary and does not change the info hash.
+var templateVars = []string{"Name", "Size", "Date", "InfoHash", "Tracker", "Preset"}
+
+// expandTemplate executes text as a text/template with data. Unknown
+// variables are an error instead of expanding to "<no value>". Text without
+// "{{" is returned as is, so single braces such as {year} are left alone; a
+// literal "{{" is written as {{"{{"}}.
+func expandTemplate(field, text string, data map[string]string) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := template.New(field).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", field, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", field, err)
+	}
+	return b.String(), nil
+}
+
+// templatePrefix returns the leading directories of dir before the first
+// element that uses a template, which can only be expanded after hashing.
+// It returns dir itself when there is no template.
+func templatePrefix(dir string) string {
+	i := strings.Index(dir, "{{")
+	if i < 0 {
+		return dir
+	}
+	if prefix := filepath.Dir(dir[:i]); prefix != "." {
+		return prefix
+	}
+	return ""
+}
+
+// checkTemplate expands text with placeholder values, so a typo in a
+// template fails before the content is hashed.
+func checkTemplate(field, text string) error {
+	data := make(map[string]string, len(templateVars))
+	for _, name := range templateVars {
+		data[name] = name
+	}
+	_, err := expandTemplate(field, text, data)
+	return err
+}
+
+// checkTemplates checks the comment and output templates of opts.
+func checkTemplates(opts CreateOptions) error {
+	for _, field := range []struct{ name, text string }{
+		{name: "comment", text: opts.Comment},
+		{name: "output", text: opts.OutputPath},
+		{name: "output dir", text: opts.OutputDir},
+	} {
+		if err := checkTemplate(field.name, field.text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateData returns the template variables for a created torrent.
+func templateData(t *Torrent, opts CreateOptions) map[string]string {
+	info := t.GetInfo()
+
+	date := now()
+	if t.CreationDate != 0 {
+		date = time.Unix(t.CreationDate, 0)
+	}
+
+	var tracker string
+	if len(opts.TrackerURLs) > 0 && opts.TrackerURLs[0] != "" {
+		tracker = preset.GetDomainPrefix(opts.TrackerURLs[0])
+	}
+
+	return map[string]string{
+		"Name":     info.Name,
+		"Size":     humanize.IBytes(uint64(info.TotalLength())),
+		"Date":     date.Format("2006-01-02"),
+		"InfoHash": t.HashInfoBytes().HexString(),
+		"Tracker":  tracker,
+		"Preset":   opts.PresetName,
+	}
+}
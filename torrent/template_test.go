@@ -0,0 +1,241 @@
+package torrent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	data := map[string]string{
+		"Name":     "Show.S01",
+		"Size":     "1.0 MiB",
+		"Date":     "2024-01-02",
+		"InfoHash": "0123456789abcdef0123456789abcdef01234567",
+		"Tracker":  "example",
+		"Preset":   "ptp",
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "name", text: "{{.Name}}", want: "Show.S01"},
+		{name: "size", text: "size {{.Size}}", want: "size 1.0 MiB"},
+		{name: "date", text: "{{.Date}}", want: "2024-01-02"},
+		{name: "info hash", text: "{{.InfoHash}}.torrent", want: "0123456789abcdef0123456789abcdef01234567.torrent"},
+		{name: "tracker", text: "{{.Tracker}}/{{.Name}}", want: "example/Show.S01"},
+		{name: "preset", text: "made with {{.Preset}}", want: "made with ptp"},
+		{name: "no template", text: "plain comment", want: "plain comment"},
+		{name: "single braces", text: "/data/{year}/{tracker}", want: "/data/{year}/{tracker}"},
+		{name: "escaped braces", text: `{{"{{"}}.Name}}`, want: "{{.Name}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandTemplate("comment", tt.text, data)
+			if err != nil {
+				t.Fatalf("expandTemplate(%q) failed: %v", tt.text, err)
+			}
+			if got != tt.want {
+				t.Errorf("expandTemplate(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandTemplate_Errors(t *testing.T) {
+	for _, text := range []string{"{{.Unknown}}", "{{.Name"} {
+		if err := checkTemplate("comment", text); err == nil || !strings.Contains(err.Error(), "invalid comment template") {
+			t.Errorf("checkTemplate(%q) = %v, want an invalid comment template error", text, err)
+		}
+	}
+}
+
+func TestCreate_Templates(t *testing.T) {
+	dir := t.TempDir()
+	contentPath := filepath.Join(dir, "content.bin")
+	if err := os.WriteFile(contentPath, make([]byte, 1<<16), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+	outDir := t.TempDir()
+
+	info, err := Create(CreateOptions{
+		Path:        contentPath,
+		OutputDir:   filepath.Join(outDir, "{{.Tracker}}"),
+		TrackerURLs: []string{"https://tracker.example.com/announce"},
+		Comment:     "{{.Name}} ({{.Size}}) {{.InfoHash}} {{.Preset}} {{.Date}}",
+		PresetName:  "custom",
+		SkipPrefix:  true,
+		Quiet:       true,
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	wantPath := filepath.Join(outDir, "example", "content.bin.torrent")
+	if info.Path != wantPath {
+		t.Errorf("Path = %q, want %q", info.Path, wantPath)
+	}
+
+	loaded, err := LoadFromFile(info.Path)
+	if err != nil {
+		t.Fatalf("failed to load torrent: %v", err)
+	}
+	date := time.Unix(loaded.CreationDate, 0).Format("2006-01-02")
+	want := "content.bin (64 KiB) " + info.InfoHash + " custom " + date
+	if loaded.Comment != want {
+		t.Errorf("Comment = %q, want %q", loaded.Comment, want)
+	}
+
+	// the comment is outside the info dictionary, so the info hash matches a
+	// torrent created without it
+	plain, err := CreateTorrent(CreateOptions{Path: contentPath, TrackerURLs: []string{"https://tracker.example.com/announce"}, Quiet: true})
+	if err != nil {
+		t.Fatalf("CreateTorrent failed: %v", err)
+	}
+	if got := plain.HashInfoBytes().HexString(); got != info.InfoHash {
+		t.Errorf("info hash = %s, want %s", info.InfoHash, got)
+	}
+}
+
+func TestCreate_OutputTemplate(t *testing.T) {
+	dir := t.TempDir()
+	contentPath := filepath.Join(dir, "content.bin")
+	if err := os.WriteFile(contentPath, make([]byte, 1<<16), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+
+	info, err := Create(CreateOptions{
+		Path:       contentPath,
+		OutputPath: filepath.Join(dir, "{{.Name}}-{{.InfoHash}}.torrent"),
+		Quiet:      true,
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	want := filepath.Join(dir, "content.bin-"+info.InfoHash+".torrent")
+	if info.Path != want {
+		t.Errorf("Path = %q, want %q", info.Path, want)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Errorf("expected the torrent at %q: %v", want, err)
+	}
+}
+
+func TestCreate_InvalidTemplateFailsBeforeHashing(t *testing.T) {
+	dir := t.TempDir()
+	contentPath := filepath.Join(dir, "content.bin")
+	if err := os.WriteFile(contentPath, make([]byte, 1<<16), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+
+	called := false
+	_, err := Create(CreateOptions{
+		Path:             contentPath,
+		OutputPath:       filepath.Join(dir, "out.torrent"),
+		Comment:          "{{.Nmae}}",
+		Quiet:            true,
+		ProgressCallback: func(completed, total int, hashRate float64) { called = true },
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid comment template") {
+		t.Fatalf("Create = %v, want an invalid comment template error", err)
+	}
+	if called {
+		t.Error("expected the template to fail before hashing")
+	}
+}
+
+func TestTemplatePrefix(t *testing.T) {
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{dir: filepath.Join("out", "{year}"), want: filepath.Join("out", "{year}")},
+		{dir: filepath.Join("out", "{{.Tracker}}", "{{.InfoHash}}"), want: "out"},
+		{dir: filepath.Join("out", "sub", "hash-{{.InfoHash}}"), want: filepath.Join("out", "sub")},
+		{dir: "{{.InfoHash}}", want: ""},
+		{dir: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := templatePrefix(tt.dir); got != tt.want {
+			t.Errorf("templatePrefix(%q) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestCreate_OutputDirFailsBeforeHashing(t *testing.T) {
+	dir := t.TempDir()
+	contentPath := filepath.Join(dir, "content.bin")
+	if err := os.WriteFile(contentPath, make([]byte, 1<<16), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+	// a regular file where the output dir should go cannot be created over
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatalf("failed to write blocker: %v", err)
+	}
+
+	for _, outputDir := range []string{
+		filepath.Join(blocker, "torrents"),
+		filepath.Join(blocker, "torrents", "{{.InfoHash}}"),
+	} {
+		t.Run(outputDir, func(t *testing.T) {
+			called := false
+			_, err := Create(CreateOptions{
+				Path:             contentPath,
+				OutputDir:        outputDir,
+				Quiet:            true,
+				ProgressCallback: func(completed, total int, hashRate float64) { called = true },
+			})
+			if err == nil || !strings.Contains(err.Error(), "error creating output directory") {
+				t.Fatalf("Create = %v, want an output directory error", err)
+			}
+			if called {
+				t.Error("expected the output dir to fail before hashing")
+			}
+		})
+	}
+}
+
+func TestProcessBatch_OutputTemplate(t *testing.T) {
+	dir := t.TempDir()
+	contentPath := filepath.Join(dir, "content.bin")
+	if err := os.WriteFile(contentPath, make([]byte, 1<<16), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+
+	configPath := filepath.Join(dir, "batch.yaml")
+	config := "version: 1\njobs:\n" +
+		"  - path: " + contentPath + "\n" +
+		"    output_dir: " + filepath.Join(dir, "{{.Tracker}}", "{{.InfoHash}}") + "\n" +
+		"    comment: \"{{.InfoHash}}\"\n" +
+		"    trackers:\n      - https://tracker.example.com/announce\n"
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	results, err := ProcessBatch(configPath, false, true, false, false, "test-version")
+	if err != nil {
+		t.Fatalf("ProcessBatch failed: %v", err)
+	}
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("expected one successful job, got %+v", results)
+	}
+
+	info := results[0].Info
+	want := filepath.Join(dir, "example", info.InfoHash, "example_content.bin.torrent")
+	if info.Path != want {
+		t.Errorf("Path = %q, want %q", info.Path, want)
+	}
+	loaded, err := LoadFromFile(want)
+	if err != nil {
+		t.Fatalf("failed to load torrent: %v", err)
+	}
+	if loaded.Comment != info.InfoHash {
+		t.Errorf("Comment = %q, want the info hash %q", loaded.Comment, info.InfoHash)
+	}
+}
@@ -0,0 +1,91 @@
+package torrent
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"golang.org/x/time/rate"
+)
+
+// readThrottle limits the combined read rate of all workers of a hashing or
+// verification run using a token bucket holding one second worth of bytes.
+// A nil throttle does not limit anything.
+type readThrottle struct {
+	limiter *rate.Limiter
+	now     func() time.Time                                 // replaceable in tests
+	sleep   func(ctx context.Context, d time.Duration) error // replaceable in tests
+}
+
+// newReadThrottle returns a throttle for bytesPerSecond, or nil when it is not positive.
+func newReadThrottle(bytesPerSecond int64) *readThrottle {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+
+	burst := int(min(bytesPerSecond, math.MaxInt32))
+	return &readThrottle{
+		limiter: rate.NewLimiter(rate.Limit(bytesPerSecond), burst),
+		now:     time.Now,
+		sleep:   sleepContext,
+	}
+}
+
+// sleepContext sleeps for d or until ctx is done, returning its error then.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// wait blocks until n more bytes may be read. Reads larger than the bucket
+// are split so they never exceed the burst size. It returns early with the
+// error of ctx once ctx is done, which may be nil to never stop.
+func (t *readThrottle) wait(ctx context.Context, n int) error {
+	if t == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for n > 0 {
+		chunk := min(n, t.limiter.Burst())
+		reservation := t.limiter.ReserveN(t.now(), chunk)
+		if delay := reservation.DelayFrom(t.now()); delay > 0 {
+			if err := t.sleep(ctx, delay); err != nil {
+				// give the bytes back to workers that keep reading
+				reservation.CancelAt(t.now())
+				return err
+			}
+		}
+		n -= chunk
+	}
+	return nil
+}
+
+// ParseByteRate parses a human-readable read rate such as "100MB", "1.5GiB"
+// or "500KB/s" into bytes per second. An empty string or "0" disables throttling.
+func ParseByteRate(s string) (int64, error) {
+	s = strings.TrimSuffix(strings.TrimSpace(s), "/s")
+	if s == "" {
+		return 0, nil
+	}
+
+	bytes, err := humanize.ParseBytes(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte rate %q: %w", s, err)
+	}
+	if bytes > math.MaxInt64 {
+		return 0, fmt.Errorf("invalid byte rate %q: too large", s)
+	}
+	return int64(bytes), nil
+}
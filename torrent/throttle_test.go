@@ -0,0 +1,183 @@
+package torrent
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a simulated clock whose sleep advances time instantly.
+type fakeClock struct {
+	mu      sync.Mutex
+	current time.Time
+	slept   time.Duration
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.current
+}
+
+func (c *fakeClock) sleep(_ context.Context, d time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current = c.current.Add(d)
+	c.slept += d
+	return nil
+}
+
+func newFakeThrottle(bytesPerSecond int64) (*readThrottle, *fakeClock) {
+	clock := &fakeClock{current: time.Unix(1700000000, 0)}
+	throttle := newReadThrottle(bytesPerSecond)
+	throttle.now = clock.now
+	throttle.sleep = clock.sleep
+	return throttle, clock
+}
+
+func TestReadThrottle_Wait(t *testing.T) {
+	const total = 10 << 20
+	throttle, clock := newFakeThrottle(1 << 20)
+
+	for read := 0; read < total; read += 64 << 10 {
+		if err := throttle.wait(nil, 64<<10); err != nil {
+			t.Fatalf("wait failed: %v", err)
+		}
+	}
+
+	// the first second worth of bytes comes from the initially full bucket
+	if clock.slept < 9*time.Second {
+		t.Errorf("reading 10 MiB at 1 MiB/s took %v of simulated time, want at least 9s", clock.slept)
+	}
+	if clock.slept > 10*time.Second {
+		t.Errorf("reading 10 MiB at 1 MiB/s took %v of simulated time, want at most 10s", clock.slept)
+	}
+}
+
+func TestReadThrottle_LargeRead(t *testing.T) {
+	throttle, clock := newFakeThrottle(1 << 20)
+
+	// a single read larger than the bucket is split instead of failing
+	if err := throttle.wait(nil, 4<<20); err != nil {
+		t.Fatalf("wait failed: %v", err)
+	}
+	if clock.slept < 3*time.Second {
+		t.Errorf("reading 4 MiB at 1 MiB/s took %v of simulated time, want at least 3s", clock.slept)
+	}
+}
+
+func TestReadThrottle_Disabled(t *testing.T) {
+	if throttle := newReadThrottle(0); throttle != nil {
+		t.Fatal("expected no throttle for a zero rate")
+	}
+
+	var throttle *readThrottle
+	if err := throttle.wait(nil, 1<<30); err != nil { // must not block or panic
+		t.Fatalf("wait failed: %v", err)
+	}
+}
+
+func TestHashPieces_Throttled(t *testing.T) {
+	const size = 10 << 20
+	path := filepath.Join(t.TempDir(), "content.bin")
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+
+	const pieceLen = 1 << 18
+	files := []fileEntry{{path: path, length: size}}
+	hasher := NewPieceHasher(files, pieceLen, size/pieceLen, &mockDisplay{}, false)
+	throttle, clock := newFakeThrottle(1 << 20)
+	hasher.throttle = throttle
+
+	if err := hasher.hashPieces(2); err != nil {
+		t.Fatalf("hashPieces failed: %v", err)
+	}
+	if clock.slept < 9*time.Second {
+		t.Errorf("hashing 10 MiB at 1 MiB/s took %v of simulated time, want at least 9s", clock.slept)
+	}
+}
+
+func TestParseByteRate(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{input: "", want: 0},
+		{input: "0", want: 0},
+		{input: "100MB", want: 100_000_000},
+		{input: "100MiB", want: 100 << 20},
+		{input: "1.5 GB", want: 1_500_000_000},
+		{input: "500KB/s", want: 500_000},
+		{input: "fast", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseByteRate(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseByteRate(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseByteRate(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestReadThrottle_Cancel(t *testing.T) {
+	throttle := newReadThrottle(1 << 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	// 10 KiB at 1 KiB/s would sleep for 9 seconds
+	start := time.Now()
+	if err := throttle.wait(ctx, 10<<10); err != context.Canceled {
+		t.Fatalf("wait error = %v, want %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("wait returned %v after cancellation, want it to stop sleeping right away", elapsed)
+	}
+}
+
+func TestHashPieces_ThrottledRealTime(t *testing.T) {
+	const (
+		size     = 768 << 10
+		rate     = 512 << 10
+		pieceLen = 1 << 16
+	)
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	path := filepath.Join(t.TempDir(), "content.bin")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+	files := []fileEntry{{path: path, length: size}}
+
+	unthrottled := NewPieceHasher(files, pieceLen, size/pieceLen, &mockDisplay{}, false)
+	if err := unthrottled.hashPieces(2); err != nil {
+		t.Fatalf("hashPieces failed: %v", err)
+	}
+
+	hasher := NewPieceHasher(files, pieceLen, size/pieceLen, &mockDisplay{}, false)
+	hasher.throttle = newReadThrottle(rate)
+	start := time.Now()
+	if err := hasher.hashPieces(2); err != nil {
+		t.Fatalf("hashPieces failed: %v", err)
+	}
+	elapsed := time.Since(start)
+
+	// the bucket starts with a second worth of bytes, the rest waits for the rate
+	if want := time.Duration(float64(size-rate) / rate * 0.9 * float64(time.Second)); elapsed < want {
+		t.Errorf("hashing %d bytes at %d bytes/s took %v, want at least %v", size, rate, elapsed, want)
+	}
+	if !bytes.Equal(hasher.pieceHashStorage, unthrottled.pieceHashStorage) {
+		t.Error("throttled hashes differ from unthrottled ones")
+	}
+}
@@ -0,0 +1,99 @@
+package torrent
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/anacrolix/torrent/metainfo"
+
+	"github.com/autobrr/mkbrr/internal/preset"
+)
+
+// CreateTorrentSet creates a torrent for each of opts, which describe the same
+// content for different trackers, e.g. with their own trackers, source,
+// comment and entropy. The content is hashed once for each piece length the
+// torrents end up with: a torrent copies the piece hashes of an earlier one of
+// the set with the same piece length, hashing again only the files modified
+// since that one was hashed. The torrents are returned in the order of opts.
+func CreateTorrentSet(opts []CreateOptions) ([]*Torrent, error) {
+	torrents := make([]*Torrent, 0, len(opts))
+	err := createSet(opts, func(o CreateOptions) (*metainfo.MetaInfo, error) {
+		t, err := createTorrentFrom(o, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		torrents = append(torrents, t)
+		return t.MetaInfo, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return torrents, nil
+}
+
+// CreateSet is Create for each of opts, hashing the content as few times as
+// CreateTorrentSet does. Each torrent is written once it is created.
+func CreateSet(opts []CreateOptions) ([]*TorrentInfo, error) {
+	infos := make([]*TorrentInfo, 0, len(opts))
+	err := createSet(opts, func(o CreateOptions) (*metainfo.MetaInfo, error) {
+		info, err := Create(o)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+		return info.MetaInfo, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+// createSet runs createOne for each of opts in order, handing it the piece
+// hashes of the torrents created so far.
+func createSet(opts []CreateOptions, createOne func(CreateOptions) (*metainfo.MetaInfo, error)) error {
+	if len(opts) == 0 {
+		return fmt.Errorf("no torrents to create")
+	}
+	for _, o := range opts[1:] {
+		if filepath.Clean(o.Path) != filepath.Clean(opts[0].Path) {
+			return fmt.Errorf("torrents of a set must have the same content, got %q and %q", opts[0].Path, o.Path)
+		}
+	}
+
+	sources := make(map[int64]*reuseSource)
+	for _, o := range opts {
+		o.setSources = sources
+		// files modified from here on may not match the hashes
+		hashStart := time.Now()
+		mi, err := createOne(o)
+		if err != nil {
+			return fmt.Errorf("could not create %s: %w", setLabel(o), err)
+		}
+		if o.SkipHashing {
+			continue
+		}
+		info, err := mi.UnmarshalInfo()
+		if err != nil {
+			return fmt.Errorf("could not unmarshal info dictionary of %s: %w", setLabel(o), err)
+		}
+		if _, ok := sources[info.PieceLength]; !ok {
+			sources[info.PieceLength] = newReuseSource(setLabel(o), &info, hashStart)
+		}
+	}
+	return nil
+}
+
+// setLabel names the torrent of a set created from opts in messages, by its
+// preset or tracker domain so passkeys are never printed.
+func setLabel(opts CreateOptions) string {
+	switch {
+	case opts.PresetName != "":
+		return fmt.Sprintf("the %s torrent", opts.PresetName)
+	case len(opts.TrackerURLs) > 0:
+		return fmt.Sprintf("the %s torrent", preset.GetDomainPrefix(opts.TrackerURLs[0]))
+	default:
+		return "the torrent without trackers"
+	}
+}
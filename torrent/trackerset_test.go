@@ -0,0 +1,115 @@
+package torrent
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+// countContentOpens replaces openContent for the test and returns the
+// number of content files opened for hashing.
+func countContentOpens(t *testing.T) *atomic.Int64 {
+	t.Helper()
+	opens := new(atomic.Int64)
+	openContent = func(name string) (io.ReadSeekCloser, error) {
+		opens.Add(1)
+		return os.Open(longPath(name))
+	}
+	t.Cleanup(func() { openContent = nil })
+	return opens
+}
+
+func TestCreateTorrentSet(t *testing.T) {
+	contentDir := filepath.Join(t.TempDir(), "Release")
+	if err := os.MkdirAll(contentDir, 0755); err != nil {
+		t.Fatalf("failed to create content dir: %v", err)
+	}
+	for name, data := range map[string][]byte{
+		"release.mkv": bytes.Repeat([]byte("m"), 5<<16+100),
+		"release.nfo": []byte("nfo"),
+	} {
+		if err := os.WriteFile(filepath.Join(contentDir, name), data, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	pieceLenExp := uint(16)
+	largerPieceLenExp := uint(17)
+	base := CreateOptions{Path: contentDir, PieceLengthExp: &pieceLenExp, IsPrivate: true, Workers: 1, Quiet: true}
+
+	opens := countContentOpens(t)
+	single, err := CreateTorrent(base)
+	if err != nil {
+		t.Fatalf("CreateTorrent failed: %v", err)
+	}
+	onePass := opens.Load()
+	if onePass == 0 {
+		t.Fatal("expected the content to be read through openContent")
+	}
+
+	tests := []struct {
+		name       string
+		exps       []*uint
+		wantPasses int
+	}{
+		{name: "same piece length", exps: []*uint{&pieceLenExp, &pieceLenExp, &pieceLenExp}, wantPasses: 1},
+		{name: "two piece lengths", exps: []*uint{&pieceLenExp, &largerPieceLenExp, &pieceLenExp}, wantPasses: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []CreateOptions
+			for i, exp := range tt.exps {
+				o := base
+				o.PieceLengthExp = exp
+				o.TrackerURLs = []string{[]string{"https://a.example/announce", "https://b.example/announce", "https://c.example/announce"}[i]}
+				o.Source = []string{"A", "B", "C"}[i]
+				o.Entropy = i == 2
+				opts = append(opts, o)
+			}
+
+			opens.Store(0)
+			torrents, err := CreateTorrentSet(opts)
+			if err != nil {
+				t.Fatalf("CreateTorrentSet failed: %v", err)
+			}
+			if want := int64(tt.wantPasses) * onePass; opens.Load() != want {
+				t.Errorf("content files opened %d times, want %d", opens.Load(), want)
+			}
+
+			hashes := make(map[string]bool)
+			for i, tor := range torrents {
+				info := tor.GetInfo()
+				if tor.Announce != opts[i].TrackerURLs[0] || info.Source != opts[i].Source {
+					t.Errorf("torrent %d has announce %q and source %q, want %q and %q", i, tor.Announce, info.Source, opts[i].TrackerURLs[0], opts[i].Source)
+				}
+				if *tt.exps[i] == pieceLenExp && !bytes.Equal(info.Pieces, single.GetInfo().Pieces) {
+					t.Errorf("torrent %d pieces differ from a torrent created on its own", i)
+				}
+				hashes[tor.HashInfoBytes().HexString()] = true
+			}
+			if len(hashes) != len(torrents) {
+				t.Errorf("expected %d distinct info hashes, got %d", len(torrents), len(hashes))
+			}
+		})
+	}
+}
+
+func TestCreateTorrentSet_DifferentContent(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"a.bin", "b.bin"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("content"), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	_, err := CreateTorrentSet([]CreateOptions{
+		{Path: filepath.Join(tmpDir, "a.bin"), Quiet: true},
+		{Path: filepath.Join(tmpDir, "b.bin"), Quiet: true},
+	})
+	if err == nil {
+		t.Fatal("expected an error for torrents of different content")
+	}
+}
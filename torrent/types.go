@@ -1,9 +1,14 @@
 package torrent
 
 import (
-	"os"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"time"
 
 	"github.com/anacrolix/torrent/metainfo"
+	infohash_v2 "github.com/anacrolix/torrent/types/infohash-v2"
 )
 
 // ProgressCallback is called during hashing to report progress.
@@ -12,6 +17,18 @@ import (
 // hashRate: current hashing rate in MiB per second
 type ProgressCallback func(completed, total int, hashRate float64)
 
+// File orders supported by CreateOptions.FileOrder
+const (
+	FileOrderPath     = "path"      // lexicographic by path (default)
+	FileOrderNatural  = "natural"   // numeric runs compared by value, e.g. track2 before track10
+	FileOrderNone     = "none"      // keep the order of the directory walk
+	FileOrderInode    = "inode"     // by inode number, usually the order the files were written in (unix only)
+	FileOrderSizeDesc = "size-desc" // largest file first, equal sizes keep walk order
+	FileOrderSizeAsc  = "size-asc"  // smallest file first, equal sizes keep walk order
+
+	FileOrderLargestFirst = "largest-first" // alias of FileOrderSizeDesc
+)
+
 // CreateOptions contains all options for creating a torrent
 type CreateOptions struct {
 	PieceLengthExp          *uint
@@ -25,10 +42,12 @@ type CreateOptions struct {
 	Version                 string
 	OutputPath              string
 	OutputDir               string
+	FileOrder               string // one of the FileOrder* constants, defaults to FileOrderPath
 	WebSeeds                []string
 	ExcludePatterns         []string
 	IncludePatterns         []string
 	Workers                 int
+	ReadRetries             int // retry failed file reads this many times with backoff (0 fails immediately)
 	IsPrivate               bool
 	NoDate                  bool
 	NoCreator               bool
@@ -38,14 +57,152 @@ type CreateOptions struct {
 	InfoOnly                bool
 	SkipPrefix              bool
 	FailOnSeasonPackWarning bool
+	SanitizeName            bool   // normalize the torrent name so it is valid on all platforms
+	ASCIIName               bool   // transliterate non-ASCII characters when sanitizing the name
+	ShowTree                bool   // print the nested file tree of multi-file torrents after creation, as Verbose does
+	SkipHashing             bool   // write all-zero placeholder piece hashes; the torrent cannot be seeded
+	ReuseFrom               string // existing torrent whose piece hashes are copied for unchanged files
+	VerifyReused            int    // number of randomly chosen reused pieces to rehash as a spot-check
+	MaxReadBytesPerSecond   int64  // limit the combined read rate while hashing (0 disables throttling); waits end when the context is done
+	FAT32Check              bool   // warn about files larger than FAT32 can store (4 GiB)
+	StrictFileChecks        bool   // fail instead of warning about FAT32 oversized and sparse files
 	// ProgressCallback is called during hashing to report progress.
 	// If nil, no progress callbacks will be made.
-	ProgressCallback        ProgressCallback
+	ProgressCallback ProgressCallback
+	// Logger receives diagnostic messages such as skipped files; wrap a
+	// slog.Handler with slog.New to use one. If nil, the logger set with
+	// SetDefaultLogger is used, which writes warnings to stderr by default.
+	Logger Logger
+	// HTTPSeeds are BEP 17 http seed URLs, written as the httpseeds key
+	// separately from the BEP 19 WebSeeds.
+	HTTPSeeds []string
+	// RandomizeAnnounceList shuffles the tracker tiers and the trackers within
+	// each tier; Announce is the first tracker after shuffling.
+	RandomizeAnnounceList bool
+	// MinVideoSize is the size below which video files are reported as likely
+	// stubs; 0 uses the 1 MiB default and a negative value disables the check.
+	// Empty files are always reported.
+	MinVideoSize int64
+	// StrictContent fails instead of warning about empty and stub files.
+	StrictContent bool
+	// Context cancels the directory walk and hashing once it is done.
+	// If nil, context.Background() is used.
+	Context context.Context
+	// CreationDate is written instead of the current time when not zero;
+	// together with NoCreator it makes the output reproducible.
+	CreationDate time.Time
+	// ExcludeDirs names directories whose whole subtree is left out, matched
+	// case-insensitively against the directory name at any depth.
+	ExcludeDirs []string
+	// Creator replaces the default created by field of mkbrr and its version.
+	// NoCreator still leaves the field out.
+	Creator string
+	// FailFast returns an error for paths that cannot be read while walking
+	// the content, such as broken symlinks, instead of skipping them with a
+	// warning, so no torrent is created with files missing.
+	FailFast bool
+	// NoProgress prints progress as periodic lines instead of a progress bar.
+	// Output that is not a terminal always gets plain lines.
+	NoProgress bool
+	// PresetName is the preset the options came from, for {{.Preset}} in the
+	// comment and output templates
+	PresetName string
+	// FollowDirSymlinks includes the contents of symlinked directories under
+	// the link's path; directories already walked are skipped, so links to
+	// an ancestor cannot loop. Without it directory symlinks are left out.
+	FollowDirSymlinks bool
+	// PreserveAttrs records a BEP 47 attr for each file, "x" for executables
+	// and "h" for hidden files. It is part of the info dictionary, so it
+	// changes the info hash.
+	PreserveAttrs bool
+	// CaseSensitivePatterns matches ExcludePatterns and IncludePatterns
+	// without ignoring case. The built-in ignores always ignore case.
+	CaseSensitivePatterns bool
+	// SkipHidden leaves out files and directories whose name starts with a
+	// dot, and on Windows those with the hidden attribute. A .mkbrrignore
+	// file is still read.
+	SkipHidden bool
+	// MaxDepth limits how many directory levels below Path are walked; 0
+	// includes only the files directly in Path. Depth is counted along the
+	// paths in the torrent, so a followed directory symlink is one level
+	// wherever its target is. Nil walks the whole tree.
+	MaxDepth *int
+	// SkipSeasonPackCheck skips the season pack analysis, which matches
+	// every file name against a set of patterns, leaving Torrent.SeasonPack
+	// nil. It is ignored when FailOnSeasonPackWarning is set.
+	SkipSeasonPackCheck bool
+	// PadToPieceBoundary starts every file of a multi-file torrent on a piece
+	// boundary by adding a BEP 47 padding file after each file but the last,
+	// named .pad/<length>. Padding is hashed as zeros and not written to
+	// disk. It changes the info hash and cannot be combined with ReuseFrom.
+	PadToPieceBoundary bool
+	// ExtraRootFields are written as additional string keys of the root
+	// dictionary, such as "publisher". They are never part of the info
+	// dictionary, so they do not change the info hash. Keys of standard
+	// fields such as announce or info are rejected.
+	ExtraRootFields map[string]string
+	// HashCacheDir keeps piece hashes in a cache file in this directory,
+	// keyed by the path, mtime, size and offset of every file a piece covers
+	// and the piece length. Pieces found there are not hashed again, so
+	// re-creating a torrent of mostly unchanged content reads only the
+	// changed files. Like ReuseFrom, it relies on the mtime changing
+	// whenever a file is modified. It needs the OS filesystem.
+	HashCacheDir string
+
+	// setSources are the piece hashes of the torrents CreateTorrentSet
+	// created before this one, by piece length
+	setSources map[int64]*reuseSource
 }
 
 // Torrent represents a torrent file with additional functionality
 type Torrent struct {
 	*metainfo.MetaInfo
+	// HTTPSeeds holds BEP 17 http seeds, written as the httpseeds key next to
+	// the BEP 19 url-list web seeds of MetaInfo.UrlList
+	HTTPSeeds []string
+	// ExtraFields holds additional string keys written to the root
+	// dictionary, from CreateOptions.ExtraRootFields
+	ExtraFields map[string]string
+	// Warnings lists the problems the pre-hash file checks found during creation
+	Warnings []string
+	// SeasonPack holds the season pack analysis of the files, nil when
+	// CreateOptions.SkipSeasonPackCheck skipped it
+	SeasonPack *SeasonPackInfo
+}
+
+// InfoHashSHA256 returns the hex encoded SHA-256 hash of the bencoded info
+// dictionary. For v2 and hybrid torrents this is the v2 info hash; for v1
+// torrents some cross-seeding tools use it as an identifier as well.
+func (t *Torrent) InfoHashSHA256() string {
+	sum := sha256.Sum256(t.InfoBytes)
+	return hex.EncodeToString(sum[:])
+}
+
+// MagnetWithSHA256 returns the magnet link of MagnetV2 with the SHA-256 info
+// hash added as xt=urn:btmh:1220<sha256> when the torrent has no v2 metadata.
+func (t *Torrent) MagnetWithSHA256() (metainfo.MagnetV2, error) {
+	m, err := t.MagnetV2()
+	if err != nil {
+		return m, err
+	}
+	if !m.V2InfoHash.Ok {
+		m.V2InfoHash.Set(infohash_v2.HashBytes(t.InfoBytes))
+	}
+	return m, nil
+}
+
+// HasPlaceholderPieces reports whether every piece hash in info is zero, as
+// written by CreateOptions.SkipHashing. Such torrents are metadata-only templates.
+func HasPlaceholderPieces(info *metainfo.Info) bool {
+	if len(info.Pieces) == 0 {
+		return false
+	}
+	for _, b := range info.Pieces {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
 }
 
 // FileEntry represents a file in the torrent
@@ -60,11 +217,12 @@ type fileEntry struct {
 	path   string
 	length int64
 	offset int64
+	pad    bool // BEP 47 padding, read as zeros instead of from path
 }
 
 // internal file reader for processing
 type fileReader struct {
-	file     *os.File
+	file     io.ReadSeekCloser
 	position int64
 	length   int64
 }
@@ -76,7 +234,50 @@ type TorrentInfo struct {
 	InfoHash string
 	Announce string
 	Size     int64
-	Files    int
+	Files    int // 0 for a single-file torrent
+	// PieceLength is the piece length in bytes and PieceCount the number of pieces
+	PieceLength int64
+	PieceCount  int
+	// InfoHashSHA256 is the hex SHA-256 hash of the info dictionary, see Torrent.InfoHashSHA256
+	InfoHashSHA256 string
+	// Warnings lists the problems the pre-hash file checks found
+	Warnings []string
+	// SeasonPack holds the season pack analysis, see Torrent.SeasonPack
+	SeasonPack *SeasonPackInfo
+}
+
+// CreateSummary is the result of creating a torrent in a form fit for JSON
+// output, see NewCreateSummary
+type CreateSummary struct {
+	Path        string `json:"path"`
+	InfoHash    string `json:"info_hash"`
+	Size        int64  `json:"size"`  // total size of the files in bytes
+	Files       int    `json:"files"` // 1 for a single-file torrent
+	PieceLength int64  `json:"piece_length"`
+	PieceCount  int    `json:"piece_count"`
+}
+
+// NewCreateSummary returns the summary of the torrent described by info.
+func NewCreateSummary(info *TorrentInfo) CreateSummary {
+	return CreateSummary{
+		Path:        info.Path,
+		InfoHash:    info.InfoHash,
+		Size:        info.Size,
+		Files:       max(info.Files, 1),
+		PieceLength: info.PieceLength,
+		PieceCount:  info.PieceCount,
+	}
+}
+
+// Estimate describes the torrent CreateTorrent would produce, see EstimateTorrent
+type Estimate struct {
+	Name           string `json:"name"`
+	Files          int    `json:"files"`
+	TotalSize      int64  `json:"total_size"` // content size after filtering
+	PieceLength    int64  `json:"piece_length"`
+	PieceLengthExp uint   `json:"piece_length_exp"`
+	Pieces         int64  `json:"pieces"`
+	TorrentSize    int64  `json:"torrent_size"` // estimated size of the .torrent file
 }
 
 // VerificationResult holds the outcome of a torrent data verification check
@@ -88,6 +289,33 @@ type VerificationResult struct {
 	BadPieces       int
 	MissingPieces   int
 	Completion      float64
+	RestoredAttrs   []string           // torrent paths of files whose execute bits were restored
+	Files           []FileVerification // status of each file, in torrent order
+	ContentPath     string             // content that was verified, the match when VerifyOptions.FuzzyPathMatch found one
+	ExtraFiles      []ExtraFile        // files under ContentPath that are not in the torrent, with VerifyOptions.FindExtraFiles
+}
+
+// ExtraFile is a file found under the content path that is not part of the torrent
+type ExtraFile struct {
+	Path string `json:"path"` // relative to VerificationResult.ContentPath, "/" separated
+	Size int64  `json:"size"`
+}
+
+// Statuses of a file in FileVerification
+const (
+	FileStatusOK           = "ok"            // every piece of the file matched
+	FileStatusBad          = "bad"           // at least one piece of the file did not match
+	FileStatusMissing      = "missing"       // the file was not found
+	FileStatusSizeMismatch = "size-mismatch" // the file has a different size and was not checked
+	FileStatusUnverified   = "unverified"    // a piece shared with a missing file could not be checked
+)
+
+// FileVerification is the outcome of verifying one file of a torrent
+type FileVerification struct {
+	Path      string `json:"path"` // path in the torrent, "/" separated
+	Size      int64  `json:"size"`
+	Status    string `json:"status"`     // one of the FileStatus* constants
+	BadPieces int    `json:"bad_pieces"` // pieces holding data of the file that did not match
 }
 
 // callbackDisplayer adapts a ProgressCallback to the Displayer interface
@@ -97,7 +325,7 @@ type callbackDisplayer struct {
 }
 
 // ShowProgress implements Displayer interface
-func (c *callbackDisplayer) ShowProgress(total int) {
+func (c *callbackDisplayer) ShowProgress(total int, totalSize int64) {
 	c.total = total
 	if c.callback != nil {
 		c.callback(0, total, 0)
@@ -105,7 +333,7 @@ func (c *callbackDisplayer) ShowProgress(total int) {
 }
 
 // UpdateProgress implements Displayer interface
-func (c *callbackDisplayer) UpdateProgress(completed int, hashrate float64) {
+func (c *callbackDisplayer) UpdateProgress(completed int, hashrate float64, currentFile string, currentFileSize int64) {
 	if c.callback != nil {
 		c.callback(completed, c.total, hashrate/(1024*1024))
 	}
@@ -10,8 +10,8 @@ func TestCallbackDisplayerReportsHashRateInMiB(t *testing.T) {
 		},
 	}
 
-	displayer.ShowProgress(1)
-	displayer.UpdateProgress(1, 1024*1024)
+	displayer.ShowProgress(1, 1<<20)
+	displayer.UpdateProgress(1, 1024*1024, "", 0)
 
 	if got != 1 {
 		t.Fatalf("callback hash rate = %v, want 1 MiB/s", got)
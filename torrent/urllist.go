@@ -0,0 +1,71 @@
+package torrent
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadURLList reads URLs from a text file with one URL per line, such as a
+// list of web seeds. Empty lines and lines starting with # are skipped, and a
+// line "@other.txt" includes the URLs of another file, relative to the
+// directory of the file that includes it. URLs that do not start with http://
+// or https:// are kept but reported in warnings.
+func ReadURLList(path string) (urls []string, warnings []string, err error) {
+	err = readURLList(path, nil, &urls, &warnings)
+	return urls, warnings, err
+}
+
+// readURLList appends the URLs of path to urls. including holds the files
+// currently being read, so an include cycle is reported instead of recursing
+// forever.
+func readURLList(path string, including []string, urls, warnings *[]string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("could not resolve URL list %q: %w", path, err)
+	}
+	for _, p := range including {
+		if p == absPath {
+			return fmt.Errorf("URL list %q includes itself", path)
+		}
+	}
+	including = append(including, absPath)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("could not open URL list: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if include, ok := strings.CutPrefix(line, "@"); ok {
+			include = strings.TrimSpace(include)
+			if !filepath.IsAbs(include) {
+				include = filepath.Join(filepath.Dir(path), include)
+			}
+			if err := readURLList(include, including, urls, warnings); err != nil {
+				return fmt.Errorf("%s:%d: %w", path, lineNum, err)
+			}
+			continue
+		}
+
+		if !strings.HasPrefix(line, "http://") && !strings.HasPrefix(line, "https://") {
+			*warnings = append(*warnings, fmt.Sprintf("%s:%d: %q is not an http:// or https:// URL", path, lineNum, line))
+		}
+		*urls = append(*urls, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("could not read URL list %q: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package torrent
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadURLList(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "lists"), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	files := map[string]string{
+		"seeds.txt": "# primary mirrors\n" +
+			"https://seed1.example/files/\n" +
+			"\n" +
+			"   http://seed2.example/files/   \n" +
+			"@lists/more.txt\n" +
+			"ftp://seed4.example/files/\n",
+		"lists/more.txt": "# included from seeds.txt\nhttps://seed3.example/files/\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(data), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	listPath := filepath.Join(dir, "seeds.txt")
+
+	urls, warnings, err := ReadURLList(listPath)
+	if err != nil {
+		t.Fatalf("ReadURLList failed: %v", err)
+	}
+	want := []string{
+		"https://seed1.example/files/",
+		"http://seed2.example/files/",
+		"https://seed3.example/files/",
+		"ftp://seed4.example/files/",
+	}
+	if !slices.Equal(urls, want) {
+		t.Errorf("urls = %q, want %q", urls, want)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "ftp://seed4.example/files/") || !strings.Contains(warnings[0], "seeds.txt:6") {
+		t.Errorf("warnings = %q, want one warning for the ftp URL on line 6", warnings)
+	}
+
+	// the URLs end up in the url-list of the created torrent
+	contentPath := filepath.Join(dir, "content.bin")
+	if err := os.WriteFile(contentPath, make([]byte, 1<<16), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+	outputPath := filepath.Join(dir, "seeded.torrent")
+	if _, err := Create(CreateOptions{Path: contentPath, OutputPath: outputPath, WebSeeds: urls, Quiet: true}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	mi, err := LoadFromFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to load torrent: %v", err)
+	}
+	if !slices.Equal(mi.UrlList, want) {
+		t.Errorf("UrlList = %q, want %q", mi.UrlList, want)
+	}
+}
+
+func TestReadURLList_Errors(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.txt":       "https://a.example/\n@b.txt\n",
+		"b.txt":       "@a.txt\n",
+		"missing.txt": "@nowhere.txt\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "include cycle", path: "a.txt", wantErr: "includes itself"},
+		{name: "missing include", path: "missing.txt", wantErr: "missing.txt:1"},
+		{name: "missing list", path: "none.txt", wantErr: "could not open URL list"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ReadURLList(filepath.Join(dir, tt.path))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package torrent
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/anacrolix/torrent/bencode"
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// ValidationError describes a failed sanity check of a torrent
+type ValidationError struct {
+	Check   string // what was checked, such as "piece count" or "file path"
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return e.Check + ": " + e.Message
+}
+
+// ValidateTorrent runs sanity checks against a torrent that may be corrupt or
+// tampered with and returns the checks that failed, or nil when all pass:
+//   - the pieces field holds whole 20-byte hashes, one per piece of the
+//     total size;
+//   - file lengths are not negative and file paths are relative, without
+//     empty or ".." components;
+//   - the name is not a path;
+//   - private, when present, is 0 or 1.
+//
+// The piece checks are skipped for v2-only torrents, which have no pieces field.
+func ValidateTorrent(mi *metainfo.MetaInfo, info *metainfo.Info) []ValidationError {
+	var errs []ValidationError
+	fail := func(check, format string, args ...any) {
+		errs = append(errs, ValidationError{Check: check, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// v1 total size, from the files list or the single file length
+	totalLength := info.Length
+	for i, f := range info.Files {
+		if f.Length < 0 {
+			fail("file length", "file #%d has negative length %d", i+1, f.Length)
+		}
+		totalLength += f.Length
+	}
+
+	if info.PieceLength <= 0 {
+		fail("piece length", "piece length %d is not positive", info.PieceLength)
+	}
+	if len(info.Pieces) > 0 || !info.HasV2() {
+		if len(info.Pieces)%pieceHashSize != 0 {
+			fail("pieces", "length %d is not a multiple of %d", len(info.Pieces), pieceHashSize)
+		} else if info.PieceLength > 0 && totalLength >= 0 {
+			want := (totalLength + info.PieceLength - 1) / info.PieceLength
+			if got := int64(len(info.Pieces) / pieceHashSize); got != want {
+				fail("piece count", "%d pieces, but %d bytes in %d byte pieces need %d", got, totalLength, info.PieceLength, want)
+			}
+		}
+	}
+
+	if info.Name == "" {
+		fail("name", "name is empty")
+	} else if strings.ContainsAny(info.Name, `/\`) || info.Name == "." || info.Name == ".." {
+		fail("name", "name %q is a path", info.Name)
+	}
+
+	for i, f := range info.Files {
+		if len(f.Path) == 0 {
+			fail("file path", "file #%d has no path", i+1)
+			continue
+		}
+		p := strings.Join(f.Path, "/")
+		if strings.HasPrefix(f.Path[0], "/") || strings.HasPrefix(f.Path[0], `\`) {
+			fail("file path", "file #%d path %q is absolute", i+1, p)
+		}
+		if slices.Contains(f.Path, "") {
+			fail("file path", "file #%d path %q has an empty component", i+1, p)
+		}
+		// backslashes count as separators, as they do when verifying
+		if slices.Contains(strings.Split(normalizePathComponent(p), "/"), "..") {
+			fail("file path", "file #%d path %q leaves the torrent directory", i+1, p)
+		}
+	}
+
+	// the typed Info turns private into a bool, so check the raw value
+	var raw map[string]bencode.Bytes
+	if err := bencode.Unmarshal(mi.InfoBytes, &raw); err == nil {
+		if value, ok := raw["private"]; ok {
+			var private int64
+			if err := bencode.Unmarshal(value, &private); err != nil || (private != 0 && private != 1) {
+				fail("private", "private is %s, want 0 or 1", value)
+			}
+		}
+	}
+
+	return errs
+}
+
+// validationError joins errs into one error, or returns nil when there are none.
+func validationError(errs []ValidationError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	joined := make([]error, len(errs))
+	for i, err := range errs {
+		joined[i] = err
+	}
+	return errors.Join(joined...)
+}
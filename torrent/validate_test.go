@@ -0,0 +1,122 @@
+package torrent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anacrolix/torrent/bencode"
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+func TestValidateTorrent(t *testing.T) {
+	valid := func() (*metainfo.MetaInfo, *metainfo.Info) {
+		info := &metainfo.Info{
+			Name:        "Show.S01",
+			PieceLength: 1 << 16,
+			Pieces:      make([]byte, 3*pieceHashSize),
+			Files: []metainfo.FileInfo{
+				{Path: []string{"Show.S01E01.mkv"}, Length: 1 << 16},
+				{Path: []string{"Extras", "sample.mkv"}, Length: 1<<16 + 100},
+			},
+		}
+		return &metainfo.MetaInfo{InfoBytes: bencode.MustMarshal(info)}, info
+	}
+
+	tests := []struct {
+		name      string
+		tamper    func(mi *metainfo.MetaInfo, info *metainfo.Info)
+		wantCheck string
+	}{
+		{name: "valid"},
+		{name: "single file", tamper: func(mi *metainfo.MetaInfo, info *metainfo.Info) {
+			info.Files = nil
+			info.Length = 2*(1<<16) + 1
+		}},
+		{name: "too few pieces", tamper: func(mi *metainfo.MetaInfo, info *metainfo.Info) {
+			info.Pieces = info.Pieces[:2*pieceHashSize]
+		}, wantCheck: "piece count"},
+		{name: "file lengths exceed pieces", tamper: func(mi *metainfo.MetaInfo, info *metainfo.Info) {
+			info.Files[0].Length += 1 << 20
+		}, wantCheck: "piece count"},
+		{name: "partial piece hash", tamper: func(mi *metainfo.MetaInfo, info *metainfo.Info) {
+			info.Pieces = append(info.Pieces, 0)
+		}, wantCheck: "pieces"},
+		{name: "zero piece length", tamper: func(mi *metainfo.MetaInfo, info *metainfo.Info) {
+			info.PieceLength = 0
+		}, wantCheck: "piece length"},
+		{name: "negative file length", tamper: func(mi *metainfo.MetaInfo, info *metainfo.Info) {
+			info.Files = append(info.Files, metainfo.FileInfo{Path: []string{"x"}, Length: -1})
+		}, wantCheck: "file length"},
+		{name: "absolute path", tamper: func(mi *metainfo.MetaInfo, info *metainfo.Info) {
+			info.Files[0].Path = []string{"/etc", "passwd"}
+		}, wantCheck: "file path"},
+		{name: "parent component", tamper: func(mi *metainfo.MetaInfo, info *metainfo.Info) {
+			info.Files[1].Path = []string{"..", "sample.mkv"}
+		}, wantCheck: "file path"},
+		{name: "parent with backslash", tamper: func(mi *metainfo.MetaInfo, info *metainfo.Info) {
+			info.Files[1].Path = []string{`Extras\..\..\sample.mkv`}
+		}, wantCheck: "file path"},
+		{name: "empty component", tamper: func(mi *metainfo.MetaInfo, info *metainfo.Info) {
+			info.Files[1].Path = []string{"Extras", "", "sample.mkv"}
+		}, wantCheck: "file path"},
+		{name: "name with separator", tamper: func(mi *metainfo.MetaInfo, info *metainfo.Info) {
+			info.Name = "../Show.S01"
+		}, wantCheck: "name"},
+		{name: "invalid private", tamper: func(mi *metainfo.MetaInfo, info *metainfo.Info) {
+			raw := map[string]any{"name": info.Name, "piece length": info.PieceLength, "pieces": string(info.Pieces), "length": 1, "private": 2}
+			mi.InfoBytes = bencode.MustMarshal(raw)
+		}, wantCheck: "private"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mi, info := valid()
+			if tt.tamper != nil {
+				tt.tamper(mi, info)
+			}
+
+			errs := ValidateTorrent(mi, info)
+			if tt.wantCheck == "" {
+				if len(errs) != 0 {
+					t.Errorf("ValidateTorrent = %v, want no errors", errs)
+				}
+				return
+			}
+			found := false
+			for _, err := range errs {
+				found = found || err.Check == tt.wantCheck
+			}
+			if !found {
+				t.Errorf("ValidateTorrent = %v, want a %q error", errs, tt.wantCheck)
+			}
+		})
+	}
+}
+
+func TestModifyTorrent_RejectsInvalidTorrent(t *testing.T) {
+	dir := t.TempDir()
+	info := metainfo.Info{
+		Name:        "content.bin",
+		PieceLength: 1 << 16,
+		Pieces:      make([]byte, pieceHashSize), // two pieces needed
+		Length:      1<<16 + 1,
+	}
+	mi := metainfo.MetaInfo{InfoBytes: bencode.MustMarshal(info)}
+
+	torrentPath := filepath.Join(dir, "invalid.torrent")
+	f, err := os.Create(torrentPath)
+	if err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+	if err := mi.Write(f); err != nil {
+		t.Fatalf("failed to write torrent: %v", err)
+	}
+	f.Close()
+
+	_, err = ModifyTorrent(torrentPath, ModifyOptions{OutputDir: dir, Comment: "new", CommentSet: true, Quiet: true})
+	if err == nil || !strings.Contains(err.Error(), "piece count") {
+		t.Fatalf("ModifyTorrent = %v, want a piece count error", err)
+	}
+}
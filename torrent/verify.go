@@ -2,10 +2,12 @@ package torrent
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha1"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -23,22 +25,68 @@ type VerifyOptions struct {
 	Verbose          bool
 	Quiet            bool
 	Workers          int              // Number of worker goroutines for verification
-	ProgressCallback ProgressCallback // Optional callback for progress updates
+	ProgressCallback ProgressCallback // Optional callback for progress updates, replaces the built-in progress display
+	// MaxReadBytesPerSecond limits the combined read rate of all workers; 0 disables throttling
+	MaxReadBytesPerSecond int64
+	// Logger receives diagnostic messages, the logger set with SetDefaultLogger if nil
+	Logger Logger
+	// NoProgress prints progress as periodic lines instead of a progress bar
+	NoProgress bool
+	// PiecesPath verifies against a piece export from WritePiecesExport
+	// instead of the torrent at TorrentPath
+	PiecesPath string
+	// RestoreAttrs sets the execute bits of verified files the torrent
+	// marks executable with the BEP 47 "x" attribute
+	RestoreAttrs bool
+	// FuzzyPathMatch verifies the closest match to the torrent name in the
+	// parent directory of ContentPath when ContentPath does not exist, such
+	// as a folder renamed with a -FIXED suffix. Names within an edit distance
+	// of 2 are accepted.
+	FuzzyPathMatch bool
+	// IgnoreName verifies a single-file torrent against the one file in the
+	// ContentPath directory with the size of the torrent when no file there
+	// has the torrent name, for downloads renamed after the fact. A file
+	// given as ContentPath is verified whatever its name either way.
+	IgnoreName bool
+	// FindExtraFiles reports the files under the content directory of a
+	// multi-file torrent that are not in the torrent, such as leftovers of an
+	// earlier version of a release, in VerificationResult.ExtraFiles. The
+	// torrent file is not reported when it sits inside the content directory.
+	// Single-file torrents have no content directory of their own, so
+	// nothing is reported for them.
+	FindExtraFiles bool
+}
+
+// normalizePathComponent replaces backslashes in a path component of a torrent
+// with forward slashes. Some Windows tools write dir\file as a single
+// component instead of two, which filepath.ToSlash leaves alone on other OSes.
+func normalizePathComponent(s string) string {
+	return strings.ReplaceAll(s, `\`, "/")
+}
+
+// torrentFilePath returns the slash-separated relative path of a file in a
+// torrent, the same form the content walk produces.
+func torrentFilePath(components []string) string {
+	normalized := make([]string, len(components))
+	for i, c := range components {
+		normalized[i] = normalizePathComponent(c)
+	}
+	return path.Clean(strings.Join(normalized, "/"))
 }
 
 type pieceVerifier struct {
 	startTime   time.Time
 	lastUpdate  time.Time
 	torrentInfo *metainfo.Info
-	display     *Display // Changed to concrete type
+	display     Displayer // callbackDisplayer when a progress callback is set
 	bufferPool  *sync.Pool
 	contentPath string
 	files       []fileEntry // Mapped files based on contentPath
 
-	badPieceIndices  []int
-	missingFiles     []string
-	missingRanges    [][2]int64       // Byte ranges [start, end) of missing/mismatched files
-	progressCallback ProgressCallback // Optional callback for progress updates
+	badPieceIndices []int
+	missingFiles    []string
+	missingRanges   [][2]int64    // Byte ranges [start, end) of missing/mismatched files
+	throttle        *readThrottle // limits the read rate across workers, nil for no limit
 
 	pieceLen  int64
 	numPieces int
@@ -49,6 +97,7 @@ type pieceVerifier struct {
 	missingPieces uint64 // Pieces belonging to missing files
 
 	bytesVerified int64
+	fileReopens   int64 // files reopened after being evicted from a worker's reader cache
 	mutex         sync.RWMutex
 }
 
@@ -56,34 +105,68 @@ type pieceVerifier struct {
 // It compares the actual file data against the piece hashes in the torrent.
 // Returns detailed verification results including bad pieces and missing files.
 func VerifyData(opts VerifyOptions) (*VerificationResult, error) {
-	mi, err := metainfo.LoadFromFile(opts.TorrentPath)
-	if err != nil {
-		return nil, fmt.Errorf("could not load torrent file %q: %w", opts.TorrentPath, err)
-	}
-
-	info, err := mi.UnmarshalInfo()
+	info, source, err := loadVerifyInfo(opts)
 	if err != nil {
-		return nil, fmt.Errorf("could not unmarshal info dictionary from %q: %w", opts.TorrentPath, err)
+		return nil, err
 	}
 
+	logger := resolveLogger(opts.Logger)
 	mappedFiles := make([]fileEntry, 0)
 	var totalSize int64
 	var missingFiles []string
+	var extraFiles []ExtraFile
 	baseContentPath := filepath.Clean(opts.ContentPath)
+	if opts.FuzzyPathMatch {
+		if _, err := os.Stat(longPath(baseContentPath)); os.IsNotExist(err) {
+			match, dist, err := resolveFuzzyContentPath(baseContentPath, &info)
+			if err != nil {
+				return nil, err
+			}
+			logger.Warn("content path not found, verifying the closest match to the torrent name instead",
+				"path", baseContentPath, "match", match, "distance", dist)
+			baseContentPath = match
+		}
+	}
 
 	if info.IsDir() {
 		// Multi-file torrent
 		expectedFiles := make(map[string]int64) // Map relative path (using '/') to expected size
+		torrentPaths := make(map[string]bool)   // every path in the torrent, padding included
+		var backslashPaths int
 		for _, f := range info.Files {
 			// Ensure the key uses forward slashes, consistent with torrent format
-			relPathKey := filepath.ToSlash(filepath.Join(f.Path...))
+			relPathKey := torrentFilePath(f.Path)
+			torrentPaths[relPathKey] = true
+			if isPadFile(f) {
+				// padding is read as zeros, whether or not a client wrote it to disk
+				mappedFiles = append(mappedFiles, fileEntry{
+					path:   filepath.Join(baseContentPath, filepath.FromSlash(relPathKey)),
+					length: f.Length,
+					pad:    true,
+				})
+				totalSize += f.Length
+				continue
+			}
+			if strings.Contains(strings.Join(f.Path, ""), `\`) {
+				backslashPaths++
+			}
 			expectedFiles[relPathKey] = f.Length
 		}
+		if backslashPaths > 0 {
+			logger.Warn("torrent file paths contain backslash separators, treating them as directory separators",
+				"torrent", source, "files", backslashPaths)
+		}
+
+		// the torrent file is not extraneous when it is kept next to the content
+		var torrentFileAbs string
+		if opts.FindExtraFiles && opts.TorrentPath != "" {
+			torrentFileAbs, _ = filepath.Abs(opts.TorrentPath)
+		}
 
 		// Walk the content directory provided by the user
-		err = filepath.Walk(baseContentPath, func(currentPath string, fileInfo os.FileInfo, walkErr error) error {
+		err = walkLong(baseContentPath, func(currentPath string, fileInfo os.FileInfo, walkErr error) error {
 			if walkErr != nil {
-				fmt.Fprintf(os.Stderr, "Warning: error walking path %q: %v\n", currentPath, walkErr)
+				logger.Warn("error walking path, skipping", "path", currentPath, "error", walkErr)
 				return nil
 			}
 			if fileInfo.IsDir() {
@@ -113,6 +196,14 @@ func VerifyData(opts VerifyOptions) (*VerificationResult, error) {
 				})
 				totalSize += fileInfo.Size()
 				delete(expectedFiles, relPath)
+				return nil
+			}
+
+			if opts.FindExtraFiles && !torrentPaths[relPath] {
+				if abs, err := filepath.Abs(currentPath); err == nil && abs == torrentFileAbs {
+					return nil
+				}
+				extraFiles = append(extraFiles, ExtraFile{Path: relPath, Size: fileInfo.Size()})
 			}
 			return nil
 		})
@@ -127,7 +218,7 @@ func VerifyData(opts VerifyOptions) (*VerificationResult, error) {
 
 	} else {
 		// Single-file torrent
-		contentFileInfo, err := os.Stat(baseContentPath)
+		contentFileInfo, err := os.Stat(longPath(baseContentPath))
 		if err != nil {
 			if os.IsNotExist(err) {
 				missingFiles = append(missingFiles, info.Name)
@@ -137,7 +228,18 @@ func VerifyData(opts VerifyOptions) (*VerificationResult, error) {
 		} else {
 			if contentFileInfo.IsDir() {
 				filePathInDir := filepath.Join(baseContentPath, info.Name)
-				contentFileInfo, err = os.Stat(filePathInDir)
+				if _, err := os.Stat(longPath(filePathInDir)); opts.IgnoreName && os.IsNotExist(err) {
+					match, err := findSameSizeFile(baseContentPath, info.Length)
+					if err != nil {
+						return nil, err
+					}
+					if match != "" {
+						logger.Warn("content file not found under the torrent name, verifying the file of the same size instead",
+							"name", info.Name, "file", match)
+						filePathInDir = match
+					}
+				}
+				contentFileInfo, err = os.Stat(longPath(filePathInDir))
 				if err != nil {
 					if os.IsNotExist(err) {
 						missingFiles = append(missingFiles, info.Name)
@@ -175,7 +277,7 @@ func VerifyData(opts VerifyOptions) (*VerificationResult, error) {
 	if info.IsDir() && len(info.Files) > 0 && len(mappedFiles) > 1 {
 		originalOrder := make(map[string]int)
 		for i, f := range info.Files {
-			originalOrder[filepath.ToSlash(filepath.Join(f.Path...))] = i
+			originalOrder[torrentFilePath(f.Path)] = i
 		}
 		sort.SliceStable(mappedFiles, func(i, j int) bool {
 			relPathI, _ := filepath.Rel(baseContentPath, mappedFiles[i].path)
@@ -190,7 +292,7 @@ func VerifyData(opts VerifyOptions) (*VerificationResult, error) {
 		torrentOffsets := make(map[string]int64)
 		currentOffset := int64(0)
 		for _, f := range info.Files {
-			relPath := filepath.ToSlash(filepath.Join(f.Path...))
+			relPath := torrentFilePath(f.Path)
 			torrentOffsets[relPath] = currentOffset
 			currentOffset += f.Length
 		}
@@ -206,17 +308,27 @@ func VerifyData(opts VerifyOptions) (*VerificationResult, error) {
 
 	// 4. Initialize Verifier
 	numPieces := len(info.Pieces) / 20
+	var display Displayer
+	var defaultDisplay *Display
+	if opts.ProgressCallback != nil {
+		// the callback replaces the built-in progress output
+		display = &callbackDisplayer{callback: opts.ProgressCallback}
+	} else {
+		defaultDisplay = NewDisplay(NewFormatter(opts.Verbose))
+		defaultDisplay.SetQuiet(opts.Quiet)
+		defaultDisplay.SetPlainProgress(opts.NoProgress)
+		display = defaultDisplay
+	}
 	verifier := &pieceVerifier{
-		torrentInfo:      &info,
-		contentPath:      opts.ContentPath,
-		pieceLen:         info.PieceLength,
-		numPieces:        numPieces,
-		files:            mappedFiles,
-		display:          NewDisplay(NewFormatter(opts.Verbose)),
-		missingFiles:     missingFiles,
-		progressCallback: opts.ProgressCallback,
+		torrentInfo:  &info,
+		contentPath:  baseContentPath,
+		pieceLen:     info.PieceLength,
+		numPieces:    numPieces,
+		files:        mappedFiles,
+		display:      display,
+		missingFiles: missingFiles,
+		throttle:     newReadThrottle(opts.MaxReadBytesPerSecond),
 	}
-	verifier.display.SetQuiet(opts.Quiet)
 
 	// Calculate missing ranges *before* verification starts
 	if len(verifier.missingFiles) > 0 {
@@ -229,7 +341,7 @@ func VerifyData(opts VerifyOptions) (*VerificationResult, error) {
 		currentOffset := int64(0)
 		if info.IsDir() {
 			for _, f := range info.Files {
-				relPath := filepath.ToSlash(filepath.Join(f.Path...))
+				relPath := torrentFilePath(f.Path)
 				fileEndOffset := currentOffset + f.Length
 				if missingFileSet[relPath] {
 					verifier.missingRanges = append(verifier.missingRanges, [2]int64{currentOffset, fileEndOffset})
@@ -248,6 +360,19 @@ func VerifyData(opts VerifyOptions) (*VerificationResult, error) {
 		return nil, fmt.Errorf("verification failed: %w", err)
 	}
 
+	if defaultDisplay != nil && opts.Verbose && verifier.fileReopens > 0 {
+		defaultDisplay.ShowMessage(fmt.Sprintf("reopened files %d times to stay within %d open files per worker",
+			verifier.fileReopens, maxOpenFilesPerWorker))
+	}
+
+	var restoredAttrs []string
+	if opts.RestoreAttrs {
+		restoredAttrs, err = verifier.restoreExecutableAttrs(baseContentPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// 6. Compile and Return Results
 	result := &VerificationResult{
 		TotalPieces:     verifier.numPieces,
@@ -257,6 +382,10 @@ func VerifyData(opts VerifyOptions) (*VerificationResult, error) {
 		Completion:      0.0,                         // Will be calculated below
 		BadPieceIndices: verifier.badPieceIndices,
 		MissingFiles:    verifier.missingFiles,
+		RestoredAttrs:   restoredAttrs,
+		ContentPath:     baseContentPath,
+		ExtraFiles:      extraFiles,
+		Files:           verifier.fileResults(),
 	}
 
 	// Final calculation of completion percentage based on pieces that could be checked
@@ -275,6 +404,107 @@ func VerifyData(opts VerifyOptions) (*VerificationResult, error) {
 	return result, nil
 }
 
+// badPieceSet returns the indices of the pieces that did not match as a set.
+func (v *pieceVerifier) badPieceSet() map[int]bool {
+	bad := make(map[int]bool, len(v.badPieceIndices))
+	for _, idx := range v.badPieceIndices {
+		bad[idx] = true
+	}
+	return bad
+}
+
+// fileStatus returns the status of the length bytes of a file at offset in
+// the torrent, bad being the indices of the pieces that did not match, and
+// the number of bad pieces holding its data. A file is unverified when one
+// of its pieces overlaps a missing file and could not be hashed.
+func (v *pieceVerifier) fileStatus(offset, length int64, bad map[int]bool) (string, int) {
+	if length == 0 {
+		return FileStatusOK, 0
+	}
+	badPieces := 0
+	unverified := false
+	end := offset + length
+	for idx := offset / v.pieceLen; idx <= (end-1)/v.pieceLen; idx++ {
+		pieceOffset := idx * v.pieceLen
+		for _, r := range v.missingRanges {
+			if pieceOffset < r[1] && pieceOffset+v.pieceLen > r[0] {
+				unverified = true
+			}
+		}
+		if bad[int(idx)] {
+			badPieces++
+		}
+	}
+	switch {
+	case badPieces > 0:
+		return FileStatusBad, badPieces
+	case unverified:
+		return FileStatusUnverified, 0
+	default:
+		return FileStatusOK, 0
+	}
+}
+
+// fileResults returns the status of each file of the torrent, in torrent order.
+func (v *pieceVerifier) fileResults() []FileVerification {
+	missing := make(map[string]string, len(v.missingFiles))
+	for _, mf := range v.missingFiles {
+		if path, ok := strings.CutSuffix(mf, " (size mismatch)"); ok {
+			missing[path] = FileStatusSizeMismatch
+		} else {
+			missing[mf] = FileStatusMissing
+		}
+	}
+
+	bad := v.badPieceSet()
+	files := v.torrentInfo.UpvertedFiles()
+	results := make([]FileVerification, 0, len(files))
+	var offset int64
+	for _, f := range files {
+		if isPadFile(f) {
+			offset += f.Length
+			continue
+		}
+		path := v.torrentInfo.Name
+		if v.torrentInfo.IsDir() {
+			path = torrentFilePath(f.Path)
+		}
+		result := FileVerification{Path: path, Size: f.Length}
+		if status, ok := missing[path]; ok {
+			result.Status = status
+		} else {
+			result.Status, result.BadPieces = v.fileStatus(offset, f.Length, bad)
+		}
+		results = append(results, result)
+		offset += f.Length
+	}
+	return results
+}
+
+// loadVerifyInfo returns the info dictionary to verify against, read from the
+// piece export at opts.PiecesPath or else the torrent at opts.TorrentPath, and
+// the path it was read from.
+func loadVerifyInfo(opts VerifyOptions) (metainfo.Info, string, error) {
+	if opts.PiecesPath != "" {
+		info, err := LoadPiecesExport(opts.PiecesPath)
+		if err != nil {
+			return metainfo.Info{}, "", err
+		}
+		return *info, opts.PiecesPath, nil
+	}
+
+	mi, err := metainfo.LoadFromFile(opts.TorrentPath)
+	if err != nil {
+		return metainfo.Info{}, "", fmt.Errorf("could not load torrent file %q: %w", opts.TorrentPath, err)
+	}
+
+	info, err := mi.UnmarshalInfo()
+	if err != nil {
+		return metainfo.Info{}, "", fmt.Errorf("could not unmarshal info dictionary from %q: %w", opts.TorrentPath, err)
+	}
+	return info, opts.TorrentPath, nil
+}
+
 // optimizeForWorkload determines optimal read buffer size and number of worker goroutines
 func (v *pieceVerifier) optimizeForWorkload() (int, int) {
 	if len(v.files) == 0 {
@@ -372,14 +602,14 @@ func (v *pieceVerifier) verifyPieces(numWorkersOverride int) error {
 	v.startTime = time.Now()
 	v.bytesVerified = 0
 
-	v.display.ShowFiles(v.files, numWorkers)
+	v.display.ShowFiles(contentFileEntries(v.files), numWorkers)
 
 	var completedPieces uint64
 	piecesPerWorker := (v.numPieces + numWorkers - 1) / numWorkers
 	errorsCh := make(chan error, numWorkers)
 	done := make(chan struct{}) // Signal channel to stop progress monitoring
 
-	v.display.ShowProgress(v.numPieces) // Show progress bar only if numPieces > 0
+	v.display.ShowProgress(v.numPieces, v.torrentInfo.TotalLength()) // Show progress bar only if numPieces > 0
 
 	var wg sync.WaitGroup
 
@@ -437,30 +667,14 @@ func (v *pieceVerifier) verifyPieces(numWorkersOverride int) error {
 					rate = float64(bytesVerified) / elapsed
 				}
 				// Pass total completed count and rate to UpdateProgress
-				v.display.UpdateProgress(int(completed), rate)
-
-				// Call progress callback if provided
-				if v.progressCallback != nil {
-					v.progressCallback(int(completed), v.numPieces, rate/(1024*1024)) // Convert to MiB/s
-				}
+				v.display.UpdateProgress(int(completed), rate, "", 0)
 			}
 		}
 	}()
 
 	wg.Wait()
 	close(done)   // Signal progress goroutine to stop
-	<-monitorDone // Ensure the progress monitoring has fully exited before the final callback
-	// Emit one final progress update so consumers observe 100% completion.
-	if v.progressCallback != nil {
-		v.mutex.RLock()
-		elapsed := time.Since(v.startTime).Seconds()
-		v.mutex.RUnlock()
-		var rate float64
-		if elapsed > 0 {
-			rate = float64(atomic.LoadInt64(&v.bytesVerified)) / elapsed
-		}
-		v.progressCallback(v.numPieces, v.numPieces, rate/(1024*1024)) // Shows 100% completion, convert to MiB/s
-	}
+	<-monitorDone // Ensure the progress monitoring has fully exited before FinishProgress
 	close(errorsCh)
 
 	for err := range errorsCh {
@@ -480,13 +694,10 @@ func (v *pieceVerifier) verifyPieceRange(startPiece, endPiece int, completedPiec
 	defer v.bufferPool.Put(buf)
 
 	hasher := sha1.New()
-	readers := make([]*fileReader, len(v.files))
+	readers := newReaderCache(maxOpenFilesPerWorker)
 	defer func() {
-		for _, r := range readers {
-			if r != nil && r.file != nil {
-				r.file.Close()
-			}
-		}
+		readers.closeAll()
+		atomic.AddInt64(&v.fileReopens, readers.reopens)
 	}()
 
 	currentFileIndex := 0
@@ -556,19 +767,14 @@ func (v *pieceVerifier) verifyPieceRange(startPiece, endPiece int, completedPiec
 				continue
 			}
 
-			reader := readers[fIdx]
-			if reader == nil {
-				f, err := os.OpenFile(file.path, os.O_RDONLY, 0)
-				if err != nil {
-					// File became unreadable after initial check? Mark as bad.
-					atomic.AddUint64(&v.badPieces, 1)
-					v.mutex.Lock()
-					v.badPieceIndices = append(v.badPieceIndices, pieceIndex)
-					v.mutex.Unlock()
-					goto nextPiece // Use goto to ensure completedPieces is incremented
-				}
-				reader = &fileReader{file: f, position: -1, length: file.length}
-				readers[fIdx] = reader
+			reader, err := readers.get(fIdx, file)
+			if err != nil {
+				// File became unreadable after initial check? Mark as bad.
+				atomic.AddUint64(&v.badPieces, 1)
+				v.mutex.Lock()
+				v.badPieceIndices = append(v.badPieceIndices, pieceIndex)
+				v.mutex.Unlock()
+				goto nextPiece // Use goto to ensure completedPieces is incremented
 			}
 
 			if reader.position != readStartInFile {
@@ -597,6 +803,8 @@ func (v *pieceVerifier) verifyPieceRange(startPiece, endPiece int, completedPiec
 					v.mutex.Unlock()
 					goto nextPiece
 				}
+				// verification cannot be cancelled, so waiting never fails
+				_ = v.throttle.wait(context.Background(), n)
 				if n == 0 && err == io.EOF {
 					break
 				}
@@ -630,3 +838,96 @@ func (v *pieceVerifier) verifyPieceRange(startPiece, endPiece int, completedPiec
 
 	return nil
 }
+
+// findSameSizeFile returns the file directly in dir of the given size, or ""
+// when there is none. It is an error for more than one file to match.
+func findSameSizeFile(dir string, size int64) (string, error) {
+	entries, err := os.ReadDir(longPath(dir))
+	if err != nil {
+		return "", fmt.Errorf("could not read content directory %q: %w", dir, err)
+	}
+
+	var candidates []string
+	for _, entry := range entries {
+		entryPath := filepath.Join(dir, entry.Name())
+		// stat instead of entry.Info so symlinked files are followed
+		entryInfo, err := os.Stat(longPath(entryPath))
+		if err != nil || !entryInfo.Mode().IsRegular() || entryInfo.Size() != size {
+			continue
+		}
+		candidates = append(candidates, entryPath)
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", nil
+	case 1:
+		return candidates[0], nil
+	default:
+		return "", fmt.Errorf("multiple files of %d bytes found in %q: %s", size, dir, strings.Join(candidates, ", "))
+	}
+}
+
+// ResolveContentPath looks inside dir for the content of the torrent at torrentPath.
+// Entries are matched against the torrent name, first exactly and then case-insensitively
+// ignoring trailing dots (a file may also match without its extension). Only directories
+// are considered for multi-file torrents and only files for single-file torrents.
+// If dir is not a directory it is returned unchanged. An error listing the candidates
+// is returned when the match is ambiguous.
+func ResolveContentPath(torrentPath, dir string) (string, error) {
+	dirInfo, err := os.Stat(dir)
+	if err != nil {
+		return "", fmt.Errorf("invalid content path %q: %w", dir, err)
+	}
+	if !dirInfo.IsDir() {
+		return dir, nil
+	}
+
+	mi, err := metainfo.LoadFromFile(torrentPath)
+	if err != nil {
+		return "", fmt.Errorf("could not load torrent file %q: %w", torrentPath, err)
+	}
+	info, err := mi.UnmarshalInfo()
+	if err != nil {
+		return "", fmt.Errorf("could not unmarshal info dictionary from %q: %w", torrentPath, err)
+	}
+
+	exact := filepath.Join(dir, info.Name)
+	if entryInfo, err := os.Stat(exact); err == nil && entryInfo.IsDir() == info.IsDir() {
+		return exact, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("could not read content directory %q: %w", dir, err)
+	}
+
+	normalize := func(name string) string {
+		return strings.ToLower(strings.TrimRight(name, "."))
+	}
+	want := normalize(info.Name)
+
+	var candidates []string
+	for _, entry := range entries {
+		entryPath := filepath.Join(dir, entry.Name())
+		// stat instead of entry.IsDir so symlinked content is followed
+		entryInfo, err := os.Stat(entryPath)
+		if err != nil || entryInfo.IsDir() != info.IsDir() {
+			continue
+		}
+
+		name := normalize(entry.Name())
+		if name == want || (!entryInfo.IsDir() && normalize(strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))) == want) {
+			candidates = append(candidates, entryPath)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("no content matching torrent name %q found in %q", info.Name, dir)
+	case 1:
+		return candidates[0], nil
+	default:
+		return "", fmt.Errorf("multiple candidates for torrent name %q found in %q: %s", info.Name, dir, strings.Join(candidates, ", "))
+	}
+}
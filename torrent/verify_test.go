@@ -1,13 +1,19 @@
 package torrent
 
 import (
+	"bytes"
 	"crypto/sha1"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
 	"testing"
+
+	"github.com/anacrolix/torrent/bencode"
 )
 
 // Reusing the helper from hasher_test.go to create test files efficiently.
@@ -298,6 +304,108 @@ func TestVerifyData_PerfectMatch_MultiFile(t *testing.T) {
 	}
 }
 
+// writeBackslashTorrent creates content in a Release folder and a torrent
+// for it whose file paths use a backslash inside a single path component,
+// the way some Windows tools write them. It returns the content directory
+// and torrent path.
+func writeBackslashTorrent(t *testing.T) (string, string) {
+	t.Helper()
+
+	contentDir := filepath.Join(t.TempDir(), "Release")
+	for name, data := range map[string][]byte{
+		"a.bin":          bytes.Repeat([]byte{1}, 100<<10),
+		"subdir/b.bin":   bytes.Repeat([]byte{2}, 70<<10),
+		"subdir/c/d.bin": bytes.Repeat([]byte{3}, 30<<10),
+	} {
+		path := filepath.Join(contentDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	pieceLenExp := uint(16)
+	tor, err := CreateTorrent(CreateOptions{Path: contentDir, PieceLengthExp: &pieceLenExp, Quiet: true})
+	if err != nil {
+		t.Fatalf("CreateTorrent failed: %v", err)
+	}
+
+	// rewrite the paths the way some Windows tools do, with a backslash
+	// inside a single path component
+	info := tor.GetInfo()
+	info.Files[1].Path = []string{`subdir\b.bin`}
+	info.Files[2].Path = []string{"subdir", `c\d.bin`}
+	if tor.InfoBytes, err = bencode.Marshal(info); err != nil {
+		t.Fatalf("failed to encode info: %v", err)
+	}
+	torrentPath := filepath.Join(t.TempDir(), "backslash.torrent")
+	f, err := os.Create(torrentPath)
+	if err != nil {
+		t.Fatalf("failed to create torrent file: %v", err)
+	}
+	if err := tor.Write(f); err != nil {
+		t.Fatalf("failed to write torrent: %v", err)
+	}
+	f.Close()
+	return contentDir, torrentPath
+}
+
+func TestVerifyData_BackslashInPathComponent(t *testing.T) {
+	contentDir, torrentPath := writeBackslashTorrent(t)
+
+	var logs bytes.Buffer
+	result, err := VerifyData(VerifyOptions{
+		TorrentPath: torrentPath,
+		ContentPath: contentDir,
+		Quiet:       true,
+		Logger:      slog.New(NewLogHandler(&logs, slog.LevelWarn, false)),
+	})
+	if err != nil {
+		t.Fatalf("VerifyData failed: %v", err)
+	}
+	if result.Completion != 100.0 || len(result.MissingFiles) != 0 {
+		t.Errorf("got %.2f%% with missing files %v, want 100%% with none", result.Completion, result.MissingFiles)
+	}
+	if !strings.Contains(logs.String(), "backslash separators") || !strings.Contains(logs.String(), "files=2") {
+		t.Errorf("expected a warning about 2 files with backslash separators, got %q", logs.String())
+	}
+}
+
+func TestVerifyData_BackslashInPathComponent_MissingFile(t *testing.T) {
+	contentDir, torrentPath := writeBackslashTorrent(t)
+	if err := os.Remove(filepath.Join(contentDir, "subdir", "b.bin")); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+
+	result, err := VerifyData(VerifyOptions{TorrentPath: torrentPath, ContentPath: contentDir, Quiet: true})
+	if err != nil {
+		t.Fatalf("VerifyData failed: %v", err)
+	}
+	if len(result.MissingFiles) != 1 || result.MissingFiles[0] != "subdir/b.bin" {
+		t.Errorf("MissingFiles = %v, want [subdir/b.bin]", result.MissingFiles)
+	}
+	if result.BadPieces != 0 || result.MissingPieces == 0 {
+		t.Errorf("expected the pieces of the deleted file to be missing rather than bad, got %d bad and %d missing",
+			result.BadPieces, result.MissingPieces)
+	}
+	for _, file := range result.Files {
+		if file.Path == "subdir/b.bin" && file.Status != FileStatusMissing {
+			t.Errorf("status of subdir/b.bin = %q, want %q", file.Status, FileStatusMissing)
+		}
+	}
+}
+
+func TestNormalizePathComponent(t *testing.T) {
+	if got := normalizePathComponent(`a\b\c.txt`); got != "a/b/c.txt" {
+		t.Errorf("normalizePathComponent() = %q, want %q", got, "a/b/c.txt")
+	}
+	if got := torrentFilePath([]string{"dir", `sub\file.txt`}); got != "dir/sub/file.txt" {
+		t.Errorf("torrentFilePath() = %q, want %q", got, "dir/sub/file.txt")
+	}
+}
+
 func TestVerifyData_CorruptedData(t *testing.T) {
 	numFiles := 3
 	fileSize := int64(1 * 1024 * 1024) // 1 MiB per file
@@ -452,6 +560,47 @@ func TestVerifyData_MissingFile(t *testing.T) {
 		result.GoodPieces, result.TotalPieces, result.BadPieces, result.MissingPieces, len(result.MissingFiles), result.Completion)
 }
 
+func TestVerifyData_ProgressCallback(t *testing.T) {
+	pieceLenExp := uint(16)
+	contentDir, files, _ := createTestFilesFastForVerify(t, 3, 256<<10, 1<<pieceLenExp)
+	tempDir := filepath.Dir(contentDir)
+	t.Cleanup(func() { os.RemoveAll(tempDir) })
+
+	torrentPath := filepath.Join(tempDir, "progress.torrent")
+	if _, err := Create(CreateOptions{Path: contentDir, OutputPath: torrentPath, PieceLengthExp: &pieceLenExp, Quiet: true}); err != nil {
+		t.Fatalf("Failed to create test torrent file: %v", err)
+	}
+	if err := os.Remove(files[1].path); err != nil {
+		t.Fatalf("Failed to delete test file: %v", err)
+	}
+
+	var mu sync.Mutex
+	var updates [][2]int
+	result, err := VerifyData(VerifyOptions{
+		TorrentPath: torrentPath,
+		ContentPath: contentDir,
+		ProgressCallback: func(completed, total int, _ float64) {
+			mu.Lock()
+			defer mu.Unlock()
+			updates = append(updates, [2]int{completed, total})
+		},
+	})
+	if err != nil {
+		t.Fatalf("VerifyData failed: %v", err)
+	}
+	if len(result.MissingFiles) != 1 {
+		t.Fatalf("Expected 1 missing file, got %v", result.MissingFiles)
+	}
+
+	if len(updates) == 0 {
+		t.Fatal("Expected progress updates")
+	}
+	last := updates[len(updates)-1]
+	if last[0] != result.TotalPieces || last[1] != result.TotalPieces {
+		t.Errorf("Final update = %d/%d, want %d/%d", last[0], last[1], result.TotalPieces, result.TotalPieces)
+	}
+}
+
 func TestVerifyData_SizeMismatch(t *testing.T) {
 	numFiles := 3
 	fileSize := int64(1 * 1024 * 1024) // 1 MiB per file
@@ -858,3 +1007,276 @@ func TestVerifyData_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveContentPath(t *testing.T) {
+	workspace := t.TempDir()
+	downloads := filepath.Join(workspace, "downloads")
+
+	contentDir := filepath.Join(workspace, "src", "TestContent")
+	if err := os.MkdirAll(contentDir, 0755); err != nil {
+		t.Fatalf("failed to create content dir: %v", err)
+	}
+	for _, name := range []string{"a.mkv", "b.nfo"} {
+		if err := os.WriteFile(filepath.Join(contentDir, name), []byte("content "+name), 0644); err != nil {
+			t.Fatalf("failed to write content file: %v", err)
+		}
+	}
+
+	torrentPath := filepath.Join(workspace, "TestContent.torrent")
+	if _, err := Create(CreateOptions{Path: contentDir, OutputPath: torrentPath, NoDate: true, Quiet: true}); err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+
+	// place the content in downloads alongside unrelated entries
+	if err := os.MkdirAll(downloads, 0755); err != nil {
+		t.Fatalf("failed to create downloads dir: %v", err)
+	}
+	if err := os.Rename(contentDir, filepath.Join(downloads, "TestContent")); err != nil {
+		t.Fatalf("failed to move content: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(downloads, "OtherContent"), 0755); err != nil {
+		t.Fatalf("failed to create unrelated dir: %v", err)
+	}
+	// a file with the torrent name is ignored for a multi-file torrent
+	if err := os.WriteFile(filepath.Join(downloads, "TestContent.nfo"), []byte("nfo"), 0644); err != nil {
+		t.Fatalf("failed to write unrelated file: %v", err)
+	}
+
+	got, err := ResolveContentPath(torrentPath, downloads)
+	if err != nil {
+		t.Fatalf("ResolveContentPath returned error: %v", err)
+	}
+	if want := filepath.Join(downloads, "TestContent"); got != want {
+		t.Errorf("ResolveContentPath = %q, want %q", got, want)
+	}
+
+	result, err := VerifyData(VerifyOptions{TorrentPath: torrentPath, ContentPath: got, Quiet: true})
+	if err != nil {
+		t.Fatalf("VerifyData failed: %v", err)
+	}
+	if result.Completion != 100.0 {
+		t.Errorf("Expected completion 100.0, got %.2f", result.Completion)
+	}
+
+	// fuzzy match: different case and a trailing dot
+	if err := os.Rename(filepath.Join(downloads, "TestContent"), filepath.Join(downloads, "testcontent.")); err != nil {
+		t.Fatalf("failed to rename content: %v", err)
+	}
+	got, err = ResolveContentPath(torrentPath, downloads)
+	if err != nil {
+		t.Fatalf("ResolveContentPath fuzzy match returned error: %v", err)
+	}
+	if want := filepath.Join(downloads, "testcontent."); got != want {
+		t.Errorf("ResolveContentPath fuzzy = %q, want %q", got, want)
+	}
+
+	// two fuzzy candidates are ambiguous
+	if err := os.Mkdir(filepath.Join(downloads, "TESTCONTENT"), 0755); err != nil {
+		t.Fatalf("failed to create second candidate: %v", err)
+	}
+	if _, err := ResolveContentPath(torrentPath, downloads); err == nil {
+		t.Error("expected error for multiple candidates")
+	}
+
+	// content that is not a directory is returned unchanged
+	nfo := filepath.Join(downloads, "TestContent.nfo")
+	if got, err := ResolveContentPath(torrentPath, nfo); err != nil || got != nfo {
+		t.Errorf("ResolveContentPath(file) = %q, %v; want %q", got, err, nfo)
+	}
+}
+
+func TestResolveContentPath_SingleFileWithoutExtension(t *testing.T) {
+	workspace := t.TempDir()
+	source := filepath.Join(workspace, "Movie.Name.mkv")
+	if err := os.WriteFile(source, []byte("movie data"), 0644); err != nil {
+		t.Fatalf("failed to write content file: %v", err)
+	}
+
+	torrentPath := filepath.Join(workspace, "movie.torrent")
+	if _, err := Create(CreateOptions{Path: source, Name: "Movie.Name", OutputPath: torrentPath, NoDate: true, Quiet: true}); err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+
+	got, err := ResolveContentPath(torrentPath, workspace)
+	if err != nil {
+		t.Fatalf("ResolveContentPath returned error: %v", err)
+	}
+	if got != source {
+		t.Errorf("ResolveContentPath = %q, want %q", got, source)
+	}
+}
+
+func TestVerifyData_FuzzyPathMatch(t *testing.T) {
+	workspace := t.TempDir()
+
+	// the content was renamed after the torrent was made as TestDir-v1
+	contentDir := filepath.Join(workspace, "TestDir-v2")
+	if err := os.MkdirAll(contentDir, 0755); err != nil {
+		t.Fatalf("failed to create content dir: %v", err)
+	}
+	for _, name := range []string{"a.mkv", "b.nfo"} {
+		if err := os.WriteFile(filepath.Join(contentDir, name), bytes.Repeat([]byte(name), 40000), 0644); err != nil {
+			t.Fatalf("failed to write content file: %v", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(workspace, "Unrelated"), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+
+	torrentPath := filepath.Join(workspace, "TestDir.torrent")
+	if _, err := Create(CreateOptions{Path: contentDir, Name: "TestDir-v1", OutputPath: torrentPath, NoDate: true, Quiet: true}); err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+
+	missingPath := filepath.Join(workspace, "TestDir")
+	result, err := VerifyData(VerifyOptions{TorrentPath: torrentPath, ContentPath: missingPath, Quiet: true})
+	if err != nil {
+		t.Fatalf("VerifyData failed: %v", err)
+	}
+	if len(result.MissingFiles) != 2 {
+		t.Errorf("expected both files missing without fuzzy matching, got %v", result.MissingFiles)
+	}
+
+	result, err = VerifyData(VerifyOptions{TorrentPath: torrentPath, ContentPath: missingPath, FuzzyPathMatch: true, Quiet: true})
+	if err != nil {
+		t.Fatalf("VerifyData failed: %v", err)
+	}
+	if result.ContentPath != contentDir {
+		t.Errorf("ContentPath = %q, want %q", result.ContentPath, contentDir)
+	}
+	if result.GoodPieces != result.TotalPieces || len(result.MissingFiles) != 0 {
+		t.Errorf("expected every piece to match, got %d of %d good, missing %v", result.GoodPieces, result.TotalPieces, result.MissingFiles)
+	}
+
+	if err := os.Rename(contentDir, filepath.Join(workspace, "Completely-Different")); err != nil {
+		t.Fatalf("failed to rename content: %v", err)
+	}
+	if _, err := VerifyData(VerifyOptions{TorrentPath: torrentPath, ContentPath: missingPath, FuzzyPathMatch: true, Quiet: true}); err == nil {
+		t.Error("expected an error when nothing is close to the torrent name")
+	}
+}
+
+func TestVerifyData_IgnoreName(t *testing.T) {
+	contentDir := t.TempDir()
+	data := bytes.Repeat([]byte("movie"), 50000)
+	if err := os.WriteFile(filepath.Join(contentDir, "Movie.2024.mkv"), data, 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+	torrentPath := filepath.Join(t.TempDir(), "movie.torrent")
+	if _, err := Create(CreateOptions{Path: filepath.Join(contentDir, "Movie.2024.mkv"), OutputPath: torrentPath, NoDate: true, Quiet: true}); err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+
+	// the download was renamed next to an unrelated file of another size
+	if err := os.Rename(filepath.Join(contentDir, "Movie.2024.mkv"), filepath.Join(contentDir, "movie renamed.mkv")); err != nil {
+		t.Fatalf("failed to rename content: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(contentDir, "movie.nfo"), []byte("nfo"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	result, err := VerifyData(VerifyOptions{TorrentPath: torrentPath, ContentPath: contentDir, Quiet: true})
+	if err != nil {
+		t.Fatalf("VerifyData failed: %v", err)
+	}
+	if len(result.MissingFiles) != 1 {
+		t.Errorf("expected the renamed file to be missing without IgnoreName, got %v", result.MissingFiles)
+	}
+
+	result, err = VerifyData(VerifyOptions{TorrentPath: torrentPath, ContentPath: contentDir, IgnoreName: true, Quiet: true})
+	if err != nil {
+		t.Fatalf("VerifyData failed: %v", err)
+	}
+	if result.GoodPieces != result.TotalPieces || len(result.MissingFiles) != 0 {
+		t.Errorf("expected every piece to match, got %d of %d good, missing %v", result.GoodPieces, result.TotalPieces, result.MissingFiles)
+	}
+
+	// two files of the right size leave no way to tell which one is meant
+	if err := os.WriteFile(filepath.Join(contentDir, "copy.mkv"), data, 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := VerifyData(VerifyOptions{TorrentPath: torrentPath, ContentPath: contentDir, IgnoreName: true, Quiet: true}); err == nil || !strings.Contains(err.Error(), "multiple files") {
+		t.Errorf("expected an ambiguity error, got %v", err)
+	}
+}
+
+func TestVerifyData_FindExtraFiles(t *testing.T) {
+	contentDir := filepath.Join(t.TempDir(), "Release")
+	if err := os.MkdirAll(filepath.Join(contentDir, "Subs"), 0755); err != nil {
+		t.Fatalf("failed to create content dir: %v", err)
+	}
+	files := map[string][]byte{
+		"release.mkv":      bytes.Repeat([]byte("m"), 100000),
+		"Subs/english.srt": []byte("subs"),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(contentDir, filepath.FromSlash(name)), data, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	torrentPath := filepath.Join(t.TempDir(), "release.torrent")
+	if _, err := Create(CreateOptions{Path: contentDir, OutputPath: torrentPath, NoDate: true, Quiet: true}); err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+
+	// the torrent file kept inside the content folder is not extraneous
+	inside := filepath.Join(contentDir, "release.torrent")
+	if err := os.Rename(torrentPath, inside); err != nil {
+		t.Fatalf("failed to move torrent: %v", err)
+	}
+	extras := map[string][]byte{
+		"sample.mkv":   []byte("sample"),
+		"Subs/old.srt": []byte("old subs"),
+	}
+	for name, data := range extras {
+		if err := os.WriteFile(filepath.Join(contentDir, filepath.FromSlash(name)), data, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	result, err := VerifyData(VerifyOptions{TorrentPath: inside, ContentPath: contentDir, Quiet: true})
+	if err != nil {
+		t.Fatalf("VerifyData failed: %v", err)
+	}
+	if len(result.ExtraFiles) != 0 {
+		t.Errorf("expected no extra files without FindExtraFiles, got %v", result.ExtraFiles)
+	}
+
+	result, err = VerifyData(VerifyOptions{TorrentPath: inside, ContentPath: contentDir, FindExtraFiles: true, Quiet: true})
+	if err != nil {
+		t.Fatalf("VerifyData failed: %v", err)
+	}
+	if result.GoodPieces != result.TotalPieces || len(result.MissingFiles) != 0 {
+		t.Errorf("expected every piece to match, got %d of %d good, missing %v", result.GoodPieces, result.TotalPieces, result.MissingFiles)
+	}
+	want := []ExtraFile{{Path: "Subs/old.srt", Size: 8}, {Path: "sample.mkv", Size: 6}}
+	if fmt.Sprint(result.ExtraFiles) != fmt.Sprint(want) {
+		t.Errorf("ExtraFiles = %v, want %v", result.ExtraFiles, want)
+	}
+}
+
+func TestVerifyData_FindExtraFiles_SingleFileInDir(t *testing.T) {
+	contentDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(contentDir, "movie.mkv"), bytes.Repeat([]byte("m"), 100000), 0644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+	torrentPath := filepath.Join(contentDir, "movie.torrent")
+	if _, err := Create(CreateOptions{Path: filepath.Join(contentDir, "movie.mkv"), OutputPath: torrentPath, NoDate: true, Quiet: true}); err != nil {
+		t.Fatalf("failed to create torrent: %v", err)
+	}
+	// unrelated siblings of a single file are not part of its content
+	if err := os.WriteFile(filepath.Join(contentDir, "other.mkv"), []byte("other"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	result, err := VerifyData(VerifyOptions{TorrentPath: torrentPath, ContentPath: contentDir, FindExtraFiles: true, Quiet: true})
+	if err != nil {
+		t.Fatalf("VerifyData failed: %v", err)
+	}
+	if result.GoodPieces != result.TotalPieces {
+		t.Errorf("expected every piece to match, got %d of %d good", result.GoodPieces, result.TotalPieces)
+	}
+	if len(result.ExtraFiles) != 0 {
+		t.Errorf("expected no extra files for a single-file torrent, got %v", result.ExtraFiles)
+	}
+}
@@ -0,0 +1,121 @@
+package torrent
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Report formats supported by WriteVerifyReport
+const (
+	ReportFormatJSON = "json" // the whole VerifyReport as one JSON document
+	ReportFormatCSV  = "csv"  // one row per file with its status
+)
+
+// VerifyReportVersion is the version of the JSON report written by
+// WriteVerifyReport, raised whenever a field changes meaning or is removed
+const VerifyReportVersion = 1
+
+// VerifyReport is a VerificationResult with the context of the check, as
+// written by WriteVerifyReport
+type VerifyReport struct {
+	Version         int                `json:"version"`
+	TorrentPath     string             `json:"torrent_path,omitempty"`
+	PiecesPath      string             `json:"pieces_path,omitempty"` // piece export verified against instead of a torrent
+	ContentPath     string             `json:"content_path"`
+	Timestamp       time.Time          `json:"timestamp"`
+	DurationSeconds float64            `json:"duration_seconds"`
+	TotalPieces     int                `json:"total_pieces"`
+	GoodPieces      int                `json:"good_pieces"`
+	BadPieces       int                `json:"bad_pieces"`
+	MissingPieces   int                `json:"missing_pieces"`
+	Completion      float64            `json:"completion"` // percentage of the checked pieces that matched
+	BadPieceIndices []int              `json:"bad_piece_indices"`
+	MissingFiles    []string           `json:"missing_files"`
+	RestoredAttrs   []string           `json:"restored_attrs,omitempty"`
+	ExtraFiles      []ExtraFile        `json:"extra_files,omitempty"` // files not in the torrent, when looked for
+	Files           []FileVerification `json:"files"`
+}
+
+// NewVerifyReport builds the report of a check started at start that took
+// duration, with the paths taken from opts.
+func NewVerifyReport(opts VerifyOptions, result *VerificationResult, start time.Time, duration time.Duration) *VerifyReport {
+	report := &VerifyReport{
+		Version:         VerifyReportVersion,
+		TorrentPath:     opts.TorrentPath,
+		PiecesPath:      opts.PiecesPath,
+		ContentPath:     opts.ContentPath,
+		Timestamp:       start.UTC(),
+		DurationSeconds: duration.Seconds(),
+		TotalPieces:     result.TotalPieces,
+		GoodPieces:      result.GoodPieces,
+		BadPieces:       result.BadPieces,
+		MissingPieces:   result.MissingPieces,
+		Completion:      result.Completion,
+		BadPieceIndices: result.BadPieceIndices,
+		MissingFiles:    result.MissingFiles,
+		RestoredAttrs:   result.RestoredAttrs,
+		ExtraFiles:      result.ExtraFiles,
+		Files:           result.Files,
+	}
+	if result.ContentPath != "" {
+		report.ContentPath = result.ContentPath
+	}
+	// write empty lists as [] rather than null
+	if report.BadPieceIndices == nil {
+		report.BadPieceIndices = []int{}
+	}
+	if report.MissingFiles == nil {
+		report.MissingFiles = []string{}
+	}
+	if report.Files == nil {
+		report.Files = []FileVerification{}
+	}
+	return report
+}
+
+// ReportFormatFromPath returns the report format matching the extension of
+// path, ReportFormatJSON for .json and ReportFormatCSV for .csv.
+func ReportFormatFromPath(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ReportFormatJSON, nil
+	case ".csv":
+		return ReportFormatCSV, nil
+	default:
+		return "", fmt.Errorf("unsupported report file %q: the extension must be .json or .csv", path)
+	}
+}
+
+// WriteVerifyReport writes report to w in the given format, one of the
+// ReportFormat* constants. The CSV format has a header row followed by one
+// row per file: path, size, status and bad_pieces.
+func WriteVerifyReport(w io.Writer, report *VerifyReport, format string) error {
+	switch format {
+	case ReportFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		return nil
+	case ReportFormatCSV:
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"path", "size", "status", "bad_pieces"})
+		for _, f := range report.Files {
+			_ = cw.Write([]string{f.Path, strconv.FormatInt(f.Size, 10), f.Status, strconv.Itoa(f.BadPieces)})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid report format %q: must be %q or %q", format, ReportFormatJSON, ReportFormatCSV)
+	}
+}
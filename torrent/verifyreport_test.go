@@ -0,0 +1,118 @@
+package torrent
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// verifyReportFixture creates a torrent with 64 KiB pieces, then corrupts
+// a.bin and deletes d.bin, which shares its first piece with c.bin.
+func verifyReportFixture(t *testing.T) (VerifyOptions, *VerificationResult) {
+	t.Helper()
+
+	files := map[string][]byte{
+		"a.bin": bytes.Repeat([]byte("a"), 2<<16),
+		"b.bin": bytes.Repeat([]byte("b"), 1<<16),
+		"c.bin": bytes.Repeat([]byte("c"), 100),
+		"d.bin": bytes.Repeat([]byte("d"), 1<<16),
+	}
+	contentDir, torrentPath := writeReleaseFixture(t, t.TempDir(), files, CreateOptions{})
+
+	corrupted := files["a.bin"]
+	corrupted[10] = 'x'
+	if err := os.WriteFile(filepath.Join(contentDir, "a.bin"), corrupted, 0644); err != nil {
+		t.Fatalf("failed to corrupt a.bin: %v", err)
+	}
+	if err := os.Remove(filepath.Join(contentDir, "d.bin")); err != nil {
+		t.Fatalf("failed to remove d.bin: %v", err)
+	}
+
+	opts := VerifyOptions{TorrentPath: torrentPath, ContentPath: contentDir, Quiet: true}
+	result, err := VerifyData(opts)
+	if err != nil {
+		t.Fatalf("VerifyData failed: %v", err)
+	}
+	return opts, result
+}
+
+func TestVerifyData_Files(t *testing.T) {
+	_, result := verifyReportFixture(t)
+
+	want := []FileVerification{
+		{Path: "a.bin", Size: 2 << 16, Status: FileStatusBad, BadPieces: 1},
+		{Path: "b.bin", Size: 1 << 16, Status: FileStatusOK},
+		{Path: "c.bin", Size: 100, Status: FileStatusUnverified},
+		{Path: "d.bin", Size: 1 << 16, Status: FileStatusMissing},
+	}
+	if !reflect.DeepEqual(result.Files, want) {
+		t.Errorf("Files = %+v, want %+v", result.Files, want)
+	}
+}
+
+func TestWriteVerifyReport_JSON(t *testing.T) {
+	opts, result := verifyReportFixture(t)
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	report := NewVerifyReport(opts, result, start, 1500*time.Millisecond)
+
+	var buf bytes.Buffer
+	if err := WriteVerifyReport(&buf, report, ReportFormatJSON); err != nil {
+		t.Fatalf("WriteVerifyReport failed: %v", err)
+	}
+
+	var got VerifyReport
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal report: %v", err)
+	}
+	if !reflect.DeepEqual(&got, report) {
+		t.Errorf("report = %+v, want %+v", got, *report)
+	}
+	if got.Version != VerifyReportVersion || got.DurationSeconds != 1.5 || got.TorrentPath != opts.TorrentPath {
+		t.Errorf("unexpected report header: %+v", got)
+	}
+}
+
+func TestWriteVerifyReport_CSV(t *testing.T) {
+	opts, result := verifyReportFixture(t)
+	report := NewVerifyReport(opts, result, time.Now(), time.Second)
+
+	var buf bytes.Buffer
+	if err := WriteVerifyReport(&buf, report, ReportFormatCSV); err != nil {
+		t.Fatalf("WriteVerifyReport failed: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	want := [][]string{
+		{"path", "size", "status", "bad_pieces"},
+		{"a.bin", "131072", "bad", "1"},
+		{"b.bin", "65536", "ok", "0"},
+		{"c.bin", "100", "unverified", "0"},
+		{"d.bin", "65536", "missing", "0"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %v, want %v", rows, want)
+	}
+}
+
+func TestReportFormatFromPath(t *testing.T) {
+	for path, want := range map[string]string{
+		"report.json":     ReportFormatJSON,
+		"out/Report.JSON": ReportFormatJSON,
+		"report.csv":      ReportFormatCSV,
+	} {
+		got, err := ReportFormatFromPath(path)
+		if err != nil || got != want {
+			t.Errorf("ReportFormatFromPath(%q) = %q, %v, want %q", path, got, err, want)
+		}
+	}
+	if _, err := ReportFormatFromPath("report.txt"); err == nil {
+		t.Error("expected an error for a .txt report")
+	}
+}